@@ -18,17 +18,21 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 
 	// Call init function for the providers
 	_ "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/apisix"
@@ -39,9 +43,6 @@ import (
 	_ "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/kong"
 	_ "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/nginx"
 	_ "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/openapi3"
-
-	// Call init for notifications
-	_ "github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 )
 
 type PrintRunner struct {
@@ -72,6 +73,62 @@ type PrintRunner struct {
 
 	// Provider specific flags --<provider>-<flag>.
 	providerSpecificFlags map[string]*string
+
+	// diff indicates whether to report the changes against the Gateway API
+	// resources in the cluster instead of printing the generated resources.
+	// Value assigned via --diff flag.
+	diff bool
+
+	// dumpIRFile is the path of the file the intermediate representation is
+	// written to for debugging. Value assigned via --dump-ir flag.
+	dumpIRFile string
+
+	// outputSplitBy groups the printed resources into bundles. Value assigned
+	// via --output-split-by flag.
+	outputSplitBy string
+
+	// gatewayAPIVersion is the Gateway API release the printed resources
+	// target. Value assigned via --gateway-api-version flag.
+	gatewayAPIVersion string
+
+	// annotationPrefix replaces the ingress2gateway.kubernetes.io prefix of the
+	// annotations of the printed resources. Value assigned via
+	// --annotation-prefix flag.
+	annotationPrefix string
+
+	// checkCRDs indicates whether to adapt the printed resources to the Gateway
+	// API CRDs installed in the cluster. Value assigned via --check-crds flag.
+	checkCRDs bool
+
+	// emitKustomization is the directory the bundles of --output-split-by are
+	// written to, each to its own file, along with a kustomization.yaml
+	// listing them. Value assigned via --emit-kustomization flag.
+	emitKustomization string
+
+	// listAnnotations indicates whether to print the annotations supported by
+	// the providers instead of converting resources. Value assigned via
+	// --list-annotations flag.
+	listAnnotations bool
+
+	// failOnBlocker indicates whether to fail when the providers report
+	// migration blockers, for use in CI pipelines. Value assigned via
+	// --fail-on-blocker flag.
+	failOnBlocker bool
+}
+
+// outputSplitByClass prints the resources of each ingress class as a separate
+// bundle.
+const outputSplitByClass = "class"
+
+// kustomizationFileName is the name of the kustomization written by
+// --emit-kustomization.
+const kustomizationFileName = "kustomization.yaml"
+
+// kustomization is the kustomization.yaml listing the bundle files.
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
 }
 
 // PrintGatewayAPIObjects performs necessary steps to digest and print
@@ -79,6 +136,9 @@ type PrintRunner struct {
 // construct ingresses and provider-specific resources, convert them, then print
 // the Gateway API objects out.
 func (pr *PrintRunner) PrintGatewayAPIObjects(cmd *cobra.Command, _ []string) error {
+	if pr.listAnnotations {
+		return pr.printSupportedAnnotations()
+	}
 	err := pr.initializeResourcePrinter()
 	if err != nil {
 		return fmt.Errorf("failed to initialize resrouce printer: %w", err)
@@ -87,8 +147,25 @@ func (pr *PrintRunner) PrintGatewayAPIObjects(cmd *cobra.Command, _ []string) er
 	if err != nil {
 		return fmt.Errorf("failed to initialize namespace filter: %w", err)
 	}
+	if pr.outputSplitBy != "" && pr.outputSplitBy != outputSplitByClass {
+		return fmt.Errorf("%s is not a supported --output-split-by value, only %q is supported", pr.outputSplitBy, outputSplitByClass)
+	}
+	if pr.emitKustomization != "" {
+		if pr.outputSplitBy == "" {
+			return fmt.Errorf("--emit-kustomization requires --output-split-by")
+		}
+		if pr.outputFormat == "json" {
+			return fmt.Errorf("--emit-kustomization requires the yaml or kyaml output format")
+		}
+	}
+	if err = i2gw.ValidateGatewayAPIVersion(pr.gatewayAPIVersion); err != nil {
+		return err
+	}
+	if err = i2gw.ValidateAnnotationPrefix(pr.annotationPrefix); err != nil {
+		return err
+	}
 
-	gatewayResources, notificationTablesMap, err := i2gw.ToGatewayAPIResources(cmd.Context(), pr.namespaceFilter, pr.inputFile, pr.providers, pr.getProviderSpecificFlags())
+	gatewayResources, notificationTablesMap, err := i2gw.ToGatewayAPIResources(cmd.Context(), pr.namespaceFilter, kubeContext, pr.inputFile, pr.dumpIRFile, pr.checkCRDs, pr.providers, pr.getProviderSpecificFlags())
 	if err != nil {
 		return err
 	}
@@ -97,21 +174,136 @@ func (pr *PrintRunner) PrintGatewayAPIObjects(cmd *cobra.Command, _ []string) er
 		fmt.Fprintln(os.Stderr, table)
 	}
 
-	pr.outputResult(gatewayResources)
+	if err = i2gw.SetGatewayAPIVersion(gatewayResources, pr.gatewayAPIVersion); err != nil {
+		return err
+	}
+
+	if pr.outputSplitBy == "" {
+		// The ingress class annotation is only read to split the resources
+		i2gw.RemoveSourceIngressClassAnnotations(gatewayResources)
+	}
+
+	if pr.diff {
+		// Compare the resources as they would be printed
+		if err = i2gw.SetAnnotationPrefix(gatewayResources, pr.annotationPrefix); err != nil {
+			return err
+		}
+		existing, err := i2gw.ReadExistingGatewayResources(cmd.Context(), pr.namespaceFilter, kubeContext)
+		if err != nil {
+			return fmt.Errorf("failed to read existing Gateway API resources: %w", err)
+		}
+		fmt.Print(i2gw.Diff(gatewayResources, existing).Summary())
+		return pr.checkBlockers(cmd)
+	}
+
+	if err = pr.outputResult(gatewayResources); err != nil {
+		return err
+	}
+	return pr.checkBlockers(cmd)
+}
 
+// checkBlockers returns an error summarizing the migration blockers reported
+// by the providers if --fail-on-blocker is set. The resources are still
+// printed, so the blockers can be reviewed along with them.
+func (pr *PrintRunner) checkBlockers(cmd *cobra.Command) error {
+	if !pr.failOnBlocker {
+		return nil
+	}
+	blockers := notifications.NotificationAggr.Blockers()
+	if len(blockers) == 0 {
+		return nil
+	}
+	// The usage does not help with blockers in the input
+	cmd.SilenceUsage = true
+	return fmt.Errorf("found %d migration blocker(s):\n%s", len(blockers), notifications.BlockerSummary(blockers))
+}
+
+func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) error {
+	resourceCount := 0
+	switch {
+	case pr.outputSplitBy == outputSplitByClass && pr.emitKustomization != "":
+		count, err := pr.writeKustomization(i2gw.SplitByIngressClass(gatewayResources))
+		if err != nil {
+			return err
+		}
+		resourceCount = count
+	case pr.outputSplitBy == outputSplitByClass:
+		for _, bundle := range i2gw.SplitByIngressClass(gatewayResources) {
+			fmt.Printf("# Bundle: ingress class %s\n", bundle.Name)
+			resourceCount += pr.printResources(os.Stdout, []i2gw.GatewayResources{bundle.Resources})
+		}
+	default:
+		resourceCount = pr.printResources(os.Stdout, gatewayResources)
+	}
+
+	if resourceCount == 0 {
+		msg := "No resources found"
+		if pr.namespaceFilter != "" {
+			msg = fmt.Sprintf("%s in %s namespace", msg, pr.namespaceFilter)
+		}
+		fmt.Println(msg)
+	}
 	return nil
 }
 
-func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
+// writeKustomization writes each bundle to <bundle>.yaml in the
+// --emit-kustomization directory, and a kustomization.yaml listing them in
+// order, so they can be applied with kubectl apply -k. It returns how many
+// resources were written.
+func (pr *PrintRunner) writeKustomization(bundles []i2gw.ResourceBundle) (int, error) {
+	if err := os.MkdirAll(pr.emitKustomization, 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create the kustomization directory: %w", err)
+	}
+
 	resourceCount := 0
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{},
+	}
+	for _, bundle := range bundles {
+		fileName := bundle.Name + ".yaml"
+		f, err := os.Create(filepath.Join(pr.emitKustomization, fileName))
+		if err != nil {
+			return resourceCount, fmt.Errorf("failed to create the file of bundle %s: %w", bundle.Name, err)
+		}
+		fmt.Fprintf(f, "# Bundle: ingress class %s\n", bundle.Name)
+		resourceCount += pr.printResources(f, []i2gw.GatewayResources{bundle.Resources})
+		if err := f.Close(); err != nil {
+			return resourceCount, fmt.Errorf("failed to write the file of bundle %s: %w", bundle.Name, err)
+		}
+		k.Resources = append(k.Resources, fileName)
+	}
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		return resourceCount, fmt.Errorf("failed to marshal the kustomization: %w", err)
+	}
+	kustomizationPath := filepath.Join(pr.emitKustomization, kustomizationFileName)
+	if err := os.WriteFile(kustomizationPath, data, 0o644); err != nil {
+		return resourceCount, fmt.Errorf("failed to write the kustomization: %w", err)
+	}
+	fmt.Printf("# Wrote %d bundles and %s to %s\n", len(bundles), kustomizationFileName, pr.emitKustomization)
+	return resourceCount, nil
+}
+
+// printResources prints the resources to w and returns how many were printed.
+func (pr *PrintRunner) printResources(w io.Writer, gatewayResources []i2gw.GatewayResources) int {
+	resourceCount := 0
+
+	// Applied per bundle, since splitting by class reads the annotations
+	if err := i2gw.SetAnnotationPrefix(gatewayResources, pr.annotationPrefix); err != nil {
+		fmt.Fprintf(w, "# Error setting the annotation prefix: %v\n", err)
+		return resourceCount
+	}
 
 	for _, r := range gatewayResources {
 		resourceCount += len(r.GatewayClasses)
 		for _, gatewayClass := range r.GatewayClasses {
 			gatewayClass := gatewayClass
-			err := pr.resourcePrinter.PrintObj(&gatewayClass, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&gatewayClass, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s GatewayClass: %v\n", gatewayClass.Name, err)
+				fmt.Fprintf(w, "# Error printing %s GatewayClass: %v\n", gatewayClass.Name, err)
 			}
 		}
 	}
@@ -124,9 +316,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				gateway.Annotations = make(map[string]string)
 			}
 			gateway.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&gateway, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&gateway, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s Gateway: %v\n", gateway.Name, err)
+				fmt.Fprintf(w, "# Error printing %s Gateway: %v\n", gateway.Name, err)
 			}
 		}
 	}
@@ -139,9 +331,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				httpRoute.Annotations = make(map[string]string)
 			}
 			httpRoute.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&httpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&httpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s HTTPRoute: %v\n", httpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s HTTPRoute: %v\n", httpRoute.Name, err)
 			}
 		}
 	}
@@ -154,9 +346,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				grpcRoute.Annotations = make(map[string]string)
 			}
 			grpcRoute.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&grpcRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&grpcRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s GRPCRoute: %v\n", grpcRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s GRPCRoute: %v\n", grpcRoute.Name, err)
 			}
 		}
 	}
@@ -169,9 +361,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				tlsRoute.Annotations = make(map[string]string)
 			}
 			tlsRoute.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&tlsRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&tlsRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s TLSRoute: %v\n", tlsRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s TLSRoute: %v\n", tlsRoute.Name, err)
 			}
 		}
 	}
@@ -184,9 +376,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				tcpRoute.Annotations = make(map[string]string)
 			}
 			tcpRoute.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&tcpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&tcpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s TCPRoute: %v\n", tcpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s TCPRoute: %v\n", tcpRoute.Name, err)
 			}
 		}
 	}
@@ -199,9 +391,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				udpRoute.Annotations = make(map[string]string)
 			}
 			udpRoute.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&udpRoute, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&udpRoute, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s UDPRoute: %v\n", udpRoute.Name, err)
+				fmt.Fprintf(w, "# Error printing %s UDPRoute: %v\n", udpRoute.Name, err)
 			}
 		}
 	}
@@ -214,9 +406,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				backendTLSPolicy.Annotations = make(map[string]string)
 			}
 			backendTLSPolicy.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&backendTLSPolicy, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&backendTLSPolicy, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s BackendTLSPolicy: %v\n", backendTLSPolicy.Name, err)
+				fmt.Fprintf(w, "# Error printing %s BackendTLSPolicy: %v\n", backendTLSPolicy.Name, err)
 			}
 		}
 	}
@@ -229,9 +421,9 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 				referenceGrant.Annotations = make(map[string]string)
 			}
 			referenceGrant.Annotations[i2gw.GeneratorAnnotationKey] = fmt.Sprintf("ingress2gateway-%s", i2gw.Version)
-			err := pr.resourcePrinter.PrintObj(&referenceGrant, os.Stdout)
+			err := pr.resourcePrinter.PrintObj(&referenceGrant, w)
 			if err != nil {
-				fmt.Printf("# Error printing %s ReferenceGrant: %v\n", referenceGrant.Name, err)
+				fmt.Fprintf(w, "# Error printing %s ReferenceGrant: %v\n", referenceGrant.Name, err)
 			}
 		}
 	}
@@ -240,20 +432,14 @@ func (pr *PrintRunner) outputResult(gatewayResources []i2gw.GatewayResources) {
 		resourceCount += len(r.GatewayExtensions)
 		for _, gatewayExtension := range r.GatewayExtensions {
 			gatewayExtension := gatewayExtension
-			fmt.Println("---")
-			if err := PrintUnstructuredAsYaml(&gatewayExtension); err != nil {
-				fmt.Printf("# Error printing %s gatewayExtension: %v\n", gatewayExtension.GetName(), err)
+			fmt.Fprintln(w, "---")
+			if err := PrintUnstructuredAsYaml(&gatewayExtension, w); err != nil {
+				fmt.Fprintf(w, "# Error printing %s gatewayExtension: %v\n", gatewayExtension.GetName(), err)
 			}
 		}
 	}
 
-	if resourceCount == 0 {
-		msg := "No resources found"
-		if pr.namespaceFilter != "" {
-			msg = fmt.Sprintf("%s in %s namespace", msg, pr.namespaceFilter)
-		}
-		fmt.Println(msg)
-	}
+	return resourceCount
 }
 
 // initializeResourcePrinter assign a specific type of printers.ResourcePrinter
@@ -289,7 +475,7 @@ func (pr *PrintRunner) initializeNamespaceFilter() error {
 
 	// If namespace flag is not specified, try to use the default namespace from the cluster
 	if pr.namespace == "" {
-		ns, err := getNamespaceInCurrentContext()
+		ns, err := getNamespaceInContext(kubeContext)
 		if err != nil && pr.inputFile == "" {
 			// When asked to read from the cluster, but getting the current namespace
 			// failed for whatever reason - do not process the request.
@@ -336,6 +522,40 @@ func newPrintCommand() *cobra.Command {
 		`If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even
 if specified with --namespace.`)
 
+	cmd.Flags().BoolVar(&pr.diff, "diff", false,
+		`If present, compare the generated Gateways and HTTPRoutes against those in the cluster and report
+the resources to add, update and delete instead of printing them.`)
+
+	cmd.Flags().StringVar(&pr.dumpIRFile, "dump-ir", "",
+		`If present, write the intermediate representation produced by each provider to this file for debugging.
+Files with a .json extension are written as JSON, all others as YAML.`)
+
+	cmd.Flags().StringVar(&pr.outputSplitBy, "output-split-by", "",
+		fmt.Sprintf(`If set, print the resources grouped into bundles, each starting with a "# Bundle:" comment. Only %q is supported: the resources of each ingress class go to their own bundle, and resources shared by several classes to the %q bundle.`, outputSplitByClass, i2gw.SharedBundleName))
+
+	cmd.Flags().StringVar(&pr.emitKustomization, "emit-kustomization", "",
+		fmt.Sprintf(`If set with --output-split-by, write each bundle to <bundle>.yaml in this directory instead of printing it,
+along with a %s listing the bundle files, so they can be applied with kubectl apply -k.`, kustomizationFileName))
+
+	cmd.Flags().StringVar(&pr.gatewayAPIVersion, "gateway-api-version", i2gw.DefaultGatewayAPIVersion,
+		fmt.Sprintf("The Gateway API release installed in the cluster. The apiVersion of the printed resources is set to the one served by this release, e.g. v1alpha3 for BackendTLSPolicy before v1.4. Supported values are %v.", i2gw.SupportedGatewayAPIVersions()))
+
+	cmd.Flags().StringVar(&pr.annotationPrefix, "annotation-prefix", i2gw.DefaultAnnotationPrefix,
+		"The prefix of the annotations recording the provenance of the printed resources, such as <prefix>/source on EnvoyFilters, HTTPRoutes and ReferenceGrants.")
+
+	cmd.Flags().BoolVar(&pr.checkCRDs, "check-crds", false,
+		`If present, query the cluster for the installed Gateway API CRDs and adapt the printed resources to them:
+kinds that are not installed are skipped, BackendTLSPolicies are printed as v1alpha3 if v1 is not served, and experimental
+HTTPRoute fields are dropped without the experimental channel, each with a warning. Cannot be used with --input-file.`)
+
+	cmd.Flags().BoolVar(&pr.listAnnotations, "list-annotations", false,
+		`If present, print the annotations supported by the providers, with their migration status and the
+resource they are converted to, instead of converting resources.`)
+
+	cmd.Flags().BoolVar(&pr.failOnBlocker, "fail-on-blocker", false,
+		`If present, exit with a non-zero status after printing the resources when the providers report
+migration blockers (ERROR notifications), and list them.`)
+
 	cmd.Flags().StringSliceVar(&pr.providers, "providers", []string{},
 		fmt.Sprintf("If present, the tool will try to convert only resources related to the specified providers, supported values are %v.", i2gw.GetSupportedProviders()))
 
@@ -352,14 +572,29 @@ if specified with --namespace.`)
 	return cmd
 }
 
-// getNamespaceInCurrentContext returns the namespace in the current active context of the user.
-func getNamespaceInCurrentContext() (string, error) {
+// printSupportedAnnotations prints the annotations supported by each of the
+// providers, with their migration status.
+func (pr *PrintRunner) printSupportedAnnotations() error {
+	for _, provider := range pr.providers {
+		annotations, ok := i2gw.GetSupportedAnnotations(i2gw.ProviderName(provider))
+		if !ok {
+			return fmt.Errorf("provider %s does not list its supported annotations", provider)
+		}
+		fmt.Printf("# Provider: %s\n", provider)
+		fmt.Print(i2gw.FormatSupportedAnnotations(annotations))
+	}
+	return nil
+}
+
+// getNamespaceInContext returns the namespace in the given kubeconfig context of the user.
+// An empty context selects the current active context.
+func getNamespaceInContext(kubeContext string) (string, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
-	currentNamespace, _, err := kubeConfig.Namespace()
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	namespace, _, err := kubeConfig.Namespace()
 
-	return currentNamespace, err
+	return namespace, err
 }
 
 // getProviderSpecificFlags returns the provider specific flags input by the user.
@@ -380,7 +615,7 @@ func (pr *PrintRunner) getProviderSpecificFlags() map[string]map[string]string {
 	return providerSpecificFlags
 }
 
-func PrintUnstructuredAsYaml(obj *unstructured.Unstructured) error {
+func PrintUnstructuredAsYaml(obj *unstructured.Unstructured, w io.Writer) error {
 	// Create a YAML serializer
 	serializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil,
 		json.SerializerOptions{
@@ -390,7 +625,7 @@ func PrintUnstructuredAsYaml(obj *unstructured.Unstructured) error {
 		})
 
 	// Encode the unstructured object to YAML
-	err := serializer.Encode(obj, os.Stdout)
+	err := serializer.Encode(obj, w)
 	if err != nil {
 		return err
 	}
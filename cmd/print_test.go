@@ -17,15 +17,24 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/printers"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_getResourcePrinter(t *testing.T) {
@@ -245,22 +254,42 @@ preferences: {}
 	return cleanupFunc, nil
 }
 
-func Test_getNamespaceInCurrentContext(t *testing.T) {
+func Test_getNamespaceInContext(t *testing.T) {
 	destroy, err := setupKubeConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer destroy()
 
-	expectedNamespace := "non-default-ns" // according to the kube-config at setupKubeConfig()
-	actualNamespace, err := getNamespaceInCurrentContext()
-	if err != nil {
-		t.Fatalf("Expected no error but got %v", err)
+	testCases := []struct {
+		name              string
+		kubeContext       string
+		expectedNamespace string
+	}{
+		{
+			name:              "current context",
+			kubeContext:       "",
+			expectedNamespace: "non-default-ns", // according to the kube-config at setupKubeConfig()
+		},
+		{
+			name:              "selected context without namespace",
+			kubeContext:       "kind-i2gw",
+			expectedNamespace: "default",
+		},
 	}
 
-	if expectedNamespace != actualNamespace {
-		t.Errorf(`getNamespaceInCurrentContext() = "%s", %v, expected %s, %v`,
-			actualNamespace, err, expectedNamespace, nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualNamespace, err := getNamespaceInContext(tc.kubeContext)
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+
+			if tc.expectedNamespace != actualNamespace {
+				t.Errorf(`getNamespaceInContext(%q) = "%s", %v, expected %s, %v`,
+					tc.kubeContext, actualNamespace, err, tc.expectedNamespace, nil)
+			}
+		})
 	}
 }
 
@@ -320,3 +349,159 @@ func Test_getProviderSpecificFlags(t *testing.T) {
 		})
 	}
 }
+
+func Test_emitKustomization(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bundles")
+	pr := &PrintRunner{
+		outputFormat:      "yaml",
+		outputSplitBy:     outputSplitByClass,
+		emitKustomization: dir,
+		annotationPrefix:  i2gw.DefaultAnnotationPrefix,
+	}
+	if err := pr.initializeResourcePrinter(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gatewayResources := []i2gw.GatewayResources{{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			{Namespace: "default", Name: "nginx"}: {
+				TypeMeta: metav1.TypeMeta{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "nginx",
+					Namespace:   "default",
+					Annotations: map[string]string{i2gw.SourceIngressClassAnnotationKey: "nginx"},
+				},
+			},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			{Namespace: "default", Name: "internal"}: {
+				TypeMeta: metav1.TypeMeta{APIVersion: "gateway.networking.k8s.io/v1", Kind: "HTTPRoute"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "internal",
+					Namespace:   "default",
+					Annotations: map[string]string{i2gw.SourceIngressClassAnnotationKey: "internal"},
+				},
+			},
+		},
+		GatewayClasses: map[types.NamespacedName]gatewayv1.GatewayClass{
+			{Name: "istio"}: {
+				TypeMeta:   metav1.TypeMeta{APIVersion: "gateway.networking.k8s.io/v1", Kind: "GatewayClass"},
+				ObjectMeta: metav1.ObjectMeta{Name: "istio"},
+			},
+		},
+	}}
+	if err := pr.outputResult(gatewayResources); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, kustomizationFileName))
+	if err != nil {
+		t.Fatalf("failed to read the kustomization: %v", err)
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		t.Fatalf("failed to parse the kustomization: %v", err)
+	}
+	expected := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{"shared.yaml", "internal.yaml", "nginx.yaml"},
+	}
+	if diff := cmp.Diff(expected, k); diff != "" {
+		t.Errorf("unexpected kustomization (-want +got):\n%s", diff)
+	}
+
+	// Each file listed in the kustomization holds the resources of its bundle
+	expectedKinds := map[string]string{
+		"shared.yaml":   "kind: GatewayClass",
+		"internal.yaml": "kind: HTTPRoute",
+		"nginx.yaml":    "kind: Gateway",
+	}
+	for _, resource := range k.Resources {
+		content, err := os.ReadFile(filepath.Join(dir, resource))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", resource, err)
+		}
+		if !strings.Contains(string(content), expectedKinds[resource]+"\n") {
+			t.Errorf("expected %s to contain %q, got:\n%s", resource, expectedKinds[resource], content)
+		}
+	}
+}
+
+func Test_failOnBlocker(t *testing.T) {
+	inputFile := filepath.Join(t.TempDir(), "ingress.yaml")
+	ingress := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: snippet
+  namespace: default
+  annotations:
+    nginx.ingress.kubernetes.io/server-snippet: |
+      location /internal { deny all; }
+spec:
+  ingressClassName: nginx
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 80
+`
+	if err := os.WriteFile(inputFile, []byte(ingress), 0o600); err != nil {
+		t.Fatalf("failed to write the input file: %v", err)
+	}
+
+	testCases := []struct {
+		name          string
+		failOnBlocker bool
+		expectError   bool
+	}{
+		{
+			name: "blockers are only reported by default",
+		},
+		{
+			name:          "blockers fail the conversion with --fail-on-blocker",
+			failOnBlocker: true,
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications = map[string][]notifications.Notification{}
+			ingressClass := "nginx"
+			pr := &PrintRunner{
+				outputFormat:      "yaml",
+				inputFile:         inputFile,
+				providers:         []string{"ingress-nginx"},
+				gatewayAPIVersion: i2gw.DefaultGatewayAPIVersion,
+				annotationPrefix:  i2gw.DefaultAnnotationPrefix,
+				failOnBlocker:     tc.failOnBlocker,
+				providerSpecificFlags: map[string]*string{
+					"ingress-nginx-ingress-class": &ingressClass,
+				},
+			}
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+
+			err := pr.PrintGatewayAPIObjects(cmd, nil)
+			if !tc.expectError {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error for the server-snippet blocker")
+			}
+			if !strings.Contains(err.Error(), "UnsupportedSnippet") || !strings.Contains(err.Error(), "Ingress: default/snippet") {
+				t.Errorf("expected the error to list the server-snippet blocker, got: %v", err)
+			}
+		})
+	}
+}
@@ -25,6 +25,9 @@ import (
 // kubeconfig indicates kubeconfig file location.
 var kubeconfig string
 
+// kubeContext indicates the kubeconfig context to use. Empty selects the current context.
+var kubeContext string
+
 func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "ingress2gateway",
@@ -36,6 +39,8 @@ func newRootCmd() *cobra.Command {
 
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "",
 		`The kubeconfig file to use when talking to the cluster. If the flag is not set, a set of standard locations can be searched for an existing kubeconfig file.`)
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "",
+		`The name of the kubeconfig context to use when talking to the cluster. If the flag is not set, the current context is used.`)
 	return rootCmd
 }
 
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultAnnotationPrefix is the prefix of the annotations recording the
+// provenance of the generated resources, such as
+// ingress2gateway.kubernetes.io/source.
+const DefaultAnnotationPrefix = "ingress2gateway.kubernetes.io"
+
+// ValidateAnnotationPrefix returns an error if the prefix is not a valid
+// annotation prefix, a DNS subdomain.
+func ValidateAnnotationPrefix(prefix string) error {
+	if errs := validation.IsDNS1123Subdomain(prefix); len(errs) > 0 {
+		return fmt.Errorf("%s is not a valid annotation prefix: %s", prefix, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// SetAnnotationPrefix replaces DefaultAnnotationPrefix with the given prefix in
+// the annotations of the generated resources. It must be applied after the
+// resources are split with SplitByIngressClass, which reads the
+// SourceIngressClassAnnotationKey annotation.
+func SetAnnotationPrefix(gatewayResources []GatewayResources, prefix string) error {
+	if prefix == DefaultAnnotationPrefix {
+		return nil
+	}
+	if err := ValidateAnnotationPrefix(prefix); err != nil {
+		return err
+	}
+
+	for _, r := range gatewayResources {
+		for key, gateway := range r.Gateways {
+			gateway.Annotations = prefixAnnotations(gateway.Annotations, prefix)
+			r.Gateways[key] = gateway
+		}
+		for key, gatewayClass := range r.GatewayClasses {
+			gatewayClass.Annotations = prefixAnnotations(gatewayClass.Annotations, prefix)
+			r.GatewayClasses[key] = gatewayClass
+		}
+		for key, httpRoute := range r.HTTPRoutes {
+			httpRoute.Annotations = prefixAnnotations(httpRoute.Annotations, prefix)
+			r.HTTPRoutes[key] = httpRoute
+		}
+		for key, grpcRoute := range r.GRPCRoutes {
+			grpcRoute.Annotations = prefixAnnotations(grpcRoute.Annotations, prefix)
+			r.GRPCRoutes[key] = grpcRoute
+		}
+		for key, tlsRoute := range r.TLSRoutes {
+			tlsRoute.Annotations = prefixAnnotations(tlsRoute.Annotations, prefix)
+			r.TLSRoutes[key] = tlsRoute
+		}
+		for key, tcpRoute := range r.TCPRoutes {
+			tcpRoute.Annotations = prefixAnnotations(tcpRoute.Annotations, prefix)
+			r.TCPRoutes[key] = tcpRoute
+		}
+		for key, udpRoute := range r.UDPRoutes {
+			udpRoute.Annotations = prefixAnnotations(udpRoute.Annotations, prefix)
+			r.UDPRoutes[key] = udpRoute
+		}
+		for key, backendTLSPolicy := range r.BackendTLSPolicies {
+			backendTLSPolicy.Annotations = prefixAnnotations(backendTLSPolicy.Annotations, prefix)
+			r.BackendTLSPolicies[key] = backendTLSPolicy
+		}
+		for key, referenceGrant := range r.ReferenceGrants {
+			referenceGrant.Annotations = prefixAnnotations(referenceGrant.Annotations, prefix)
+			r.ReferenceGrants[key] = referenceGrant
+		}
+		for i := range r.GatewayExtensions {
+			if annotations := r.GatewayExtensions[i].GetAnnotations(); annotations != nil {
+				r.GatewayExtensions[i].SetAnnotations(prefixAnnotations(annotations, prefix))
+			}
+		}
+	}
+	return nil
+}
+
+// prefixAnnotations returns a copy of the annotations with DefaultAnnotationPrefix
+// replaced by the prefix, so maps shared between resources are not modified
+func prefixAnnotations(annotations map[string]string, prefix string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	updated := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if name, ok := strings.CutPrefix(key, DefaultAnnotationPrefix+"/"); ok {
+			key = prefix + "/" + name
+		}
+		updated[key] = value
+	}
+	return updated
+}
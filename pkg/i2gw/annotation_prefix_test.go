@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestSetAnnotationPrefix(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "example"}
+	sourceAnnotations := map[string]string{
+		"ingress2gateway.kubernetes.io/source": "nginx.ingress.kubernetes.io/ssl-redirect",
+		"example.com/owner":                    "team-a",
+	}
+	envoyFilter := unstructured.Unstructured{}
+	envoyFilter.SetKind("EnvoyFilter")
+	envoyFilter.SetAnnotations(map[string]string{"ingress2gateway.kubernetes.io/source": "nginx.ingress.kubernetes.io/auth-tls-secret"})
+
+	gatewayResources := []GatewayResources{{
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			key: {ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Annotations: sourceAnnotations}},
+		},
+		ReferenceGrants: map[types.NamespacedName]gatewayv1beta1.ReferenceGrant{
+			key: {ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Annotations: sourceAnnotations}},
+		},
+		GatewayExtensions: []unstructured.Unstructured{envoyFilter},
+	}}
+
+	if err := SetAnnotationPrefix(gatewayResources, "migration.example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedAnnotations := map[string]string{
+		"migration.example.com/source": "nginx.ingress.kubernetes.io/ssl-redirect",
+		"example.com/owner":            "team-a",
+	}
+	if diff := cmp.Diff(expectedAnnotations, gatewayResources[0].HTTPRoutes[key].Annotations); diff != "" {
+		t.Errorf("unexpected HTTPRoute annotations (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedAnnotations, gatewayResources[0].ReferenceGrants[key].Annotations); diff != "" {
+		t.Errorf("unexpected ReferenceGrant annotations (-want +got):\n%s", diff)
+	}
+	expectedExtensionAnnotations := map[string]string{"migration.example.com/source": "nginx.ingress.kubernetes.io/auth-tls-secret"}
+	if diff := cmp.Diff(expectedExtensionAnnotations, gatewayResources[0].GatewayExtensions[0].GetAnnotations()); diff != "" {
+		t.Errorf("unexpected EnvoyFilter annotations (-want +got):\n%s", diff)
+	}
+	// The shared map of the input is not modified
+	if _, ok := sourceAnnotations["ingress2gateway.kubernetes.io/source"]; !ok {
+		t.Errorf("expected the source annotations to be left unchanged, got %v", sourceAnnotations)
+	}
+
+	if err := SetAnnotationPrefix(gatewayResources, "Not A Prefix"); err == nil {
+		t.Errorf("expected an error for an invalid prefix")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SupportLevel is how completely a provider migrates an annotation.
+type SupportLevel string
+
+const (
+	// FullSupport annotations are converted without loss of behavior.
+	FullSupport SupportLevel = "full"
+	// PartialSupport annotations are converted for some values, or with
+	// differences reported as notifications.
+	PartialSupport SupportLevel = "partial"
+	// NoSupport annotations are not converted, only reported.
+	NoSupport SupportLevel = "none"
+)
+
+// AnnotationSupport describes the migration status of an annotation.
+type AnnotationSupport struct {
+	Level SupportLevel
+	// Resource is the kind of resource, and the part of it, the annotation is
+	// converted to, e.g. "HTTPRoute (RequestMirror filter)". Empty for
+	// NoSupport annotations.
+	Resource string
+}
+
+var supportedAnnotationsByProvider = struct {
+	annotations map[ProviderName]func() map[string]AnnotationSupport
+	mu          sync.RWMutex
+}{
+	annotations: make(map[ProviderName]func() map[string]AnnotationSupport),
+}
+
+// RegisterSupportedAnnotations registers the function listing the annotations
+// a provider supports, for --list-annotations.
+// RegisterSupportedAnnotations is thread-safe.
+func RegisterSupportedAnnotations(provider ProviderName, annotations func() map[string]AnnotationSupport) {
+	supportedAnnotationsByProvider.mu.Lock()
+	defer supportedAnnotationsByProvider.mu.Unlock()
+	supportedAnnotationsByProvider.annotations[provider] = annotations
+}
+
+// GetSupportedAnnotations returns the annotations the provider supports, and
+// false if the provider does not list them.
+func GetSupportedAnnotations(provider ProviderName) (map[string]AnnotationSupport, bool) {
+	supportedAnnotationsByProvider.mu.RLock()
+	defer supportedAnnotationsByProvider.mu.RUnlock()
+	annotations, ok := supportedAnnotationsByProvider.annotations[provider]
+	if !ok {
+		return nil, false
+	}
+	return annotations(), true
+}
+
+// FormatSupportedAnnotations renders the annotations as a table sorted by
+// annotation.
+func FormatSupportedAnnotations(annotations map[string]AnnotationSupport) string {
+	width := len("ANNOTATION")
+	for annotation := range annotations {
+		width = max(width, len(annotation))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-*s  %-7s  %s\n", width, "ANNOTATION", "SUPPORT", "RESOURCE")
+	for _, annotation := range slices.Sorted(maps.Keys(annotations)) {
+		support := annotations[annotation]
+		line := fmt.Sprintf("%-*s  %-7s  %s", width, annotation, support.Level, support.Resource)
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return sb.String()
+}
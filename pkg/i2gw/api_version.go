@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// DefaultGatewayAPIVersion is the Gateway API release the generated resources
+// target by default, the one of the vendored Gateway API types.
+const DefaultGatewayAPIVersion = "v1.4"
+
+// gatewayAPIKindVersions maps the supported Gateway API releases to the API
+// versions of the kinds that were served under a different version in that
+// release than in DefaultGatewayAPIVersion. Only releases whose schemas match
+// the vendored types are supported: before v1.1, BackendTLSPolicy was a
+// v1alpha2 API with a different spec.
+var gatewayAPIKindVersions = map[string]map[string]string{
+	"v1.1": {"BackendTLSPolicy": "v1alpha3"},
+	"v1.2": {"BackendTLSPolicy": "v1alpha3"},
+	"v1.3": {"BackendTLSPolicy": "v1alpha3"},
+	"v1.4": {},
+}
+
+// SupportedGatewayAPIVersions returns the Gateway API releases the generated
+// resources can target, sorted.
+func SupportedGatewayAPIVersions() []string {
+	return slices.Sorted(maps.Keys(gatewayAPIKindVersions))
+}
+
+// ValidateGatewayAPIVersion returns an error if the Gateway API release is not
+// supported.
+func ValidateGatewayAPIVersion(version string) error {
+	if _, ok := gatewayAPIKindVersions[version]; !ok {
+		return fmt.Errorf("%s is not a supported Gateway API version, supported versions are %v", version, SupportedGatewayAPIVersions())
+	}
+	return nil
+}
+
+// SetGatewayAPIVersion sets the apiVersion of the generated resources to the
+// one served by the given Gateway API release.
+func SetGatewayAPIVersion(gatewayResources []GatewayResources, version string) error {
+	if err := ValidateGatewayAPIVersion(version); err != nil {
+		return err
+	}
+	kindVersions := gatewayAPIKindVersions[version]
+
+	if backendTLSPolicyVersion, ok := kindVersions["BackendTLSPolicy"]; ok {
+		apiVersion := gatewayv1.GroupName + "/" + backendTLSPolicyVersion
+		for _, r := range gatewayResources {
+			for key, policy := range r.BackendTLSPolicies {
+				policy.APIVersion = apiVersion
+				policy.Kind = "BackendTLSPolicy"
+				r.BackendTLSPolicies[key] = policy
+			}
+		}
+	}
+	return nil
+}
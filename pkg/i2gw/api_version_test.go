@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestSetGatewayAPIVersion(t *testing.T) {
+	testCases := []struct {
+		version            string
+		expectedAPIVersion string
+		expectError        bool
+	}{
+		{
+			version:            "v1.3",
+			expectedAPIVersion: "gateway.networking.k8s.io/v1alpha3",
+		},
+		{
+			version:            "v1.1",
+			expectedAPIVersion: "gateway.networking.k8s.io/v1alpha3",
+		},
+		{
+			version:            DefaultGatewayAPIVersion,
+			expectedAPIVersion: "gateway.networking.k8s.io/v1",
+		},
+		{
+			version:            "v1.0",
+			expectedAPIVersion: "gateway.networking.k8s.io/v1",
+			expectError:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			policyKey := types.NamespacedName{Namespace: "default", Name: "my-service-backend-tls"}
+			gatewayResources := []GatewayResources{{
+				BackendTLSPolicies: map[types.NamespacedName]gatewayv1.BackendTLSPolicy{
+					policyKey: {
+						TypeMeta:   metav1.TypeMeta{APIVersion: gatewayv1.GroupVersion.String(), Kind: "BackendTLSPolicy"},
+						ObjectMeta: metav1.ObjectMeta{Name: policyKey.Name, Namespace: policyKey.Namespace},
+					},
+				},
+			}}
+
+			err := SetGatewayAPIVersion(gatewayResources, tc.version)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error: %v, got %v", tc.expectError, err)
+			}
+
+			policy := gatewayResources[0].BackendTLSPolicies[policyKey]
+			if policy.APIVersion != tc.expectedAPIVersion {
+				t.Errorf("expected BackendTLSPolicy apiVersion %s, got %s", tc.expectedAPIVersion, policy.APIVersion)
+			}
+			if policy.Kind != "BackendTLSPolicy" {
+				t.Errorf("expected kind BackendTLSPolicy, got %s", policy.Kind)
+			}
+		})
+	}
+}
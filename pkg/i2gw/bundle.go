@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"maps"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// SourceIngressClassAnnotationKey is set by providers on the generated
+// resources built from the Ingresses of a single ingress class.
+const SourceIngressClassAnnotationKey = "ingress2gateway.kubernetes.io/ingress-class"
+
+// SharedBundleName is the name of the bundle holding the resources that are
+// not tied to a single ingress class, such as GatewayClasses, ReferenceGrants
+// and Gateways shared by several classes.
+const SharedBundleName = "shared"
+
+// ResourceBundle is a named subset of the generated resources.
+type ResourceBundle struct {
+	Name      string
+	Resources GatewayResources
+}
+
+// SplitByIngressClass groups the generated resources by the ingress class in
+// their SourceIngressClassAnnotationKey annotation. Resources without the
+// annotation go to the SharedBundleName bundle, which comes first as the
+// other bundles may depend on it. The other bundles are sorted by class.
+func SplitByIngressClass(gatewayResources []GatewayResources) []ResourceBundle {
+	bundles := make(map[string]*GatewayResources)
+	bundle := func(annotations map[string]string) *GatewayResources {
+		name := annotations[SourceIngressClassAnnotationKey]
+		if name == "" {
+			name = SharedBundleName
+		}
+		if bundles[name] == nil {
+			bundles[name] = &GatewayResources{
+				Gateways:           make(map[types.NamespacedName]gatewayv1.Gateway),
+				GatewayClasses:     make(map[types.NamespacedName]gatewayv1.GatewayClass),
+				HTTPRoutes:         make(map[types.NamespacedName]gatewayv1.HTTPRoute),
+				GRPCRoutes:         make(map[types.NamespacedName]gatewayv1.GRPCRoute),
+				TLSRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.TLSRoute),
+				TCPRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.TCPRoute),
+				UDPRoutes:          make(map[types.NamespacedName]gatewayv1alpha2.UDPRoute),
+				BackendTLSPolicies: make(map[types.NamespacedName]gatewayv1.BackendTLSPolicy),
+				ReferenceGrants:    make(map[types.NamespacedName]gatewayv1beta1.ReferenceGrant),
+			}
+		}
+		return bundles[name]
+	}
+
+	for _, r := range gatewayResources {
+		for key, gateway := range r.Gateways {
+			bundle(gateway.Annotations).Gateways[key] = gateway
+		}
+		for key, gatewayClass := range r.GatewayClasses {
+			bundle(gatewayClass.Annotations).GatewayClasses[key] = gatewayClass
+		}
+		for key, httpRoute := range r.HTTPRoutes {
+			bundle(httpRoute.Annotations).HTTPRoutes[key] = httpRoute
+		}
+		for key, grpcRoute := range r.GRPCRoutes {
+			bundle(grpcRoute.Annotations).GRPCRoutes[key] = grpcRoute
+		}
+		for key, tlsRoute := range r.TLSRoutes {
+			bundle(tlsRoute.Annotations).TLSRoutes[key] = tlsRoute
+		}
+		for key, tcpRoute := range r.TCPRoutes {
+			bundle(tcpRoute.Annotations).TCPRoutes[key] = tcpRoute
+		}
+		for key, udpRoute := range r.UDPRoutes {
+			bundle(udpRoute.Annotations).UDPRoutes[key] = udpRoute
+		}
+		for key, backendTLSPolicy := range r.BackendTLSPolicies {
+			bundle(backendTLSPolicy.Annotations).BackendTLSPolicies[key] = backendTLSPolicy
+		}
+		for key, referenceGrant := range r.ReferenceGrants {
+			bundle(referenceGrant.Annotations).ReferenceGrants[key] = referenceGrant
+		}
+		for _, gatewayExtension := range r.GatewayExtensions {
+			b := bundle(gatewayExtension.GetAnnotations())
+			b.GatewayExtensions = append(b.GatewayExtensions, *gatewayExtension.DeepCopy())
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(bundles))
+	if i := slices.Index(names, SharedBundleName); i > 0 {
+		names = append([]string{SharedBundleName}, slices.Delete(names, i, i+1)...)
+	}
+
+	var result []ResourceBundle
+	for _, name := range names {
+		result = append(result, ResourceBundle{Name: name, Resources: *bundles[name]})
+	}
+	return result
+}
+
+// RemoveSourceIngressClassAnnotations removes the SourceIngressClassAnnotationKey
+// annotation from the generated resources. It is only read by
+// SplitByIngressClass, so it is removed when the resources are not split.
+func RemoveSourceIngressClassAnnotations(gatewayResources []GatewayResources) {
+	for _, r := range gatewayResources {
+		for key, gateway := range r.Gateways {
+			gateway.Annotations = withoutSourceIngressClass(gateway.Annotations)
+			r.Gateways[key] = gateway
+		}
+		for key, gatewayClass := range r.GatewayClasses {
+			gatewayClass.Annotations = withoutSourceIngressClass(gatewayClass.Annotations)
+			r.GatewayClasses[key] = gatewayClass
+		}
+		for key, httpRoute := range r.HTTPRoutes {
+			httpRoute.Annotations = withoutSourceIngressClass(httpRoute.Annotations)
+			r.HTTPRoutes[key] = httpRoute
+		}
+		for key, grpcRoute := range r.GRPCRoutes {
+			grpcRoute.Annotations = withoutSourceIngressClass(grpcRoute.Annotations)
+			r.GRPCRoutes[key] = grpcRoute
+		}
+		for key, tlsRoute := range r.TLSRoutes {
+			tlsRoute.Annotations = withoutSourceIngressClass(tlsRoute.Annotations)
+			r.TLSRoutes[key] = tlsRoute
+		}
+		for key, tcpRoute := range r.TCPRoutes {
+			tcpRoute.Annotations = withoutSourceIngressClass(tcpRoute.Annotations)
+			r.TCPRoutes[key] = tcpRoute
+		}
+		for key, udpRoute := range r.UDPRoutes {
+			udpRoute.Annotations = withoutSourceIngressClass(udpRoute.Annotations)
+			r.UDPRoutes[key] = udpRoute
+		}
+		for key, backendTLSPolicy := range r.BackendTLSPolicies {
+			backendTLSPolicy.Annotations = withoutSourceIngressClass(backendTLSPolicy.Annotations)
+			r.BackendTLSPolicies[key] = backendTLSPolicy
+		}
+		for key, referenceGrant := range r.ReferenceGrants {
+			referenceGrant.Annotations = withoutSourceIngressClass(referenceGrant.Annotations)
+			r.ReferenceGrants[key] = referenceGrant
+		}
+		for i := range r.GatewayExtensions {
+			if annotations := r.GatewayExtensions[i].GetAnnotations(); annotations != nil {
+				r.GatewayExtensions[i].SetAnnotations(withoutSourceIngressClass(annotations))
+			}
+		}
+	}
+}
+
+// withoutSourceIngressClass returns a copy of the annotations without
+// SourceIngressClassAnnotationKey, so maps shared between resources are not
+// modified. It returns nil if no annotation is left.
+func withoutSourceIngressClass(annotations map[string]string) map[string]string {
+	if _, ok := annotations[SourceIngressClassAnnotationKey]; !ok {
+		return annotations
+	}
+	updated := maps.Clone(annotations)
+	delete(updated, SourceIngressClassAnnotationKey)
+	if len(updated) == 0 {
+		return nil
+	}
+	return updated
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestSplitByIngressClass(t *testing.T) {
+	classMeta := func(name, class string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{SourceIngressClassAnnotationKey: class},
+		}
+	}
+	routeA := types.NamespacedName{Namespace: "default", Name: "route-a"}
+	routeB := types.NamespacedName{Namespace: "default", Name: "route-b"}
+	gatewayA := types.NamespacedName{Namespace: "default", Name: "class-a"}
+	gatewayB := types.NamespacedName{Namespace: "default", Name: "class-b"}
+	grant := types.NamespacedName{Namespace: "gateways", Name: "from-default"}
+
+	gatewayResources := []GatewayResources{{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			gatewayA: {ObjectMeta: classMeta("class-a", "class-a")},
+			gatewayB: {ObjectMeta: classMeta("class-b", "class-b")},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			routeA: {ObjectMeta: classMeta("route-a", "class-a")},
+			routeB: {ObjectMeta: classMeta("route-b", "class-b")},
+		},
+		ReferenceGrants: map[types.NamespacedName]gatewayv1beta1.ReferenceGrant{
+			grant: {ObjectMeta: metav1.ObjectMeta{Name: "from-default", Namespace: "gateways"}},
+		},
+	}}
+
+	bundles := SplitByIngressClass(gatewayResources)
+
+	var names []string
+	for _, bundle := range bundles {
+		names = append(names, bundle.Name)
+	}
+	if diff := cmp.Diff([]string{SharedBundleName, "class-a", "class-b"}, names); diff != "" {
+		t.Fatalf("unexpected bundles (-want +got):\n%s", diff)
+	}
+
+	expected := map[string]struct {
+		gateways        []types.NamespacedName
+		httpRoutes      []types.NamespacedName
+		referenceGrants []types.NamespacedName
+	}{
+		SharedBundleName: {referenceGrants: []types.NamespacedName{grant}},
+		"class-a":        {gateways: []types.NamespacedName{gatewayA}, httpRoutes: []types.NamespacedName{routeA}},
+		"class-b":        {gateways: []types.NamespacedName{gatewayB}, httpRoutes: []types.NamespacedName{routeB}},
+	}
+	for _, bundle := range bundles {
+		want := expected[bundle.Name]
+		if diff := cmp.Diff(want.gateways, slices.Collect(maps.Keys(bundle.Resources.Gateways))); diff != "" {
+			t.Errorf("unexpected Gateways in bundle %s (-want +got):\n%s", bundle.Name, diff)
+		}
+		if diff := cmp.Diff(want.httpRoutes, slices.Collect(maps.Keys(bundle.Resources.HTTPRoutes))); diff != "" {
+			t.Errorf("unexpected HTTPRoutes in bundle %s (-want +got):\n%s", bundle.Name, diff)
+		}
+		if diff := cmp.Diff(want.referenceGrants, slices.Collect(maps.Keys(bundle.Resources.ReferenceGrants))); diff != "" {
+			t.Errorf("unexpected ReferenceGrants in bundle %s (-want +got):\n%s", bundle.Name, diff)
+		}
+	}
+}
+
+func TestRemoveSourceIngressClassAnnotations(t *testing.T) {
+	shared := map[string]string{SourceIngressClassAnnotationKey: "nginx", "example.com/owner": "team-a"}
+	gateway := types.NamespacedName{Namespace: "default", Name: "nginx"}
+	route := types.NamespacedName{Namespace: "default", Name: "route"}
+	extension := unstructured.Unstructured{}
+	extension.SetAnnotations(map[string]string{SourceIngressClassAnnotationKey: "nginx"})
+
+	gatewayResources := []GatewayResources{{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			gateway: {ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", Annotations: shared}},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			route: {ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "default", Annotations: shared}},
+		},
+		GatewayExtensions: []unstructured.Unstructured{extension},
+	}}
+	RemoveSourceIngressClassAnnotations(gatewayResources)
+
+	expected := map[string]string{"example.com/owner": "team-a"}
+	if diff := cmp.Diff(expected, gatewayResources[0].Gateways[gateway].Annotations); diff != "" {
+		t.Errorf("unexpected Gateway annotations (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expected, gatewayResources[0].HTTPRoutes[route].Annotations); diff != "" {
+		t.Errorf("unexpected HTTPRoute annotations (-want +got):\n%s", diff)
+	}
+	if annotations := gatewayResources[0].GatewayExtensions[0].GetAnnotations(); annotations != nil {
+		t.Errorf("expected no extension annotations, got %v", annotations)
+	}
+	if shared[SourceIngressClassAnnotationKey] != "nginx" {
+		t.Errorf("expected the shared annotations to be left unmodified, got %v", shared)
+	}
+}
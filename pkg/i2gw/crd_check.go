@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// newDiscoveryClient creates a discovery client for the cluster selected by
+// the kubeconfig context.
+var newDiscoveryClient = func(kubeContext string) (discovery.DiscoveryInterface, error) {
+	conf, err := restConfigLoader(kubeContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client config: %w", err)
+	}
+	return discovery.NewDiscoveryClientForConfig(conf)
+}
+
+// InstalledGatewayAPIKinds maps the Gateway API kinds served by a cluster to
+// the versions they are served in.
+type InstalledGatewayAPIKinds map[string][]string
+
+// experimentalOnlyKinds are only installed with the experimental channel CRDs,
+// which also add the experimental fields to the standard kinds.
+var experimentalOnlyKinds = []string{"TCPRoute", "UDPRoute"}
+
+// gatewayAPIKindFallbackVersions are the older versions a kind can be printed
+// in when the cluster does not serve the version of the vendored types.
+var gatewayAPIKindFallbackVersions = map[string]string{
+	"BackendTLSPolicy": "v1alpha3",
+}
+
+// DiscoverGatewayAPIKinds returns the Gateway API kinds served by the cluster.
+// The result is empty if the Gateway API CRDs are not installed.
+func DiscoverGatewayAPIKinds(discoveryClient discovery.DiscoveryInterface) (InstalledGatewayAPIKinds, error) {
+	groups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the API groups of the cluster: %w", err)
+	}
+
+	installed := make(InstalledGatewayAPIKinds)
+	for _, group := range groups.Groups {
+		if group.Name != gatewayv1.GroupName {
+			continue
+		}
+		for _, version := range group.Versions {
+			resources, err := discoveryClient.ServerResourcesForGroupVersion(version.GroupVersion)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to discover the resources of %s: %w", version.GroupVersion, err)
+			}
+			for _, resource := range resources.APIResources {
+				// Skip subresources such as httproutes/status
+				if strings.Contains(resource.Name, "/") || slices.Contains(installed[resource.Kind], version.Version) {
+					continue
+				}
+				installed[resource.Kind] = append(installed[resource.Kind], version.Version)
+			}
+		}
+	}
+	return installed, nil
+}
+
+// Serves returns true if the cluster serves the kind in the version.
+func (k InstalledGatewayAPIKinds) Serves(kind, version string) bool {
+	return slices.Contains(k[kind], version)
+}
+
+// ExperimentalChannel returns true if the experimental channel CRDs are
+// installed.
+func (k InstalledGatewayAPIKinds) ExperimentalChannel() bool {
+	for _, kind := range experimentalOnlyKinds {
+		if len(k[kind]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyInstalledGatewayAPIKinds adapts the resources generated by a provider to
+// the Gateway API CRDs installed in the cluster, with a Warning for each
+// change: kinds that are not installed are skipped, BackendTLSPolicies are
+// downgraded to v1alpha3 when v1 is not served, and the experimental Retry and
+// SessionPersistence fields of HTTPRoutes are dropped without the
+// experimental channel.
+func ApplyInstalledGatewayAPIKinds(gatewayResources *GatewayResources, installed InstalledGatewayAPIKinds, providerName ProviderName) {
+	warn := func(message string) {
+		notifications.NotificationAggr.DispatchNotification(
+			notifications.NewNotification(notifications.WarningNotification, message), string(providerName))
+	}
+	// skipKind returns true if the resources of the kind must be skipped
+	skipKind := func(kind, version string, count int) bool {
+		if count == 0 || installed.Serves(kind, version) {
+			return false
+		}
+		warn(fmt.Sprintf("skipping %d %s resources: the %s CRD is not installed in version %s in the cluster", count, kind, kind, version))
+		return true
+	}
+
+	if skipKind("GatewayClass", "v1", len(gatewayResources.GatewayClasses)) {
+		gatewayResources.GatewayClasses = nil
+	}
+	if skipKind("Gateway", "v1", len(gatewayResources.Gateways)) {
+		gatewayResources.Gateways = nil
+	}
+	if skipKind("HTTPRoute", "v1", len(gatewayResources.HTTPRoutes)) {
+		gatewayResources.HTTPRoutes = nil
+	}
+	if skipKind("GRPCRoute", "v1", len(gatewayResources.GRPCRoutes)) {
+		gatewayResources.GRPCRoutes = nil
+	}
+	if skipKind("TLSRoute", "v1alpha2", len(gatewayResources.TLSRoutes)) {
+		gatewayResources.TLSRoutes = nil
+	}
+	if skipKind("TCPRoute", "v1alpha2", len(gatewayResources.TCPRoutes)) {
+		gatewayResources.TCPRoutes = nil
+	}
+	if skipKind("UDPRoute", "v1alpha2", len(gatewayResources.UDPRoutes)) {
+		gatewayResources.UDPRoutes = nil
+	}
+	if skipKind("ReferenceGrant", "v1beta1", len(gatewayResources.ReferenceGrants)) {
+		gatewayResources.ReferenceGrants = nil
+	}
+
+	if policies := gatewayResources.BackendTLSPolicies; len(policies) > 0 && !installed.Serves("BackendTLSPolicy", "v1") {
+		if fallback := gatewayAPIKindFallbackVersions["BackendTLSPolicy"]; installed.Serves("BackendTLSPolicy", fallback) {
+			for key, policy := range policies {
+				policy.APIVersion = gatewayv1.GroupName + "/" + fallback
+				policy.Kind = "BackendTLSPolicy"
+				policies[key] = policy
+			}
+			warn(fmt.Sprintf("the cluster only serves BackendTLSPolicy %s, %d BackendTLSPolicies are printed in that version", fallback, len(policies)))
+		} else {
+			warn(fmt.Sprintf("skipping %d BackendTLSPolicy resources: the BackendTLSPolicy CRD is not installed in the cluster", len(policies)))
+			gatewayResources.BackendTLSPolicies = nil
+		}
+	}
+
+	if installed.ExperimentalChannel() {
+		return
+	}
+	for key, route := range gatewayResources.HTTPRoutes {
+		var dropped []string
+		for i := range route.Spec.Rules {
+			if route.Spec.Rules[i].Retry != nil {
+				route.Spec.Rules[i].Retry = nil
+				dropped = appendIfMissing(dropped, "retry")
+			}
+			if route.Spec.Rules[i].SessionPersistence != nil {
+				route.Spec.Rules[i].SessionPersistence = nil
+				dropped = appendIfMissing(dropped, "sessionPersistence")
+			}
+		}
+		if len(dropped) > 0 {
+			gatewayResources.HTTPRoutes[key] = route
+			warn(fmt.Sprintf("dropping the %s of the rules of HTTPRoute %s: the experimental channel CRDs are not installed in the cluster",
+				strings.Join(dropped, " and "), key))
+		}
+	}
+}
+
+func appendIfMissing(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
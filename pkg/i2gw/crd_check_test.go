@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// standardChannelV12 is the discovery of the standard channel CRDs of Gateway
+// API v1.2, which serve BackendTLSPolicy in v1alpha3 only
+var standardChannelV12 = []*metav1.APIResourceList{
+	{
+		GroupVersion: "gateway.networking.k8s.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "gatewayclasses", Kind: "GatewayClass"},
+			{Name: "gateways", Kind: "Gateway"},
+			{Name: "gateways/status", Kind: "Gateway"},
+			{Name: "httproutes", Kind: "HTTPRoute"},
+			{Name: "grpcroutes", Kind: "GRPCRoute"},
+		},
+	},
+	{
+		GroupVersion: "gateway.networking.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "referencegrants", Kind: "ReferenceGrant"},
+		},
+	},
+	{
+		GroupVersion: "gateway.networking.k8s.io/v1alpha3",
+		APIResources: []metav1.APIResource{
+			{Name: "backendtlspolicies", Kind: "BackendTLSPolicy"},
+		},
+	},
+}
+
+func TestDiscoverGatewayAPIKinds(t *testing.T) {
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: append([]*metav1.APIResourceList{
+		{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}},
+		},
+	}, standardChannelV12...)}}
+
+	installed, err := DiscoverGatewayAPIKinds(discoveryClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := InstalledGatewayAPIKinds{
+		"GatewayClass":     {"v1"},
+		"Gateway":          {"v1"},
+		"HTTPRoute":        {"v1"},
+		"GRPCRoute":        {"v1"},
+		"ReferenceGrant":   {"v1beta1"},
+		"BackendTLSPolicy": {"v1alpha3"},
+	}
+	if diff := cmp.Diff(expected, installed); diff != "" {
+		t.Errorf("unexpected installed kinds (-want +got):\n%s", diff)
+	}
+	if installed.ExperimentalChannel() {
+		t.Errorf("expected the standard channel")
+	}
+}
+
+func TestApplyInstalledGatewayAPIKinds(t *testing.T) {
+	const providerName ProviderName = "crd-check-test"
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: standardChannelV12}}
+	installed, err := DiscoverGatewayAPIKinds(discoveryClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: "route"}
+	policyKey := types.NamespacedName{Namespace: "default", Name: "policy"}
+	attempts := 3
+	gatewayResources := GatewayResources{
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			routeKey: {
+				Spec: gatewayv1.HTTPRouteSpec{
+					Rules: []gatewayv1.HTTPRouteRule{
+						{Retry: &gatewayv1.HTTPRouteRetry{Attempts: &attempts}},
+						{SessionPersistence: &gatewayv1.SessionPersistence{}},
+					},
+				},
+			},
+		},
+		TCPRoutes: map[types.NamespacedName]gatewayv1alpha2.TCPRoute{
+			routeKey: {},
+		},
+		BackendTLSPolicies: map[types.NamespacedName]gatewayv1.BackendTLSPolicy{
+			policyKey: {TypeMeta: metav1.TypeMeta{APIVersion: "gateway.networking.k8s.io/v1", Kind: "BackendTLSPolicy"}},
+		},
+		ReferenceGrants: map[types.NamespacedName]gatewayv1beta1.ReferenceGrant{
+			routeKey: {},
+		},
+	}
+
+	ApplyInstalledGatewayAPIKinds(&gatewayResources, installed, providerName)
+
+	if len(gatewayResources.TCPRoutes) != 0 {
+		t.Errorf("expected the TCPRoutes to be skipped, got %d", len(gatewayResources.TCPRoutes))
+	}
+	if len(gatewayResources.ReferenceGrants) != 1 {
+		t.Errorf("expected the ReferenceGrant to be kept, got %d", len(gatewayResources.ReferenceGrants))
+	}
+	if apiVersion := gatewayResources.BackendTLSPolicies[policyKey].APIVersion; apiVersion != "gateway.networking.k8s.io/v1alpha3" {
+		t.Errorf("expected the BackendTLSPolicy to be downgraded to v1alpha3, got %s", apiVersion)
+	}
+	for i, rule := range gatewayResources.HTTPRoutes[routeKey].Spec.Rules {
+		if rule.Retry != nil || rule.SessionPersistence != nil {
+			t.Errorf("expected the experimental fields of rule %d to be dropped, got %+v", i, rule)
+		}
+	}
+
+	warnings := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(string(providerName)) {
+		if n.Type == notifications.WarningNotification {
+			warnings++
+		}
+	}
+	// Skipped TCPRoutes, downgraded BackendTLSPolicies and dropped HTTPRoute fields
+	if warnings != 3 {
+		t.Errorf("expected 3 warnings, got %d", warnings)
+	}
+}
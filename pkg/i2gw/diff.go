@@ -0,0 +1,311 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ChangeType describes how a generated resource differs from the cluster.
+type ChangeType string
+
+const (
+	ChangeTypeAdd    ChangeType = "add"
+	ChangeTypeUpdate ChangeType = "update"
+	ChangeTypeDelete ChangeType = "delete"
+)
+
+// ResourceChange is a single difference between the generated and existing resources.
+type ResourceChange struct {
+	Kind string
+	Key  types.NamespacedName
+	Type ChangeType
+}
+
+// DiffResult holds the changes needed to go from the existing resources to
+// the generated ones, sorted by kind, namespace and name.
+type DiffResult struct {
+	Changes []ResourceChange
+}
+
+// Count returns the number of changes of the given type.
+func (d DiffResult) Count(changeType ChangeType) int {
+	count := 0
+	for _, change := range d.Changes {
+		if change.Type == changeType {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary returns a human-readable report of the changes.
+func (d DiffResult) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %d to add, %d to update, %d to delete\n",
+		d.Count(ChangeTypeAdd), d.Count(ChangeTypeUpdate), d.Count(ChangeTypeDelete))
+	for _, change := range d.Changes {
+		fmt.Fprintf(&sb, "%s %s %s\n", change.Type, change.Kind, change.Key)
+	}
+	return sb.String()
+}
+
+// Diff compares the generated Gateways and HTTPRoutes against the existing ones.
+// A resource is reported as added if it does not exist, and as updated if its
+// spec differs once the defaults the API server applies are filled in on both
+// sides. Existing resources missing from the generated output are reported as
+// deleted only if they were previously generated by ingress2gateway, so that
+// resources managed by other means are never flagged.
+func Diff(generated []GatewayResources, existing GatewayResources) DiffResult {
+	generatedGateways := make(map[types.NamespacedName]gatewayv1.Gateway)
+	generatedHTTPRoutes := make(map[types.NamespacedName]gatewayv1.HTTPRoute)
+	for _, resources := range generated {
+		for key, gateway := range resources.Gateways {
+			generatedGateways[key] = gateway
+		}
+		for key, httpRoute := range resources.HTTPRoutes {
+			generatedHTTPRoutes[key] = httpRoute
+		}
+	}
+
+	var result DiffResult
+	result.Changes = append(result.Changes, diffResources("Gateway", generatedGateways, existing.Gateways,
+		func(g gatewayv1.Gateway) any { return defaultGatewaySpec(g.Spec) },
+		func(g gatewayv1.Gateway) map[string]string { return g.Annotations })...)
+	result.Changes = append(result.Changes, diffResources("HTTPRoute", generatedHTTPRoutes, existing.HTTPRoutes,
+		func(r gatewayv1.HTTPRoute) any { return defaultHTTPRouteSpec(r.Spec) },
+		func(r gatewayv1.HTTPRoute) map[string]string { return r.Annotations })...)
+
+	slices.SortFunc(result.Changes, func(a, b ResourceChange) int {
+		return cmp.Or(
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Key.Namespace, b.Key.Namespace),
+			cmp.Compare(a.Key.Name, b.Key.Name),
+		)
+	})
+	return result
+}
+
+func diffResources[T any](kind string, generated, existing map[types.NamespacedName]T, spec func(T) any, annotations func(T) map[string]string) []ResourceChange {
+	var changes []ResourceChange
+	for key, obj := range generated {
+		existingObj, ok := existing[key]
+		if !ok {
+			changes = append(changes, ResourceChange{Kind: kind, Key: key, Type: ChangeTypeAdd})
+			continue
+		}
+		if !apiequality.Semantic.DeepEqual(spec(obj), spec(existingObj)) {
+			changes = append(changes, ResourceChange{Kind: kind, Key: key, Type: ChangeTypeUpdate})
+		}
+	}
+	for key, obj := range existing {
+		if _, ok := generated[key]; ok {
+			continue
+		}
+		if _, generatedByUs := annotations(obj)[GeneratorAnnotationKey]; generatedByUs {
+			changes = append(changes, ResourceChange{Kind: kind, Key: key, Type: ChangeTypeDelete})
+		}
+	}
+	return changes
+}
+
+// defaultGatewaySpec returns a copy of the spec with the defaults of the
+// Gateway CRD filled in, so generated specs compare equal to the applied ones
+func defaultGatewaySpec(spec gatewayv1.GatewaySpec) gatewayv1.GatewaySpec {
+	spec = *spec.DeepCopy()
+	for i := range spec.Addresses {
+		if spec.Addresses[i].Type == nil {
+			spec.Addresses[i].Type = ptr.To(gatewayv1.IPAddressType)
+		}
+	}
+	for i := range spec.Listeners {
+		listener := &spec.Listeners[i]
+		if listener.AllowedRoutes == nil {
+			listener.AllowedRoutes = &gatewayv1.AllowedRoutes{}
+		}
+		if listener.AllowedRoutes.Namespaces == nil {
+			listener.AllowedRoutes.Namespaces = &gatewayv1.RouteNamespaces{}
+		}
+		if listener.AllowedRoutes.Namespaces.From == nil {
+			listener.AllowedRoutes.Namespaces.From = ptr.To(gatewayv1.NamespacesFromSame)
+		}
+		for j := range listener.AllowedRoutes.Kinds {
+			if listener.AllowedRoutes.Kinds[j].Group == nil {
+				listener.AllowedRoutes.Kinds[j].Group = ptr.To(gatewayv1.Group(gatewayv1.GroupName))
+			}
+		}
+		if listener.TLS != nil {
+			if listener.TLS.Mode == nil {
+				listener.TLS.Mode = ptr.To(gatewayv1.TLSModeTerminate)
+			}
+			for j := range listener.TLS.CertificateRefs {
+				certificateRef := &listener.TLS.CertificateRefs[j]
+				if certificateRef.Group == nil {
+					certificateRef.Group = ptr.To(gatewayv1.Group(""))
+				}
+				if certificateRef.Kind == nil {
+					certificateRef.Kind = ptr.To(gatewayv1.Kind("Secret"))
+				}
+			}
+		}
+	}
+	return spec
+}
+
+// defaultHTTPRouteSpec returns a copy of the spec with the defaults of the
+// HTTPRoute CRD filled in, so generated specs compare equal to the applied ones
+func defaultHTTPRouteSpec(spec gatewayv1.HTTPRouteSpec) gatewayv1.HTTPRouteSpec {
+	spec = *spec.DeepCopy()
+	for i := range spec.ParentRefs {
+		if spec.ParentRefs[i].Group == nil {
+			spec.ParentRefs[i].Group = ptr.To(gatewayv1.Group(gatewayv1.GroupName))
+		}
+		if spec.ParentRefs[i].Kind == nil {
+			spec.ParentRefs[i].Kind = ptr.To(gatewayv1.Kind("Gateway"))
+		}
+	}
+	if len(spec.Rules) == 0 {
+		spec.Rules = []gatewayv1.HTTPRouteRule{{}}
+	}
+	for i := range spec.Rules {
+		rule := &spec.Rules[i]
+		if len(rule.Matches) == 0 {
+			rule.Matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for j := range rule.Matches {
+			defaultHTTPRouteMatch(&rule.Matches[j])
+		}
+		for j := range rule.Filters {
+			defaultHTTPRouteFilter(&rule.Filters[j])
+		}
+		for j := range rule.BackendRefs {
+			backendRef := &rule.BackendRefs[j]
+			if backendRef.Weight == nil {
+				backendRef.Weight = ptr.To(int32(1))
+			}
+			defaultBackendObjectReference(&backendRef.BackendObjectReference)
+			for k := range backendRef.Filters {
+				defaultHTTPRouteFilter(&backendRef.Filters[k])
+			}
+		}
+		if rule.SessionPersistence != nil {
+			if rule.SessionPersistence.Type == nil {
+				rule.SessionPersistence.Type = ptr.To(gatewayv1.CookieBasedSessionPersistence)
+			}
+			if rule.SessionPersistence.CookieConfig != nil && rule.SessionPersistence.CookieConfig.LifetimeType == nil {
+				rule.SessionPersistence.CookieConfig.LifetimeType = ptr.To(gatewayv1.SessionCookieLifetimeType)
+			}
+		}
+	}
+	return spec
+}
+
+func defaultHTTPRouteMatch(match *gatewayv1.HTTPRouteMatch) {
+	if match.Path == nil {
+		match.Path = &gatewayv1.HTTPPathMatch{}
+	}
+	if match.Path.Type == nil {
+		match.Path.Type = ptr.To(gatewayv1.PathMatchPathPrefix)
+	}
+	if match.Path.Value == nil {
+		match.Path.Value = ptr.To("/")
+	}
+	for i := range match.Headers {
+		if match.Headers[i].Type == nil {
+			match.Headers[i].Type = ptr.To(gatewayv1.HeaderMatchExact)
+		}
+	}
+	for i := range match.QueryParams {
+		if match.QueryParams[i].Type == nil {
+			match.QueryParams[i].Type = ptr.To(gatewayv1.QueryParamMatchExact)
+		}
+	}
+}
+
+func defaultHTTPRouteFilter(filter *gatewayv1.HTTPRouteFilter) {
+	if filter.RequestRedirect != nil && filter.RequestRedirect.StatusCode == nil {
+		filter.RequestRedirect.StatusCode = ptr.To(302)
+	}
+	if filter.RequestMirror != nil {
+		defaultBackendObjectReference(&filter.RequestMirror.BackendRef)
+	}
+	if filter.CORS != nil && filter.CORS.MaxAge == 0 {
+		filter.CORS.MaxAge = 5
+	}
+}
+
+func defaultBackendObjectReference(ref *gatewayv1.BackendObjectReference) {
+	if ref.Group == nil {
+		ref.Group = ptr.To(gatewayv1.Group(""))
+	}
+	if ref.Kind == nil {
+		ref.Kind = ptr.To(gatewayv1.Kind("Service"))
+	}
+}
+
+// ReadExistingGatewayResources reads the Gateways and HTTPRoutes in the given
+// namespace from the cluster selected by the kubeconfig context. An empty
+// namespace reads all namespaces.
+func ReadExistingGatewayResources(ctx context.Context, namespace string, kubeContext string) (GatewayResources, error) {
+	scheme := runtime.NewScheme()
+	if err := gatewayv1.AddToScheme(scheme); err != nil {
+		return GatewayResources{}, fmt.Errorf("failed to add Gateway API types to scheme: %w", err)
+	}
+
+	cl, err := newClusterClient(kubeContext, namespace, client.Options{Scheme: scheme})
+	if err != nil {
+		return GatewayResources{}, err
+	}
+
+	return readExistingGatewayResources(ctx, cl)
+}
+
+func readExistingGatewayResources(ctx context.Context, cl client.Client) (GatewayResources, error) {
+	resources := GatewayResources{
+		Gateways:   make(map[types.NamespacedName]gatewayv1.Gateway),
+		HTTPRoutes: make(map[types.NamespacedName]gatewayv1.HTTPRoute),
+	}
+
+	var gatewayList gatewayv1.GatewayList
+	if err := cl.List(ctx, &gatewayList); err != nil {
+		return GatewayResources{}, fmt.Errorf("failed to list Gateways: %w", err)
+	}
+	for _, gateway := range gatewayList.Items {
+		resources.Gateways[types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}] = gateway
+	}
+
+	var httpRouteList gatewayv1.HTTPRouteList
+	if err := cl.List(ctx, &httpRouteList); err != nil {
+		return GatewayResources{}, fmt.Errorf("failed to list HTTPRoutes: %w", err)
+	}
+	for _, httpRoute := range httpRouteList.Items {
+		resources.HTTPRoutes[types.NamespacedName{Namespace: httpRoute.Namespace, Name: httpRoute.Name}] = httpRoute
+	}
+
+	return resources, nil
+}
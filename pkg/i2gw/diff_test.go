@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func diffTestHTTPRoute(name, hostname string, annotations map[string]string) gatewayv1.HTTPRoute {
+	return gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(hostname)},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	generatedAnnotation := map[string]string{GeneratorAnnotationKey: "ingress2gateway-dev"}
+
+	generated := []GatewayResources{{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			{Namespace: "default", Name: "gw"}: {
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+				Spec:       gatewayv1.GatewaySpec{GatewayClassName: "istio"},
+			},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			{Namespace: "default", Name: "unchanged"}: diffTestHTTPRoute("unchanged", "a.example.com", nil),
+			{Namespace: "default", Name: "changed"}:   diffTestHTTPRoute("changed", "new.example.com", nil),
+			{Namespace: "default", Name: "new"}:       diffTestHTTPRoute("new", "c.example.com", nil),
+		},
+	}}
+
+	existing := GatewayResources{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			{Namespace: "default", Name: "gw"}: {
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+				Spec:       gatewayv1.GatewaySpec{GatewayClassName: "istio"},
+			},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			{Namespace: "default", Name: "unchanged"}: diffTestHTTPRoute("unchanged", "a.example.com", generatedAnnotation),
+			{Namespace: "default", Name: "changed"}:   diffTestHTTPRoute("changed", "old.example.com", generatedAnnotation),
+			{Namespace: "default", Name: "stale"}:     diffTestHTTPRoute("stale", "d.example.com", generatedAnnotation),
+			{Namespace: "default", Name: "manual"}:    diffTestHTTPRoute("manual", "e.example.com", nil),
+		},
+	}
+
+	result := Diff(generated, existing)
+
+	expected := []ResourceChange{
+		{Kind: "HTTPRoute", Key: types.NamespacedName{Namespace: "default", Name: "changed"}, Type: ChangeTypeUpdate},
+		{Kind: "HTTPRoute", Key: types.NamespacedName{Namespace: "default", Name: "new"}, Type: ChangeTypeAdd},
+		{Kind: "HTTPRoute", Key: types.NamespacedName{Namespace: "default", Name: "stale"}, Type: ChangeTypeDelete},
+	}
+	if diff := cmp.Diff(expected, result.Changes); diff != "" {
+		t.Errorf("Diff() changes mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedSummary := "# 1 to add, 1 to update, 1 to delete\n" +
+		"update HTTPRoute default/changed\n" +
+		"add HTTPRoute default/new\n" +
+		"delete HTTPRoute default/stale\n"
+	if summary := result.Summary(); summary != expectedSummary {
+		t.Errorf("expected summary:\n%s\ngot:\n%s", expectedSummary, summary)
+	}
+}
+
+func TestDiff_APIServerDefaults(t *testing.T) {
+	gwKey := types.NamespacedName{Namespace: "default", Name: "gw"}
+	routeKey := types.NamespacedName{Namespace: "default", Name: "route"}
+
+	generatedRoute := diffTestHTTPRoute("route", "a.example.com", nil)
+	generatedRoute.Spec.ParentRefs = []gatewayv1.ParentReference{{Name: "gw"}}
+	generatedRoute.Spec.Rules = []gatewayv1.HTTPRouteRule{{
+		Matches: []gatewayv1.HTTPRouteMatch{{
+			Path:    &gatewayv1.HTTPPathMatch{Value: ptr.To("/api")},
+			Headers: []gatewayv1.HTTPHeaderMatch{{Name: "X-Canary", Value: "always"}},
+		}},
+		Filters: []gatewayv1.HTTPRouteFilter{{
+			Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+			RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{Scheme: ptr.To("https")},
+		}},
+		BackendRefs: []gatewayv1.HTTPBackendRef{{
+			BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "app", Port: ptr.To(gatewayv1.PortNumber(80))}},
+		}},
+	}}
+
+	// The route and Gateway as read back from the API server
+	existingRoute := *generatedRoute.DeepCopy()
+	existingRoute.Spec.ParentRefs[0].Group = ptr.To(gatewayv1.Group(gatewayv1.GroupName))
+	existingRoute.Spec.ParentRefs[0].Kind = ptr.To(gatewayv1.Kind("Gateway"))
+	rule := &existingRoute.Spec.Rules[0]
+	rule.Matches[0].Path.Type = ptr.To(gatewayv1.PathMatchPathPrefix)
+	rule.Matches[0].Headers[0].Type = ptr.To(gatewayv1.HeaderMatchExact)
+	rule.Filters[0].RequestRedirect.StatusCode = ptr.To(302)
+	rule.BackendRefs[0].Weight = ptr.To(int32(1))
+	rule.BackendRefs[0].Group = ptr.To(gatewayv1.Group(""))
+	rule.BackendRefs[0].Kind = ptr.To(gatewayv1.Kind("Service"))
+
+	generatedGateway := gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwKey.Name, Namespace: gwKey.Namespace},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "istio",
+			Listeners: []gatewayv1.Listener{{
+				Name:     "https",
+				Port:     443,
+				Protocol: gatewayv1.HTTPSProtocolType,
+				TLS:      &gatewayv1.ListenerTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "cert"}}},
+			}},
+		},
+	}
+	existingGateway := *generatedGateway.DeepCopy()
+	listener := &existingGateway.Spec.Listeners[0]
+	listener.AllowedRoutes = &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: ptr.To(gatewayv1.NamespacesFromSame)}}
+	listener.TLS.Mode = ptr.To(gatewayv1.TLSModeTerminate)
+	listener.TLS.CertificateRefs[0].Group = ptr.To(gatewayv1.Group(""))
+	listener.TLS.CertificateRefs[0].Kind = ptr.To(gatewayv1.Kind("Secret"))
+
+	generated := []GatewayResources{{
+		Gateways:   map[types.NamespacedName]gatewayv1.Gateway{gwKey: generatedGateway},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{routeKey: generatedRoute},
+	}}
+	existing := GatewayResources{
+		Gateways:   map[types.NamespacedName]gatewayv1.Gateway{gwKey: existingGateway},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{routeKey: existingRoute},
+	}
+
+	if changes := Diff(generated, existing).Changes; len(changes) != 0 {
+		t.Errorf("expected no changes for resources only differing by API server defaults, got %v", changes)
+	}
+
+	// A real change is still reported
+	existing.HTTPRoutes[routeKey].Spec.Rules[0].BackendRefs[0].Weight = ptr.To(int32(2))
+	expected := []ResourceChange{{Kind: "HTTPRoute", Key: routeKey, Type: ChangeTypeUpdate}}
+	if diff := cmp.Diff(expected, Diff(generated, existing).Changes); diff != "" {
+		t.Errorf("Diff() changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_readExistingGatewayResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := gatewayv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+
+	httpRoute := diffTestHTTPRoute("route", "a.example.com", nil)
+	gateway := gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"}}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&httpRoute, &gateway).Build()
+
+	resources, err := readExistingGatewayResources(context.Background(), cl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := resources.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "route"}]; !ok {
+		t.Errorf("expected HTTPRoute default/route, got %v", resources.HTTPRoutes)
+	}
+	if _, ok := resources.Gateways[types.NamespacedName{Namespace: "default", Name: "gw"}]; !ok {
+		t.Errorf("expected Gateway default/gw, got %v", resources.Gateways)
+	}
+}
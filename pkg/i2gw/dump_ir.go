@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"sigs.k8s.io/yaml"
+)
+
+// MarshalIR serializes the IR of each provider, keyed by provider name.
+// The output is JSON if format is "json", and YAML otherwise.
+func MarshalIR(irByProvider map[ProviderName]intermediate.IR, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(irByProvider, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize IR: %w", err)
+	}
+	if format == "json" {
+		return append(data, '\n'), nil
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// DumpIR writes the IR of each provider to the given file for debugging.
+// Files with a .json extension are written as JSON, all others as YAML.
+func DumpIR(filename string, irByProvider map[ProviderName]intermediate.IR) error {
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		format = "json"
+	}
+
+	data, err := MarshalIR(irByProvider, format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write IR to %s: %w", filename, err)
+	}
+	return nil
+}
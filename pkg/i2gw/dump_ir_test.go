@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i2gw
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func dumpIRTestIR() map[ProviderName]intermediate.IR {
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "my-ingress", Namespace: "default"}}
+	return map[ProviderName]intermediate.IR{
+		"ingress-nginx": {
+			HTTPRoutes: map[types.NamespacedName]intermediate.HTTPRouteContext{
+				{Namespace: "default", Name: "my-route"}: {
+					HTTPRoute: gatewayv1.HTTPRoute{
+						ObjectMeta: metav1.ObjectMeta{Name: "my-route", Namespace: "default"},
+					},
+					ProviderSpecificIR: intermediate.ProviderSpecificHTTPRouteIR{
+						IngressNginx: &intermediate.IngressNginxHTTPRouteIR{
+							RateLimitRPS:   10,
+							RateLimitBurst: 50,
+						},
+					},
+					RuleBackendSources: [][]intermediate.BackendSource{
+						{{Ingress: ingress, DefaultBackend: &networkingv1.IngressBackend{}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDumpIR_YAML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ir.yaml")
+	if err := DumpIR(filename, dumpIRTestIR()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read dumped IR: %v", err)
+	}
+	dump := string(data)
+
+	for _, expected := range []string{
+		"ingress-nginx:",
+		"default/my-route:",
+		"RateLimitRPS: 10",
+		"RateLimitBurst: 50",
+		"Ingress: default/my-ingress",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("expected dumped IR to contain %q, got:\n%s", expected, dump)
+		}
+	}
+}
+
+func TestDumpIR_JSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ir.json")
+	if err := DumpIR(filename, dumpIRTestIR()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read dumped IR: %v", err)
+	}
+
+	var dump map[string]struct {
+		HTTPRoutes map[string]struct {
+			ProviderSpecificIR struct {
+				IngressNginx struct {
+					RateLimitRPS int
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatalf("dumped IR is not valid JSON: %v", err)
+	}
+
+	rps := dump["ingress-nginx"].HTTPRoutes["default/my-route"].ProviderSpecificIR.IngressNginx.RateLimitRPS
+	if rps != 10 {
+		t.Errorf("expected RateLimitRPS 10 in dumped IR, got %d", rps)
+	}
+}
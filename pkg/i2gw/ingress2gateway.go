@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,32 +37,56 @@ const GeneratorAnnotationKey = "gateway.networking.k8s.io/generator"
 // Examples: "v0.4.0", "v0.4.0-5-gabcdef", "v0.4.0-5-gabcdef-dirty"
 var Version = "dev" // Default value if not built with linker flags
 
-func ToGatewayAPIResources(ctx context.Context, namespace string, inputFile string, providers []string, providerSpecificFlags map[string]map[string]string) ([]GatewayResources, map[string]string, error) {
+// restConfigLoader loads the REST config for the given kubeconfig context.
+// An empty context selects the current context.
+var restConfigLoader = config.GetConfigWithContext
+
+// newClusterClient creates a client scoped to the given namespace, talking to
+// the cluster selected by the kubeconfig context.
+func newClusterClient(kubeContext string, namespace string, options client.Options) (client.Client, error) {
+	conf, err := restConfigLoader(kubeContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client config: %w", err)
+	}
+
+	cl, err := client.New(conf, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return client.NewNamespacedClient(cl, namespace), nil
+}
+
+// ToGatewayAPIResources reads the resources of the given providers from the
+// input file or the cluster and converts them to Gateway API resources. When
+// dumpIRFile is set, the IR produced by each provider is also written to it.
+// When checkCRDs is set, the resources are read from the cluster and adapted
+// to the Gateway API CRDs installed in it, see ApplyInstalledGatewayAPIKinds.
+func ToGatewayAPIResources(ctx context.Context, namespace string, kubeContext string, inputFile string, dumpIRFile string, checkCRDs bool, providers []string, providerSpecificFlags map[string]map[string]string) ([]GatewayResources, map[string]string, error) {
 	var clusterClient client.Client
 
 	if inputFile == "" {
-		conf, err := config.GetConfig()
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get client config: %w", err)
-		}
-
-		cl, err := client.New(conf, client.Options{})
+		cl, err := newClusterClient(kubeContext, namespace, client.Options{})
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create client: %w", err)
+			return nil, nil, err
 		}
-		clusterClient = client.NewNamespacedClient(cl, namespace)
+		clusterClient = cl
 	}
 
 	providerByName, err := constructProviders(&ProviderConf{
 		Client:                clusterClient,
 		Namespace:             namespace,
+		KubeContext:           kubeContext,
 		ProviderSpecificFlags: providerSpecificFlags,
 	}, providers)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	var installedKinds InstalledGatewayAPIKinds
 	if inputFile != "" {
+		if checkCRDs {
+			return nil, nil, fmt.Errorf("checking the installed Gateway API CRDs requires reading the resources from the cluster")
+		}
 		if err = readProviderResourcesFromFile(ctx, providerByName, inputFile); err != nil {
 			return nil, nil, err
 		}
@@ -69,19 +94,40 @@ func ToGatewayAPIResources(ctx context.Context, namespace string, inputFile stri
 		if err = readProviderResourcesFromCluster(ctx, providerByName); err != nil {
 			return nil, nil, err
 		}
+		if checkCRDs {
+			discoveryClient, err := newDiscoveryClient(kubeContext)
+			if err != nil {
+				return nil, nil, err
+			}
+			if installedKinds, err = DiscoverGatewayAPIKinds(discoveryClient); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	var (
 		gatewayResources []GatewayResources
 		errs             field.ErrorList
 	)
-	for _, provider := range providerByName {
+	irByProvider := make(map[ProviderName]intermediate.IR, len(providerByName))
+	for name, provider := range providerByName {
 		ir, conversionErrs := provider.ToIR()
 		errs = append(errs, conversionErrs...)
+		irByProvider[name] = ir
 		providerGatewayResources, conversionErrs := provider.ToGatewayResources(ir)
 		errs = append(errs, conversionErrs...)
+		if checkCRDs {
+			ApplyInstalledGatewayAPIKinds(&providerGatewayResources, installedKinds, name)
+		}
 		gatewayResources = append(gatewayResources, providerGatewayResources)
 	}
+
+	// Dump the IR even if the conversion failed, since that is when it is most useful
+	if dumpIRFile != "" {
+		if err := DumpIR(dumpIRFile, irByProvider); err != nil {
+			return nil, nil, err
+		}
+	}
 	notificationTablesMap := notifications.NotificationAggr.CreateNotificationTables()
 	if len(errs) > 0 {
 		return nil, notificationTablesMap, aggregatedErrs(errs)
@@ -17,10 +17,14 @@ limitations under the License.
 package i2gw
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -93,3 +97,48 @@ func Test_GetSupportedProviders(t *testing.T) {
 		}
 	})
 }
+
+// noopProvider is a Provider that reads and converts nothing.
+type noopProvider struct{}
+
+func (noopProvider) ReadResourcesFromCluster(_ context.Context) error { return nil }
+
+func (noopProvider) ReadResourcesFromFile(_ context.Context, _ string) error { return nil }
+
+func (noopProvider) ToIR() (intermediate.IR, field.ErrorList) { return intermediate.IR{}, nil }
+
+func (noopProvider) ToGatewayResources(_ intermediate.IR) (GatewayResources, field.ErrorList) {
+	return GatewayResources{}, nil
+}
+
+func Test_ToGatewayAPIResources_KubeContext(t *testing.T) {
+	var loadedContext string
+	originalLoader := restConfigLoader
+	restConfigLoader = func(kubeContext string) (*rest.Config, error) {
+		loadedContext = kubeContext
+		return &rest.Config{Host: "https://127.0.0.1:6443"}, nil
+	}
+	t.Cleanup(func() { restConfigLoader = originalLoader })
+
+	var providerConf *ProviderConf
+	ProviderConstructorByName["kube-context-test"] = func(conf *ProviderConf) Provider {
+		providerConf = conf
+		return noopProvider{}
+	}
+	t.Cleanup(func() { delete(ProviderConstructorByName, "kube-context-test") })
+
+	_, _, err := ToGatewayAPIResources(context.Background(), "default", "staging", "", "", false, []string{"kube-context-test"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if loadedContext != "staging" {
+		t.Errorf("Expected the client config to be loaded for context %q but got %q", "staging", loadedContext)
+	}
+	if providerConf == nil || providerConf.KubeContext != "staging" {
+		t.Errorf("Expected the provider conf to carry context %q but got %+v", "staging", providerConf)
+	}
+	if providerConf != nil && providerConf.Client == nil {
+		t.Errorf("Expected the provider conf to carry a cluster client")
+	}
+}
@@ -16,10 +16,16 @@ limitations under the License.
 
 package intermediate
 
+import networkingv1 "k8s.io/api/networking/v1"
+
 // IngressNginxGatewayIR holds ingress-nginx specific Gateway configuration
 type IngressNginxGatewayIR struct {
 	// EnableSSLRedirect indicates if HTTP to HTTPS redirect should be enabled
 	EnableSSLRedirect bool
+
+	// TLSOnlyIngresses are the Ingresses with TLS but no rules the Gateway has
+	// HTTPS listeners of, which no HTTPRoute attaches to
+	TLSOnlyIngresses []*networkingv1.Ingress
 }
 
 // IngressNginxHTTPRouteIR holds ingress-nginx specific HTTPRoute configuration
@@ -27,6 +33,18 @@ type IngressNginxHTTPRouteIR struct {
 	// SSLRedirect indicates if this route should redirect HTTP to HTTPS
 	SSLRedirect bool
 
+	// UsePortInRedirects indicates if the HTTPS port should be set explicitly
+	// on the SSL redirect
+	UsePortInRedirects bool
+
+	// ListenerPortHint is the port the Ingress rule host of the route
+	// mistakenly included (example.com:8080), 0 if none
+	ListenerPortHint int32
+
+	// GatewayMode overrides the global gateway mode for this route
+	// ("centralized" or "per-namespace")
+	GatewayMode string
+
 	// ProxyBuffering indicates if proxy buffering is enabled
 	ProxyBuffering *bool
 
@@ -36,17 +54,135 @@ type IngressNginxHTTPRouteIR struct {
 	// ProxyBodySize is the max body size (e.g., "100m")
 	ProxyBodySize string
 
+	// ClientBodyBufferBytes is the size of the buffer for reading request
+	// bodies, from client-body-buffer-size, 0 if unset
+	ClientBodyBufferBytes int64
+
+	// ConnectTimeoutSeconds is the timeout for establishing a connection to
+	// the backends, from proxy-connect-timeout
+	ConnectTimeoutSeconds int
+
 	// RateLimitRPS is the rate limit in requests per second
 	RateLimitRPS int
 
+	// RateLimitSource is the annotation RateLimitRPS was converted from,
+	// limit-rps, limit-rpm or limit-req-zone
+	RateLimitSource string
+
 	// RateLimitBurst is the burst limit for rate limiting
 	RateLimitBurst int
 
+	// RateLimitNoDelay indicates requests within the burst are served
+	// immediately instead of being delayed to match the rate (nginx nodelay)
+	RateLimitNoDelay bool
+
+	// AllowSourceRanges are the client CIDRs allowed to access the route, from
+	// whitelist-source-range. Empty allows all clients.
+	AllowSourceRanges []string
+
+	// RateLimitExemptRanges are the client CIDRs not rate limited, from
+	// limit-whitelist
+	RateLimitExemptRanges []string
+
 	// ClientCertAuth holds client certificate authentication configuration
 	ClientCertAuth *ClientCertAuthConfig
 
 	// ExternalAuth holds external authentication configuration
 	ExternalAuth *ExternalAuthConfig
+
+	// MaxRequestHeadersKB is the max total request headers size in KB, from
+	// header buffer directives in a server-snippet
+	MaxRequestHeadersKB int
+
+	// Tracing holds request tracing configuration
+	Tracing *TracingConfig
+
+	// AccessLogFormat is the Envoy access log format of the route, translated
+	// from log-format-upstream
+	AccessLogFormat string
+
+	// UpstreamHTTPVersion is the HTTP version used to proxy requests to the
+	// backends ("1.0" or "1.1"), from proxy-http-version
+	UpstreamHTTPVersion string
+
+	// ProxyBufferBytes is the total size of the buffers for backend responses,
+	// proxy-buffers-number × proxy-buffer-size, 0 if unset
+	ProxyBufferBytes int64
+
+	// CustomHTTPErrors holds the statuses served by an error backend, from
+	// custom-http-errors and default-backend
+	CustomHTTPErrors *CustomHTTPErrorsConfig
+
+	// CORS holds the CORS policy of the route, from enable-cors
+	CORS *CORSConfig
+
+	// ProxyRedirect holds the rewrite of the Location response headers of the
+	// route, from proxy-redirect-from and proxy-redirect-to
+	ProxyRedirect *ProxyRedirectConfig
+}
+
+// ProxyRedirectConfig holds the rewrite of the Location response headers of a
+// route. Variables and regex captures are written as ${name}.
+type ProxyRedirectConfig struct {
+	// From is the Location prefix replaced by To, or a Lua pattern matching
+	// the Location if Regex is set
+	From string
+
+	// To replaces the From prefix, or the whole Location if Regex is set
+	To string
+
+	// Regex indicates if From is a Lua pattern
+	Regex bool
+}
+
+// CORSConfig holds the CORS policy of a route
+type CORSConfig struct {
+	// AllowOrigins are the origins allowed to make cross-origin requests
+	AllowOrigins []CORSOrigin
+
+	// AllowMethods is the comma separated list of allowed methods
+	AllowMethods string
+
+	// AllowHeaders is the comma separated list of allowed request headers
+	AllowHeaders string
+
+	// ExposeHeaders is the comma separated list of response headers exposed
+	// to the client
+	ExposeHeaders string
+
+	// AllowCredentials indicates if requests with credentials are allowed
+	AllowCredentials bool
+
+	// MaxAge is how long the preflight response can be cached, in seconds
+	MaxAge int
+}
+
+// CORSOrigin is an allowed CORS origin
+type CORSOrigin struct {
+	// Value is the origin, or a RE2 expression matching the full origin if
+	// Regex is set
+	Value string
+
+	// Regex indicates if Value is a regular expression
+	Regex bool
+}
+
+// CustomHTTPErrorsConfig holds the custom error backend settings of a route
+type CustomHTTPErrorsConfig struct {
+	// Codes are the response statuses replaced by the error backend response
+	Codes []int
+
+	// Service is the name of the error backend service
+	Service string
+
+	// Namespace is the namespace of the error backend service
+	Namespace string
+
+	// Port is the port of the error backend service
+	Port int32
+
+	// IngressName is the name of the Ingress, sent to the error backend
+	IngressName string
 }
 
 // ClientCertAuthConfig holds client certificate authentication settings
@@ -78,6 +214,10 @@ type ExternalAuthConfig struct {
 	// SigninURL is the URL to redirect unauthenticated requests to
 	SigninURL string
 
+	// SigninRedirectParam is the query parameter of the SigninURL carrying
+	// the original request URL
+	SigninRedirectParam string
+
 	// ResponseHeaders are headers to copy from auth response to request
 	ResponseHeaders []string
 
@@ -89,6 +229,23 @@ type ExternalAuthConfig struct {
 
 	// CacheDuration is how long to cache auth responses
 	CacheDuration string
+
+	// FailOpen allows requests through when the auth service is unavailable
+	FailOpen bool
+
+	// JWTIssuer is set when the auth service validates JWTs of this issuer,
+	// which are then validated by the Gateway instead
+	JWTIssuer string
+
+	// JWKSURI is the URL of the public keys of the JWT issuer, discovered from
+	// the issuer if empty
+	JWKSURI string
+}
+
+// TracingConfig holds request tracing settings
+type TracingConfig struct {
+	// SamplingPercentage is the percentage of requests to trace (0-100)
+	SamplingPercentage float64
 }
 
 // IngressNginxServiceIR holds ingress-nginx specific Service configuration
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intermediate
+
+import (
+	"encoding/json"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// MarshalJSON serializes the IR for debugging. Field names are kept as in the
+// Go types, matching the untagged provider-specific IR structs. Maps keyed by
+// types.NamespacedName are keyed by "namespace/name" instead, since JSON
+// object keys must be strings.
+func (ir IR) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Gateways   map[string]GatewayContext            `json:",omitempty"`
+		HTTPRoutes map[string]HTTPRouteContext          `json:",omitempty"`
+		Services   map[string]ProviderSpecificServiceIR `json:",omitempty"`
+
+		GatewayClasses map[string]gatewayv1.GatewayClass   `json:",omitempty"`
+		TLSRoutes      map[string]gatewayv1alpha2.TLSRoute `json:",omitempty"`
+		TCPRoutes      map[string]gatewayv1alpha2.TCPRoute `json:",omitempty"`
+		UDPRoutes      map[string]gatewayv1alpha2.UDPRoute `json:",omitempty"`
+		GRPCRoutes     map[string]gatewayv1.GRPCRoute      `json:",omitempty"`
+
+		BackendTLSPolicies map[string]gatewayv1.BackendTLSPolicy    `json:",omitempty"`
+		ReferenceGrants    map[string]gatewayv1beta1.ReferenceGrant `json:",omitempty"`
+	}{
+		Gateways:           stringKeyed(ir.Gateways),
+		HTTPRoutes:         stringKeyed(ir.HTTPRoutes),
+		Services:           stringKeyed(ir.Services),
+		GatewayClasses:     stringKeyed(ir.GatewayClasses),
+		TLSRoutes:          stringKeyed(ir.TLSRoutes),
+		TCPRoutes:          stringKeyed(ir.TCPRoutes),
+		UDPRoutes:          stringKeyed(ir.UDPRoutes),
+		GRPCRoutes:         stringKeyed(ir.GRPCRoutes),
+		BackendTLSPolicies: stringKeyed(ir.BackendTLSPolicies),
+		ReferenceGrants:    stringKeyed(ir.ReferenceGrants),
+	})
+}
+
+// MarshalJSON serializes the BackendSource for debugging. The source Ingress is
+// referenced by "namespace/name" rather than embedded in full.
+func (s BackendSource) MarshalJSON() ([]byte, error) {
+	var ingress string
+	if s.Ingress != nil {
+		ingress = types.NamespacedName{Namespace: s.Ingress.Namespace, Name: s.Ingress.Name}.String()
+	}
+	return json.Marshal(struct {
+		Ingress        string                        `json:",omitempty"`
+		Path           *networkingv1.HTTPIngressPath `json:",omitempty"`
+		DefaultBackend *networkingv1.IngressBackend  `json:",omitempty"`
+	}{
+		Ingress:        ingress,
+		Path:           s.Path,
+		DefaultBackend: s.DefaultBackend,
+	})
+}
+
+func stringKeyed[T any](m map[types.NamespacedName]T) map[string]T {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]T, len(m))
+	for key, value := range m {
+		out[key.String()] = value
+	}
+	return out
+}
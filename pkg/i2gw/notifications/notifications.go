@@ -18,6 +18,7 @@ package notifications
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -38,10 +39,36 @@ const (
 
 type MessageType string
 
+// ErrorCode identifies the kind of problem a notification reports, so tools
+// can act on specific migration blockers without parsing the message text.
+type ErrorCode string
+
+const (
+	// UnsupportedRewriteCaptureGroups is a rewrite target referencing regex
+	// capture groups, which Gateway API URLRewrite filters can't express.
+	UnsupportedRewriteCaptureGroups ErrorCode = "UnsupportedRewriteCaptureGroups"
+	// UnsupportedRewriteTarget is a rewrite target that needs manual review.
+	UnsupportedRewriteTarget ErrorCode = "UnsupportedRewriteTarget"
+	// UnsupportedSnippet is raw proxy configuration with no Gateway API equivalent.
+	UnsupportedSnippet ErrorCode = "UnsupportedSnippet"
+	// UnsupportedRegexPath is a regex path that can't be converted to a path match.
+	UnsupportedRegexPath ErrorCode = "UnsupportedRegexPath"
+	// MissingSecret is a Secret referenced by an annotation that is not part of
+	// the input.
+	MissingSecret ErrorCode = "MissingSecret"
+	// UnsupportedResourceBackend is an Ingress backend referencing a resource
+	// instead of a Service, which Gateway API has no equivalent for.
+	UnsupportedResourceBackend ErrorCode = "UnsupportedResourceBackend"
+	// InvalidHostname is an Ingress host that is not a valid Gateway API hostname.
+	InvalidHostname ErrorCode = "InvalidHostname"
+)
+
 type Notification struct {
 	Type           MessageType
 	Message        string
 	CallingObjects []client.Object
+	// Code is optional and set for notifications about known migration blockers
+	Code ErrorCode
 }
 
 type NotificationAggregator struct {
@@ -58,6 +85,53 @@ func (na *NotificationAggregator) DispatchNotification(notification Notification
 	na.mutex.Unlock()
 }
 
+// ProviderNotifications returns a copy of the notifications dispatched by the provider
+func (na *NotificationAggregator) ProviderNotifications(providerName string) []Notification {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+	return append([]Notification(nil), na.Notifications[providerName]...)
+}
+
+// Blockers returns the ERROR notifications dispatched by the providers, the
+// migration blockers, ordered by provider name
+func (na *NotificationAggregator) Blockers() []Notification {
+	na.mutex.Lock()
+	defer na.mutex.Unlock()
+
+	providers := make([]string, 0, len(na.Notifications))
+	for provider := range na.Notifications {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	var blockers []Notification
+	for _, provider := range providers {
+		for _, n := range na.Notifications[provider] {
+			if n.Type == ErrorNotification {
+				blockers = append(blockers, n)
+			}
+		}
+	}
+	return blockers
+}
+
+// BlockerSummary returns one line per blocker with its code, the first line
+// of its message and its calling objects
+func BlockerSummary(blockers []Notification) string {
+	var sb strings.Builder
+	for i, n := range blockers {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		message, _, _ := strings.Cut(n.Message, "\n")
+		fmt.Fprintf(&sb, "- %s: %s", messageTypeStr(n), message)
+		if objects := convertObjectsToStr(n.CallingObjects); objects != "" {
+			fmt.Fprintf(&sb, " (%s)", objects)
+		}
+	}
+	return sb.String()
+}
+
 // CreateNotificationTables takes all generated notifications and returns a map[string]string
 // that displays the notifications in a tabular format based on provider
 func (na *NotificationAggregator) CreateNotificationTables() map[string]string {
@@ -72,7 +146,7 @@ func (na *NotificationAggregator) CreateNotificationTables() map[string]string {
 		t.SetRowLine(true)
 
 		for _, n := range msgs {
-			row := []string{string(n.Type), n.Message, convertObjectsToStr(n.CallingObjects)}
+			row := []string{messageTypeStr(n), n.Message, convertObjectsToStr(n.CallingObjects)}
 			t.Append(row)
 		}
 
@@ -84,6 +158,15 @@ func (na *NotificationAggregator) CreateNotificationTables() map[string]string {
 	return notificationTablesMap
 }
 
+// messageTypeStr returns the message type of the notification, followed by
+// its code if it has one
+func messageTypeStr(n Notification) string {
+	if n.Code == "" {
+		return string(n.Type)
+	}
+	return fmt.Sprintf("%s (%s)", n.Type, n.Code)
+}
+
 func convertObjectsToStr(ob []client.Object) string {
 	var sb strings.Builder
 
@@ -118,3 +201,8 @@ func convertObjectsToStr(ob []client.Object) string {
 func NewNotification(mType MessageType, message string, callingObject ...client.Object) Notification {
 	return Notification{Type: mType, Message: message, CallingObjects: callingObject}
 }
+
+// NewNotificationWithCode returns a notification tagged with an ErrorCode
+func NewNotificationWithCode(mType MessageType, code ErrorCode, message string, callingObject ...client.Object) Notification {
+	return Notification{Type: mType, Code: code, Message: message, CallingObjects: callingObject}
+}
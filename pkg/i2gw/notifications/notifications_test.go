@@ -56,6 +56,27 @@ func TestCreateNotificationsTables(t *testing.T) {
 +--------------+-----------------+----------------+
 | WARNING      | warning message |                |
 +--------------+-----------------+----------------+
+`,
+			},
+		},
+		{
+			name: "notification with an error code",
+			notifications: map[string][]Notification{
+				"provider1": {
+					{
+						Type:    ErrorNotification,
+						Code:    UnsupportedSnippet,
+						Message: "snippet",
+					},
+				},
+			},
+			wantedTables: map[string]string{
+				"provider1": `Notifications from PROVIDER1:
++----------------------------+--------------+----------------+
+|        MESSAGE TYPE        | NOTIFICATION | CALLING OBJECT |
++----------------------------+--------------+----------------+
+| ERROR (UnsupportedSnippet) | snippet      |                |
++----------------------------+--------------+----------------+
 `,
 			},
 		},
@@ -208,3 +229,25 @@ func TestConvertObjectsToStr(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockers(t *testing.T) {
+	ingress := &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+	}
+	aggregator := NotificationAggregator{Notifications: map[string][]Notification{
+		"nginx": {
+			NewNotification(InfoNotification, "converted", ingress),
+			NewNotification(ErrorNotification, "invalid value"),
+		},
+		"ingress-nginx": {
+			NewNotificationWithCode(ErrorNotification, UnsupportedSnippet, "MIGRATION BLOCKER - server-snippet\nRaw nginx configuration", ingress),
+			NewNotification(WarningNotification, "partially converted", ingress),
+		},
+	}}
+
+	blockers := aggregator.Blockers()
+	expected := "- ERROR (UnsupportedSnippet): MIGRATION BLOCKER - server-snippet (Ingress: default/app)\n" +
+		"- ERROR: invalid value"
+	assert.Equal(t, expected, BlockerSummary(blockers))
+}
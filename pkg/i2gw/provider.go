@@ -46,8 +46,11 @@ type ProviderConstructor func(conf *ProviderConf) Provider
 // ProviderConf contains all the configuration required for every concrete
 // Provider implementation.
 type ProviderConf struct {
-	Client                client.Client
-	Namespace             string
+	Client    client.Client
+	Namespace string
+	// KubeContext is the kubeconfig context of the cluster that Client talks to.
+	// Empty when the current context is used.
+	KubeContext           string
 	ProviderSpecificFlags map[string]map[string]string
 }
 
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// AllowCrossNamespaceRoutes sets allowedRoutes on the listeners of each Gateway
+// that has HTTPRoutes attached from other namespaces. Listeners only allow
+// routes from their own namespace by default, so without this a centralized or
+// dedicated-namespace Gateway would reject the converted routes.
+func AllowCrossNamespaceRoutes(gatewayResources *i2gw.GatewayResources) {
+	routeNamespaces := make(map[types.NamespacedName]sets.Set[string])
+	for routeKey, route := range gatewayResources.HTTPRoutes {
+		for _, parentRef := range route.Spec.ParentRefs {
+			if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+				continue
+			}
+			gwKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				gwKey.Namespace = string(*parentRef.Namespace)
+			}
+			if routeNamespaces[gwKey] == nil {
+				routeNamespaces[gwKey] = sets.New[string]()
+			}
+			routeNamespaces[gwKey].Insert(routeKey.Namespace)
+		}
+	}
+
+	for gwKey, gateway := range gatewayResources.Gateways {
+		AllowRoutesFromNamespaces(&gateway, routeNamespaces[gwKey])
+		gatewayResources.Gateways[gwKey] = gateway
+	}
+}
+
+// AllowRoutesFromNamespaces sets allowedRoutes on all listeners of the Gateway
+// to a selector matching the given namespaces, using the
+// kubernetes.io/metadata.name label set on every namespace. Listeners are left
+// unchanged if all namespaces are the Gateway namespace.
+func AllowRoutesFromNamespaces(gateway *gatewayv1.Gateway, namespaces sets.Set[string]) {
+	if namespaces.Len() == 0 || namespaces.Equal(sets.New(gateway.Namespace)) {
+		return
+	}
+
+	from := gatewayv1.NamespacesFromSelector
+	allowedRoutes := gatewayv1.AllowedRoutes{
+		Namespaces: &gatewayv1.RouteNamespaces{
+			From: &from,
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      apiv1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   sets.List(namespaces),
+				}},
+			},
+		},
+	}
+	for i := range gateway.Spec.Listeners {
+		gateway.Spec.Listeners[i].AllowedRoutes = allowedRoutes.DeepCopy()
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestAllowCrossNamespaceRoutes(t *testing.T) {
+	gatewayNamespace := gatewayv1.Namespace("gateways")
+
+	testCases := []struct {
+		name               string
+		routeNamespaces    []string
+		expectedNamespaces []string
+	}{
+		{
+			name:            "routes in the gateway namespace",
+			routeNamespaces: []string{"gateways"},
+		},
+		{
+			name:               "routes in other namespaces",
+			routeNamespaces:    []string{"team-b", "team-a", "team-a"},
+			expectedNamespaces: []string{"team-a", "team-b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gwKey := types.NamespacedName{Namespace: string(gatewayNamespace), Name: "gateway"}
+			gatewayResources := i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+					gwKey: {
+						ObjectMeta: metav1.ObjectMeta{Namespace: gwKey.Namespace, Name: gwKey.Name},
+						Spec: gatewayv1.GatewaySpec{
+							Listeners: []gatewayv1.Listener{{Name: "http"}, {Name: "https"}},
+						},
+					},
+				},
+				HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{},
+			}
+			for i, namespace := range tc.routeNamespaces {
+				routeKey := types.NamespacedName{Namespace: namespace, Name: string(rune('a' + i))}
+				gatewayResources.HTTPRoutes[routeKey] = gatewayv1.HTTPRoute{
+					Spec: gatewayv1.HTTPRouteSpec{
+						CommonRouteSpec: gatewayv1.CommonRouteSpec{
+							ParentRefs: []gatewayv1.ParentReference{{Namespace: &gatewayNamespace, Name: "gateway"}},
+						},
+					},
+				}
+			}
+
+			AllowCrossNamespaceRoutes(&gatewayResources)
+
+			for _, listener := range gatewayResources.Gateways[gwKey].Spec.Listeners {
+				if tc.expectedNamespaces == nil {
+					if listener.AllowedRoutes != nil {
+						t.Errorf("listener %s: expected allowedRoutes to be unset, got %v", listener.Name, listener.AllowedRoutes)
+					}
+					continue
+				}
+				if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil || listener.AllowedRoutes.Namespaces.Selector == nil {
+					t.Fatalf("listener %s: expected an allowedRoutes namespace selector", listener.Name)
+				}
+				values := listener.AllowedRoutes.Namespaces.Selector.MatchExpressions[0].Values
+				if !reflect.DeepEqual(values, tc.expectedNamespaces) {
+					t.Errorf("listener %s: expected namespaces %v, got %v", listener.Name, tc.expectedNamespaces, values)
+				}
+			}
+		})
+	}
+}
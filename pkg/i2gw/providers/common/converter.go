@@ -187,9 +187,8 @@ func (a *ingressAggregator) addIngressRule(ingress networkingv1.Ingress, ingress
 		}
 		a.ruleGroups[rgKey] = rg
 	}
-	if len(ingress.Spec.TLS) > 0 {
-		rg.tls = append(rg.tls, ingress.Spec.TLS...)
-	}
+	// Only the TLS entries covering the rule host give it an HTTPS listener
+	rg.tls = append(rg.tls, IngressTLSForHost(ingress.Spec.TLS, rule.Host)...)
 	rg.rules = append(rg.rules, ingressRule{
 		ingress: &ingress,
 		rule:    rule,
@@ -228,8 +227,11 @@ func (a *ingressAggregator) toHTTPRoutesAndGateways(options i2gw.ProviderImpleme
 			listener.TLS = &gatewayv1.ListenerTLSConfig{}
 		}
 		for _, tls := range rg.tls {
-			listener.TLS.CertificateRefs = append(listener.TLS.CertificateRefs,
-				gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(tls.SecretName)})
+			// The same secret is listed again for every rule and Ingress sharing the host
+			certificateRef := gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(tls.SecretName)}
+			if !slices.Contains(listener.TLS.CertificateRefs, certificateRef) {
+				listener.TLS.CertificateRefs = append(listener.TLS.CertificateRefs, certificateRef)
+			}
 		}
 		gwKey := fmt.Sprintf("%s/%s", rg.namespace, rg.ingressClass)
 		listenersByNamespacedGateway[gwKey] = append(listenersByNamespacedGateway[gwKey], listener)
@@ -498,3 +498,74 @@ func Test_ToIR(t *testing.T) {
 		})
 	}
 }
+
+func Test_ToIR_TLSMultipleHosts(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	rule := func(host string) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &iPrefix,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: "web",
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							},
+						},
+					}},
+				},
+			},
+		}
+	}
+
+	ingresses := []networkingv1.Ingress{{
+		ObjectMeta: metav1.ObjectMeta{Name: "multi-host", Namespace: "test"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: PtrTo("nginx"),
+			TLS: []networkingv1.IngressTLS{{
+				Hosts:      []string{"a.example.com", "b.example.com"},
+				SecretName: "shared-cert",
+			}},
+			Rules: []networkingv1.IngressRule{
+				rule("a.example.com"),
+				rule("b.example.com"),
+				rule("plain.example.com"),
+			},
+		},
+	}}
+
+	ir, errs := ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	gatewayCtx, ok := ir.Gateways[types.NamespacedName{Namespace: "test", Name: "nginx"}]
+	if !ok {
+		t.Fatalf("expected Gateway test/nginx, got %v", ir.Gateways)
+	}
+
+	sharedCert := []gatewayv1.SecretObjectReference{{Name: "shared-cert"}}
+	expectedTLS := map[gatewayv1.SectionName][]gatewayv1.SecretObjectReference{
+		"a-example-com-http":     nil,
+		"a-example-com-https":    sharedCert,
+		"b-example-com-http":     nil,
+		"b-example-com-https":    sharedCert,
+		"plain-example-com-http": nil,
+	}
+
+	gotTLS := map[gatewayv1.SectionName][]gatewayv1.SecretObjectReference{}
+	for _, listener := range gatewayCtx.Gateway.Spec.Listeners {
+		var certificateRefs []gatewayv1.SecretObjectReference
+		if listener.TLS != nil {
+			certificateRefs = listener.TLS.CertificateRefs
+		}
+		gotTLS[listener.Name] = certificateRefs
+	}
+	if diff := cmp.Diff(expectedTLS, gotTLS); diff != "" {
+		t.Errorf("unexpected listener certificates (-want +got):\n%s", diff)
+	}
+}
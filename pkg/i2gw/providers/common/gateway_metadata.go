@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ParseGatewayAnnotations parses a comma-separated list of key=value pairs
+// into the annotations of generated Gateways.
+func ParseGatewayAnnotations(value string, path *field.Path) (map[string]string, field.ErrorList) {
+	return parseKeyValuePairs(value, path, nil)
+}
+
+// ParseGatewayLabels parses a comma-separated list of key=value pairs into the
+// labels of generated Gateways. Unlike annotations, label values are limited
+// to 63 alphanumeric characters, '-', '_' or '.'.
+func ParseGatewayLabels(value string, path *field.Path) (map[string]string, field.ErrorList) {
+	return ParseLabels(value, path)
+}
+
+// ParseLabels parses a comma-separated list of key=value pairs into labels,
+// validating the keys and values like ParseGatewayLabels.
+func ParseLabels(value string, path *field.Path) (map[string]string, field.ErrorList) {
+	return parseKeyValuePairs(value, path, validation.IsValidLabelValue)
+}
+
+func parseKeyValuePairs(value string, path *field.Path, validateValue func(string) []string) (map[string]string, field.ErrorList) {
+	var errs field.ErrorList
+	pairs := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found {
+			errs = append(errs, field.Invalid(path, value, "must be a comma-separated list of key=value pairs"))
+			continue
+		}
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path, value, "key "+key+": "+msg))
+		}
+		if validateValue != nil {
+			for _, msg := range validateValue(val) {
+				errs = append(errs, field.Invalid(path, value, "value of "+key+": "+msg))
+			}
+		}
+		pairs[key] = val
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return pairs, nil
+}
+
+// ApplyGatewayMetadata adds the annotations and labels to every generated
+// Gateway, overriding existing values of the same keys.
+func ApplyGatewayMetadata(gatewayResources *i2gw.GatewayResources, annotations, labels map[string]string) {
+	if len(annotations) == 0 && len(labels) == 0 {
+		return
+	}
+
+	for key, gateway := range gatewayResources.Gateways {
+		if len(annotations) > 0 {
+			// Gateways may share maps with their templates, so copy before writing
+			gateway.Annotations = maps.Clone(gateway.Annotations)
+			if gateway.Annotations == nil {
+				gateway.Annotations = make(map[string]string, len(annotations))
+			}
+			maps.Copy(gateway.Annotations, annotations)
+		}
+		if len(labels) > 0 {
+			gateway.Labels = maps.Clone(gateway.Labels)
+			if gateway.Labels == nil {
+				gateway.Labels = make(map[string]string, len(labels))
+			}
+			maps.Copy(gateway.Labels, labels)
+		}
+		gatewayResources.Gateways[key] = gateway
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestParseGatewayMetadata(t *testing.T) {
+	testCases := []struct {
+		name           string
+		value          string
+		labels         bool
+		expected       map[string]string
+		expectedErrors int
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "annotations with prefixed keys and spaces",
+			value:    "networking.istio.io/service-type=ClusterIP, team = platform ,",
+			expected: map[string]string{"networking.istio.io/service-type": "ClusterIP", "team": "platform"},
+		},
+		{
+			name:     "annotation value with special characters",
+			value:    "description=edge gateway: public",
+			expected: map[string]string{"description": "edge gateway: public"},
+		},
+		{
+			name:     "label with empty value",
+			value:    "istio.io/rev=,env=prod",
+			labels:   true,
+			expected: map[string]string{"istio.io/rev": "", "env": "prod"},
+		},
+		{
+			name:           "missing value separator",
+			value:          "team",
+			expectedErrors: 1,
+		},
+		{
+			name:           "invalid key",
+			value:          "bad key=value",
+			expectedErrors: 1,
+		},
+		{
+			name:           "invalid label value",
+			value:          "description=edge gateway",
+			labels:         true,
+			expectedErrors: 1,
+		},
+	}
+
+	path := field.NewPath("test")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pairs map[string]string
+			var errs field.ErrorList
+			if tc.labels {
+				pairs, errs = ParseGatewayLabels(tc.value, path)
+			} else {
+				pairs, errs = ParseGatewayAnnotations(tc.value, path)
+			}
+
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("expected %d errors, got %d: %v", tc.expectedErrors, len(errs), errs)
+			}
+			if tc.expectedErrors > 0 {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, pairs); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyGatewayMetadata(t *testing.T) {
+	sharedLabels := map[string]string{"existing": "true"}
+	gatewayResources := i2gw.GatewayResources{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			{Namespace: "a", Name: "gw"}: {ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "gw", Labels: sharedLabels}},
+			{Namespace: "b", Name: "gw"}: {ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "gw"}},
+		},
+	}
+
+	ApplyGatewayMetadata(&gatewayResources,
+		map[string]string{"networking.istio.io/service-type": "ClusterIP"},
+		map[string]string{"env": "prod", "existing": "overridden"},
+	)
+
+	for key, gateway := range gatewayResources.Gateways {
+		if gateway.Annotations["networking.istio.io/service-type"] != "ClusterIP" {
+			t.Errorf("Gateway %s: expected annotation to be set, got %v", key, gateway.Annotations)
+		}
+		if gateway.Labels["env"] != "prod" || gateway.Labels["existing"] != "overridden" {
+			t.Errorf("Gateway %s: expected labels to be set, got %v", key, gateway.Labels)
+		}
+	}
+	if sharedLabels["existing"] != "true" || len(sharedLabels) != 1 {
+		t.Errorf("expected the original labels map to be left unchanged, got %v", sharedLabels)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// GatewayNamespacePlaceholder is replaced by the service namespace when
+// expanding a per-namespace gateway namespace template.
+const GatewayNamespacePlaceholder = "{ns}"
+
+// DefaultGatewayNamespaceTemplate places each per-namespace Gateway in a
+// dedicated "<namespace>-gateway" namespace, with the same name.
+const DefaultGatewayNamespaceTemplate = GatewayNamespacePlaceholder + "-gateway"
+
+// ExpandGatewayNamespaceTemplate returns the Gateway namespace and name for the
+// given service namespace. A template of the form "<namespace>/<name>" sets
+// both separately (e.g. "gateways/{ns}"), otherwise the expanded template is
+// used as both namespace and name (e.g. "{ns}-gw").
+func ExpandGatewayNamespaceTemplate(template, serviceNamespace string) (namespace, name string) {
+	expanded := strings.ReplaceAll(template, GatewayNamespacePlaceholder, serviceNamespace)
+	if namespace, name, found := strings.Cut(expanded, "/"); found {
+		return namespace, name
+	}
+	return expanded, expanded
+}
+
+// ValidateGatewayNamespaceTemplate checks that the template references the
+// service namespace and expands to a legal namespace and Gateway name.
+func ValidateGatewayNamespaceTemplate(template string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if !strings.Contains(template, GatewayNamespacePlaceholder) {
+		errs = append(errs, field.Invalid(path, template,
+			fmt.Sprintf("must contain %s, otherwise all namespaces share one Gateway (use centralized mode instead)", GatewayNamespacePlaceholder)))
+		return errs
+	}
+	if strings.Count(template, "/") > 1 {
+		errs = append(errs, field.Invalid(path, template, "must be of the form <namespace> or <namespace>/<name>"))
+		return errs
+	}
+
+	// Expanding with a minimal namespace checks the characters of the template;
+	// only length limits depend on the actual service namespace.
+	namespace, name := ExpandGatewayNamespaceTemplate(template, "ns")
+	for _, msg := range validation.IsDNS1123Label(namespace) {
+		errs = append(errs, field.Invalid(path, template, "gateway namespace "+msg))
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, field.Invalid(path, template, "gateway name "+msg))
+	}
+
+	return errs
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestExpandGatewayNamespaceTemplate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		template          string
+		expectedNamespace string
+		expectedName      string
+	}{
+		{
+			name:              "default template",
+			template:          DefaultGatewayNamespaceTemplate,
+			expectedNamespace: "team-a-gateway",
+			expectedName:      "team-a-gateway",
+		},
+		{
+			name:              "custom suffix",
+			template:          "{ns}-gw",
+			expectedNamespace: "team-a-gw",
+			expectedName:      "team-a-gw",
+		},
+		{
+			name:              "shared namespace with per-namespace name",
+			template:          "gateways/{ns}",
+			expectedNamespace: "gateways",
+			expectedName:      "team-a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace, name := ExpandGatewayNamespaceTemplate(tc.template, "team-a")
+			if namespace != tc.expectedNamespace || name != tc.expectedName {
+				t.Errorf("expected %s/%s, got %s/%s", tc.expectedNamespace, tc.expectedName, namespace, name)
+			}
+		})
+	}
+}
+
+func TestValidateGatewayNamespaceTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		template    string
+		expectValid bool
+	}{
+		{name: "default template", template: DefaultGatewayNamespaceTemplate, expectValid: true},
+		{name: "custom suffix", template: "{ns}-gw", expectValid: true},
+		{name: "namespace and name", template: "gateways/{ns}", expectValid: true},
+		{name: "missing placeholder", template: "gateways", expectValid: false},
+		{name: "too many segments", template: "a/b/{ns}", expectValid: false},
+		{name: "illegal characters", template: "{ns}_GW", expectValid: false},
+		{name: "empty namespace", template: "/{ns}", expectValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateGatewayNamespaceTemplate(tc.template, field.NewPath("gateway-namespace-template"))
+			if tc.expectValid && len(errs) > 0 {
+				t.Errorf("expected template %q to be valid, got %v", tc.template, errs)
+			}
+			if !tc.expectValid && len(errs) == 0 {
+				t.Errorf("expected template %q to be invalid", tc.template)
+			}
+		})
+	}
+}
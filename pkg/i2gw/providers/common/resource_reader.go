@@ -26,6 +26,7 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -124,6 +125,86 @@ func ReadServicesFromFile(filename, namespace string) (map[types.NamespacedName]
 	return services, nil
 }
 
+// ReadSecretNamesFromCluster returns the names of the Secrets in the cluster.
+// Only their metadata is read, so the contents of the Secrets are not fetched.
+func ReadSecretNamesFromCluster(ctx context.Context, client client.Client) (sets.Set[types.NamespacedName], error) {
+	secretList := &metav1.PartialObjectMetadataList{}
+	secretList.SetGroupVersionKind(apiv1.SchemeGroupVersion.WithKind("SecretList"))
+	err := client.List(ctx, secretList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secrets from the cluster: %w", err)
+	}
+
+	secrets := sets.New[types.NamespacedName]()
+	for _, secret := range secretList.Items {
+		secrets.Insert(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name})
+	}
+
+	return secrets, nil
+}
+
+// ReadSecretNamesFromFile returns the names of the Secrets in the file.
+func ReadSecretNamesFromFile(filename, namespace string) (sets.Set[types.NamespacedName], error) {
+	stream, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
+	}
+
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract objects: %w", err)
+	}
+
+	secrets := sets.New[types.NamespacedName]()
+	for _, f := range unstructuredObjects {
+		if !f.GroupVersionKind().Empty() && f.GroupVersionKind().Kind == "Secret" {
+			secrets.Insert(types.NamespacedName{Namespace: f.GetNamespace(), Name: f.GetName()})
+		}
+	}
+	return secrets, nil
+}
+
+func ReadConfigMapsFromCluster(ctx context.Context, client client.Client) (map[types.NamespacedName]*apiv1.ConfigMap, error) {
+	var configMapList apiv1.ConfigMapList
+	err := client.List(ctx, &configMapList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmaps from the cluster: %w", err)
+	}
+
+	configMaps := map[types.NamespacedName]*apiv1.ConfigMap{}
+	for i, configMap := range configMapList.Items {
+		configMaps[types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}] = &configMapList.Items[i]
+	}
+
+	return configMaps, nil
+}
+
+func ReadConfigMapsFromFile(filename, namespace string) (map[types.NamespacedName]*apiv1.ConfigMap, error) {
+	stream, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", filename, err)
+	}
+
+	unstructuredObjects, err := ExtractObjectsFromReader(bytes.NewReader(stream), namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract objects: %w", err)
+	}
+
+	configMaps := map[types.NamespacedName]*apiv1.ConfigMap{}
+	for _, f := range unstructuredObjects {
+		if !f.GroupVersionKind().Empty() && f.GroupVersionKind().Kind == "ConfigMap" {
+			var configMap apiv1.ConfigMap
+			err = runtime.DefaultUnstructuredConverter.
+				FromUnstructured(f.UnstructuredContent(), &configMap)
+			if err != nil {
+				return nil, err
+			}
+			configMaps[types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}] = &configMap
+		}
+	}
+	return configMaps, nil
+}
+
 // ExtractObjectsFromReader extracts all objects from a reader,
 // which is created from YAML or JSON input files.
 // It retrieves all objects, including nested ones if they are contained within a list.
@@ -19,6 +19,7 @@ package common
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -77,7 +78,7 @@ func GetRuleGroups(ingresses []networkingv1.Ingress) map[string]IngressRuleGroup
 				}
 				ruleGroups[rgKey] = rg
 			}
-			rg.TLS = append(rg.TLS, ingress.Spec.TLS...)
+			rg.TLS = append(rg.TLS, IngressTLSForHost(ingress.Spec.TLS, rule.Host)...)
 			rg.Rules = append(rg.Rules, Rule{
 				Ingress:     ingress,
 				IngressRule: rule,
@@ -91,6 +92,39 @@ func GetRuleGroups(ingresses []networkingv1.Ingress) map[string]IngressRuleGroup
 	return ruleGroups
 }
 
+// IngressTLSForHost returns the TLS entries of an Ingress that apply to the
+// host of one of its rules. An entry applies when it lists the host, a wildcard
+// matching it, or no hosts at all. Rules without a host use every entry.
+func IngressTLSForHost(tls []networkingv1.IngressTLS, host string) []networkingv1.IngressTLS {
+	if host == "" {
+		return tls
+	}
+
+	var matching []networkingv1.IngressTLS
+	for _, entry := range tls {
+		if len(entry.Hosts) == 0 || slices.ContainsFunc(entry.Hosts, func(tlsHost string) bool {
+			return tlsHostMatches(tlsHost, host)
+		}) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+// tlsHostMatches checks if a TLS host covers the rule host, where a wildcard
+// TLS host covers a single leftmost label
+func tlsHostMatches(tlsHost, host string) bool {
+	if strings.EqualFold(tlsHost, host) {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(tlsHost, "*.")
+	if !ok {
+		return false
+	}
+	_, domain, found := strings.Cut(host, ".")
+	return found && strings.EqualFold(domain, suffix)
+}
+
 func NameFromHost(host string) string {
 	// replace all special chars with -
 	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
@@ -111,6 +145,9 @@ func RouteName(ingressName, host string) string {
 
 func ToBackendRef(namespace string, ib networkingv1.IngressBackend, servicePorts map[types.NamespacedName]map[string]int32, path *field.Path) (*gatewayv1.BackendRef, *field.Error) {
 	if ib.Service != nil {
+		if ib.Service.Name == "" {
+			return nil, field.Required(path.Child("service", "name"), "backend service name must not be empty")
+		}
 		if ib.Service.Port.Name == "" {
 			return &gatewayv1.BackendRef{
 				BackendObjectReference: gatewayv1.BackendObjectReference{
@@ -133,6 +170,9 @@ func ToBackendRef(namespace string, ib networkingv1.IngressBackend, servicePorts
 			},
 		}, nil
 	}
+	if ib.Resource == nil {
+		return nil, field.Required(path, "backend must reference a service or a resource")
+	}
 	return &gatewayv1.BackendRef{
 		BackendObjectReference: gatewayv1.BackendObjectReference{
 			Group: (*gatewayv1.Group)(ib.Resource.APIGroup),
@@ -155,6 +195,10 @@ func groupIngressPathsByMatchKey(rules []ingressRule) orderedIngressPathsByMatch
 	}
 
 	for i, ir := range rules {
+		// Rules without HTTP paths have no matches to group
+		if ir.rule.HTTP == nil {
+			continue
+		}
 		for j, path := range ir.rule.HTTP.Paths {
 			ip := ingressPath{
 				ruleIdx:       i,
@@ -920,3 +920,33 @@ func TestCreateBackendTLSPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestIngressTLSForHost(t *testing.T) {
+	tls := []networkingv1.IngressTLS{
+		{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "shared"},
+		{Hosts: []string{"*.wild.example.com"}, SecretName: "wildcard"},
+		{SecretName: "default"},
+	}
+
+	testCases := []struct {
+		host            string
+		expectedSecrets []string
+	}{
+		{host: "a.example.com", expectedSecrets: []string{"shared", "default"}},
+		{host: "B.example.com", expectedSecrets: []string{"shared", "default"}},
+		{host: "foo.wild.example.com", expectedSecrets: []string{"wildcard", "default"}},
+		{host: "foo.bar.wild.example.com", expectedSecrets: []string{"default"}},
+		{host: "plain.example.com", expectedSecrets: []string{"default"}},
+		{host: "", expectedSecrets: []string{"shared", "wildcard", "default"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			var secrets []string
+			for _, entry := range IngressTLSForHost(tls, tc.host) {
+				secrets = append(secrets, entry.SecretName)
+			}
+			require.Equal(t, tc.expectedSecrets, secrets)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const logFormatUpstreamAnnotation = "nginx.ingress.kubernetes.io/log-format-upstream"
+
+// nginxLogVariableRegex matches an nginx variable, optionally in braces
+var nginxLogVariableRegex = regexp.MustCompile(`\$(\{[a-zA-Z0-9_]+\}|[a-zA-Z0-9_]+)`)
+
+// nginxLogVariables maps nginx log variables to Envoy access log command
+// operators
+var nginxLogVariables = map[string]string{
+	"remote_addr":            "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+	"the_real_ip":            "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+	"time_local":             "%START_TIME(%d/%b/%Y:%H:%M:%S %z)%",
+	"time_iso8601":           "%START_TIME(%Y-%m-%dT%H:%M:%S%z)%",
+	"request":                "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%",
+	"request_method":         "%REQ(:METHOD)%",
+	"request_uri":            "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%",
+	"server_protocol":        "%PROTOCOL%",
+	"scheme":                 "%REQ(:SCHEME)%",
+	"host":                   "%REQ(:AUTHORITY)%",
+	"status":                 "%RESPONSE_CODE%",
+	"body_bytes_sent":        "%BYTES_SENT%",
+	"bytes_sent":             "%BYTES_SENT%",
+	"request_length":         "%BYTES_RECEIVED%",
+	"request_time":           "%DURATION%",
+	"upstream_addr":          "%UPSTREAM_HOST%",
+	"upstream_response_time": "%RESPONSE_DURATION%",
+	"proxy_upstream_name":    "%UPSTREAM_CLUSTER%",
+	"req_id":                 "%REQ(X-REQUEST-ID)%",
+	"request_id":             "%REQ(X-REQUEST-ID)%",
+}
+
+// accessLogFeature translates the log-format-upstream of an Ingress to an
+// Envoy access log format, stored in the IR of the routes generated from it.
+// Variables without an Envoy equivalent are logged as "-" with a Warning.
+func accessLogFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		logFormat := strings.TrimSpace(ing.Annotations[logFormatUpstreamAnnotation])
+		if logFormat == "" {
+			continue
+		}
+
+		envoyFormat, unmapped := translateLogFormat(logFormat)
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.AccessLogFormat = envoyFormat
+		})
+		if updated == 0 {
+			continue
+		}
+
+		if len(unmapped) > 0 {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s variables %s have no Envoy access log equivalent and are logged as \"-\"",
+					logFormatUpstreamAnnotation, strings.Join(unmapped, ", ")),
+				ing,
+			)
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s converted to an access log EnvoyFilter with format %q. $request_time and $upstream_response_time are logged in milliseconds instead of seconds",
+				logFormatUpstreamAnnotation, envoyFormat),
+			ing,
+		)
+	}
+
+	return errs
+}
+
+// translateLogFormat converts an nginx log format to an Envoy access log
+// format, returning the nginx variables that could not be translated
+func translateLogFormat(logFormat string) (string, []string) {
+	var unmapped []string
+	var sb strings.Builder
+
+	last := 0
+	for _, match := range nginxLogVariableRegex.FindAllStringSubmatchIndex(logFormat, -1) {
+		// A literal % starts a command operator in Envoy formats
+		sb.WriteString(strings.ReplaceAll(logFormat[last:match[0]], "%", "%%"))
+		last = match[1]
+
+		variable := strings.Trim(logFormat[match[2]:match[3]], "{}")
+		if operator, ok := nginxLogVariableOperator(variable); ok {
+			sb.WriteString(operator)
+			continue
+		}
+		sb.WriteString("-")
+		if !slices.Contains(unmapped, "$"+variable) {
+			unmapped = append(unmapped, "$"+variable)
+		}
+	}
+	sb.WriteString(strings.ReplaceAll(logFormat[last:], "%", "%%"))
+
+	return sb.String(), unmapped
+}
+
+// nginxLogVariableOperator returns the Envoy command operator of an nginx
+// variable, including the $http_ request header and $sent_http_ response
+// header variables
+func nginxLogVariableOperator(variable string) (string, bool) {
+	if operator, ok := nginxLogVariables[variable]; ok {
+		return operator, true
+	}
+	headerName := func(name string) string {
+		return strings.ToUpper(strings.ReplaceAll(name, "_", "-"))
+	}
+	if name, ok := strings.CutPrefix(variable, "sent_http_"); ok && name != "" {
+		return "%RESP(" + headerName(name) + ")%", true
+	}
+	if name, ok := strings.CutPrefix(variable, "http_"); ok && name != "" {
+		return "%REQ(" + headerName(name) + ")%", true
+	}
+	return "", false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAccessLogFeature(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{
+		logFormatUpstreamAnnotation: `$remote_addr - [$time_local] "$request" $status $body_bytes_sent "$http_user_agent" $request_time $geoip_country_code`,
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := accessLogFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expectedFormat := `%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT% - [%START_TIME(%d/%b/%Y:%H:%M:%S %z)%] ` +
+		`"%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%" %RESPONSE_CODE% %BYTES_SENT% "%REQ(USER-AGENT)%" %DURATION% -`
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+	if nginxIR == nil || nginxIR.AccessLogFormat != expectedFormat {
+		t.Fatalf("expected access log format %q, got %+v", expectedFormat, nginxIR)
+	}
+
+	warnings := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected 1 warning for $geoip_country_code, got %d", warnings)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-accesslog"}]
+	if filter == nil {
+		t.Fatalf("expected an accesslog EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	accessLogs, _, _ := unstructured.NestedSlice(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "access_log")
+	expectedAccessLogs := []interface{}{
+		map[string]interface{}{
+			"name": "envoy.access_loggers.file",
+			"filter": map[string]interface{}{
+				"header_filter": map[string]interface{}{
+					"header": map[string]interface{}{
+						"name": ":authority",
+						"string_match": map[string]interface{}{
+							"safe_regex": map[string]interface{}{"regex": `^(example\.com)(:[0-9]+)?$`},
+						},
+					},
+				},
+			},
+			"typed_config": map[string]interface{}{
+				"@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+				"path":  "/dev/stdout",
+				"log_format": map[string]interface{}{
+					"text_format_source": map[string]interface{}{"inline_string": expectedFormat + "\n"},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedAccessLogs, accessLogs); diff != "" {
+		t.Errorf("unexpected access_log (-want +got):\n%s", diff)
+	}
+}
+
+func TestTranslateLogFormat(t *testing.T) {
+	testCases := []struct {
+		logFormat        string
+		expectedFormat   string
+		expectedUnmapped []string
+	}{
+		{
+			logFormat:      "${host} $sent_http_content_type 100%",
+			expectedFormat: "%REQ(:AUTHORITY)% %RESP(CONTENT-TYPE)% 100%%",
+		},
+		{
+			logFormat:        "$remote_user $remote_user $upstream_addr",
+			expectedFormat:   "- - %UPSTREAM_HOST%",
+			expectedUnmapped: []string{"$remote_user"},
+		},
+	}
+
+	for _, tc := range testCases {
+		format, unmapped := translateLogFormat(tc.logFormat)
+		if format != tc.expectedFormat {
+			t.Errorf("translateLogFormat(%q) = %q, expected %q", tc.logFormat, format, tc.expectedFormat)
+		}
+		if diff := cmp.Diff(tc.expectedUnmapped, unmapped); diff != "" {
+			t.Errorf("translateLogFormat(%q): unexpected unmapped variables (-want +got):\n%s", tc.logFormat, diff)
+		}
+	}
+}
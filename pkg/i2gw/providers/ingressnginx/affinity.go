@@ -0,0 +1,361 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	upstreamHashByAnnotation         = "nginx.ingress.kubernetes.io/upstream-hash-by"
+	affinityAnnotation               = "nginx.ingress.kubernetes.io/affinity"
+	affinityCanaryBehaviorAnnotation = "nginx.ingress.kubernetes.io/affinity-canary-behavior"
+	affinityModeAnnotation           = "nginx.ingress.kubernetes.io/affinity-mode"
+	sessionCookieNameAnnotation      = "nginx.ingress.kubernetes.io/session-cookie-name"
+	sessionCookiePathAnnotation      = "nginx.ingress.kubernetes.io/session-cookie-path"
+	sessionCookieMaxAgeAnnotation    = "nginx.ingress.kubernetes.io/session-cookie-max-age"
+	sessionCookieExpiresAnnotation   = "nginx.ingress.kubernetes.io/session-cookie-expires"
+	// sessionCookieHashAnnotation is how ingress-nginx encodes the backend in
+	// the cookie (md5, sha1 or index)
+	sessionCookieHashAnnotation = "nginx.ingress.kubernetes.io/session-cookie-hash"
+	// sessionCookieChangeOnFailureAnnotation picks a new backend for the
+	// cookie when the pinned one fails, instead of retrying it
+	sessionCookieChangeOnFailureAnnotation = "nginx.ingress.kubernetes.io/session-cookie-change-on-failure"
+
+	defaultSessionCookieName = "INGRESSCOOKIE"
+
+	// affinityModeBalanced redistributes the sessions when the backends scale,
+	// affinityModePersistent keeps them on their backend
+	affinityModeBalanced   = "balanced"
+	affinityModePersistent = "persistent"
+)
+
+// affinityFeature validates the session affinity and upstream-hash-by
+// annotations, which are converted to the consistentHash load balancer of the
+// DestinationRules of the backends. Header and cookie canaries are decided by
+// the HTTPRoute rules before a backend is picked, so the affinity applies
+// within each backend. Weighted canaries are decided per request by the
+// Gateway, so their decision is not sticky. With session-cookie-change-on-failure,
+// the DestinationRules also eject failing backends with outlierDetection.
+func affinityFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		if isCanaryIngress(ing) {
+			continue
+		}
+		consistentHash, annotation, err := parseConsistentHash(ing)
+		if err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("metadata", "annotations", annotation), ing.Annotations[annotation], err.Error()))
+			continue
+		}
+		if consistentHash == nil {
+			continue
+		}
+		if ing.Annotations[affinityAnnotation] != "" && ing.Annotations[upstreamHashByAnnotation] != "" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("both %s and %s are set, the DestinationRule uses the cookie affinity", affinityAnnotation, upstreamHashByAnnotation), ing)
+		}
+		if hash := strings.TrimSpace(ing.Annotations[sessionCookieHashAnnotation]); hash != "" && annotation == affinityAnnotation {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s %q is not preserved: the Gateway generates its own session cookie value, sessions are still sticky", sessionCookieHashAnnotation, hash), ing)
+		}
+		if changesBackendOnFailure(ing) {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s: the DestinationRule ejects failing backends with outlierDetection, so the consistent hash moves to another backend, "+
+					"but the cookie is not rewritten: clients return to their original backend once it is no longer ejected", sessionCookieChangeOnFailureAnnotation), ing)
+		}
+
+		for _, routeCtx := range ir.HTTPRoutes {
+			if !routeContainsIngress(routeCtx, ing) {
+				continue
+			}
+			for _, canaryIng := range routeSourceIngresses(routeCtx) {
+				if !isCanaryIngress(canaryIng) {
+					continue
+				}
+				config, err := parseCanaryConfig(canaryIng)
+				if err != nil {
+					continue
+				}
+				if config.header != "" || config.cookie != "" {
+					notify(notifications.InfoNotification,
+						fmt.Sprintf("the canary of ingress %s/%s is decided by header or cookie first, then %s applies within each backend",
+							canaryIng.Namespace, canaryIng.Name, annotation), &routeCtx.HTTPRoute)
+				} else if config.weight > 0 {
+					notify(notifications.WarningNotification,
+						fmt.Sprintf("the weighted canary of ingress %s/%s is decided per request, so clients are not kept on the canary like with %s: sticky. "+
+							"Affinity only applies within each backend", canaryIng.Namespace, canaryIng.Name, affinityCanaryBehaviorAnnotation), &routeCtx.HTTPRoute)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// isCanaryIngress returns true if the Ingress has canary: true
+func isCanaryIngress(ing *networkingv1.Ingress) bool {
+	return ing.Annotations[canaryAnnotation] == "true"
+}
+
+// parseConsistentHash converts the affinity annotations of an Ingress to an
+// Istio consistentHash load balancer, nil if the Ingress has none. Cookie
+// affinity takes precedence over upstream-hash-by, like in ingress-nginx. On
+// error, the annotation at fault is returned.
+func parseConsistentHash(ing *networkingv1.Ingress) (map[string]interface{}, string, error) {
+	if affinity := strings.TrimSpace(ing.Annotations[affinityAnnotation]); affinity != "" {
+		if affinity != "cookie" {
+			return nil, affinityAnnotation, fmt.Errorf("only cookie affinity is supported")
+		}
+		cookie := map[string]interface{}{
+			"name": defaultSessionCookieName,
+			// Envoy only generates the cookie with a TTL, 0s is a session cookie
+			"ttl": "0s",
+		}
+		if name := strings.TrimSpace(ing.Annotations[sessionCookieNameAnnotation]); name != "" {
+			cookie["name"] = name
+		}
+		if path := strings.TrimSpace(ing.Annotations[sessionCookiePathAnnotation]); path != "" {
+			cookie["path"] = path
+		}
+		if mode := strings.TrimSpace(ing.Annotations[affinityModeAnnotation]); mode != "" && mode != affinityModeBalanced && mode != affinityModePersistent {
+			return nil, affinityModeAnnotation, fmt.Errorf("must be %s or %s", affinityModeBalanced, affinityModePersistent)
+		}
+		if changeOnFailure := strings.TrimSpace(ing.Annotations[sessionCookieChangeOnFailureAnnotation]); changeOnFailure != "" && changeOnFailure != "true" && changeOnFailure != "false" {
+			return nil, sessionCookieChangeOnFailureAnnotation, fmt.Errorf("must be true or false")
+		}
+		for _, annotation := range []string{sessionCookieMaxAgeAnnotation, sessionCookieExpiresAnnotation} {
+			value := strings.TrimSpace(ing.Annotations[annotation])
+			if value == "" {
+				continue
+			}
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				return nil, annotation, fmt.Errorf("must be a non-negative number of seconds")
+			}
+			cookie["ttl"] = fmt.Sprintf("%ds", seconds)
+			break
+		}
+		return map[string]interface{}{"httpCookie": cookie}, affinityAnnotation, nil
+	}
+
+	hashBy := strings.TrimSpace(ing.Annotations[upstreamHashByAnnotation])
+	if hashBy == "" {
+		return nil, "", nil
+	}
+	// Only a single variable can be mapped, not an expression combining several
+	if strings.ContainsAny(hashBy[1:], "${} ") {
+		return nil, upstreamHashByAnnotation, fmt.Errorf("only a single $remote_addr, $binary_remote_addr, $http_*, $cookie_* or $arg_* variable can be converted to a consistent hash")
+	}
+	switch {
+	case hashBy == "$remote_addr" || hashBy == "$binary_remote_addr":
+		return map[string]interface{}{"useSourceIp": true}, upstreamHashByAnnotation, nil
+	case strings.HasPrefix(hashBy, "$http_") && len(hashBy) > len("$http_"):
+		return map[string]interface{}{
+			"httpHeaderName": strings.ReplaceAll(strings.TrimPrefix(hashBy, "$http_"), "_", "-"),
+		}, upstreamHashByAnnotation, nil
+	case strings.HasPrefix(hashBy, "$cookie_") && len(hashBy) > len("$cookie_"):
+		return map[string]interface{}{
+			"httpCookie": map[string]interface{}{"name": strings.TrimPrefix(hashBy, "$cookie_")},
+		}, upstreamHashByAnnotation, nil
+	case strings.HasPrefix(hashBy, "$arg_") && len(hashBy) > len("$arg_"):
+		return map[string]interface{}{
+			"httpQueryParameterName": strings.TrimPrefix(hashBy, "$arg_"),
+		}, upstreamHashByAnnotation, nil
+	}
+	return nil, upstreamHashByAnnotation, fmt.Errorf("only $remote_addr, $binary_remote_addr, $http_*, $cookie_* and $arg_* can be converted to a consistent hash")
+}
+
+// sessionPersistenceFeature returns a feature parser converting cookie
+// affinity with affinity-mode: persistent to the sessionPersistence of the
+// HTTPRoute rules generated from the Ingress. The consistent hash of the
+// default balanced mode moves sessions to the new backends when the Service
+// scales, while sessionPersistence keeps each session on the backend recorded
+// in its cookie. sessionPersistence is only in the experimental Gateway API
+// channel, so without experimentalChannel the persistent mode falls back to
+// the consistent hash with a Warning.
+func sessionPersistenceFeature(experimentalChannel bool) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		for i := range ingresses {
+			ing := &ingresses[i]
+			if isCanaryIngress(ing) || !persistentAffinity(ing) {
+				continue
+			}
+			consistentHash, _, err := parseConsistentHash(ing)
+			if err != nil {
+				// Reported by affinityFeature
+				continue
+			}
+			if !experimentalChannel {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s: %s requires HTTPRoute sessionPersistence from the experimental Gateway API channel, "+
+						"the DestinationRule consistent hash is used instead and moves sessions when the backends scale. Set --%s-%s to convert it",
+						affinityModeAnnotation, affinityModePersistent, Name, ExperimentalChannelFlag),
+					ing,
+				)
+				continue
+			}
+
+			cookie, _ := consistentHash["httpCookie"].(map[string]interface{})
+			sessionPersistence := cookieSessionPersistence(cookie)
+			updated := 0
+			for routeKey, routeCtx := range ir.HTTPRoutes {
+				if routeKey.Namespace != ing.Namespace {
+					continue
+				}
+				for ruleIdx, sources := range routeCtx.RuleBackendSources {
+					if ruleIdx < len(routeCtx.HTTPRoute.Spec.Rules) && sourcesContainIngress(sources, ing) {
+						routeCtx.HTTPRoute.Spec.Rules[ruleIdx].SessionPersistence = sessionPersistence.DeepCopy()
+						updated++
+					}
+				}
+			}
+			if updated > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s: %s converted to HTTPRoute sessionPersistence, which requires the experimental Gateway API channel",
+						affinityModeAnnotation, affinityModePersistent),
+					ing,
+				)
+			}
+		}
+		return nil
+	}
+}
+
+// cookieSessionPersistence returns the sessionPersistence pinning sessions
+// with the cookie of a consistentHash. A cookie with a TTL is permanent, with
+// the TTL as its lifetime.
+func cookieSessionPersistence(cookie map[string]interface{}) *gatewayv1.SessionPersistence {
+	sessionPersistence := &gatewayv1.SessionPersistence{
+		Type:         ptr.To(gatewayv1.CookieBasedSessionPersistence),
+		CookieConfig: &gatewayv1.CookieConfig{LifetimeType: ptr.To(gatewayv1.SessionCookieLifetimeType)},
+	}
+	if name, ok := cookie["name"].(string); ok {
+		sessionPersistence.SessionName = ptr.To(name)
+	}
+	if ttl, ok := cookie["ttl"].(string); ok && ttl != "0s" {
+		sessionPersistence.AbsoluteTimeout = ptr.To(gatewayv1.Duration(ttl))
+		sessionPersistence.CookieConfig.LifetimeType = ptr.To(gatewayv1.PermanentCookieLifetimeType)
+	}
+	return sessionPersistence
+}
+
+// persistentAffinity returns true if the Ingress has cookie affinity with
+// affinity-mode: persistent
+func persistentAffinity(ing *networkingv1.Ingress) bool {
+	return strings.TrimSpace(ing.Annotations[affinityAnnotation]) == "cookie" &&
+		strings.TrimSpace(ing.Annotations[affinityModeAnnotation]) == affinityModePersistent
+}
+
+// changesBackendOnFailure returns true if the Ingress has cookie affinity with
+// session-cookie-change-on-failure: true
+func changesBackendOnFailure(ing *networkingv1.Ingress) bool {
+	return strings.TrimSpace(ing.Annotations[affinityAnnotation]) == "cookie" &&
+		strings.TrimSpace(ing.Annotations[sessionCookieChangeOnFailureAnnotation]) == "true"
+}
+
+// backendConsistentHash returns the consistentHash of the backend of the rule
+// and the annotation it comes from. Rules with a sessionPersistence pin the
+// sessions themselves, so their backends get none.
+func backendConsistentHash(routeCtx intermediate.HTTPRouteContext, ruleIdx, backendIdx int) (map[string]interface{}, string) {
+	if ruleHasSessionPersistence(routeCtx, ruleIdx) {
+		return nil, ""
+	}
+	ing := backendAffinityIngress(routeCtx, ruleIdx, backendIdx)
+	if ing == nil {
+		return nil, ""
+	}
+	consistentHash, source, err := parseConsistentHash(ing)
+	if err != nil {
+		return nil, ""
+	}
+	return consistentHash, source
+}
+
+// backendChangesOnFailure returns true if the backend of the rule should be
+// replaced when it fails, see changesBackendOnFailure
+func backendChangesOnFailure(routeCtx intermediate.HTTPRouteContext, ruleIdx, backendIdx int) bool {
+	if ruleHasSessionPersistence(routeCtx, ruleIdx) {
+		return false
+	}
+	ing := backendAffinityIngress(routeCtx, ruleIdx, backendIdx)
+	if ing == nil {
+		return false
+	}
+	if _, _, err := parseConsistentHash(ing); err != nil {
+		return false
+	}
+	return changesBackendOnFailure(ing)
+}
+
+// ruleHasSessionPersistence returns true if the rule has a sessionPersistence,
+// see sessionPersistenceFeature
+func ruleHasSessionPersistence(routeCtx intermediate.HTTPRouteContext, ruleIdx int) bool {
+	return ruleIdx < len(routeCtx.HTTPRoute.Spec.Rules) && routeCtx.HTTPRoute.Spec.Rules[ruleIdx].SessionPersistence != nil
+}
+
+// backendAffinityIngress returns the Ingress whose affinity annotations apply
+// to the backend of the rule. The backend of a canary Ingress uses the
+// affinity of the main Ingress of the rule, since ingress-nginx ignores the
+// affinity annotations of canary Ingresses.
+func backendAffinityIngress(routeCtx intermediate.HTTPRouteContext, ruleIdx, backendIdx int) *networkingv1.Ingress {
+	if ruleIdx >= len(routeCtx.RuleBackendSources) || backendIdx >= len(routeCtx.RuleBackendSources[ruleIdx]) {
+		return nil
+	}
+	ing := routeCtx.RuleBackendSources[ruleIdx][backendIdx].Ingress
+	if ing == nil {
+		return nil
+	}
+	if isCanaryIngress(ing) {
+		ing = nil
+		for _, source := range routeCtx.RuleBackendSources[ruleIdx] {
+			if source.Ingress != nil && !isCanaryIngress(source.Ingress) {
+				ing = source.Ingress
+				break
+			}
+		}
+		if ing == nil {
+			ing = mainIngressOfRoute(routeCtx)
+		}
+	}
+	return ing
+}
+
+// mainIngressOfRoute returns the first non-canary Ingress of the route, used
+// for the decision rules of header and cookie canaries, which only hold the
+// canary backend
+func mainIngressOfRoute(routeCtx intermediate.HTTPRouteContext) *networkingv1.Ingress {
+	for _, ing := range routeSourceIngresses(routeCtx) {
+		if !isCanaryIngress(ing) {
+			return ing
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestToGatewayResources_CookieCanaryWithUpstreamHash(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace"},
+		},
+	}).(*Provider)
+
+	main := headersTestIngress(map[string]string{upstreamHashByAnnotation: "$http_x_user"})
+	canary := headersTestIngress(map[string]string{
+		canaryAnnotation:         "true",
+		canaryByCookieAnnotation: "canary",
+	})
+	canary.Name = "test-ingress-canary"
+	canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "my-service-canary"
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: main.Namespace, Name: main.Name}:     &main,
+		{Namespace: canary.Namespace, Name: canary.Name}: &canary,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if len(gatewayResources.HTTPRoutes) != 1 {
+		t.Fatalf("expected 1 HTTPRoute, got %d", len(gatewayResources.HTTPRoutes))
+	}
+	type ruleSummary struct {
+		Cookie   string
+		Backends []string
+	}
+	var rules []ruleSummary
+	for _, route := range gatewayResources.HTTPRoutes {
+		for _, rule := range route.Spec.Rules {
+			summary := ruleSummary{}
+			for _, header := range rule.Matches[0].Headers {
+				if header.Name == "Cookie" && ptr.Deref(header.Type, "") == gatewayv1.HeaderMatchRegularExpression {
+					summary.Cookie = header.Value
+				}
+			}
+			for _, backendRef := range rule.BackendRefs {
+				summary.Backends = append(summary.Backends, string(backendRef.Name))
+			}
+			// The order of the weighted backends depends on the Ingress order
+			slices.Sort(summary.Backends)
+			rules = append(rules, summary)
+		}
+	}
+	// The canary is decided by the cookie first, then by weight
+	expectedRules := []ruleSummary{
+		{Cookie: `^(.*;\s*)?canary=always(\s*;.*)?$`, Backends: []string{"my-service-canary"}},
+		{Cookie: `^(.*;\s*)?canary=never(\s*;.*)?$`, Backends: []string{"my-service"}},
+		{Backends: []string{"my-service", "my-service-canary"}},
+	}
+	if diff := cmp.Diff(expectedRules, rules); diff != "" {
+		t.Errorf("unexpected HTTPRoute rules (-want +got):\n%s", diff)
+	}
+
+	// Then the affinity of the main Ingress applies within each backend
+	loadBalancers := make(map[string]interface{})
+	for _, extension := range gatewayResources.GatewayExtensions {
+		if extension.GetKind() != "DestinationRule" {
+			continue
+		}
+		loadBalancer, _, _ := unstructured.NestedMap(extension.Object, "spec", "trafficPolicy", "loadBalancer")
+		loadBalancers[extension.GetName()] = loadBalancer
+	}
+	consistentHash := map[string]interface{}{
+		"consistentHash": map[string]interface{}{"httpHeaderName": "x-user"},
+	}
+	expectedLoadBalancers := map[string]interface{}{
+		"my-service-traffic-policy":        consistentHash,
+		"my-service-canary-traffic-policy": consistentHash,
+	}
+	if diff := cmp.Diff(expectedLoadBalancers, loadBalancers); diff != "" {
+		t.Errorf("unexpected DestinationRule load balancers (-want +got):\n%s", diff)
+	}
+}
+
+func TestToGatewayResources_CookieChangeOnFailure(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		changeOnFailure          string
+		expectedOutlierDetection map[string]interface{}
+		expectedWarnings         int
+	}{
+		{
+			name:            "change on failure",
+			changeOnFailure: "true",
+			expectedOutlierDetection: map[string]interface{}{
+				"consecutive5xxErrors": int64(1),
+				"interval":             "10s",
+				"baseEjectionTime":     "30s",
+				"maxEjectionPercent":   int64(100),
+			},
+			expectedWarnings: 1,
+		},
+		{
+			name:            "retry the pinned backend",
+			changeOnFailure: "false",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayModeFlag: "per-namespace"},
+				},
+			}).(*Provider)
+
+			ingress := headersTestIngress(map[string]string{
+				affinityAnnotation:                     "cookie",
+				sessionCookieChangeOnFailureAnnotation: tc.changeOnFailure,
+			})
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var destinationRule *unstructured.Unstructured
+			for i := range gatewayResources.GatewayExtensions {
+				if gatewayResources.GatewayExtensions[i].GetKind() == "DestinationRule" {
+					destinationRule = &gatewayResources.GatewayExtensions[i]
+				}
+			}
+			if destinationRule == nil {
+				t.Fatalf("expected a DestinationRule")
+			}
+			outlierDetection, _, _ := unstructured.NestedMap(destinationRule.Object, "spec", "trafficPolicy", "outlierDetection")
+			if diff := cmp.Diff(tc.expectedOutlierDetection, outlierDetection); diff != "" {
+				t.Errorf("unexpected outlierDetection (-want +got):\n%s", diff)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, sessionCookieChangeOnFailureAnnotation) {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d change-on-failure warnings, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_AffinityMode(t *testing.T) {
+	testCases := []struct {
+		name                       string
+		mode                       string
+		experimentalChannel        string
+		expectedConsistentHash     map[string]interface{}
+		expectedSessionPersistence *gatewayv1.SessionPersistence
+	}{
+		{
+			name:                "balanced uses a cookie consistent hash",
+			mode:                affinityModeBalanced,
+			experimentalChannel: "true",
+			expectedConsistentHash: map[string]interface{}{
+				"httpCookie": map[string]interface{}{"name": "route", "ttl": "3600s"},
+			},
+		},
+		{
+			name:                "persistent pins sessions with sessionPersistence",
+			mode:                affinityModePersistent,
+			experimentalChannel: "true",
+			expectedSessionPersistence: &gatewayv1.SessionPersistence{
+				SessionName:     ptr.To("route"),
+				AbsoluteTimeout: ptr.To[gatewayv1.Duration]("3600s"),
+				Type:            ptr.To(gatewayv1.CookieBasedSessionPersistence),
+				CookieConfig:    &gatewayv1.CookieConfig{LifetimeType: ptr.To(gatewayv1.PermanentCookieLifetimeType)},
+			},
+		},
+		{
+			name:                "persistent falls back to a consistent hash without the experimental channel",
+			mode:                affinityModePersistent,
+			experimentalChannel: "false",
+			expectedConsistentHash: map[string]interface{}{
+				"httpCookie": map[string]interface{}{"name": "route", "ttl": "3600s"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayModeFlag: "per-namespace", ExperimentalChannelFlag: tc.experimentalChannel},
+				},
+			}).(*Provider)
+
+			ingress := headersTestIngress(map[string]string{
+				affinityAnnotation:            "cookie",
+				affinityModeAnnotation:        tc.mode,
+				sessionCookieNameAnnotation:   "route",
+				sessionCookieMaxAgeAnnotation: "3600",
+			})
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var consistentHash map[string]interface{}
+			for i := range gatewayResources.GatewayExtensions {
+				if gatewayResources.GatewayExtensions[i].GetKind() == "DestinationRule" {
+					consistentHash, _, _ = unstructured.NestedMap(gatewayResources.GatewayExtensions[i].Object, "spec", "trafficPolicy", "loadBalancer", "consistentHash")
+				}
+			}
+			if diff := cmp.Diff(tc.expectedConsistentHash, consistentHash); diff != "" {
+				t.Errorf("unexpected consistentHash (-want +got):\n%s", diff)
+			}
+
+			for _, route := range gatewayResources.HTTPRoutes {
+				for _, rule := range route.Spec.Rules {
+					if diff := cmp.Diff(tc.expectedSessionPersistence, rule.SessionPersistence); diff != "" {
+						t.Errorf("unexpected sessionPersistence (-want +got):\n%s", diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_SessionCookieHash(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace"},
+		},
+	}).(*Provider)
+
+	ingress := headersTestIngress(map[string]string{
+		affinityAnnotation:          "cookie",
+		sessionCookieHashAnnotation: "sha1",
+	})
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// The cookie affinity is still converted
+	var consistentHash map[string]interface{}
+	for i := range gatewayResources.GatewayExtensions {
+		if gatewayResources.GatewayExtensions[i].GetKind() == "DestinationRule" {
+			consistentHash, _, _ = unstructured.NestedMap(gatewayResources.GatewayExtensions[i].Object, "spec", "trafficPolicy", "loadBalancer", "consistentHash")
+		}
+	}
+	expectedConsistentHash := map[string]interface{}{
+		"httpCookie": map[string]interface{}{"name": defaultSessionCookieName, "ttl": "0s"},
+	}
+	if diff := cmp.Diff(expectedConsistentHash, consistentHash); diff != "" {
+		t.Errorf("unexpected consistentHash (-want +got):\n%s", diff)
+	}
+
+	infos := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification && strings.Contains(n.Message, sessionCookieHashAnnotation) {
+			infos++
+		}
+	}
+	if infos != 1 {
+		t.Errorf("expected 1 session-cookie-hash info, got %d", infos)
+	}
+}
+
+func TestParseConsistentHash(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		annotations            map[string]string
+		expectedConsistentHash map[string]interface{}
+		expectError            bool
+	}{
+		{
+			name:                   "source IP",
+			annotations:            map[string]string{upstreamHashByAnnotation: "$binary_remote_addr"},
+			expectedConsistentHash: map[string]interface{}{"useSourceIp": true},
+		},
+		{
+			name:                   "query parameter",
+			annotations:            map[string]string{upstreamHashByAnnotation: "$arg_user"},
+			expectedConsistentHash: map[string]interface{}{"httpQueryParameterName": "user"},
+		},
+		{
+			name: "cookie affinity takes precedence",
+			annotations: map[string]string{
+				affinityAnnotation:            "cookie",
+				sessionCookieNameAnnotation:   "route",
+				sessionCookieMaxAgeAnnotation: "3600",
+				upstreamHashByAnnotation:      "$remote_addr",
+			},
+			expectedConsistentHash: map[string]interface{}{
+				"httpCookie": map[string]interface{}{"name": "route", "ttl": "3600s"},
+			},
+		},
+		{
+			name: "invalid change-on-failure",
+			annotations: map[string]string{
+				affinityAnnotation:                     "cookie",
+				sessionCookieChangeOnFailureAnnotation: "yes",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid affinity mode",
+			annotations: map[string]string{
+				affinityAnnotation:     "cookie",
+				affinityModeAnnotation: "sticky",
+			},
+			expectError: true,
+		},
+		{
+			name:        "variable expression",
+			annotations: map[string]string{upstreamHashByAnnotation: "$host$request_uri"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := headersTestIngress(tc.annotations)
+			consistentHash, _, err := parseConsistentHash(&ingress)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectError, err)
+			}
+			if diff := cmp.Diff(tc.expectedConsistentHash, consistentHash); diff != "" {
+				t.Errorf("unexpected consistentHash (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
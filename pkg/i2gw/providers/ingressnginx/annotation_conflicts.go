@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// routeScopedAnnotation is an annotation with a single value per HTTPRoute.
+// When Ingresses with different values are merged into one route, only one of
+// them can be used.
+type routeScopedAnnotation struct {
+	annotation string
+	// parse returns the normalized value of the annotation, or "" if it is
+	// unset or invalid (invalid values are reported by the feature itself)
+	parse func(value string) string
+	// chosen returns the value the features stored on the route
+	chosen func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string
+}
+
+// routeScopedAnnotations are the annotations checked for conflicts between
+// merged Ingresses
+var routeScopedAnnotations = []routeScopedAnnotation{
+	{
+		annotation: proxyBodySizeAnnotation,
+		parse:      strings.TrimSpace,
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			return nginxIR.ProxyBodySize
+		},
+	},
+	{
+		annotation: proxyBufferingAnnotation,
+		parse:      parseOnOffValue,
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			return formatOnOff(nginxIR.ProxyBuffering)
+		},
+	},
+	{
+		annotation: proxyRequestBufferingAnnotation,
+		parse:      parseOnOffValue,
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			return formatOnOff(nginxIR.ProxyRequestBuffering)
+		},
+	},
+	{
+		annotation: proxyConnectTimeoutAnnotation,
+		parse: func(value string) string {
+			if seconds, err := strconv.Atoi(value); err == nil {
+				return strconv.Itoa(seconds)
+			}
+			return ""
+		},
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			if nginxIR.ConnectTimeoutSeconds <= 0 {
+				return ""
+			}
+			return strconv.Itoa(nginxIR.ConnectTimeoutSeconds)
+		},
+	},
+	{
+		annotation: authURLAnnotation,
+		parse: func(value string) string {
+			normalized, err := normalizeAuthURL(strings.TrimSpace(value))
+			if err != nil {
+				return ""
+			}
+			return normalized
+		},
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			if nginxIR.ExternalAuth == nil {
+				return ""
+			}
+			return nginxIR.ExternalAuth.URL
+		},
+	},
+	{
+		annotation: authTLSSecretAnnotation,
+		parse:      strings.TrimSpace,
+		chosen: func(nginxIR *intermediate.IngressNginxHTTPRouteIR) string {
+			if nginxIR.ClientCertAuth == nil {
+				return ""
+			}
+			return nginxIR.ClientCertAuth.Secret
+		},
+	},
+}
+
+// annotationConflictsFeature emits a Warning for every route-scoped annotation
+// set to different values by Ingresses merged into the same HTTPRoute, since
+// the value used then depends on the order the Ingresses are processed in.
+// It runs after the other features so it can report the value they chose.
+func annotationConflictsFeature(_ []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	for _, routeKey := range slices.SortedFunc(maps.Keys(ir.HTTPRoutes), compareNamespacedNames) {
+		routeCtx := ir.HTTPRoutes[routeKey]
+		sourceIngresses := routeSourceIngresses(routeCtx)
+		if len(sourceIngresses) < 2 {
+			continue
+		}
+
+		for _, scoped := range routeScopedAnnotations {
+			// Ingress names by parsed value
+			values := make(map[string][]string)
+			for _, ing := range sourceIngresses {
+				raw, ok := ing.Annotations[scoped.annotation]
+				if !ok {
+					continue
+				}
+				if value := scoped.parse(raw); value != "" {
+					values[value] = append(values[value], ing.Name)
+				}
+			}
+			if len(values) < 2 {
+				continue
+			}
+
+			var conflicts []string
+			for _, value := range slices.Sorted(maps.Keys(values)) {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %s", value, strings.Join(values[value], ", ")))
+			}
+			chosen := "none"
+			if nginxIR := routeCtx.ProviderSpecificIR.IngressNginx; nginxIR != nil {
+				chosen = cmp.Or(scoped.chosen(nginxIR), chosen)
+			}
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Ingresses merged into HTTPRoute %s/%s set conflicting %s values (%s); %s is used",
+					routeKey.Namespace, routeKey.Name, scoped.annotation, strings.Join(conflicts, "; "), chosen),
+				&routeCtx.HTTPRoute,
+			)
+		}
+	}
+
+	return nil
+}
+
+// routeSourceIngresses returns the distinct Ingresses contributing backends to
+// the route, sorted by name
+func routeSourceIngresses(routeCtx intermediate.HTTPRouteContext) []*networkingv1.Ingress {
+	ingresses := make(map[string]*networkingv1.Ingress)
+	for _, sources := range routeCtx.RuleBackendSources {
+		for _, source := range sources {
+			if source.Ingress != nil {
+				ingresses[source.Ingress.Name] = source.Ingress
+			}
+		}
+	}
+
+	var sorted []*networkingv1.Ingress
+	for _, name := range slices.Sorted(maps.Keys(ingresses)) {
+		sorted = append(sorted, ingresses[name])
+	}
+	return sorted
+}
+
+// parseOnOffValue normalizes an on/off annotation value
+func parseOnOffValue(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	enabled := parseOnOff(value)
+	return formatOnOff(&enabled)
+}
+
+// formatOnOff formats an optional on/off setting, "" if unset
+func formatOnOff(enabled *bool) string {
+	switch {
+	case enabled == nil:
+		return ""
+	case *enabled:
+		return "on"
+	default:
+		return "off"
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAnnotationConflictsFeature_ProxyBodySize(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	newIngress := func(name, path, bodySize string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{proxyBodySizeAnnotation: bodySize},
+			},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: ptrTo("nginx"),
+				Rules: []networkingv1.IngressRule{{
+					Host: "shop.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     path,
+								PathType: &iPrefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: name + "-service",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		bodySizes       map[string]string
+		expectedWarning string
+	}{
+		{
+			name:      "same value",
+			bodySizes: map[string]string{"cart": "10m", "checkout": "10m", "catalog": "10m"},
+		},
+		{
+			name:            "conflicting values",
+			bodySizes:       map[string]string{"cart": "10m", "checkout": "20m", "catalog": "10m"},
+			expectedWarning: "set conflicting nginx.ingress.kubernetes.io/proxy-body-size values (10m: cart, catalog; 20m: checkout)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := OrderedIngressMap{ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{}}
+			for _, name := range []string{"cart", "checkout", "catalog"} {
+				key := types.NamespacedName{Namespace: "default", Name: name}
+				ingresses.ingressNames = append(ingresses.ingressNames, key)
+				ingresses.ingressObjects[key] = newIngress(name, "/"+name, tc.bodySizes[name])
+			}
+
+			provider := NewProvider(&i2gw.ProviderConf{})
+			provider.(*Provider).storage.Ingresses = ingresses
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("Expected no errors, got %v", errs)
+			}
+			if len(ir.HTTPRoutes) != 1 {
+				t.Fatalf("Expected 1 HTTPRoute, got %d", len(ir.HTTPRoutes))
+			}
+
+			var warnings []string
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "set conflicting") {
+					warnings = append(warnings, n.Message)
+				}
+			}
+
+			if tc.expectedWarning == "" {
+				if len(warnings) > 0 {
+					t.Errorf("Expected no conflict warnings, got %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("Expected 1 conflict warning, got %v", warnings)
+			}
+			if !strings.Contains(warnings[0], tc.expectedWarning) {
+				t.Errorf("Expected warning to contain %q, got %q", tc.expectedWarning, warnings[0])
+			}
+
+			// The warning names the value the merged route actually uses
+			for _, routeCtx := range ir.HTTPRoutes {
+				chosen := routeCtx.ProviderSpecificIR.IngressNginx.ProxyBodySize
+				if !strings.HasSuffix(warnings[0], "; "+chosen+" is used") {
+					t.Errorf("Expected warning to report the chosen value %s, got %q", chosen, warnings[0])
+				}
+			}
+		})
+	}
+}
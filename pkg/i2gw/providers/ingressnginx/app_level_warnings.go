@@ -46,7 +46,7 @@ The logic must be moved to your application or handled differently:
 - Proxy modifications: May need EnvoyFilter or app changes
 - Lua scripts: Must be rewritten for Envoy or moved to app`,
 
-	"nginx.ingress.kubernetes.io/use-regex": `
+	useRegexAnnotation: `
 REGEX PATH MATCHING NOT GA IN GATEWAY API:
 The 'use-regex' annotation enables regex path matching which is NOT GA in Gateway API.
 Options:
@@ -61,7 +61,16 @@ Gateway API URLRewrite filter does NOT support regex capture groups.
 If your rewrite uses $1, $2, etc., you must:
 1. Refactor your application to accept the original paths
 2. Or implement path rewriting in your application/reverse proxy
-Simple rewrites (without capture groups) can use HTTPRoute URLRewrite filter.`,
+Rewrites without capture groups are converted to HTTPRoute URLRewrite filters,
+as are rewrites only keeping the end of the paths, like /prefix/(.*) to /$1.`,
+}
+
+// Error codes of the annotations requiring app-level changes
+var appLevelAnnotationCodes = map[string]notifications.ErrorCode{
+	serverSnippetAnnotation:                             notifications.UnsupportedSnippet,
+	"nginx.ingress.kubernetes.io/configuration-snippet": notifications.UnsupportedSnippet,
+	useRegexAnnotation:                                  notifications.UnsupportedRegexPath,
+	"nginx.ingress.kubernetes.io/rewrite-target":        notifications.UnsupportedRewriteTarget,
 }
 
 // Annotations that require special handling in meshless Istio
@@ -101,7 +110,23 @@ func appLevelWarningsFeature(ingresses []networkingv1.Ingress, _ map[types.Names
 		// Check for annotations requiring app-level changes
 		for annotation, warningMsg := range appLevelAnnotations {
 			if value, exists := annotations[annotation]; exists {
-				notify(notifications.ErrorNotification,
+				// Header buffer only snippets are translated by serverSnippetFeature
+				if _, ok := parseHeaderBufferSnippet(value); ok && annotation == serverSnippetAnnotation {
+					continue
+				}
+				// use-regex is reported by regexPathFeature, which may convert it
+				if annotation == useRegexAnnotation {
+					continue
+				}
+				// rewrite-target without capture groups, or only keeping the
+				// end of the paths, is converted by rewriteTargetFeature
+				if annotation == rewriteTargetAnnotation && !strings.Contains(value, "$") {
+					continue
+				}
+				if _, ok := capturePrefixRewrites(&ing); ok && annotation == rewriteTargetAnnotation {
+					continue
+				}
+				notifyWithCode(notifications.ErrorNotification, appLevelAnnotationCodes[annotation],
 					fmt.Sprintf("MIGRATION BLOCKER - %s\n%s\nCurrent value: %s",
 						annotation, strings.TrimSpace(warningMsg), truncateValue(value)),
 					&ing,
@@ -122,8 +147,8 @@ func appLevelWarningsFeature(ingresses []networkingv1.Ingress, _ map[types.Names
 
 		// Check for rewrite-target with capture groups
 		if rewrite := annotations["nginx.ingress.kubernetes.io/rewrite-target"]; rewrite != "" {
-			if strings.Contains(rewrite, "$") {
-				notify(notifications.ErrorNotification,
+			if _, ok := capturePrefixRewrites(&ing); strings.Contains(rewrite, "$") && !ok {
+				notifyWithCode(notifications.ErrorNotification, notifications.UnsupportedRewriteCaptureGroups,
 					fmt.Sprintf("MIGRATION BLOCKER - rewrite-target with capture groups\n%s\nCurrent value: %s",
 						strings.TrimSpace(appLevelAnnotations["nginx.ingress.kubernetes.io/rewrite-target"]),
 						rewrite),
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestAppLevelWarningsFeature_ErrorCodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		annotations  map[string]string
+		expectedCode notifications.ErrorCode
+	}{
+		{
+			name:         "rewrite-target with capture groups",
+			annotations:  map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/$1"},
+			expectedCode: notifications.UnsupportedRewriteCaptureGroups,
+		},
+		{
+			name:         "configuration-snippet",
+			annotations:  map[string]string{"nginx.ingress.kubernetes.io/configuration-snippet": "more_set_headers \"X-Foo: bar\";"},
+			expectedCode: notifications.UnsupportedSnippet,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir := intermediate.IR{}
+			if errs := appLevelWarningsFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("Expected no errors, got %v", errs)
+			}
+
+			found := false
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.ErrorNotification && n.Code == tc.expectedCode {
+					found = true
+					if !strings.HasPrefix(n.Message, "MIGRATION BLOCKER") {
+						t.Errorf("Expected a migration blocker message, got %q", n.Message)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("Expected an error notification with code %s, got %+v", tc.expectedCode, notifications.NotificationAggr.Notifications[Name])
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package ingressnginx
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
@@ -26,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -34,11 +37,12 @@ const (
 	backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
 
 	// Proxy SSL annotations for mTLS to backend
-	proxySSLSecretAnnotation    = "nginx.ingress.kubernetes.io/proxy-ssl-secret"
-	proxySSLVerifyAnnotation    = "nginx.ingress.kubernetes.io/proxy-ssl-verify"
-	proxySSLNameAnnotation      = "nginx.ingress.kubernetes.io/proxy-ssl-name"
-	proxySSLProtocolsAnnotation = "nginx.ingress.kubernetes.io/proxy-ssl-protocols"
-	proxySSLCiphersAnnotation   = "nginx.ingress.kubernetes.io/proxy-ssl-ciphers"
+	proxySSLSecretAnnotation     = "nginx.ingress.kubernetes.io/proxy-ssl-secret"
+	proxySSLVerifyAnnotation     = "nginx.ingress.kubernetes.io/proxy-ssl-verify"
+	proxySSLNameAnnotation       = "nginx.ingress.kubernetes.io/proxy-ssl-name"
+	proxySSLServerNameAnnotation = "nginx.ingress.kubernetes.io/proxy-ssl-server-name"
+	proxySSLProtocolsAnnotation  = "nginx.ingress.kubernetes.io/proxy-ssl-protocols"
+	proxySSLCiphersAnnotation    = "nginx.ingress.kubernetes.io/proxy-ssl-ciphers"
 )
 
 // backendTLSConfig holds the parsed backend TLS configuration from an Ingress
@@ -47,6 +51,8 @@ type backendTLSConfig struct {
 	sslSecret     string // namespace/secretName for client cert
 	sslVerify     bool   // whether to verify backend cert
 	sslName       string // SNI hostname
+	sslServerName string // proxy-ssl-server-name: on, off or unset
+	ingressHost   string // first non-wildcard Ingress host, the SNI fallback
 	sslProtocols  string // e.g., TLSv1.3
 	sslCiphers    string // cipher list
 	backendName   string // service name
@@ -70,12 +76,14 @@ func parseBackendTLSConfig(ingress *networkingv1.Ingress) *backendTLSConfig {
 	}
 
 	config := &backendTLSConfig{
-		protocol:     strings.ToUpper(protocol),
-		sslSecret:    ingress.Annotations[proxySSLSecretAnnotation],
-		sslName:      ingress.Annotations[proxySSLNameAnnotation],
-		sslProtocols: ingress.Annotations[proxySSLProtocolsAnnotation],
-		sslCiphers:   ingress.Annotations[proxySSLCiphersAnnotation],
-		namespace:    ingress.Namespace,
+		protocol:      strings.ToUpper(protocol),
+		sslSecret:     ingress.Annotations[proxySSLSecretAnnotation],
+		sslName:       ingress.Annotations[proxySSLNameAnnotation],
+		sslServerName: strings.TrimSpace(ingress.Annotations[proxySSLServerNameAnnotation]),
+		sslProtocols:  ingress.Annotations[proxySSLProtocolsAnnotation],
+		sslCiphers:    ingress.Annotations[proxySSLCiphersAnnotation],
+		namespace:     ingress.Namespace,
+		ingressHost:   firstPreciseIngressHost(ingress),
 	}
 
 	// Parse ssl-verify (defaults to "off")
@@ -85,6 +93,17 @@ func parseBackendTLSConfig(ingress *networkingv1.Ingress) *backendTLSConfig {
 	return config
 }
 
+// firstPreciseIngressHost returns the first rule host of the Ingress that is
+// not a wildcard, or an empty string if there is none
+func firstPreciseIngressHost(ingress *networkingv1.Ingress) string {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" && !strings.HasPrefix(rule.Host, "*") {
+			return rule.Host
+		}
+	}
+	return ""
+}
+
 // backendProtocolFeature processes backend-protocol and proxy-ssl-* annotations
 // and creates BackendTLSPolicy resources for HTTPS/GRPCS backends
 func backendProtocolFeature(ingresses []networkingv1.Ingress, servicePorts map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
@@ -95,104 +114,340 @@ func backendProtocolFeature(ingresses []networkingv1.Ingress, servicePorts map[t
 		ir.BackendTLSPolicies = make(map[types.NamespacedName]gatewayv1.BackendTLSPolicy)
 	}
 
+	// The SNI hostnames asked for each policy by the Ingresses sharing its
+	// service port, reported once all the Ingresses are processed
+	policySNIs := make(map[types.NamespacedName]*backendTLSSNIs)
+
 	for _, ingress := range ingresses {
 		config := parseBackendTLSConfig(&ingress)
 		if config == nil {
 			continue // No backend TLS needed
 		}
+		if config.sslServerName != "" && config.sslServerName != "on" && config.sslServerName != "off" {
+			errList = append(errList, field.Invalid(
+				field.NewPath("ingress", ingress.Namespace, ingress.Name, "metadata", "annotations").Key(proxySSLServerNameAnnotation),
+				config.sslServerName,
+				`must be "on" or "off"`,
+			))
+			continue
+		}
 
 		// Get all backend services from this ingress
-		backends := extractBackendServices(&ingress)
+		backends, backendErrs := extractBackendServices(&ingress)
+		errList = append(errList, backendErrs...)
 		if len(backends) == 0 {
 			continue
 		}
 
 		for _, backend := range backends {
-			policyName := fmt.Sprintf("%s-backend-tls", backend.serviceName)
+			svcKey := types.NamespacedName{Namespace: ingress.Namespace, Name: backend.serviceName}
+			updateServiceIR(ir, svcKey, func(svcIR *intermediate.IngressNginxServiceIR) {
+				mergeServiceIRField(&svcIR.BackendProtocol, config.protocol, backendProtocolAnnotation, svcKey, &ingress)
+				mergeServiceIRField(&svcIR.ProxySSLSecret, config.sslSecret, proxySSLSecretAnnotation, svcKey, &ingress)
+				mergeServiceIRField(&svcIR.ProxySSLName, config.sslName, proxySSLNameAnnotation, svcKey, &ingress)
+				mergeServiceIRField(&svcIR.ProxySSLProtocols, config.sslProtocols, proxySSLProtocolsAnnotation, svcKey, &ingress)
+				mergeServiceIRField(&svcIR.ProxySSLCiphers, config.sslCiphers, proxySSLCiphersAnnotation, svcKey, &ingress)
+				svcIR.ProxySSLVerify = svcIR.ProxySSLVerify || config.sslVerify
+			})
+
+			// A service can expose several TLS ports (e.g. HTTPS and GRPCS), each
+			// needing its own policy, so the port is part of the policy name
+			portName := backendServicePortName(backend, ingress.Namespace, servicePorts)
+			portID := portName
+			if portID == "" {
+				portID = fmt.Sprintf("%d", backend.servicePort)
+			}
+			policyName := fmt.Sprintf("%s-%s-backend-tls", backend.serviceName, portID)
 			policyKey := types.NamespacedName{
 				Namespace: ingress.Namespace,
 				Name:      policyName,
 			}
 
-			// Skip if policy already exists
-			if _, exists := ir.BackendTLSPolicies[policyKey]; exists {
+			// A policy has a single hostname, so when Ingresses sharing the
+			// service port ask for different SNIs the most specific is used
+			hostname := backendTLSHostname(backend.serviceName, config)
+			specificity := backendTLSHostnameSpecificity(config)
+			if policy, exists := ir.BackendTLSPolicies[policyKey]; exists {
+				snis := policySNIs[policyKey]
+				if snis == nil {
+					continue
+				}
+				snis.add(hostname, &ingress)
+				if specificity > snis.specificity {
+					snis.specificity = specificity
+					policy.Spec.Validation.Hostname = gatewayv1.PreciseHostname(hostname)
+					ir.BackendTLSPolicies[policyKey] = policy
+				}
+				continue
+			}
+
+			// The defaultBackend is only reached through the catch-all rules
+			// built for it, so make sure one exists rather than emitting a
+			// policy for a Service no route sends traffic to
+			if backend.defaultBackendOnly && !isServiceRouted(ir, ingress.Namespace, backend.serviceName) {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("no HTTPRoute sends traffic to service %s/%s, skipping its BackendTLSPolicy",
+						ingress.Namespace, backend.serviceName),
+					&ingress)
 				continue
 			}
 
-			policy := buildBackendTLSPolicy(policyName, ingress.Namespace, backend.serviceName, config)
+			policy := buildBackendTLSPolicy(policyName, ingress.Namespace, backend.serviceName, portName, config)
 			if policy != nil {
 				ir.BackendTLSPolicies[policyKey] = *policy
+				policySNIs[policyKey] = &backendTLSSNIs{specificity: specificity}
+				policySNIs[policyKey].add(hostname, &ingress)
 
 				notify(notifications.InfoNotification,
 					fmt.Sprintf("created BackendTLSPolicy %s/%s for service %s (protocol: %s, verify: %v)",
 						ingress.Namespace, policyName, backend.serviceName, config.protocol, config.sslVerify),
 					&ingress)
+				notifyBackendTLSHostname(&ingress, policyKey, backend.serviceName, config)
 			}
 		}
 	}
 
+	for _, policyKey := range slices.SortedFunc(maps.Keys(policySNIs), compareNamespacedNames) {
+		policySNIs[policyKey].notifyConflicts(policyKey, ir.BackendTLSPolicies[policyKey])
+	}
+
 	return errList
 }
 
+// backendTLSSNIs records the SNI hostnames asked for a BackendTLSPolicy by the
+// Ingresses sharing its service port
+type backendTLSSNIs struct {
+	hostnames []string
+	ingresses map[string][]*networkingv1.Ingress
+	// specificity of the hostname the policy uses
+	specificity int
+}
+
+func (s *backendTLSSNIs) add(hostname string, ingress *networkingv1.Ingress) {
+	if s.ingresses == nil {
+		s.ingresses = make(map[string][]*networkingv1.Ingress)
+	}
+	if _, ok := s.ingresses[hostname]; !ok {
+		s.hostnames = append(s.hostnames, hostname)
+	}
+	s.ingresses[hostname] = append(s.ingresses[hostname], ingress)
+}
+
+// notifyConflicts emits a Warning listing the SNI hostnames and the Ingresses
+// asking for them when they differ, with the hostname the policy uses
+func (s *backendTLSSNIs) notifyConflicts(policyKey types.NamespacedName, policy gatewayv1.BackendTLSPolicy) {
+	if len(s.hostnames) < 2 {
+		return
+	}
+
+	var conflicts []string
+	var objects []client.Object
+	for _, hostname := range slices.Sorted(slices.Values(s.hostnames)) {
+		var names []string
+		for _, ingress := range s.ingresses[hostname] {
+			names = append(names, ingress.Name)
+			objects = append(objects, ingress)
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s: %s", hostname, strings.Join(names, ", ")))
+	}
+	notify(notifications.WarningNotification,
+		fmt.Sprintf("Ingresses sharing BackendTLSPolicy %s ask for conflicting SNI hostnames (%s); %s is used as the most specific. "+
+			"A %s is preferred over the Ingress host, then the service name, and the first Ingress wins a tie",
+			policyKey, strings.Join(conflicts, "; "), policy.Spec.Validation.Hostname, proxySSLNameAnnotation),
+		objects...,
+	)
+}
+
+// backendTLSHostnameSpecificity ranks the sources of the backend hostname:
+// proxy-ssl-name, then the Ingress host, then the service name
+func backendTLSHostnameSpecificity(config *backendTLSConfig) int {
+	switch {
+	case config.sslName != "":
+		return 2
+	case config.ingressHost != "":
+		return 1
+	}
+	return 0
+}
+
+// isServiceRouted returns true if a rule of an HTTPRoute in the namespace has
+// the Service as a backend
+func isServiceRouted(ir *intermediate.IR, namespace, serviceName string) bool {
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				backendNamespace := routeKey.Namespace
+				if backendRef.Namespace != nil {
+					backendNamespace = string(*backendRef.Namespace)
+				}
+				if backendNamespace == namespace && string(backendRef.Name) == serviceName &&
+					isServiceBackendRef(backendRef.BackendObjectReference) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 type backendService struct {
-	serviceName string
-	servicePort int32
+	serviceName     string
+	servicePort     int32
+	servicePortName string
+	// defaultBackendOnly is set if only the defaultBackend uses the service port
+	defaultBackendOnly bool
 }
 
-// extractBackendServices gets all backend services from an Ingress
-func extractBackendServices(ingress *networkingv1.Ingress) []backendService {
+// backendServicePortName returns the name of the service port the backend
+// references, looking it up by number in servicePorts if the Ingress uses a
+// port number. It returns an empty string if the name is unknown.
+func backendServicePortName(backend backendService, namespace string, servicePorts map[types.NamespacedName]map[string]int32) string {
+	if backend.servicePortName != "" {
+		return backend.servicePortName
+	}
+	ports := servicePorts[types.NamespacedName{Namespace: namespace, Name: backend.serviceName}]
+	for _, name := range slices.Sorted(maps.Keys(ports)) {
+		if name != "" && ports[name] == backend.servicePort {
+			return name
+		}
+	}
+	return ""
+}
+
+// extractBackendServices gets all backend services from an Ingress.
+// Backends without a service or resource, or with an empty service name, are
+// skipped and reported as errors. Resource backends are skipped, they are
+// reported by reportResourceBackends.
+func extractBackendServices(ingress *networkingv1.Ingress) ([]backendService, field.ErrorList) {
 	var backends []backendService
+	var errs field.ErrorList
 	seen := make(map[string]bool)
+	specPath := field.NewPath("ingress", ingress.Namespace, ingress.Name, "spec")
 
 	// Check default backend
-	if ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
-		svc := ingress.Spec.DefaultBackend.Service
-		key := fmt.Sprintf("%s:%d", svc.Name, svc.Port.Number)
-		if !seen[key] {
-			backends = append(backends, backendService{
-				serviceName: svc.Name,
-				servicePort: svc.Port.Number,
-			})
-			seen[key] = true
+	if ingress.Spec.DefaultBackend != nil {
+		if err := validateIngressBackend(*ingress.Spec.DefaultBackend, specPath.Child("defaultBackend")); err != nil {
+			errs = append(errs, err)
+		} else if svc := ingress.Spec.DefaultBackend.Service; svc != nil {
+			key := fmt.Sprintf("%s:%d:%s", svc.Name, svc.Port.Number, svc.Port.Name)
+			if !seen[key] {
+				backends = append(backends, backendService{
+					serviceName:        svc.Name,
+					servicePort:        svc.Port.Number,
+					servicePortName:    svc.Port.Name,
+					defaultBackendOnly: true,
+				})
+				seen[key] = true
+			}
 		}
 	}
 
 	// Check rule backends
-	for _, rule := range ingress.Spec.Rules {
+	for i, rule := range ingress.Spec.Rules {
 		if rule.HTTP == nil {
 			continue
 		}
-		for _, path := range rule.HTTP.Paths {
+		for j, path := range rule.HTTP.Paths {
+			backendPath := specPath.Child("rules").Index(i).Child("http", "paths").Index(j).Child("backend")
+			if err := validateIngressBackend(path.Backend, backendPath); err != nil {
+				errs = append(errs, err)
+				continue
+			}
 			if path.Backend.Service == nil {
 				continue
 			}
 			svc := path.Backend.Service
-			key := fmt.Sprintf("%s:%d", svc.Name, svc.Port.Number)
-			if !seen[key] {
-				backends = append(backends, backendService{
-					serviceName: svc.Name,
-					servicePort: svc.Port.Number,
-				})
-				seen[key] = true
+			key := fmt.Sprintf("%s:%d:%s", svc.Name, svc.Port.Number, svc.Port.Name)
+			if seen[key] {
+				// A rule using the defaultBackend service routes to it as well
+				if len(backends) > 0 && backends[0].defaultBackendOnly && backends[0].serviceName == svc.Name {
+					backends[0].defaultBackendOnly = false
+				}
+				continue
 			}
+			backends = append(backends, backendService{
+				serviceName:     svc.Name,
+				servicePort:     svc.Port.Number,
+				servicePortName: svc.Port.Name,
+			})
+			seen[key] = true
 		}
 	}
 
-	return backends
+	return backends, errs
+}
+
+// validateIngressBackend checks that the backend references either a resource
+// or a service with a name
+func validateIngressBackend(backend networkingv1.IngressBackend, path *field.Path) *field.Error {
+	switch {
+	case backend.Service == nil && backend.Resource == nil:
+		return field.Required(path, "backend must reference a service or a resource")
+	case backend.Service != nil && backend.Service.Name == "":
+		return field.Required(path.Child("service", "name"), "backend service name must not be empty")
+	}
+	return nil
+}
+
+// backendTLSHostname returns the SNI and validation hostname of the backend:
+// proxy-ssl-name, else the first Ingress host, which ingress-nginx sends as
+// the Host header to the backend, else the service name
+func backendTLSHostname(serviceName string, config *backendTLSConfig) string {
+	switch {
+	case config.sslName != "":
+		return config.sslName
+	case config.ingressHost != "":
+		return config.ingressHost
+	}
+	return serviceName
+}
+
+// notifyBackendTLSHostname reports the hostname a BackendTLSPolicy falls back
+// to without proxy-ssl-name. A BackendTLSPolicy always sends its hostname as
+// SNI, while ingress-nginx only sends proxy-ssl-name with
+// proxy-ssl-server-name "on", so both values of proxy-ssl-server-name are
+// reported.
+func notifyBackendTLSHostname(ingress *networkingv1.Ingress, policyKey types.NamespacedName, serviceName string, config *backendTLSConfig) {
+	switch {
+	case config.sslServerName == "off":
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s is off, so ingress-nginx sends no SNI to the backend, while BackendTLSPolicy %s always sends %s as SNI",
+				proxySSLServerNameAnnotation, policyKey, backendTLSHostname(serviceName, config)),
+			ingress)
+	case config.sslServerName == "on" && config.sslName == "":
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("%s is on but %s is not set, BackendTLSPolicy %s sends %s as SNI and validates the backend certificate against it. "+
+				"Set %s to the name the backend expects",
+				proxySSLServerNameAnnotation, proxySSLNameAnnotation, policyKey, backendTLSHostname(serviceName, config),
+				proxySSLNameAnnotation),
+			ingress)
+		return
+	}
+	if config.sslName != "" {
+		return
+	}
+	if config.ingressHost != "" {
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s is not set, BackendTLSPolicy %s uses the Ingress host %s as SNI and to validate the backend certificate",
+				proxySSLNameAnnotation, policyKey, config.ingressHost),
+			ingress)
+		return
+	}
+	notify(notifications.WarningNotification,
+		fmt.Sprintf("%s is not set and the Ingress has no host, the SNI of BackendTLSPolicy %s defaults to the service name, "+
+			"which rarely matches the backend certificate. Set %s to the name of the certificate",
+			proxySSLNameAnnotation, policyKey, proxySSLNameAnnotation),
+		ingress)
 }
 
-// buildBackendTLSPolicy creates a BackendTLSPolicy for mTLS to backend
-func buildBackendTLSPolicy(name, namespace, serviceName string, config *backendTLSConfig) *gatewayv1.BackendTLSPolicy {
+// buildBackendTLSPolicy creates a BackendTLSPolicy for mTLS to backend.
+// If portName is set, the policy only targets that port of the service.
+func buildBackendTLSPolicy(name, namespace, serviceName, portName string, config *backendTLSConfig) *gatewayv1.BackendTLSPolicy {
 	if config == nil {
 		return nil
 	}
 
-	// Determine the hostname for TLS validation
-	hostname := config.sslName
-	if hostname == "" {
-		// Use service name as default hostname
-		hostname = serviceName
-	}
+	hostname := backendTLSHostname(serviceName, config)
 
 	policy := &gatewayv1.BackendTLSPolicy{
 		TypeMeta: metav1.TypeMeta{
@@ -219,6 +474,11 @@ func buildBackendTLSPolicy(name, namespace, serviceName string, config *backendT
 		},
 	}
 
+	if portName != "" {
+		sectionName := gatewayv1.SectionName(portName)
+		policy.Spec.TargetRefs[0].SectionName = &sectionName
+	}
+
 	// Add CA certificate reference if proxy-ssl-secret is specified
 	if config.sslSecret != "" {
 		caConfigMapName := "ca-ame-nginx"
@@ -17,12 +17,17 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -74,7 +79,7 @@ func TestBackendProtocolFeature(t *testing.T) {
 				},
 			},
 			expectedPolicies:       1,
-			expectedPolicyName:     "my-service-backend-tls",
+			expectedPolicyName:     "my-service-443-backend-tls",
 			expectedHostname:       "backend.internal",
 			expectWellKnownCACerts: true,
 		},
@@ -119,7 +124,7 @@ func TestBackendProtocolFeature(t *testing.T) {
 				},
 			},
 			expectedPolicies:       1,
-			expectedPolicyName:     "my-service-backend-tls",
+			expectedPolicyName:     "my-service-443-backend-tls",
 			expectedHostname:       "backend.internal",
 			expectWellKnownCACerts: false,
 		},
@@ -273,3 +278,518 @@ func TestParseBackendTLSConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestBackendProtocolFeature_SNIHostname(t *testing.T) {
+	testCases := []struct {
+		name             string
+		host             string
+		sslName          string
+		sslServerName    string
+		expectedHostname string
+		expectedWarnings int
+		expectedInfo     string
+		expectError      bool
+	}{
+		{
+			name:             "explicit proxy-ssl-name",
+			host:             "example.com",
+			sslName:          "backend.internal",
+			expectedHostname: "backend.internal",
+		},
+		{
+			name:             "falls back to the Ingress host",
+			host:             "example.com",
+			expectedHostname: "example.com",
+		},
+		{
+			name:             "wildcard host falls back to the service name",
+			host:             "*.example.com",
+			expectedHostname: "my-service",
+			expectedWarnings: 1,
+		},
+		{
+			name:             "no host falls back to the service name",
+			expectedHostname: "my-service",
+			expectedWarnings: 1,
+		},
+		{
+			name:             "proxy-ssl-server-name on without proxy-ssl-name",
+			host:             "example.com",
+			sslServerName:    "on",
+			expectedHostname: "example.com",
+			expectedWarnings: 1,
+		},
+		{
+			name:             "proxy-ssl-server-name off still sends SNI",
+			host:             "example.com",
+			sslName:          "backend.internal",
+			sslServerName:    "off",
+			expectedHostname: "backend.internal",
+			expectedInfo:     proxySSLServerNameAnnotation + " is off",
+		},
+		{
+			name:          "invalid proxy-ssl-server-name causes error",
+			host:          "example.com",
+			sslServerName: "yes",
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			annotations := map[string]string{backendProtocolAnnotation: "HTTPS"}
+			if tc.sslName != "" {
+				annotations[proxySSLNameAnnotation] = tc.sslName
+			}
+			if tc.sslServerName != "" {
+				annotations[proxySSLServerNameAnnotation] = tc.sslServerName
+			}
+			ingress := headersTestIngress(annotations)
+			ingress.Spec.Rules[0].Host = tc.host
+			ir := intermediate.IR{}
+
+			errs := backendProtocolFeature([]networkingv1.Ingress{ingress}, nil, &ir)
+			if tc.expectError {
+				if len(errs) == 0 {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			policy, ok := ir.BackendTLSPolicies[types.NamespacedName{Namespace: "default", Name: "my-service-80-backend-tls"}]
+			if !ok {
+				t.Fatalf("expected a BackendTLSPolicy, got %v", ir.BackendTLSPolicies)
+			}
+			if string(policy.Spec.Validation.Hostname) != tc.expectedHostname {
+				t.Errorf("expected hostname %s, got %s", tc.expectedHostname, policy.Spec.Validation.Hostname)
+			}
+
+			warnings := 0
+			foundInfo := false
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+				if tc.expectedInfo != "" && n.Type == notifications.InfoNotification && strings.Contains(n.Message, tc.expectedInfo) {
+					foundInfo = true
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+			if tc.expectedInfo != "" && !foundInfo {
+				t.Errorf("expected an info containing %q", tc.expectedInfo)
+			}
+		})
+	}
+}
+
+func TestBackendProtocolFeature_ConflictingSNIHostnames(t *testing.T) {
+	testCases := []struct {
+		name             string
+		sslNames         [2]string
+		expectedHostname string
+		expectedConflict string
+	}{
+		{
+			name:             "proxy-ssl-name is more specific than the Ingress host",
+			sslNames:         [2]string{"", "backend.internal"},
+			expectedHostname: "backend.internal",
+			expectedConflict: "(a.example.com: ingress-a; backend.internal: ingress-b)",
+		},
+		{
+			name:             "first Ingress wins between Ingress hosts",
+			expectedHostname: "a.example.com",
+			expectedConflict: "(a.example.com: ingress-a; b.example.com: ingress-b)",
+		},
+		{
+			name:             "same proxy-ssl-name does not conflict",
+			sslNames:         [2]string{"backend.internal", "backend.internal"},
+			expectedHostname: "backend.internal",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			var ingresses []networkingv1.Ingress
+			for i, name := range []string{"a", "b"} {
+				annotations := map[string]string{backendProtocolAnnotation: "HTTPS"}
+				if tc.sslNames[i] != "" {
+					annotations[proxySSLNameAnnotation] = tc.sslNames[i]
+				}
+				ingress := headersTestIngress(annotations)
+				ingress.Name = "ingress-" + name
+				ingress.Spec.Rules[0].Host = name + ".example.com"
+				ingresses = append(ingresses, ingress)
+			}
+			ir := intermediate.IR{}
+
+			if errs := backendProtocolFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if len(ir.BackendTLSPolicies) != 1 {
+				t.Fatalf("expected a single BackendTLSPolicy for the shared service, got %d", len(ir.BackendTLSPolicies))
+			}
+			policy := ir.BackendTLSPolicies[types.NamespacedName{Namespace: "default", Name: "my-service-80-backend-tls"}]
+			if string(policy.Spec.Validation.Hostname) != tc.expectedHostname {
+				t.Errorf("expected hostname %s, got %s", tc.expectedHostname, policy.Spec.Validation.Hostname)
+			}
+
+			var conflicts []notifications.Notification
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "conflicting SNI hostnames") {
+					conflicts = append(conflicts, n)
+				}
+			}
+			if tc.expectedConflict == "" {
+				if len(conflicts) > 0 {
+					t.Errorf("expected no SNI conflict, got %q", conflicts[0].Message)
+				}
+				return
+			}
+			if len(conflicts) != 1 {
+				t.Fatalf("expected 1 SNI conflict warning, got %d", len(conflicts))
+			}
+			expected := tc.expectedConflict + "; " + tc.expectedHostname + " is used"
+			if !strings.Contains(conflicts[0].Message, expected) {
+				t.Errorf("expected the warning to contain %q, got %q", expected, conflicts[0].Message)
+			}
+		})
+	}
+}
+
+func TestBackendProtocolFeature_MultiplePorts(t *testing.T) {
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "my-service",
+											Port: networkingv1.ServiceBackendPort{Number: 443},
+										},
+									},
+								},
+								{
+									Path: "/admin",
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "my-service",
+											Port: networkingv1.ServiceBackendPort{Number: 8443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		servicePorts         map[types.NamespacedName]map[string]int32
+		expectedSectionNames map[string]string
+	}{
+		{
+			name: "unnamed ports",
+			expectedSectionNames: map[string]string{
+				"my-service-443-backend-tls":  "",
+				"my-service-8443-backend-tls": "",
+			},
+		},
+		{
+			name: "ports named in the service",
+			servicePorts: map[types.NamespacedName]map[string]int32{
+				{Namespace: "default", Name: "my-service"}: {"https": 443, "admin": 8443},
+			},
+			expectedSectionNames: map[string]string{
+				"my-service-https-backend-tls": "https",
+				"my-service-admin-backend-tls": "admin",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ir := intermediate.IR{
+				BackendTLSPolicies: make(map[types.NamespacedName]gatewayv1.BackendTLSPolicy),
+			}
+
+			errs := backendProtocolFeature([]networkingv1.Ingress{ingress}, tc.servicePorts, &ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if len(ir.BackendTLSPolicies) != len(tc.expectedSectionNames) {
+				t.Fatalf("expected %d BackendTLSPolicies, got %d", len(tc.expectedSectionNames), len(ir.BackendTLSPolicies))
+			}
+
+			for policyName, expectedSectionName := range tc.expectedSectionNames {
+				policy, exists := ir.BackendTLSPolicies[types.NamespacedName{Namespace: "default", Name: policyName}]
+				if !exists {
+					t.Fatalf("expected BackendTLSPolicy %s not found", policyName)
+				}
+
+				targetRef := policy.Spec.TargetRefs[0]
+				var sectionName string
+				if targetRef.SectionName != nil {
+					sectionName = string(*targetRef.SectionName)
+				}
+				if sectionName != expectedSectionName {
+					t.Errorf("policy %s: expected sectionName %q, got %q", policyName, expectedSectionName, sectionName)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractBackendServices_InvalidBackends(t *testing.T) {
+	pathPrefix := networkingv1.PathTypePrefix
+	validPath := networkingv1.HTTPIngressPath{
+		Path:     "/valid",
+		PathType: &pathPrefix,
+		Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: "valid-service",
+				Port: networkingv1.ServiceBackendPort{Number: 443},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		backend       networkingv1.IngressBackend
+		expectedField string
+	}{
+		{
+			name:          "path without a service or resource backend",
+			backend:       networkingv1.IngressBackend{},
+			expectedField: "ingress.default.test-ingress.spec.rules[0].http.paths[1].backend",
+		},
+		{
+			name: "path with an empty service name",
+			backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Port: networkingv1.ServiceBackendPort{Number: 443},
+				},
+			},
+			expectedField: "ingress.default.test-ingress.spec.rules[0].http.paths[1].backend.service.name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ingress", Namespace: "default"},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{
+						Host: "example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{
+									validPath,
+									{Path: "/invalid", PathType: &pathPrefix, Backend: tc.backend},
+								},
+							},
+						},
+					}},
+				},
+			}
+
+			backends, errs := extractBackendServices(&ingress)
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+			}
+			if errs[0].Type != field.ErrorTypeRequired || errs[0].Field != tc.expectedField {
+				t.Errorf("expected Required error for %s, got %v", tc.expectedField, errs[0])
+			}
+			if len(backends) != 1 || backends[0].serviceName != "valid-service" {
+				t.Errorf("expected only valid-service backend, got %+v", backends)
+			}
+		})
+	}
+}
+
+func TestBackendProtocolFeature_DefaultBackend(t *testing.T) {
+	newIngress := func() networkingv1.Ingress {
+		ingress := headersTestIngress(map[string]string{backendProtocolAnnotation: "HTTPS"})
+		ingress.Spec.DefaultBackend = &networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: "fallback",
+				Port: networkingv1.ServiceBackendPort{Number: 443},
+			},
+		}
+		return ingress
+	}
+	policyKey := types.NamespacedName{Namespace: "default", Name: "fallback-443-backend-tls"}
+
+	t.Run("policy for the defaultBackend comes with a catch-all route", func(t *testing.T) {
+		provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+		ingress := newIngress()
+		provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+			{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+		})
+
+		ir, errs := provider.ToIR()
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors converting to IR: %v", errs)
+		}
+		gatewayResources, errs := provider.ToGatewayResources(ir)
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+
+		if _, ok := gatewayResources.BackendTLSPolicies[policyKey]; !ok {
+			t.Fatalf("expected BackendTLSPolicy %s, got %v", policyKey, gatewayResources.BackendTLSPolicies)
+		}
+		catchAll := false
+		for _, route := range gatewayResources.HTTPRoutes {
+			for _, rule := range route.Spec.Rules {
+				matchesAll := len(rule.Matches) == 0 || hasCatchAllRule(gatewayv1.HTTPRoute{
+					Spec: gatewayv1.HTTPRouteSpec{Rules: []gatewayv1.HTTPRouteRule{rule}},
+				})
+				if matchesAll && len(rule.BackendRefs) == 1 && rule.BackendRefs[0].Name == "fallback" {
+					catchAll = true
+				}
+			}
+		}
+		if !catchAll {
+			t.Errorf("expected a catch-all rule to service fallback, got %v", gatewayResources.HTTPRoutes)
+		}
+	})
+
+	t.Run("no policy for a defaultBackend without a route", func(t *testing.T) {
+		ingress := newIngress()
+		ir, errs := common.ToIR([]networkingv1.Ingress{ingress}, nil, i2gw.ProviderImplementationSpecificOptions{})
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors building IR: %v", errs)
+		}
+		delete(ir.HTTPRoutes, types.NamespacedName{Namespace: "default", Name: "test-ingress-default-backend"})
+
+		if errs := backendProtocolFeature([]networkingv1.Ingress{ingress}, nil, &ir); len(errs) > 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if _, ok := ir.BackendTLSPolicies[policyKey]; ok {
+			t.Errorf("expected no BackendTLSPolicy %s", policyKey)
+		}
+		if _, ok := ir.BackendTLSPolicies[types.NamespacedName{Namespace: "default", Name: "my-service-80-backend-tls"}]; !ok {
+			t.Errorf("expected a BackendTLSPolicy for the rule backend, got %v", ir.BackendTLSPolicies)
+		}
+	})
+}
+
+func TestServiceIR_BackendProtocolWithLoadBalance(t *testing.T) {
+	httpsIngress := headersTestIngress(map[string]string{
+		backendProtocolAnnotation: "HTTPS",
+		proxySSLNameAnnotation:    "my-service.internal",
+	})
+	lbIngress := headersTestIngress(map[string]string{loadBalanceAnnotation: "ewma"})
+	lbIngress.Name = "lb-ingress"
+	lbIngress.Spec.Rules[0].Host = "other.example.com"
+
+	// The merged IR is the same whichever feature runs first, and running a
+	// feature again changes nothing
+	featureOrders := map[string][]i2gw.FeatureParser{
+		"backend protocol first": {backendProtocolFeature, proxySettingsFeature, backendProtocolFeature},
+		"load balance first":     {proxySettingsFeature, backendProtocolFeature, proxySettingsFeature},
+	}
+	for name, features := range featureOrders {
+		t.Run(name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{httpsIngress, lbIngress}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			for _, feature := range features {
+				if errs := feature(ingresses, nil, &ir); len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+			}
+
+			expected := intermediate.IngressNginxServiceIR{
+				BackendProtocol:      "HTTPS",
+				ProxySSLName:         "my-service.internal",
+				LoadBalanceAlgorithm: "ewma",
+			}
+			svcIR := ir.Services[types.NamespacedName{Namespace: "default", Name: "my-service"}].IngressNginx
+			if svcIR == nil || *svcIR != expected {
+				t.Errorf("expected service IR %+v, got %+v", expected, svcIR)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_GRPCBackendListeners(t *testing.T) {
+	for _, protocol := range []string{"GRPC", "GRPCS"} {
+		t.Run(protocol, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			ingress := headersTestIngress(map[string]string{backendProtocolAnnotation: protocol})
+			ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}}
+
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+				},
+			}).(*Provider)
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			gateway, ok := gatewayResources.Gateways[types.NamespacedName{Namespace: "default", Name: "default-gateway"}]
+			if !ok {
+				t.Fatalf("expected Gateway default/default-gateway, got %v", gatewayResources.Gateways)
+			}
+			// The backend protocol only applies between the Gateway and the
+			// backend, clients still reach the listeners over HTTP(S)
+			protocols := map[gatewayv1.SectionName]gatewayv1.ProtocolType{}
+			for _, listener := range gateway.Spec.Listeners {
+				protocols[listener.Name] = listener.Protocol
+			}
+			expectedProtocols := map[gatewayv1.SectionName]gatewayv1.ProtocolType{
+				"example-com-http":  gatewayv1.HTTPProtocolType,
+				"example-com-https": gatewayv1.HTTPSProtocolType,
+			}
+			if len(protocols) != len(expectedProtocols) {
+				t.Fatalf("expected listeners %v, got %v", expectedProtocols, protocols)
+			}
+			for name, expected := range expectedProtocols {
+				if protocols[name] != expected {
+					t.Errorf("expected listener %s to use %s, got %q", name, expected, protocols[name])
+				}
+			}
+			if len(gatewayResources.HTTPRoutes) != 1 {
+				t.Errorf("expected the gRPC backend to be routed by 1 HTTPRoute, got %d", len(gatewayResources.HTTPRoutes))
+			}
+		})
+	}
+}
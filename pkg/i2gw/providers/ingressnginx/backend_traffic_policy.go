@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// isEnvoyGatewayClass returns true if the configured GatewayClass is served by
+// Envoy Gateway, which supports BackendTrafficPolicies
+func isEnvoyGatewayClass(gwConfig GatewayConfig) bool {
+	return gatewayClassController(gwConfig) == knownGatewayClassControllers["envoy-gateway"]
+}
+
+// buildBackendTrafficPolicies generates an Envoy Gateway BackendTrafficPolicy
+// for every generated HTTPRoute with a connect timeout, a rate limit or a max
+// body size, combining in a single policy targeting the route the settings
+// Istio gets from separate EnvoyFilters and DestinationRules:
+//   - timeout.tcp.connectTimeout from proxy-connect-timeout
+//   - a Local rateLimit of limit-rps, or limit-rpm or the limit-req-zone rate
+//     converted to requests per second
+//   - requestBuffer.limit from proxy-body-size, larger requests being rejected
+//     with 413 like nginx does
+//
+// Envoy Gateway local rate limits have no burst and cannot exempt clients, so
+// limit-burst-multiplier is ignored and limit-whitelist is reported with a
+// Warning.
+func buildBackendTrafficPolicies(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	for _, routeKey := range slices.SortedFunc(maps.Keys(ir.HTTPRoutes), compareNamespacedNames) {
+		routeCtx := ir.HTTPRoutes[routeKey]
+		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+		if nginxIR == nil {
+			continue
+		}
+		// Routes pruned from the output get no policy
+		route, ok := gatewayResources.HTTPRoutes[routeKey]
+		if !ok {
+			continue
+		}
+
+		spec := map[string]interface{}{}
+		var sources, settings []string
+
+		if nginxIR.ConnectTimeoutSeconds > 0 {
+			spec["timeout"] = map[string]interface{}{
+				"tcp": map[string]interface{}{
+					"connectTimeout": fmt.Sprintf("%ds", nginxIR.ConnectTimeoutSeconds),
+				},
+			}
+			sources = append(sources, proxyConnectTimeoutAnnotation)
+			settings = append(settings, fmt.Sprintf("connect timeout %ds", nginxIR.ConnectTimeoutSeconds))
+		}
+
+		if nginxIR.RateLimitRPS > 0 {
+			spec["rateLimit"] = map[string]interface{}{
+				"type": "Local",
+				"local": map[string]interface{}{
+					"rules": []interface{}{
+						map[string]interface{}{
+							"limit": map[string]interface{}{
+								"requests": int64(nginxIR.RateLimitRPS),
+								"unit":     "Second",
+							},
+						},
+					},
+				},
+			}
+			sources = append(sources, cmp.Or(nginxIR.RateLimitSource, limitRPSAnnotation))
+			settings = append(settings, fmt.Sprintf("rate limit %d rps", nginxIR.RateLimitRPS))
+			if len(nginxIR.RateLimitExemptRanges) > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s cannot be converted to the local rate limit of BackendTrafficPolicy %s/%s, the ranges %s are rate limited too",
+						limitWhitelistAnnotation, routeKey.Namespace, backendTrafficPolicyName(routeKey), strings.Join(nginxIR.RateLimitExemptRanges, ", ")),
+					&route,
+				)
+			}
+		}
+
+		// "0" means unlimited
+		if bodyBytes, err := ParseBodySize(nginxIR.ProxyBodySize); err == nil && bodyBytes > 0 {
+			limit := resource.NewQuantity(bodyBytes, resource.BinarySI).String()
+			spec["requestBuffer"] = map[string]interface{}{
+				"limit": limit,
+			}
+			sources = append(sources, proxyBodySizeAnnotation)
+			settings = append(settings, "max body size "+limit)
+		}
+
+		if len(spec) == 0 {
+			continue
+		}
+
+		policy := buildBackendTrafficPolicy(routeKey, spec, sources, gwConfig)
+		gatewayResources.GatewayExtensions = append(gatewayResources.GatewayExtensions, *policy)
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("created BackendTrafficPolicy %s/%s with %s for HTTPRoute %s",
+				policy.GetNamespace(), policy.GetName(), strings.Join(settings, ", "), routeKey),
+			&route,
+		)
+	}
+}
+
+// backendTrafficPolicyName returns the name of the BackendTrafficPolicy of
+// the route
+func backendTrafficPolicyName(routeKey types.NamespacedName) string {
+	return routeKey.Name + "-traffic-policy"
+}
+
+// buildBackendTrafficPolicy creates a BackendTrafficPolicy with the spec
+// settings, targeting the route
+func buildBackendTrafficPolicy(routeKey types.NamespacedName, spec map[string]interface{}, sources []string, gwConfig GatewayConfig) *unstructured.Unstructured {
+	spec["targetRefs"] = []interface{}{
+		map[string]interface{}{
+			"group": "gateway.networking.k8s.io",
+			"kind":  "HTTPRoute",
+			"name":  routeKey.Name,
+		},
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "gateway.envoyproxy.io/v1alpha1",
+			"kind":       "BackendTrafficPolicy",
+			"metadata": map[string]interface{}{
+				"name":      backendTrafficPolicyName(routeKey),
+				"namespace": routeKey.Namespace,
+				"labels":    unstructuredLabels(gwConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": strings.Join(sources, ","),
+				},
+			},
+			"spec": spec,
+		},
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_BackendTrafficPolicy(t *testing.T) {
+	testCases := []struct {
+		name             string
+		gatewayClass     string
+		expectedPolicies int
+	}{
+		{name: "envoy gateway class", gatewayClass: "envoy-gateway", expectedPolicies: 1},
+		{name: "istio class", gatewayClass: "istio", expectedPolicies: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayClassFlag: tc.gatewayClass},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(map[string]string{
+				proxyConnectTimeoutAnnotation: "5",
+				limitRPSAnnotation:            "10",
+				proxyBodySizeAnnotation:       "10m",
+			})
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var policies []unstructured.Unstructured
+			for _, extension := range gatewayResources.GatewayExtensions {
+				if extension.GetKind() == "BackendTrafficPolicy" {
+					policies = append(policies, extension)
+				}
+			}
+			if len(policies) != tc.expectedPolicies {
+				t.Fatalf("expected %d BackendTrafficPolicies, got %d", tc.expectedPolicies, len(policies))
+			}
+			if tc.expectedPolicies == 0 {
+				return
+			}
+
+			routeName := common.RouteName(ingress.Name, "example.com")
+			policy := policies[0]
+			if policy.GetNamespace() != "default" || policy.GetName() != routeName+"-traffic-policy" {
+				t.Errorf("unexpected BackendTrafficPolicy %s/%s", policy.GetNamespace(), policy.GetName())
+			}
+			expectedSpec := map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"group": "gateway.networking.k8s.io",
+						"kind":  "HTTPRoute",
+						"name":  routeName,
+					},
+				},
+				"timeout": map[string]interface{}{
+					"tcp": map[string]interface{}{"connectTimeout": "5s"},
+				},
+				"rateLimit": map[string]interface{}{
+					"type": "Local",
+					"local": map[string]interface{}{
+						"rules": []interface{}{
+							map[string]interface{}{
+								"limit": map[string]interface{}{"requests": int64(10), "unit": "Second"},
+							},
+						},
+					},
+				},
+				"requestBuffer": map[string]interface{}{"limit": "10Mi"},
+			}
+			if diff := cmp.Diff(expectedSpec, policy.Object["spec"]); diff != "" {
+				t.Errorf("unexpected BackendTrafficPolicy spec (-want +got):\n%s", diff)
+			}
+			expectedSource := proxyConnectTimeoutAnnotation + "," + limitRPSAnnotation + "," + proxyBodySizeAnnotation
+			if source := policy.GetAnnotations()["ingress2gateway.kubernetes.io/source"]; source != expectedSource {
+				t.Errorf("expected source annotation %q, got %q", expectedSource, source)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_BackendTrafficPolicyRateLimitSource(t *testing.T) {
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		expectedSource string
+	}{
+		{
+			name:           "limit-rpm",
+			annotations:    map[string]string{limitRPMAnnotation: "600"},
+			expectedSource: limitRPMAnnotation,
+		},
+		{
+			name:           "limit-req-zone",
+			annotations:    map[string]string{limitReqZoneAnnotation: "$binary_remote_addr zone=rate_limit:10m rate=100r/s burst=200 nodelay"},
+			expectedSource: limitReqZoneAnnotation,
+		},
+		{
+			name:           "limit-rps takes precedence over limit-rpm",
+			annotations:    map[string]string{limitRPSAnnotation: "10", limitRPMAnnotation: "600"},
+			expectedSource: limitRPSAnnotation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayClassFlag: "envoy-gateway"},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(tc.annotations)
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var sources []string
+			for _, extension := range gatewayResources.GatewayExtensions {
+				if extension.GetKind() == "BackendTrafficPolicy" {
+					sources = append(sources, extension.GetAnnotations()["ingress2gateway.kubernetes.io/source"])
+				}
+			}
+			if diff := cmp.Diff([]string{tc.expectedSource}, sources); diff != "" {
+				t.Errorf("unexpected BackendTrafficPolicy sources (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
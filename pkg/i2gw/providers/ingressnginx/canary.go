@@ -18,7 +18,9 @@ package ingressnginx
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
@@ -30,15 +32,29 @@ import (
 )
 
 const (
-	canaryAnnotation            = "nginx.ingress.kubernetes.io/canary"
-	canaryWeightAnnotation      = "nginx.ingress.kubernetes.io/canary-weight"
-	canaryWeightTotalAnnotation = "nginx.ingress.kubernetes.io/canary-weight-total"
+	canaryAnnotation                = "nginx.ingress.kubernetes.io/canary"
+	canaryWeightAnnotation          = "nginx.ingress.kubernetes.io/canary-weight"
+	canaryWeightTotalAnnotation     = "nginx.ingress.kubernetes.io/canary-weight-total"
+	canaryByHeaderAnnotation        = "nginx.ingress.kubernetes.io/canary-by-header"
+	canaryByHeaderValueAnnotation   = "nginx.ingress.kubernetes.io/canary-by-header-value"
+	canaryByHeaderPatternAnnotation = "nginx.ingress.kubernetes.io/canary-by-header-pattern"
+	canaryByCookieAnnotation        = "nginx.ingress.kubernetes.io/canary-by-cookie"
 )
 
 // canaryConfig holds the parsed canary configuration from a single Ingress
 type canaryConfig struct {
 	weight      int32
 	weightTotal int32
+
+	// header is the request header deciding the canary, from canary-by-header.
+	// With headerValue or headerPattern, a matching value routes to the canary,
+	// otherwise "always" routes to the canary and "never" to the main backend.
+	header        string
+	headerValue   string
+	headerPattern string
+	// cookie is the cookie deciding the canary with "always" and "never",
+	// from canary-by-cookie
+	cookie string
 }
 
 // parseCanaryConfig extracts canary weight configuration from an Ingress
@@ -74,6 +90,20 @@ func parseCanaryConfig(ingress *networkingv1.Ingress) (canaryConfig, error) {
 		return config, fmt.Errorf("canary-weight (%d) exceeds canary-weight-total (%d)", config.weight, config.weightTotal)
 	}
 
+	config.header = strings.TrimSpace(ingress.Annotations[canaryByHeaderAnnotation])
+	if config.header != "" {
+		config.headerValue = ingress.Annotations[canaryByHeaderValueAnnotation]
+		if config.headerValue == "" {
+			config.headerPattern = ingress.Annotations[canaryByHeaderPatternAnnotation]
+		}
+		if config.headerPattern != "" {
+			if _, err := regexp.Compile(config.headerPattern); err != nil {
+				return config, fmt.Errorf("invalid canary-by-header-pattern %q: %w", config.headerPattern, err)
+			}
+		}
+	}
+	config.cookie = strings.TrimSpace(ingress.Annotations[canaryByCookieAnnotation])
+
 	return config, nil
 }
 
@@ -88,6 +118,10 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 			continue
 		}
 
+		// Rules deciding the canary by header or cookie, inserted before the
+		// weighted rule they were built from
+		decisionRules := make(map[int][]canaryDecisionRule)
+
 		for ruleIdx, backendSources := range httpRouteContext.RuleBackendSources {
 			if ruleIdx >= len(httpRouteContext.HTTPRoute.Spec.Rules) {
 				errList = append(errList, field.InternalError(
@@ -101,6 +135,7 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 			// This is done in place.
 			var canaryBackend *gatewayv1.HTTPBackendRef
 			var nonCanaryBackend *gatewayv1.HTTPBackendRef
+			var canaryIdx, nonCanaryIdx int
 			var canaryConfig canaryConfig
 			var canarySourceIngress *networkingv1.Ingress
 
@@ -133,6 +168,7 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 					}
 
 					canaryBackend = backendRef
+					canaryIdx = backendIdx
 					canaryConfig = config
 					canarySourceIngress = source.Ingress
 				} else {
@@ -145,6 +181,7 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 						continue
 					}
 					nonCanaryBackend = backendRef
+					nonCanaryIdx = backendIdx
 				}
 			}
 
@@ -167,8 +204,19 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 
 				notify(notifications.InfoNotification, fmt.Sprintf("parsed canary annotations of ingress %s/%s and set weights (canary: %d, non-canary: %d, total: %d)",
 					canarySourceIngress.Namespace, canarySourceIngress.Name, canaryWeight, nonCanaryWeight, canaryConfig.weightTotal), &httpRouteContext.HTTPRoute)
+
+				if rules := buildCanaryDecisionRules(httpRouteContext.HTTPRoute.Spec.Rules[ruleIdx], backendSources, canaryIdx, nonCanaryIdx, canaryConfig); len(rules) > 0 {
+					decisionRules[ruleIdx] = rules
+					notify(notifications.InfoNotification, fmt.Sprintf("converted the canary-by-header and canary-by-cookie annotations of ingress %s/%s to %d HTTPRoute rules with header matches, "+
+						"which take precedence over the weighted rule", canarySourceIngress.Namespace, canarySourceIngress.Name, len(rules)), &httpRouteContext.HTTPRoute)
+				}
 			}
 		}
+
+		if len(decisionRules) > 0 {
+			insertCanaryDecisionRules(&httpRouteContext, decisionRules)
+			ir.HTTPRoutes[key] = httpRouteContext
+		}
 	}
 
 	if len(errList) > 0 {
@@ -176,3 +224,76 @@ func canaryFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]
 	}
 	return nil
 }
+
+// canaryDecisionRule is a rule routing the requests selected by a canary
+// header or cookie to a single backend, with the source of the backend
+type canaryDecisionRule struct {
+	rule    gatewayv1.HTTPRouteRule
+	sources []intermediate.BackendSource
+}
+
+// buildCanaryDecisionRules returns the rules deciding the canary by header and
+// cookie, in the order ingress-nginx evaluates them: the header first, then
+// the cookie. Each rule copies the matches of the weighted rule with an added
+// header match, so it is more specific than the weighted rule and takes
+// precedence over it. Between rules with the same path and number of header
+// matches, the first one wins, which keeps the header before the cookie.
+func buildCanaryDecisionRules(rule gatewayv1.HTTPRouteRule, sources []intermediate.BackendSource, canaryIdx, nonCanaryIdx int, config canaryConfig) []canaryDecisionRule {
+	var decisionRules []canaryDecisionRule
+	add := func(header gatewayv1.HTTPHeaderMatch, backendIdx int) {
+		decisionRule := *rule.DeepCopy()
+		decisionRule.Name = nil
+		if len(decisionRule.Matches) == 0 {
+			decisionRule.Matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for i := range decisionRule.Matches {
+			decisionRule.Matches[i].Headers = append(decisionRule.Matches[i].Headers, header)
+		}
+		backendRef := rule.BackendRefs[backendIdx]
+		backendRef.Weight = nil
+		decisionRule.BackendRefs = []gatewayv1.HTTPBackendRef{backendRef}
+		decisionRules = append(decisionRules, canaryDecisionRule{rule: decisionRule, sources: []intermediate.BackendSource{sources[backendIdx]}})
+	}
+	exact := gatewayv1.HeaderMatchExact
+	regex := gatewayv1.HeaderMatchRegularExpression
+
+	switch {
+	case config.header != "" && config.headerValue != "":
+		add(gatewayv1.HTTPHeaderMatch{Type: &exact, Name: gatewayv1.HTTPHeaderName(config.header), Value: config.headerValue}, canaryIdx)
+	case config.header != "" && config.headerPattern != "":
+		add(gatewayv1.HTTPHeaderMatch{Type: &regex, Name: gatewayv1.HTTPHeaderName(config.header), Value: config.headerPattern}, canaryIdx)
+	case config.header != "":
+		add(gatewayv1.HTTPHeaderMatch{Type: &exact, Name: gatewayv1.HTTPHeaderName(config.header), Value: "always"}, canaryIdx)
+		add(gatewayv1.HTTPHeaderMatch{Type: &exact, Name: gatewayv1.HTTPHeaderName(config.header), Value: "never"}, nonCanaryIdx)
+	}
+
+	// Gateway API has no cookie match, so the Cookie header is matched
+	if config.cookie != "" {
+		add(gatewayv1.HTTPHeaderMatch{Type: &regex, Name: "Cookie", Value: canaryCookieRegex(config.cookie, "always")}, canaryIdx)
+		add(gatewayv1.HTTPHeaderMatch{Type: &regex, Name: "Cookie", Value: canaryCookieRegex(config.cookie, "never")}, nonCanaryIdx)
+	}
+
+	return decisionRules
+}
+
+// canaryCookieRegex matches a Cookie header holding the cookie with the value
+func canaryCookieRegex(cookie, value string) string {
+	return fmt.Sprintf(`^(.*;\s*)?%s=%s(\s*;.*)?$`, regexp.QuoteMeta(cookie), value)
+}
+
+// insertCanaryDecisionRules inserts the decision rules before the rules they
+// were built from, keeping the backend sources aligned with the rules
+func insertCanaryDecisionRules(routeCtx *intermediate.HTTPRouteContext, decisionRules map[int][]canaryDecisionRule) {
+	var rules []gatewayv1.HTTPRouteRule
+	var sources [][]intermediate.BackendSource
+	for ruleIdx, rule := range routeCtx.HTTPRoute.Spec.Rules {
+		for _, decisionRule := range decisionRules[ruleIdx] {
+			rules = append(rules, decisionRule.rule)
+			sources = append(sources, decisionRule.sources)
+		}
+		rules = append(rules, rule)
+		sources = append(sources, routeCtx.RuleBackendSources[ruleIdx])
+	}
+	routeCtx.HTTPRoute.Spec.Rules = rules
+	routeCtx.RuleBackendSources = sources
+}
@@ -18,7 +18,9 @@ package ingressnginx
 
 import (
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
@@ -51,34 +53,49 @@ func clientCertAuthFeature(ingresses []networkingv1.Ingress, _ map[types.Namespa
 			continue
 		}
 
-		// Get or create the ingress-nginx IR for this route
-		key := types.NamespacedName{
-			Namespace: ing.Namespace,
-			Name:      ing.Name,
-		}
-
-		routeKey := findHTTPRouteKey(ir, key)
-		if routeKey == (types.NamespacedName{}) {
+		// Store the config on every route built from this ingress
+		updated := updateIngressRoutes(ir, &ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.ClientCertAuth = config
+		})
+		if updated == 0 {
 			continue
 		}
 
-		routeCtx := ir.HTTPRoutes[routeKey]
-		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
-			routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
-		}
-
-		routeCtx.ProviderSpecificIR.IngressNginx.ClientCertAuth = config
-		ir.HTTPRoutes[routeKey] = routeCtx
-
 		notify(notifications.InfoNotification,
 			fmt.Sprintf("Client cert auth config stored in IR (secret: %s, verify: %s). Requires SecurityPolicy to apply.", config.Secret, config.VerifyClient),
 			&ing,
 		)
+
+		// Without a host, the filter chains cannot be selected by SNI
+		if ingressHasHostlessRoute(&ing) {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s is set on an Ingress without a host: client certificates are validated on every TLS filter chain of the Gateway", authTLSSecretAnnotation),
+				&ing,
+			)
+		}
+
+		if config.ErrorPage != "" {
+			notifyClientCertErrorPage(&ing, config)
+		}
 	}
 
 	return errs
 }
 
+// ingressHasHostlessRoute returns true if the Ingress has a rule or a default
+// backend without a host, converted into a route without hostnames
+func ingressHasHostlessRoute(ing *networkingv1.Ingress) bool {
+	if ing.Spec.DefaultBackend != nil {
+		return true
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // parseClientCertAuthConfig extracts client certificate auth configuration from ingress annotations
 func parseClientCertAuthConfig(ing *networkingv1.Ingress) (*intermediate.ClientCertAuthConfig, field.ErrorList) {
 	annotations := ing.GetAnnotations()
@@ -131,8 +148,18 @@ func parseClientCertAuthConfig(ing *networkingv1.Ingress) (*intermediate.ClientC
 		config.VerifyDepth = 1 // default
 	}
 
-	// Parse error-page
-	config.ErrorPage = annotations[authTLSErrorPageAnnotation]
+	// Parse error-page, either a path on the same host or an absolute http(s) URL
+	if errorPage := strings.TrimSpace(annotations[authTLSErrorPageAnnotation]); errorPage != "" {
+		if !isValidErrorPage(errorPage) {
+			errs = append(errs, field.Invalid(
+				field.NewPath("metadata", "annotations", authTLSErrorPageAnnotation),
+				errorPage,
+				"must be an absolute path or an http(s) URL",
+			))
+		} else {
+			config.ErrorPage = errorPage
+		}
+	}
 
 	// Parse pass-certificate-to-upstream
 	if passCert := annotations[authTLSPassCertToUpstreamAnnotation]; passCert != "" {
@@ -141,3 +168,34 @@ func parseClientCertAuthConfig(ing *networkingv1.Ingress) (*intermediate.ClientC
 
 	return config, errs
 }
+
+// isValidErrorPage returns true if the error page is an absolute path or an http(s) URL
+func isValidErrorPage(errorPage string) bool {
+	u, err := url.Parse(errorPage)
+	if err != nil {
+		return false
+	}
+	if u.Host == "" {
+		return u.Scheme == "" && strings.HasPrefix(u.Path, "/")
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+// notifyClientCertErrorPage reports how the auth-tls-error-page of the config is converted
+func notifyClientCertErrorPage(ing *networkingv1.Ingress, config *intermediate.ClientCertAuthConfig) {
+	if !redirectsToClientCertErrorPage(config) {
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("auth-tls-error-page is ignored with auth-tls-verify-client %q, failed verifications are not redirected", config.VerifyClient),
+			ing,
+		)
+		return
+	}
+
+	if u, err := url.Parse(config.ErrorPage); err == nil && u.Host != "" {
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("auth-tls-error-page %s is an external URL: clients failing certificate verification are redirected off the Gateway. "+
+				"Make sure the target is reachable and trusted by your clients.", config.ErrorPage),
+			ing,
+		)
+	}
+}
@@ -17,30 +17,114 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"slices"
+	"sync"
+
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
+// FeatureFunc is the signature of the feature parsers run by the ingress-nginx
+// provider during ToIR. It receives the Ingresses, the service ports grouped by
+// port name, and the IR built so far, which it may modify in place.
+type FeatureFunc = i2gw.FeatureParser
+
+var customFeatures = featureRegistry{
+	features: make(map[string]FeatureFunc),
+}
+
+type featureRegistry struct {
+	names    []string
+	features map[string]FeatureFunc
+	mu       sync.RWMutex // thread-safe, so features can be registered from init functions.
+}
+
+func (r *featureRegistry) add(name string, fn FeatureFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.features[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	r.features[name] = fn
+}
+
+func (r *featureRegistry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.features, name)
+	r.names = slices.DeleteFunc(r.names, func(n string) bool { return n == name })
+}
+
+func (r *featureRegistry) all() []FeatureFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fns := make([]FeatureFunc, 0, len(r.names))
+	for _, name := range r.names {
+		fns = append(fns, r.features[name])
+	}
+	return fns
+}
+
+// RegisterFeature registers a custom feature parser that runs during ToIR,
+// after all built-in ingress-nginx features. This allows handling proprietary
+// annotations that live alongside the nginx ones.
+// Custom features run in registration order. Registering a feature with a name
+// that is already registered replaces the previous function.
+// RegisterFeature is thread-safe.
+func RegisterFeature(name string, fn FeatureFunc) {
+	customFeatures.add(name, fn)
+}
+
 // resourcesToIRConverter implements the ToIR function of i2gw.ResourcesToIRConverter interface.
 type resourcesToIRConverter struct {
 	featureParsers []i2gw.FeatureParser
+
+	// experimentalChannel allows features to generate experimental Gateway API fields
+	experimentalChannel bool
+
+	// strictTimeoutMapping keeps connect timeouts out of the HTTPRoute timeouts
+	strictTimeoutMapping bool
+
+	// tracingSamplerRatio is the ratio of requests traced, all of them if empty
+	tracingSamplerRatio string
+
+	// gatewayClassController is the controllerName of the configured
+	// GatewayClass, empty if unknown
+	gatewayClassController string
+
+	// enabledFeatures are the features whose annotations are converted, all of
+	// them if nil
+	enabledFeatures sets.Set[string]
 }
 
 // newResourcesToIRConverter returns an ingress-nginx resourcesToIRConverter instance.
 func newResourcesToIRConverter() *resourcesToIRConverter {
 	return &resourcesToIRConverter{
 		featureParsers: []i2gw.FeatureParser{
+			defaultBackendFeature,
+			serverAliasFeature,
 			canaryFeature,
+			affinityFeature,
 			backendProtocolFeature,
-			timeoutFeature,
 			sslRedirectFeature,
 			proxySettingsFeature,
+			proxyBuffersFeature,
+			proxyHTTPVersionFeature,
+			serverSnippetFeature,
+			headersFeature,
+			mirrorFeature,
+			accessLogFeature,
+			customHTTPErrorsFeature,
+			corsFeature,
+			proxyRedirectFeature,
 			rateLimitFeature,
+			sourceRangeFeature,
 			clientCertAuthFeature,
 			externalAuthFeature,
-			envoyFilterFeature,
+			gatewayModeFeature,
 			appLevelWarningsFeature,
 		},
 	}
@@ -51,19 +135,54 @@ func (c *resourcesToIRConverter) convert(storage *storage) (intermediate.IR, fie
 	// TODO(liorliberman) temporary until we decide to change ToIR and featureParsers to get a map of [types.NamespacedName]*networkingv1.Ingress instead of a list
 	ingressList := storage.Ingresses.List()
 
+	// Drop the annotations of disabled features, and the canary Ingresses
+	// when canary is disabled, default missing path types
+	// to Prefix, report resource backends, strip the ports of hosts, drop
+	// rules with hosts that Gateway API would reject, so the remaining routes
+	// can still be converted, and route host-only rules to the defaultBackend
+	// instead of generating HTTPRoutes without rules.
+	ingressList = removeDisabledFeatureAnnotations(ingressList, c.enabledFeatures)
+	ingressList = defaultPathTypes(ingressList)
+	reportResourceBackends(ingressList)
+	ingressList, ports := stripHostPorts(ingressList)
+	ingressList = validateIngressHosts(ingressList)
+	ingressList = expandHostOnlyRules(ingressList)
+
 	// Convert plain ingress resources to gateway resources, ignoring all
 	// provider-specific features.
 	ir, errs := common.ToIR(ingressList, storage.ServicePorts, i2gw.ProviderImplementationSpecificOptions{})
 	if len(errs) > 0 {
 		return intermediate.IR{}, errs
 	}
+	addTLSOnlyListeners(ingressList, &ir)
+	applyListenerPortHints(&ir, ingressList, ports)
 
-	for _, parseFeatureFunc := range c.featureParsers {
+	featureParsers := append(slices.Clone(c.featureParsers),
+		tracingFeature(c.tracingSamplerRatio),
+		timeoutFeature(c.strictTimeoutMapping),
+		// Runs after timeoutFeature to fit the per-try timeout in the rule timeouts
+		retryFeature(c.experimentalChannel),
+		proxySetHeadersFeature(storage.ConfigMaps),
+		customHeadersFeature(storage.ConfigMaps),
+		upstreamVhostFeature(c.gatewayClassController),
+		secretRefsFeature(storage.Secrets),
+		regexPathFeature(c.experimentalChannel),
+		sessionPersistenceFeature(c.experimentalChannel),
+		// Runs after regexPathFeature to rewrite the full path of regex rules
+		rewriteTargetFeature,
+		// Runs last among the built-in features to report the values they chose
+		annotationConflictsFeature,
+	)
+	featureParsers = append(featureParsers, customFeatures.all()...)
+	for _, parseFeatureFunc := range featureParsers {
 		// Apply the feature parsing function to the gateway resources, one by one.
 		parseErrs := parseFeatureFunc(ingressList, storage.ServicePorts, &ir)
 		// Append the parsing errors to the error list.
 		errs = append(errs, parseErrs...)
 	}
 
+	// Order the rules by path specificity for predictable output
+	sortHTTPRouteRules(&ir)
+
 	return ir, errs
 }
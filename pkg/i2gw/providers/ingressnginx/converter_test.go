@@ -511,14 +511,14 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/"),
+												Value: ptrTo("/orders"),
 											},
 										}},
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "foo-app",
+														Name: "foo-orders-app",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 												},
@@ -529,14 +529,14 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/orders"),
+												Value: ptrTo("/"),
 											},
 										}},
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "foo-orders-app",
+														Name: "foo-app",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 												},
@@ -733,14 +733,14 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/"),
+												Value: ptrTo("/orders"),
 											},
 										}},
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "foo-app",
+														Name: "foo-orders-app",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 												},
@@ -751,14 +751,14 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/orders"),
+												Value: ptrTo("/"),
 											},
 										}},
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "foo-orders-app",
+														Name: "foo-app",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 												},
@@ -890,15 +890,16 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/api"),
+												Value: ptrTo("/admin"),
 											},
 										}},
-										// Path "/api" has api-service-v1 from production and api-service-v2 from canary
+										// Path "/admin" has admin-service from production and api-service-v1 from canary
+										// Note: api-service-v1 appears in both rules but with different weights based on source!
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "api-service-v1",
+														Name: "admin-service",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 													Weight: ptrTo(int32(90)), // Production gets 90%
@@ -907,7 +908,7 @@ func Test_ToIR(t *testing.T) {
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "api-service-v2",
+														Name: "api-service-v1",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 													Weight: ptrTo(int32(10)), // Canary gets 10%
@@ -919,16 +920,15 @@ func Test_ToIR(t *testing.T) {
 										Matches: []gatewayv1.HTTPRouteMatch{{
 											Path: &gatewayv1.HTTPPathMatch{
 												Type:  &gPathPrefix,
-												Value: ptrTo("/admin"),
+												Value: ptrTo("/api"),
 											},
 										}},
-										// Path "/admin" has admin-service from production and api-service-v1 from canary
-										// Note: api-service-v1 appears in both rules but with different weights based on source!
+										// Path "/api" has api-service-v1 from production and api-service-v2 from canary
 										BackendRefs: []gatewayv1.HTTPBackendRef{
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "admin-service",
+														Name: "api-service-v1",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 													Weight: ptrTo(int32(90)), // Production gets 90%
@@ -937,7 +937,7 @@ func Test_ToIR(t *testing.T) {
 											{
 												BackendRef: gatewayv1.BackendRef{
 													BackendObjectReference: gatewayv1.BackendObjectReference{
-														Name: "api-service-v1",
+														Name: "api-service-v2",
 														Port: ptrTo(gatewayv1.PortNumber(80)),
 													},
 													Weight: ptrTo(int32(10)), // Canary gets 10%
@@ -1006,6 +1006,328 @@ func Test_ToIR(t *testing.T) {
 	}
 }
 
+func TestToIR_MergeableIngresses(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+
+	newIngress := func(name, path string, annotations map[string]string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: ptrTo("nginx"),
+				Rules: []networkingv1.IngressRule{{
+					Host: "shop.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     path,
+								PathType: &iPrefix,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: name + "-service",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	ingresses := OrderedIngressMap{
+		ingressNames: []types.NamespacedName{
+			{Namespace: "default", Name: "cart"},
+			{Namespace: "default", Name: "checkout"},
+			{Namespace: "default", Name: "catalog"},
+		},
+		ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+			{Namespace: "default", Name: "cart"}:     newIngress("cart", "/cart", nil),
+			{Namespace: "default", Name: "checkout"}: newIngress("checkout", "/checkout", map[string]string{limitRPSAnnotation: "10"}),
+			{Namespace: "default", Name: "catalog"}:  newIngress("catalog", "/catalog", nil),
+		},
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{})
+	provider.(*Provider).storage.Ingresses = ingresses
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+
+	if len(ir.HTTPRoutes) != 1 {
+		t.Fatalf("Expected 1 HTTPRoute, got %d: %+v", len(ir.HTTPRoutes), ir.HTTPRoutes)
+	}
+
+	for _, routeCtx := range ir.HTTPRoutes {
+		var gotPaths []string
+		for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			gotPaths = append(gotPaths, *rule.Matches[0].Path.Value)
+		}
+		// Rules are sorted from the longest to the shortest prefix
+		wantPaths := []string{"/checkout", "/catalog", "/cart"}
+		if diff := cmp.Diff(wantPaths, gotPaths); diff != "" {
+			t.Errorf("Unexpected rule paths (-want +got):\n%s", diff)
+		}
+
+		// Annotations of any contributing ingress apply to the merged route
+		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+		if nginxIR == nil || nginxIR.RateLimitRPS != 10 {
+			t.Errorf("Expected rate limit of the checkout ingress on the merged route, got %+v", nginxIR)
+		}
+	}
+}
+
+func TestToIR_MissingServiceBackend(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	key := types.NamespacedName{Namespace: "default", Name: "no-backend"}
+
+	provider := NewProvider(&i2gw.ProviderConf{})
+	provider.(*Provider).storage.Ingresses = OrderedIngressMap{
+		ingressNames: []types.NamespacedName{key},
+		ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+			key: {
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptrTo("nginx"),
+					Rules: []networkingv1.IngressRule{{
+						Host: "example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path:     "/",
+									PathType: &iPrefix,
+								}},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	_, errs := provider.ToIR()
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeRequired {
+		t.Errorf("Expected a Required error for the missing backend, got %v", errs)
+	}
+}
+
+func TestToIR_NamedServicePort(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	key := types.NamespacedName{Namespace: "default", Name: "named-port"}
+	serviceKey := types.NamespacedName{Namespace: "default", Name: "web"}
+	namedPort := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: serviceKey.Name, Port: networkingv1.ServiceBackendPort{Name: "http"}},
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		key: {
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: ptrTo("nginx"),
+				DefaultBackend:   &namedPort,
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &iPrefix,
+								Backend:  namedPort,
+							}},
+						},
+					},
+				}},
+			},
+		},
+	})
+	provider.storage.ServicePorts = common.GroupServicePortsByPortName(map[types.NamespacedName]*apiv1.Service{
+		serviceKey: {
+			ObjectMeta: metav1.ObjectMeta{Name: serviceKey.Name, Namespace: serviceKey.Namespace},
+			Spec: apiv1.ServiceSpec{Ports: []apiv1.ServicePort{
+				{Name: "metrics", Port: 9090},
+				{Name: "http", Port: 8080},
+			}},
+		},
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKeys := []types.NamespacedName{
+		{Namespace: key.Namespace, Name: common.RouteName(key.Name, "example.com")},
+		{Namespace: key.Namespace, Name: key.Name + "-default-backend"},
+	}
+	for _, routeKey := range routeKeys {
+		routeCtx, ok := ir.HTTPRoutes[routeKey]
+		if !ok {
+			t.Errorf("expected HTTPRoute %s", routeKey)
+			continue
+		}
+		for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if backendRef.Port == nil || *backendRef.Port != 8080 {
+					t.Errorf("expected the backendRef of HTTPRoute %s to use the resolved port 8080, got %v", routeKey, backendRef.Port)
+				}
+			}
+		}
+	}
+}
+
+func TestToGatewayResources_MultipleTLSBlocks(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	rule := func(host string) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+						},
+					}},
+				},
+			},
+		}
+	}
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "multi-tls", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptrTo("nginx"),
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "ab-tls"},
+				{Hosts: []string{"c.example.org"}, SecretName: "c-tls"},
+			},
+			Rules: []networkingv1.IngressRule{rule("a.example.com"), rule("b.example.com"), rule("c.example.org")},
+		},
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+		},
+	}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	gateway, ok := gatewayResources.Gateways[types.NamespacedName{Namespace: "default", Name: "default-gateway"}]
+	if !ok {
+		t.Fatalf("expected Gateway default/default-gateway, got %v", gatewayResources.Gateways)
+	}
+	// Each host of a TLS block gets an HTTPS listener with the secret of its block
+	expectedSecrets := map[string]string{
+		"a.example.com": "ab-tls",
+		"b.example.com": "ab-tls",
+		"c.example.org": "c-tls",
+	}
+	secrets := map[string]string{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Protocol != gatewayv1.HTTPSProtocolType || listener.Hostname == nil {
+			continue
+		}
+		if listener.TLS == nil || len(listener.TLS.CertificateRefs) != 1 {
+			t.Errorf("expected listener %s to reference a single certificate, got %+v", listener.Name, listener.TLS)
+			continue
+		}
+		secrets[string(*listener.Hostname)] = string(listener.TLS.CertificateRefs[0].Name)
+	}
+	if diff := cmp.Diff(expectedSecrets, secrets); diff != "" {
+		t.Errorf("unexpected HTTPS listener certificates (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegisterFeature(t *testing.T) {
+	const featureName = "company-team-label"
+	const teamAnnotation = "company.io/team"
+
+	RegisterFeature(featureName, func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		for _, ing := range ingresses {
+			team := ing.Annotations[teamAnnotation]
+			if team == "" {
+				continue
+			}
+			for key, routeCtx := range ir.HTTPRoutes {
+				if key.Namespace != ing.Namespace {
+					continue
+				}
+				if routeCtx.HTTPRoute.Labels == nil {
+					routeCtx.HTTPRoute.Labels = map[string]string{}
+				}
+				routeCtx.HTTPRoute.Labels["company.io/team"] = team
+				ir.HTTPRoutes[key] = routeCtx
+			}
+		}
+		return nil
+	})
+	t.Cleanup(func() { customFeatures.remove(featureName) })
+
+	iPrefix := networkingv1.PathTypePrefix
+	ingressKey := types.NamespacedName{Namespace: "default", Name: "example"}
+	provider := NewProvider(&i2gw.ProviderConf{})
+	nginxProvider := provider.(*Provider)
+	nginxProvider.storage.Ingresses = OrderedIngressMap{
+		ingressNames: []types.NamespacedName{ingressKey},
+		ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+			ingressKey: {
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        ingressKey.Name,
+					Namespace:   ingressKey.Namespace,
+					Annotations: map[string]string{teamAnnotation: "payments"},
+				},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptrTo("nginx"),
+					Rules: []networkingv1.IngressRule{{
+						Host: "example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path:     "/",
+									PathType: &iPrefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "example",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								}},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeCtx, ok := ir.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "example-example-com"}]
+	if !ok {
+		t.Fatalf("expected HTTPRoute default/example-example-com, got %+v", ir.HTTPRoutes)
+	}
+	if got := routeCtx.HTTPRoute.Labels["company.io/team"]; got != "payments" {
+		t.Errorf("expected custom feature to set label company.io/team=payments, got %q", got)
+	}
+}
+
 func ptrTo[T any](a T) *T {
 	return &a
 }
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	enableCORSAnnotation           = "nginx.ingress.kubernetes.io/enable-cors"
+	corsAllowOriginAnnotation      = "nginx.ingress.kubernetes.io/cors-allow-origin"
+	corsAllowMethodsAnnotation     = "nginx.ingress.kubernetes.io/cors-allow-methods"
+	corsAllowHeadersAnnotation     = "nginx.ingress.kubernetes.io/cors-allow-headers"
+	corsExposeHeadersAnnotation    = "nginx.ingress.kubernetes.io/cors-expose-headers"
+	corsAllowCredentialsAnnotation = "nginx.ingress.kubernetes.io/cors-allow-credentials"
+	corsMaxAgeAnnotation           = "nginx.ingress.kubernetes.io/cors-max-age"
+
+	// Defaults of the cors-* annotations in ingress-nginx
+	defaultCORSAllowMethods = "GET, PUT, POST, DELETE, PATCH, OPTIONS"
+	defaultCORSAllowHeaders = "DNT,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Range,Authorization"
+	defaultCORSMaxAge       = 1728000
+)
+
+// corsWildcardOriginRegex matches an origin with a subdomain wildcard, e.g.
+// https://*.example.com
+var corsWildcardOriginRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://)\*\.([^*]+)$`)
+
+// corsRegexMetaCharacters are the characters that make an origin a regular
+// expression rather than an exact origin. Dots are valid in exact origins.
+const corsRegexMetaCharacters = `^$()[]{}|+?*\`
+
+// corsMethods are the HTTP methods cors-allow-methods can list
+var corsMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "CONNECT", "OPTIONS", "TRACE"}
+
+// corsFeature converts enable-cors and the cors-* annotations to a CORS policy
+// stored in the IR of the routes generated from the Ingress. Origins are
+// matched exactly, except "*", subdomain wildcards and regular expressions,
+// which ingress-nginx also accepts and which are matched with a safe_regex.
+func corsFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		enabled := strings.TrimSpace(ing.Annotations[enableCORSAnnotation])
+		if enabled == "" {
+			continue
+		}
+		annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+		if val, err := strconv.ParseBool(enabled); err != nil {
+			errs = append(errs, field.Invalid(annotationsPath.Key(enableCORSAnnotation), enabled, "must be a boolean"))
+			continue
+		} else if !val {
+			continue
+		}
+
+		cors, corsErrs := parseCORSConfig(ing.Annotations, annotationsPath)
+		if len(corsErrs) > 0 {
+			errs = append(errs, corsErrs...)
+			continue
+		}
+
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.CORS = cors
+		})
+		if updated == 0 {
+			continue
+		}
+
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s converted to an EnvoyFilter setting the CORS policy of the virtual hosts of the route hostnames", enableCORSAnnotation),
+			ing,
+		)
+	}
+
+	return errs
+}
+
+// parseCORSConfig parses the cors-* annotations, using the ingress-nginx
+// defaults for the ones that are not set
+func parseCORSConfig(annotations map[string]string, path *field.Path) (*intermediate.CORSConfig, field.ErrorList) {
+	var errs field.ErrorList
+	cors := &intermediate.CORSConfig{
+		AllowMethods:     defaultCORSAllowMethods,
+		AllowHeaders:     defaultCORSAllowHeaders,
+		ExposeHeaders:    strings.TrimSpace(annotations[corsExposeHeadersAnnotation]),
+		AllowCredentials: true,
+		MaxAge:           defaultCORSMaxAge,
+	}
+
+	allowOrigin := strings.TrimSpace(annotations[corsAllowOriginAnnotation])
+	if allowOrigin == "" {
+		allowOrigin = "*"
+	}
+	for _, origin := range strings.Split(allowOrigin, ",") {
+		if origin = strings.TrimSpace(origin); origin == "" {
+			continue
+		}
+		corsOrigin, err := parseCORSOrigin(origin)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Key(corsAllowOriginAnnotation), origin, err.Error()))
+			continue
+		}
+		cors.AllowOrigins = append(cors.AllowOrigins, corsOrigin)
+	}
+
+	if methods := strings.TrimSpace(annotations[corsAllowMethodsAnnotation]); methods != "" {
+		allowMethods, err := parseCORSMethods(methods)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Key(corsAllowMethodsAnnotation), methods, err.Error()))
+		}
+		cors.AllowMethods = allowMethods
+	}
+	if headers := strings.TrimSpace(annotations[corsAllowHeadersAnnotation]); headers != "" {
+		cors.AllowHeaders = headers
+	}
+	if credentials := strings.TrimSpace(annotations[corsAllowCredentialsAnnotation]); credentials != "" {
+		val, err := strconv.ParseBool(credentials)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Key(corsAllowCredentialsAnnotation), credentials, "must be a boolean"))
+		}
+		cors.AllowCredentials = val
+	}
+	if maxAge := strings.TrimSpace(annotations[corsMaxAgeAnnotation]); maxAge != "" {
+		val, err := strconv.Atoi(maxAge)
+		if err != nil || val < 0 {
+			errs = append(errs, field.Invalid(path.Key(corsMaxAgeAnnotation), maxAge, "must be a non-negative number of seconds"))
+		}
+		cors.MaxAge = val
+	}
+
+	return cors, errs
+}
+
+// parseCORSOrigin converts a cors-allow-origin entry to an exact origin, or to
+// a regular expression for "*", subdomain wildcards and entries with regular
+// expression metacharacters, which must be valid RE2 expressions.
+func parseCORSOrigin(origin string) (intermediate.CORSOrigin, error) {
+	if origin == "*" {
+		return intermediate.CORSOrigin{Value: ".*", Regex: true}, nil
+	}
+	if match := corsWildcardOriginRegex.FindStringSubmatch(origin); match != nil && !strings.ContainsAny(match[2], corsRegexMetaCharacters) {
+		return intermediate.CORSOrigin{
+			Value: regexp.QuoteMeta(match[1]) + `[A-Za-z0-9\-]+\.` + regexp.QuoteMeta(match[2]),
+			Regex: true,
+		}, nil
+	}
+	if !strings.ContainsAny(origin, corsRegexMetaCharacters) {
+		return intermediate.CORSOrigin{Value: origin}, nil
+	}
+	if _, err := regexp.Compile(origin); err != nil {
+		return intermediate.CORSOrigin{}, fmt.Errorf("not a valid RE2 regular expression: %w", err)
+	}
+	return intermediate.CORSOrigin{Value: origin, Regex: true}, nil
+}
+
+// parseCORSMethods uppercases the comma separated methods of
+// cors-allow-methods, which must be corsMethods
+func parseCORSMethods(methods string) (string, error) {
+	var allowMethods []string
+	for _, method := range strings.Split(methods, ",") {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method == "" {
+			continue
+		}
+		if !slices.Contains(corsMethods, method) {
+			return "", fmt.Errorf("%q is not a valid HTTP method, must be one of %s", method, strings.Join(corsMethods, ", "))
+		}
+		allowMethods = append(allowMethods, method)
+	}
+	if len(allowMethods) == 0 {
+		return "", fmt.Errorf("must list at least one HTTP method")
+	}
+	return strings.Join(allowMethods, ", "), nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestCORSFeature(t *testing.T) {
+	ingress := headersTestIngress(map[string]string{
+		enableCORSAnnotation:        "true",
+		corsAllowOriginAnnotation:   `https://app.example.com, ^https://[a-z]+\.example\.org$`,
+		corsExposeHeadersAnnotation: "X-Request-Id",
+		corsMaxAgeAnnotation:        "600",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := corsFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-cors"}]
+	if filter == nil {
+		t.Fatalf("expected a cors EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) != 1 {
+		t.Fatalf("expected 1 config patch, got %d", len(patches))
+	}
+	domainName, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}), "match", "routeConfiguration", "vhost", "domainName")
+	if domainName != "example.com" {
+		t.Errorf("expected the patch to match the example.com virtual host, got %q", domainName)
+	}
+	corsPolicy, _, _ := unstructured.NestedMap(patches[0].(map[string]interface{}), "patch", "value", "typed_per_filter_config", "envoy.filters.http.cors")
+	expectedPolicy := map[string]interface{}{
+		"@type": "type.googleapis.com/envoy.extensions.filters.http.cors.v3.CorsPolicy",
+		"allow_origin_string_match": []interface{}{
+			map[string]interface{}{"exact": "https://app.example.com"},
+			map[string]interface{}{"safe_regex": map[string]interface{}{"regex": `^https://[a-z]+\.example\.org$`}},
+		},
+		"allow_methods":     defaultCORSAllowMethods,
+		"allow_headers":     defaultCORSAllowHeaders,
+		"expose_headers":    "X-Request-Id",
+		"allow_credentials": true,
+		"max_age":           "600",
+	}
+	if diff := cmp.Diff(expectedPolicy, corsPolicy); diff != "" {
+		t.Errorf("unexpected CORS policy (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseCORSOrigin(t *testing.T) {
+	testCases := []struct {
+		origin        string
+		expected      intermediate.CORSOrigin
+		expectedError bool
+	}{
+		{
+			origin:   "https://example.com:8443",
+			expected: intermediate.CORSOrigin{Value: "https://example.com:8443"},
+		},
+		{
+			origin:   "*",
+			expected: intermediate.CORSOrigin{Value: ".*", Regex: true},
+		},
+		{
+			origin:   "https://*.example.com",
+			expected: intermediate.CORSOrigin{Value: `https://[A-Za-z0-9\-]+\.example\.com`, Regex: true},
+		},
+		{
+			origin:   `https://(www|api)\.example\.com`,
+			expected: intermediate.CORSOrigin{Value: `https://(www|api)\.example\.com`, Regex: true},
+		},
+		{
+			origin:        `https://(?=www)example\.com`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		origin, err := parseCORSOrigin(tc.origin)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("parseCORSOrigin(%q): expected error %t, got %v", tc.origin, tc.expectedError, err)
+			continue
+		}
+		if origin != tc.expected {
+			t.Errorf("parseCORSOrigin(%q) = %+v, expected %+v", tc.origin, origin, tc.expected)
+		}
+	}
+}
+
+func TestParseCORSConfig_Invalid(t *testing.T) {
+	_, errs := parseCORSConfig(map[string]string{
+		corsAllowCredentialsAnnotation: "yes",
+		corsMaxAgeAnnotation:           "-1",
+		corsAllowMethodsAnnotation:     "GET, FETCH",
+	}, field.NewPath("metadata", "annotations"))
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+}
+
+func TestParseCORSMethods(t *testing.T) {
+	testCases := []struct {
+		methods       string
+		expected      string
+		expectedError bool
+	}{
+		{
+			methods:  "get,post , Options",
+			expected: "GET, POST, OPTIONS",
+		},
+		{
+			methods:       "GET, FETCH",
+			expectedError: true,
+		},
+		{
+			methods:       "GET; POST",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		methods, err := parseCORSMethods(tc.methods)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("parseCORSMethods(%q): expected error %t, got %v", tc.methods, tc.expectedError, err)
+			continue
+		}
+		if methods != tc.expected {
+			t.Errorf("parseCORSMethods(%q) = %q, expected %q", tc.methods, methods, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	customHTTPErrorsAnnotation = "nginx.ingress.kubernetes.io/custom-http-errors"
+	defaultBackendAnnotation   = "nginx.ingress.kubernetes.io/default-backend"
+
+	// defaultErrorBackendPort is the port used for an error backend service
+	// whose ports are unknown
+	defaultErrorBackendPort int32 = 80
+)
+
+// customHTTPErrorsFeature stores the custom-http-errors statuses and the
+// default-backend service answering them in the IR of the routes generated
+// from the Ingress. Like in ingress-nginx, responses with these statuses are
+// replaced by the response of the error backend. The default backend of the
+// controller has no equivalent, so custom-http-errors without a
+// default-backend annotation is skipped with a Warning.
+func customHTTPErrorsFeature(ingresses []networkingv1.Ingress, servicePorts map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		value := strings.TrimSpace(ing.Annotations[customHTTPErrorsAnnotation])
+		service := strings.TrimSpace(ing.Annotations[defaultBackendAnnotation])
+		if value == "" {
+			if service != "" {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s is only converted for the statuses of %s, other requests are not sent to service %s when the Ingress backends have no endpoints",
+						defaultBackendAnnotation, customHTTPErrorsAnnotation, service),
+					ing,
+				)
+			}
+			continue
+		}
+
+		codes, err := parseCustomHTTPErrors(value,
+			field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations", customHTTPErrorsAnnotation))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if service == "" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s without %s relies on the default backend of the controller, which has no equivalent; the statuses are not replaced",
+					customHTTPErrorsAnnotation, defaultBackendAnnotation),
+				ing,
+			)
+			continue
+		}
+
+		port, known := errorBackendPort(servicePorts[types.NamespacedName{Namespace: ing.Namespace, Name: service}])
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.CustomHTTPErrors = &intermediate.CustomHTTPErrorsConfig{
+				Codes:       codes,
+				Service:     service,
+				Namespace:   ing.Namespace,
+				Port:        port,
+				IngressName: ing.Name,
+			}
+		})
+		if updated == 0 {
+			continue
+		}
+
+		if !known {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("ports of %s service %s are unknown, assuming port %d",
+					defaultBackendAnnotation, service, defaultErrorBackendPort),
+				ing,
+			)
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s converted to a Lua EnvoyFilter replacing %s responses with the response of service %s:%d",
+				customHTTPErrorsAnnotation, formatStatusCodes(codes), service, port),
+			ing,
+		)
+	}
+
+	return errs
+}
+
+// parseCustomHTTPErrors parses the comma separated error statuses, which must
+// be 4xx or 5xx. Duplicates are dropped and the statuses are sorted.
+func parseCustomHTTPErrors(value string, path *field.Path) ([]int, *field.Error) {
+	var codes []int
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		code, err := strconv.Atoi(s)
+		if err != nil || code < 400 || code > 599 {
+			return nil, field.Invalid(path, value, fmt.Sprintf("%q is not an HTTP error status between 400 and 599", s))
+		}
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, field.Invalid(path, value, "must list at least one HTTP error status")
+	}
+	slices.Sort(codes)
+	return codes, nil
+}
+
+// errorBackendPort picks the port of the error backend service: its only
+// port, else the port named "http", else the lowest port. It returns
+// defaultErrorBackendPort and false if the service ports are unknown.
+func errorBackendPort(ports map[string]int32) (int32, bool) {
+	if len(ports) == 0 {
+		return defaultErrorBackendPort, false
+	}
+	if port, ok := ports["http"]; ok && len(ports) > 1 {
+		return port, true
+	}
+	return slices.Min(slices.Collect(maps.Values(ports))), true
+}
+
+// formatStatusCodes joins the statuses with commas
+func formatStatusCodes(codes []int) string {
+	s := make([]string, 0, len(codes))
+	for _, code := range codes {
+		s = append(s, strconv.Itoa(code))
+	}
+	return strings.Join(s, ",")
+}
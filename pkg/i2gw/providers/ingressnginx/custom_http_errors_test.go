@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCustomHTTPErrorsFeature(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{
+		customHTTPErrorsAnnotation: "503, 404",
+		defaultBackendAnnotation:   "error-pages",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+	servicePorts := map[types.NamespacedName]map[string]int32{
+		{Namespace: "default", Name: "error-pages"}: {"metrics": 9090, "http": 8080},
+	}
+
+	ir, errs := common.ToIR(ingresses, servicePorts, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := customHTTPErrorsFeature(ingresses, servicePorts, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	expectedConfig := &intermediate.CustomHTTPErrorsConfig{
+		Codes:       []int{404, 503},
+		Service:     "error-pages",
+		Namespace:   "default",
+		Port:        8080,
+		IngressName: "test-ingress",
+	}
+	nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+	if nginxIR == nil {
+		t.Fatalf("expected the custom errors in the route IR")
+	}
+	if diff := cmp.Diff(expectedConfig, nginxIR.CustomHTTPErrors); diff != "" {
+		t.Fatalf("unexpected custom errors config (-want +got):\n%s", diff)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-customerrors"}]
+	if filter == nil {
+		t.Fatalf("expected a customerrors EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	luaCode, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "inline_code")
+	// 404 and 503 are sent to the error backend, only for the route host
+	for _, expected := range []string{
+		`[404] = "outbound|8080||error-pages.default.svc.cluster.local",`,
+		`[503] = "outbound|8080||error-pages.default.svc.cluster.local",`,
+		"local match_all_hosts = false",
+		`["example.com"] = true,`,
+		`["x-ingress-name"] = "test-ingress",`,
+	} {
+		if !strings.Contains(luaCode, expected) {
+			t.Errorf("expected the Lua code to contain %q, got:\n%s", expected, luaCode)
+		}
+	}
+	if strings.Contains(luaCode, "[500]") {
+		t.Errorf("expected only 404 and 503 to be mapped, got:\n%s", luaCode)
+	}
+}
+
+func TestCustomHTTPErrorsFeature_Skipped(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		expectedErrors   int
+		expectedWarnings int
+	}{
+		{
+			name:             "no default-backend",
+			annotations:      map[string]string{customHTTPErrorsAnnotation: "404"},
+			expectedWarnings: 1,
+		},
+		{
+			name:             "default-backend only",
+			annotations:      map[string]string{defaultBackendAnnotation: "error-pages"},
+			expectedWarnings: 1,
+		},
+		{
+			name:           "not an error status",
+			annotations:    map[string]string{customHTTPErrorsAnnotation: "404,302", defaultBackendAnnotation: "error-pages"},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = customHTTPErrorsFeature(ingresses, nil, &ir)
+			if len(errs) != tc.expectedErrors {
+				t.Errorf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+
+			for _, routeCtx := range ir.HTTPRoutes {
+				if nginxIR := routeCtx.ProviderSpecificIR.IngressNginx; nginxIR != nil && nginxIR.CustomHTTPErrors != nil {
+					t.Errorf("expected no custom errors config, got %+v", nginxIR.CustomHTTPErrors)
+				}
+			}
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
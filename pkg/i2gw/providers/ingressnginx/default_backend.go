@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// defaultBackendFeature adds a catch-all "/" rule to each HTTPRoute built from
+// the rules of an Ingress with a defaultBackend, so requests to the Ingress hosts
+// that match none of its paths go to the default backend like in ingress-nginx.
+// The rule is appended after the explicit path rules, and is skipped if the
+// route already has a "/" prefix rule.
+func defaultBackendFeature(ingresses []networkingv1.Ingress, servicePorts map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		if ing.Spec.DefaultBackend == nil || len(ing.Spec.Rules) == 0 {
+			continue
+		}
+
+		for routeKey, routeCtx := range ir.HTTPRoutes {
+			if routeKey.Namespace != ing.Namespace || !routeHasIngressPaths(routeCtx, ing) || hasCatchAllRule(routeCtx.HTTPRoute) {
+				continue
+			}
+
+			backendRef, err := common.ToBackendRef(ing.Namespace, *ing.Spec.DefaultBackend, servicePorts,
+				field.NewPath("ingress", ing.Namespace, ing.Name, "spec", "defaultBackend"))
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+
+			pathPrefix := gatewayv1.PathMatchPathPrefix
+			rootPath := "/"
+			routeCtx.HTTPRoute.Spec.Rules = append(routeCtx.HTTPRoute.Spec.Rules, gatewayv1.HTTPRouteRule{
+				Matches: []gatewayv1.HTTPRouteMatch{{
+					Path: &gatewayv1.HTTPPathMatch{Type: &pathPrefix, Value: &rootPath},
+				}},
+				BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: *backendRef}},
+			})
+			routeCtx.RuleBackendSources = append(routeCtx.RuleBackendSources, []intermediate.BackendSource{{
+				Ingress:        ing,
+				DefaultBackend: ing.Spec.DefaultBackend,
+			}})
+			ir.HTTPRoutes[routeKey] = routeCtx
+
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("defaultBackend of Ingress %s/%s added as a catch-all \"/\" rule to HTTPRoute %s/%s",
+					ing.Namespace, ing.Name, routeKey.Namespace, routeKey.Name),
+				&routeCtx.HTTPRoute)
+		}
+	}
+
+	return errs
+}
+
+// dropConflictingDefaultBackendRoutes keeps a single <ingress>-default-backend
+// HTTPRoute per Gateway. These routes have no hostnames nor matches, so they
+// catch the requests for every host of the Gateway that no other route
+// matches, and several of them on the same Gateway conflict, e.g. the
+// defaultBackends of Ingresses in different namespaces in centralized mode.
+// Like the Gateway API conflict resolution, the route of the oldest Ingress is
+// kept and the others are dropped with a Warning. The Ingresses with rules
+// keep the catch-all rules defaultBackendFeature added to the routes of their
+// hosts.
+func dropConflictingDefaultBackendRoutes(gatewayResources *i2gw.GatewayResources, ir intermediate.IR, gwConfig GatewayConfig) {
+	routesByGateway := map[types.NamespacedName][]types.NamespacedName{}
+	for routeKey := range gatewayResources.HTTPRoutes {
+		routeCtx, ok := ir.HTTPRoutes[routeKey]
+		if !ok || defaultBackendRouteIngress(routeCtx) == nil {
+			continue
+		}
+		gwNamespace, gwName := gwConfig.GetRouteGatewayRef(routeKey, routeCtx)
+		gwKey := types.NamespacedName{Namespace: gwNamespace, Name: gwName}
+		routesByGateway[gwKey] = append(routesByGateway[gwKey], routeKey)
+	}
+
+	for _, gwKey := range slices.SortedFunc(maps.Keys(routesByGateway), compareNamespacedNames) {
+		routeKeys := routesByGateway[gwKey]
+		if len(routeKeys) < 2 {
+			continue
+		}
+		slices.SortFunc(routeKeys, func(a, b types.NamespacedName) int {
+			ingA, ingB := defaultBackendRouteIngress(ir.HTTPRoutes[a]), defaultBackendRouteIngress(ir.HTTPRoutes[b])
+			if c := ingA.CreationTimestamp.Compare(ingB.CreationTimestamp.Time); c != 0 {
+				return c
+			}
+			return compareNamespacedNames(a, b)
+		})
+
+		kept := defaultBackendRouteIngress(ir.HTTPRoutes[routeKeys[0]])
+		for _, routeKey := range routeKeys[1:] {
+			route := gatewayResources.HTTPRoutes[routeKey]
+			ing := defaultBackendRouteIngress(ir.HTTPRoutes[routeKey])
+			delete(gatewayResources.HTTPRoutes, routeKey)
+			message := fmt.Sprintf("the defaultBackend of Ingress %s/%s conflicts with the one of Ingress %s/%s, both catching all the requests of Gateway %s. "+
+				"Only HTTPRoute %s of the oldest Ingress is generated, HTTPRoute %s is not",
+				ing.Namespace, ing.Name, kept.Namespace, kept.Name, gwKey, routeKeys[0], routeKey)
+			if len(ing.Spec.Rules) > 0 {
+				message += fmt.Sprintf(". Unmatched requests for the hosts of Ingress %s/%s still reach its defaultBackend", ing.Namespace, ing.Name)
+			}
+			notify(notifications.WarningNotification, message, ing, &route)
+		}
+	}
+}
+
+// defaultBackendRouteIngress returns the Ingress of an <ingress>-default-backend
+// route, nil if the route has hostnames or rules built from Ingress paths
+func defaultBackendRouteIngress(routeCtx intermediate.HTTPRouteContext) *networkingv1.Ingress {
+	if len(routeCtx.HTTPRoute.Spec.Hostnames) > 0 || len(routeCtx.RuleBackendSources) == 0 {
+		return nil
+	}
+	var ing *networkingv1.Ingress
+	for _, sources := range routeCtx.RuleBackendSources {
+		for _, source := range sources {
+			if source.DefaultBackend == nil || source.Path != nil || source.Ingress == nil {
+				return nil
+			}
+			ing = source.Ingress
+		}
+	}
+	return ing
+}
+
+// routeHasIngressPaths returns true if the route has rules built from the
+// paths of the Ingress, rather than only from its defaultBackend
+func routeHasIngressPaths(routeCtx intermediate.HTTPRouteContext, ing *networkingv1.Ingress) bool {
+	for _, sources := range routeCtx.RuleBackendSources {
+		for _, source := range sources {
+			if source.Path != nil && sourcesContainIngress([]intermediate.BackendSource{source}, ing) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasCatchAllRule returns true if the route has a rule matching all requests
+// with a "/" path prefix
+func hasCatchAllRule(route gatewayv1.HTTPRoute) bool {
+	for _, rule := range route.Spec.Rules {
+		for _, match := range rule.Matches {
+			if match.Path == nil || match.Path.Type == nil || match.Path.Value == nil {
+				continue
+			}
+			if *match.Path.Type == gatewayv1.PathMatchPathPrefix && *match.Path.Value == "/" &&
+				len(match.Headers) == 0 && len(match.QueryParams) == 0 && match.Method == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandHostOnlyRules handles Ingress rules with a host but no HTTP paths,
+// which would otherwise produce HTTPRoutes without rules. Like in Kubernetes,
+// such rules send all requests for the host to the defaultBackend, so they are
+// given a catch-all "/" path to it. Rules of Ingresses without a defaultBackend
+// are dropped with a Warning.
+func expandHostOnlyRules(ingresses []networkingv1.Ingress) []networkingv1.Ingress {
+	expanded := make([]networkingv1.Ingress, 0, len(ingresses))
+
+	for _, ingress := range ingresses {
+		if !hasHostOnlyRules(&ingress) {
+			expanded = append(expanded, ingress)
+			continue
+		}
+
+		ingress = *ingress.DeepCopy()
+		var rules []networkingv1.IngressRule
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+				rules = append(rules, rule)
+				continue
+			}
+
+			if ingress.Spec.DefaultBackend == nil {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("rule for host %q has no HTTP paths and the Ingress has no defaultBackend, skipping it", rule.Host),
+					&ingress)
+				continue
+			}
+
+			pathPrefix := networkingv1.PathTypePrefix
+			rule.HTTP = &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     "/",
+					PathType: &pathPrefix,
+					Backend:  *ingress.Spec.DefaultBackend,
+				}},
+			}
+			rules = append(rules, rule)
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("rule for host %q has no HTTP paths, all its requests are sent to the defaultBackend", rule.Host),
+				&ingress)
+		}
+		ingress.Spec.Rules = rules
+		expanded = append(expanded, ingress)
+	}
+
+	return expanded
+}
+
+// hasHostOnlyRules returns true if any rule of the Ingress has no HTTP paths
+func hasHostOnlyRules(ingress *networkingv1.Ingress) bool {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestDefaultBackendFeature(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		expectedRules  int
+		expectCatchAll bool
+	}{
+		{
+			name:           "catch-all appended after path rules",
+			path:           "/api",
+			expectedRules:  2,
+			expectCatchAll: true,
+		},
+		{
+			name:          "existing root rule is kept",
+			path:          "/",
+			expectedRules: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := headersTestIngress(nil)
+			ingress.Spec.Rules[0].HTTP.Paths[0].Path = tc.path
+			ingress.Spec.DefaultBackend = &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "fallback-service",
+					Port: networkingv1.ServiceBackendPort{Number: 8080},
+				},
+			}
+			ingresses := []networkingv1.Ingress{ingress}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := defaultBackendFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if errs := validateIR(ir); len(errs) > 0 {
+				t.Fatalf("IR is inconsistent: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			rules := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules
+			if len(rules) != tc.expectedRules {
+				t.Fatalf("expected %d rules, got %d", tc.expectedRules, len(rules))
+			}
+			if !tc.expectCatchAll {
+				return
+			}
+
+			lastRule := rules[len(rules)-1]
+			path := lastRule.Matches[0].Path
+			if *path.Type != gatewayv1.PathMatchPathPrefix || *path.Value != "/" {
+				t.Errorf("expected the last rule to match the \"/\" prefix, got %s %s", *path.Type, *path.Value)
+			}
+			if backend := lastRule.BackendRefs[0].Name; backend != "fallback-service" {
+				t.Errorf("expected the last rule to route to fallback-service, got %s", backend)
+			}
+			if *rules[0].Matches[0].Path.Value != tc.path {
+				t.Errorf("expected the first rule to keep path %s, got %s", tc.path, *rules[0].Matches[0].Path.Value)
+			}
+		})
+	}
+}
+
+func TestExpandHostOnlyRules(t *testing.T) {
+	testCases := []struct {
+		name             string
+		defaultBackend   bool
+		expectRoute      bool
+		expectedWarnings int
+	}{
+		{
+			name:           "host-only rule routes to the defaultBackend",
+			defaultBackend: true,
+			expectRoute:    true,
+		},
+		{
+			name:             "host-only rule without defaultBackend is skipped",
+			expectedWarnings: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingress := headersTestIngress(nil)
+			ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{Host: "host-only.example.com"})
+			if tc.defaultBackend {
+				ingress.Spec.DefaultBackend = &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{
+						Name: "fallback-service",
+						Port: networkingv1.ServiceBackendPort{Number: 8080},
+					},
+				}
+			}
+
+			ingresses := expandHostOnlyRules([]networkingv1.Ingress{ingress})
+			if ingress.Spec.Rules[1].HTTP != nil {
+				t.Errorf("expected the source Ingress to be left unchanged")
+			}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeCtx, ok := ir.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "host-only.example.com")}]
+			if ok != tc.expectRoute {
+				t.Fatalf("expected HTTPRoute for the host-only rule: %v, got: %v", tc.expectRoute, ok)
+			}
+			if _, ok := ir.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}]; !ok {
+				t.Errorf("expected HTTPRoute for the rule with paths")
+			}
+
+			if tc.expectRoute {
+				rules := routeCtx.HTTPRoute.Spec.Rules
+				if len(rules) != 1 || !hasCatchAllRule(routeCtx.HTTPRoute) || rules[0].BackendRefs[0].Name != "fallback-service" {
+					t.Errorf("expected a single catch-all rule to fallback-service, got %+v", rules)
+				}
+			}
+
+			var warnings int
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_ConflictingDefaultBackends(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+
+	defaultBackendIngress := func(namespace string, created time.Time) networkingv1.Ingress {
+		ingress := headersTestIngress(nil)
+		ingress.Namespace = namespace
+		ingress.Name = "fallback"
+		ingress.CreationTimestamp = metav1.NewTime(created)
+		ingress.Spec.DefaultBackend = &ingress.Spec.Rules[0].HTTP.Paths[0].Backend
+		ingress.Spec.Rules = nil
+		return ingress
+	}
+	older := defaultBackendIngress("team-b", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := defaultBackendIngress("team-a", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: older.Namespace, Name: older.Name}: &older,
+		{Namespace: newer.Namespace, Name: newer.Name}: &newer,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// Both catch-all routes attach to the centralized Gateway, only the one of
+	// the oldest Ingress is kept
+	if _, ok := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "team-b", Name: "fallback-default-backend"}]; !ok {
+		t.Errorf("expected the defaultBackend route of the oldest Ingress to be kept, got %v", slices.Collect(maps.Keys(gatewayResources.HTTPRoutes)))
+	}
+	if _, ok := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "team-a", Name: "fallback-default-backend"}]; ok {
+		t.Errorf("expected the conflicting defaultBackend route to be dropped")
+	}
+	for grantKey := range gatewayResources.ReferenceGrants {
+		if grantKey.Name == "allow-routes-from-team-a" {
+			t.Errorf("expected the ReferenceGrant of the dropped route to be pruned")
+		}
+	}
+
+	warnings := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "conflicts with the one of Ingress team-b/fallback") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected 1 conflict warning, got %d", warnings)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// defaultTLSListenerName is the name of the fallback HTTPS listener that
+// serves the default certificate for hosts without a listener of their own
+const defaultTLSListenerName = "default-https"
+
+// applyDefaultTLSSecret fills in the certificates of Gateway HTTPS listeners
+// whose Ingress TLS blocks have no secretName. ingress-nginx serves its default
+// SSL certificate for such hosts, so the secret configured with
+// --default-tls-secret is used instead, and a fallback HTTPS listener without
+// a hostname is added for the remaining hosts. Without a default secret a
+// Warning is emitted for each listener left without a certificate.
+func applyDefaultTLSSecret(gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	defaultRef := defaultTLSSecretRef(gwConfig.DefaultTLSSecret)
+
+	for gwKey, gateway := range gatewayResources.Gateways {
+		hasHTTPS := false
+		hasFallback := false
+		for i := range gateway.Spec.Listeners {
+			listener := &gateway.Spec.Listeners[i]
+			if listener.Protocol != gatewayv1.HTTPSProtocolType || listener.TLS == nil {
+				continue
+			}
+			hasHTTPS = true
+			if listener.Hostname == nil || *listener.Hostname == "" {
+				hasFallback = true
+			}
+
+			// Drop the references for TLS blocks without a secretName
+			listener.TLS.CertificateRefs = slices.DeleteFunc(listener.TLS.CertificateRefs, func(ref gatewayv1.SecretObjectReference) bool {
+				return ref.Name == ""
+			})
+			if len(listener.TLS.CertificateRefs) > 0 {
+				continue
+			}
+
+			if defaultRef == nil {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("Gateway %s/%s listener %s has TLS enabled but no secretName; set --%s-%s to use a default certificate",
+						gwKey.Namespace, gwKey.Name, listener.Name, Name, DefaultTLSSecretFlag),
+					&gateway)
+				continue
+			}
+			listener.TLS.CertificateRefs = []gatewayv1.SecretObjectReference{*defaultRef}
+		}
+
+		if defaultRef != nil && hasHTTPS && !hasFallback {
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, gatewayv1.Listener{
+				Name:     defaultTLSListenerName,
+				Port:     443,
+				Protocol: gatewayv1.HTTPSProtocolType,
+				TLS: &gatewayv1.ListenerTLSConfig{
+					CertificateRefs: []gatewayv1.SecretObjectReference{*defaultRef},
+				},
+			})
+		}
+
+		gatewayResources.Gateways[gwKey] = gateway
+	}
+}
+
+// defaultTLSSecretRef returns a reference to the [namespace/]name secret, or
+// nil if no secret is set.
+func defaultTLSSecretRef(secret string) *gatewayv1.SecretObjectReference {
+	if secret == "" {
+		return nil
+	}
+	ref := &gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secret)}
+	if namespace, name, found := strings.Cut(secret, "/"); found {
+		ns := gatewayv1.Namespace(namespace)
+		ref.Namespace = &ns
+		ref.Name = gatewayv1.ObjectName(name)
+	}
+	return ref
+}
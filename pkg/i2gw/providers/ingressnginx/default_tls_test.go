@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func defaultTLSTestGateway() gatewayv1.Gateway {
+	httpsListener := func(name, host, secret string) gatewayv1.Listener {
+		return gatewayv1.Listener{
+			Name:     gatewayv1.SectionName(name),
+			Hostname: ptrTo(gatewayv1.Hostname(host)),
+			Port:     443,
+			Protocol: gatewayv1.HTTPSProtocolType,
+			TLS: &gatewayv1.ListenerTLSConfig{
+				CertificateRefs: []gatewayv1.SecretObjectReference{{Name: gatewayv1.ObjectName(secret)}},
+			},
+		}
+	}
+	return gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default-gateway", Name: "default-gateway"},
+		Spec: gatewayv1.GatewaySpec{
+			Listeners: []gatewayv1.Listener{
+				httpsListener("a-example-com-https", "a.example.com", "wildcard-cert"),
+				httpsListener("b-example-com-https", "b.example.com", ""),
+			},
+		},
+	}
+}
+
+func TestApplyDefaultTLSSecret(t *testing.T) {
+	gwKey := types.NamespacedName{Namespace: "default-gateway", Name: "default-gateway"}
+
+	testCases := []struct {
+		name             string
+		defaultTLSSecret string
+		expectedRefs     map[string][]gatewayv1.SecretObjectReference
+		expectWarning    bool
+	}{
+		{
+			name: "no default secret",
+			expectedRefs: map[string][]gatewayv1.SecretObjectReference{
+				"a-example-com-https": {{Name: "wildcard-cert"}},
+				"b-example-com-https": {},
+			},
+			expectWarning: true,
+		},
+		{
+			name:             "default secret in the gateway namespace",
+			defaultTLSSecret: "default-cert",
+			expectedRefs: map[string][]gatewayv1.SecretObjectReference{
+				"a-example-com-https":  {{Name: "wildcard-cert"}},
+				"b-example-com-https":  {{Name: "default-cert"}},
+				defaultTLSListenerName: {{Name: "default-cert"}},
+			},
+		},
+		{
+			name:             "default secret in another namespace",
+			defaultTLSSecret: "certs/default-cert",
+			expectedRefs: map[string][]gatewayv1.SecretObjectReference{
+				"a-example-com-https":  {{Name: "wildcard-cert"}},
+				"b-example-com-https":  {{Name: "default-cert", Namespace: ptrTo(gatewayv1.Namespace("certs"))}},
+				defaultTLSListenerName: {{Name: "default-cert", Namespace: ptrTo(gatewayv1.Namespace("certs"))}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			gatewayResources := i2gw.GatewayResources{
+				Gateways: map[types.NamespacedName]gatewayv1.Gateway{gwKey: defaultTLSTestGateway()},
+			}
+			applyDefaultTLSSecret(&gatewayResources, GatewayConfig{DefaultTLSSecret: tc.defaultTLSSecret})
+
+			listeners := gatewayResources.Gateways[gwKey].Spec.Listeners
+			if len(listeners) != len(tc.expectedRefs) {
+				t.Fatalf("expected %d listeners, got %d", len(tc.expectedRefs), len(listeners))
+			}
+			for _, listener := range listeners {
+				expectedRefs, ok := tc.expectedRefs[string(listener.Name)]
+				if !ok {
+					t.Errorf("unexpected listener %s", listener.Name)
+					continue
+				}
+				if !reflect.DeepEqual(listener.TLS.CertificateRefs, expectedRefs) {
+					t.Errorf("listener %s: expected certificateRefs %v, got %v", listener.Name, expectedRefs, listener.TLS.CertificateRefs)
+				}
+				if listener.Name == defaultTLSListenerName && listener.Hostname != nil {
+					t.Errorf("expected the fallback listener to have no hostname, got %s", *listener.Hostname)
+				}
+			}
+
+			hasWarning := false
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "b-example-com-https") {
+					hasWarning = true
+				}
+			}
+			if hasWarning != tc.expectWarning {
+				t.Errorf("expected missing secret Warning: %v, got: %v", tc.expectWarning, hasWarning)
+			}
+		})
+	}
+}
+
+func TestGatewayConfig_ValidateDefaultTLSSecret(t *testing.T) {
+	testCases := []struct {
+		secret      string
+		expectError bool
+	}{
+		{secret: ""},
+		{secret: "default-cert"},
+		{secret: "certs/default-cert"},
+		{secret: "certs/", expectError: true},
+		{secret: "/default-cert", expectError: true},
+		{secret: "a/b/c", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.secret, func(t *testing.T) {
+			config := GatewayConfig{DefaultTLSSecret: tc.secret, RateLimitBurstMultiplier: 1, SSLRedirectStatus: defaultSSLRedirectStatus}
+			errs := config.Validate()
+			if hasErr := len(errs) > 0; hasErr != tc.expectError {
+				t.Errorf("expected error: %v, got: %v", tc.expectError, errs)
+			}
+		})
+	}
+}
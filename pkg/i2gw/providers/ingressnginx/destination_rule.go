@@ -0,0 +1,302 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// serviceTrafficPolicy holds the Istio traffic settings of a Service
+type serviceTrafficPolicy struct {
+	// connectTimeoutSeconds is the TCP connect timeout, 0 if unset
+	connectTimeoutSeconds int
+	// h2cPorts are the ports of GRPC backends, which speak HTTP/2 cleartext
+	h2cPorts []int32
+	// h2cAllPorts is set for GRPC backends referenced without a port number
+	h2cAllPorts bool
+	// consistentHash is the Istio consistentHash load balancer of backends
+	// with session affinity or upstream-hash-by, nil if unset
+	consistentHash map[string]interface{}
+	// outlierDetection ejects failing endpoints, for session affinity with
+	// session-cookie-change-on-failure
+	outlierDetection bool
+	// sources are the annotations the settings come from
+	sources []string
+}
+
+// buildDestinationRules generates an Istio DestinationRule for every Service
+// backend of the routes that needs traffic settings HTTPRoutes cannot express:
+//   - with --strict-timeout-mapping, the TCP connectTimeout of routes with a
+//     proxy-connect-timeout. A Service used by routes with different connect
+//     timeouts gets the smallest one.
+//   - for backend-protocol: GRPC, an HTTP/2 upgrade of the backend ports, since
+//     Envoy otherwise proxies to a plaintext backend with HTTP/1.1.
+//   - for affinity: cookie and upstream-hash-by, a consistentHash load
+//     balancer, which BackendLBPolicy cannot express. The backends of canary
+//     Ingresses use the affinity of their main Ingress. With
+//     session-cookie-change-on-failure, an outlierDetection ejecting failing
+//     endpoints, so that the hash moves to another one.
+//
+// Istio only applies one DestinationRule per host, so a Service gets a single
+// DestinationRule with all its settings. Envoy Gateway classes get no
+// DestinationRule, and a Warning for the settings their BackendTrafficPolicies
+// do not cover.
+func buildDestinationRules(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	policies := make(map[types.NamespacedName]*serviceTrafficPolicy)
+	policyFor := func(serviceKey types.NamespacedName) *serviceTrafficPolicy {
+		if policies[serviceKey] == nil {
+			policies[serviceKey] = &serviceTrafficPolicy{}
+		}
+		return policies[serviceKey]
+	}
+
+	for _, routeKey := range slices.SortedFunc(maps.Keys(ir.HTTPRoutes), compareNamespacedNames) {
+		routeCtx := ir.HTTPRoutes[routeKey]
+		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+		connectTimeout := 0
+		if gwConfig.StrictTimeoutMapping && nginxIR != nil && nginxIR.ConnectTimeoutSeconds > 0 {
+			connectTimeout = nginxIR.ConnectTimeoutSeconds
+		}
+
+		for ruleIdx, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			for backendIdx, backendRef := range rule.BackendRefs {
+				if !isServiceBackendRef(backendRef.BackendObjectReference) {
+					continue
+				}
+				serviceKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(backendRef.Name)}
+				if backendRef.Namespace != nil {
+					serviceKey.Namespace = string(*backendRef.Namespace)
+				}
+
+				if connectTimeout > 0 {
+					policy := policyFor(serviceKey)
+					existing := policy.connectTimeoutSeconds
+					if existing > 0 && existing != connectTimeout {
+						notify(notifications.WarningNotification,
+							fmt.Sprintf("service %s has conflicting proxy-connect-timeout values (%ds, %ds), the DestinationRule uses the smallest",
+								serviceKey, existing, connectTimeout),
+							&routeCtx.HTTPRoute,
+						)
+					}
+					if existing == 0 || connectTimeout < existing {
+						policy.connectTimeoutSeconds = connectTimeout
+					}
+					policy.addSource(proxyConnectTimeoutAnnotation)
+				}
+
+				if isGRPCBackend(routeCtx, ruleIdx, backendIdx) {
+					policy := policyFor(serviceKey)
+					if backendRef.Port == nil {
+						policy.h2cAllPorts = true
+					} else if port := int32(*backendRef.Port); !slices.Contains(policy.h2cPorts, port) {
+						policy.h2cPorts = append(policy.h2cPorts, port)
+					}
+					policy.addSource(backendProtocolAnnotation)
+				}
+
+				if consistentHash, annotation := backendConsistentHash(routeCtx, ruleIdx, backendIdx); consistentHash != nil {
+					policy := policyFor(serviceKey)
+					if policy.consistentHash != nil && !reflect.DeepEqual(policy.consistentHash, consistentHash) {
+						notify(notifications.WarningNotification,
+							fmt.Sprintf("service %s has conflicting session affinity settings, the DestinationRule uses the first one", serviceKey),
+							&routeCtx.HTTPRoute,
+						)
+					}
+					if policy.consistentHash == nil {
+						policy.consistentHash = consistentHash
+					}
+					policy.addSource(annotation)
+				}
+
+				if backendChangesOnFailure(routeCtx, ruleIdx, backendIdx) {
+					policy := policyFor(serviceKey)
+					policy.outlierDetection = true
+					policy.addSource(sessionCookieChangeOnFailureAnnotation)
+				}
+			}
+		}
+	}
+
+	for _, serviceKey := range slices.SortedFunc(maps.Keys(policies), compareNamespacedNames) {
+		policy := policies[serviceKey]
+		slices.Sort(policy.h2cPorts)
+		destinationRule := buildDestinationRule(serviceKey, policy, gwConfig)
+
+		var settings []string
+		if policy.h2cAllPorts || len(policy.h2cPorts) > 0 {
+			settings = append(settings, "HTTP/2 upgrade for GRPC")
+		}
+		if policy.consistentHash != nil {
+			settings = append(settings, "consistent hash load balancing")
+		}
+		if policy.outlierDetection {
+			settings = append(settings, "outlier detection")
+		}
+
+		// Like the EnvoyFilters, DestinationRules only apply to Istio. The
+		// connect timeout is set by the BackendTrafficPolicies of the routes.
+		if isEnvoyGatewayClass(gwConfig) {
+			if len(settings) > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("DestinationRule %s/%s is Istio-specific and is not generated for the Envoy Gateway class %s, service %s gets no %s",
+						destinationRule.GetNamespace(), destinationRule.GetName(), gwConfig.GatewayClassName, serviceKey, strings.Join(settings, " and ")),
+					destinationRule,
+				)
+			}
+			continue
+		}
+		if policy.connectTimeoutSeconds > 0 {
+			settings = append([]string{fmt.Sprintf("connectTimeout %ds", policy.connectTimeoutSeconds)}, settings...)
+		}
+
+		gatewayResources.GatewayExtensions = append(gatewayResources.GatewayExtensions, *destinationRule)
+		if policy.h2cAllPorts || len(policy.h2cPorts) > 0 {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("service %s is a GRPC backend speaking HTTP/2 cleartext; DestinationRule %s/%s upgrades its connections to HTTP/2. "+
+					"Alternatively set appProtocol: kubernetes.io/h2c on the Service port",
+					serviceKey, destinationRule.GetNamespace(), destinationRule.GetName()),
+				nil,
+			)
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("created DestinationRule %s/%s with %s for service %s",
+				destinationRule.GetNamespace(), destinationRule.GetName(), strings.Join(settings, " and "), serviceKey),
+			nil,
+		)
+	}
+}
+
+func (p *serviceTrafficPolicy) addSource(annotation string) {
+	if !slices.Contains(p.sources, annotation) {
+		p.sources = append(p.sources, annotation)
+	}
+}
+
+// isGRPCBackend returns true if the backend of the rule comes from an Ingress
+// with backend-protocol: GRPC. GRPCS backends use TLS, which negotiates
+// HTTP/2 itself, and get a BackendTLSPolicy instead.
+func isGRPCBackend(routeCtx intermediate.HTTPRouteContext, ruleIdx, backendIdx int) bool {
+	if ruleIdx >= len(routeCtx.RuleBackendSources) || backendIdx >= len(routeCtx.RuleBackendSources[ruleIdx]) {
+		return false
+	}
+	source := routeCtx.RuleBackendSources[ruleIdx][backendIdx]
+	if source.Ingress == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(source.Ingress.Annotations[backendProtocolAnnotation]), "GRPC")
+}
+
+// buildDestinationRule creates a DestinationRule with the traffic settings of
+// the service
+func buildDestinationRule(serviceKey types.NamespacedName, policy *serviceTrafficPolicy, gwConfig GatewayConfig) *unstructured.Unstructured {
+	connectionPool := func(h2Upgrade bool) map[string]interface{} {
+		pool := map[string]interface{}{}
+		if policy.connectTimeoutSeconds > 0 {
+			pool["tcp"] = map[string]interface{}{
+				"connectTimeout": fmt.Sprintf("%ds", policy.connectTimeoutSeconds),
+			}
+		}
+		if h2Upgrade {
+			pool["http"] = map[string]interface{}{
+				"h2UpgradePolicy": "UPGRADE",
+			}
+		}
+		return pool
+	}
+
+	var loadBalancer map[string]interface{}
+	if policy.consistentHash != nil {
+		loadBalancer = map[string]interface{}{"consistentHash": policy.consistentHash}
+	}
+
+	// ingress-nginx picks another backend after a single failure
+	var outlierDetection map[string]interface{}
+	if policy.outlierDetection {
+		outlierDetection = map[string]interface{}{
+			"consecutive5xxErrors": int64(1),
+			"interval":             "10s",
+			"baseEjectionTime":     "30s",
+			"maxEjectionPercent":   int64(100),
+		}
+	}
+
+	trafficPolicy := map[string]interface{}{}
+	if pool := connectionPool(policy.h2cAllPorts); len(pool) > 0 {
+		trafficPolicy["connectionPool"] = pool
+	}
+	if loadBalancer != nil {
+		trafficPolicy["loadBalancer"] = loadBalancer
+	}
+	if outlierDetection != nil {
+		trafficPolicy["outlierDetection"] = outlierDetection
+	}
+	// Port level settings replace the destination level ones rather than
+	// being merged with them, so they repeat the connect timeout, load
+	// balancer and outlier detection
+	if !policy.h2cAllPorts && len(policy.h2cPorts) > 0 {
+		var portLevelSettings []interface{}
+		for _, port := range policy.h2cPorts {
+			portSettings := map[string]interface{}{
+				"port":           map[string]interface{}{"number": int64(port)},
+				"connectionPool": connectionPool(true),
+			}
+			if loadBalancer != nil {
+				portSettings["loadBalancer"] = loadBalancer
+			}
+			if outlierDetection != nil {
+				portSettings["outlierDetection"] = outlierDetection
+			}
+			portLevelSettings = append(portLevelSettings, portSettings)
+		}
+		trafficPolicy["portLevelSettings"] = portLevelSettings
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1",
+			"kind":       "DestinationRule",
+			"metadata": map[string]interface{}{
+				"name":      serviceKey.Name + "-traffic-policy",
+				"namespace": serviceKey.Namespace,
+				"labels":    unstructuredLabels(gwConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": strings.Join(policy.sources, ","),
+				},
+			},
+			"spec": map[string]interface{}{
+				"host":          fmt.Sprintf("%s.%s.svc.cluster.local", serviceKey.Name, serviceKey.Namespace),
+				"trafficPolicy": trafficPolicy,
+			},
+		},
+	}
+}
+
+// compareNamespacedNames orders NamespacedNames by namespace, then name
+func compareNamespacedNames(a, b types.NamespacedName) int {
+	return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuildDestinationRules_GRPCBackend(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		annotations           map[string]string
+		strictTimeoutMapping  bool
+		expectedTrafficPolicy map[string]interface{}
+	}{
+		{
+			name:        "GRPC backend is upgraded to HTTP/2 cleartext",
+			annotations: map[string]string{backendProtocolAnnotation: "GRPC"},
+			expectedTrafficPolicy: map[string]interface{}{
+				"portLevelSettings": []interface{}{
+					map[string]interface{}{
+						"port": map[string]interface{}{"number": int64(50051)},
+						"connectionPool": map[string]interface{}{
+							"http": map[string]interface{}{"h2UpgradePolicy": "UPGRADE"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "GRPC backend with a strict connect timeout",
+			annotations: map[string]string{
+				backendProtocolAnnotation:     "grpc",
+				proxyConnectTimeoutAnnotation: "5",
+			},
+			strictTimeoutMapping: true,
+			expectedTrafficPolicy: map[string]interface{}{
+				"connectionPool": map[string]interface{}{
+					"tcp": map[string]interface{}{"connectTimeout": "5s"},
+				},
+				"portLevelSettings": []interface{}{
+					map[string]interface{}{
+						"port": map[string]interface{}{"number": int64(50051)},
+						"connectionPool": map[string]interface{}{
+							"tcp":  map[string]interface{}{"connectTimeout": "5s"},
+							"http": map[string]interface{}{"h2UpgradePolicy": "UPGRADE"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "GRPCS backend negotiates HTTP/2 over TLS",
+			annotations: map[string]string{backendProtocolAnnotation: "GRPCS"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := headersTestIngress(tc.annotations)
+			ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = networkingv1.ServiceBackendPort{Number: 50051}
+			ingresses := []networkingv1.Ingress{ingress}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := timeoutFeature(tc.strictTimeoutMapping)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			gatewayResources := i2gw.GatewayResources{}
+			buildDestinationRules(ir, &gatewayResources, GatewayConfig{StrictTimeoutMapping: tc.strictTimeoutMapping})
+
+			if tc.expectedTrafficPolicy == nil {
+				if len(gatewayResources.GatewayExtensions) != 0 {
+					t.Errorf("expected no DestinationRule, got %d extensions", len(gatewayResources.GatewayExtensions))
+				}
+				return
+			}
+			if len(gatewayResources.GatewayExtensions) != 1 {
+				t.Fatalf("expected 1 DestinationRule, got %d extensions", len(gatewayResources.GatewayExtensions))
+			}
+
+			destinationRule := gatewayResources.GatewayExtensions[0]
+			if destinationRule.GetName() != "my-service-traffic-policy" {
+				t.Errorf("expected DestinationRule my-service-traffic-policy, got %s", destinationRule.GetName())
+			}
+			trafficPolicy, _, _ := unstructured.NestedMap(destinationRule.Object, "spec", "trafficPolicy")
+			if diff := cmp.Diff(tc.expectedTrafficPolicy, trafficPolicy); diff != "" {
+				t.Errorf("unexpected trafficPolicy (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildDestinationRules_EnvoyGatewayClass(t *testing.T) {
+	testCases := []struct {
+		name            string
+		annotations     map[string]string
+		expectedWarning string
+	}{
+		{
+			name: "GRPC backend is reported",
+			annotations: map[string]string{
+				backendProtocolAnnotation:     "GRPC",
+				proxyConnectTimeoutAnnotation: "5",
+			},
+			expectedWarning: "DestinationRule default/my-service-traffic-policy is Istio-specific and is not generated for the Envoy Gateway class envoy-gateway, " +
+				"service default/my-service gets no HTTP/2 upgrade for GRPC",
+		},
+		{
+			name:        "connect timeout is left to the BackendTrafficPolicy",
+			annotations: map[string]string{proxyConnectTimeoutAnnotation: "5"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := timeoutFeature(true)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			gatewayResources := i2gw.GatewayResources{}
+			buildDestinationRules(ir, &gatewayResources, GatewayConfig{GatewayClassName: "envoy-gateway", StrictTimeoutMapping: true})
+
+			if len(gatewayResources.GatewayExtensions) != 0 {
+				t.Errorf("expected no DestinationRule, got %d extensions", len(gatewayResources.GatewayExtensions))
+			}
+			var warnings []string
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification && strings.HasPrefix(n.Message, "DestinationRule") {
+					warnings = append(warnings, n.Message)
+				}
+			}
+			var expectedWarnings []string
+			if tc.expectedWarning != "" {
+				expectedWarnings = []string{tc.expectedWarning}
+			}
+			if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+				t.Errorf("unexpected DestinationRule warnings (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
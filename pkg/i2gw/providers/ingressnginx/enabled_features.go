@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// featureAnnotations maps the features that can be left out with
+// --enabled-features to the annotations they convert. Annotations reported as
+// migration blockers, like snippets and use-regex, are always processed.
+var featureAnnotations = map[string][]string{
+	"access-log": {logFormatUpstreamAnnotation},
+	"affinity": {
+		affinityAnnotation, affinityCanaryBehaviorAnnotation, affinityModeAnnotation, upstreamHashByAnnotation,
+		sessionCookieNameAnnotation, sessionCookiePathAnnotation, sessionCookieMaxAgeAnnotation, sessionCookieExpiresAnnotation,
+		sessionCookieChangeOnFailureAnnotation, sessionCookieHashAnnotation,
+	},
+	"backend-protocol": {
+		backendProtocolAnnotation, proxySSLSecretAnnotation, proxySSLVerifyAnnotation,
+		proxySSLNameAnnotation, proxySSLServerNameAnnotation, proxySSLProtocolsAnnotation, proxySSLCiphersAnnotation,
+	},
+	"canary": {
+		canaryAnnotation, canaryWeightAnnotation, canaryWeightTotalAnnotation,
+		canaryByHeaderAnnotation, canaryByHeaderValueAnnotation, canaryByHeaderPatternAnnotation, canaryByCookieAnnotation,
+	},
+	"client-cert-auth": {
+		authTLSSecretAnnotation, authTLSVerifyClientAnnotation, authTLSVerifyDepthAnnotation,
+		authTLSErrorPageAnnotation, authTLSPassCertToUpstreamAnnotation,
+	},
+	"cors": {
+		enableCORSAnnotation, corsAllowOriginAnnotation, corsAllowMethodsAnnotation, corsAllowHeadersAnnotation,
+		corsExposeHeadersAnnotation, corsAllowCredentialsAnnotation, corsMaxAgeAnnotation,
+	},
+	"custom-http-errors": {customHTTPErrorsAnnotation, defaultBackendAnnotation},
+	"external-auth": {
+		authURLAnnotation, authMethodAnnotation, authSigninAnnotation, authResponseHeadersAnnotation,
+		authRequestRedirectAnnotation, authCacheKeyAnnotation, authCacheDurationAnnotation, authFailOpenAnnotation,
+		authJWTIssuerAnnotation, authJWKSURIAnnotation, authSigninRedirectParamAnnotation,
+	},
+	"headers": {
+		proxyHideHeadersAnnotation, connectionProxyHeaderAnnotation, proxySetHeadersAnnotation, customHeadersAnnotation,
+		upstreamVhostAnnotation,
+	},
+	"mirror":             {mirrorTargetAnnotation, mirrorRequestBodyAnnotation, mirrorHostAnnotation, mirrorPercentageAnnotation},
+	"proxy-http-version": {proxyHTTPVersionAnnotation},
+	"proxy-redirect":     {proxyRedirectFromAnnotation, proxyRedirectToAnnotation},
+	"proxy-settings": {
+		proxyBodySizeAnnotation, proxyBufferingAnnotation, proxyRequestBufferingAnnotation, loadBalanceAnnotation,
+		clientBodyBufferSizeAnnotation,
+		proxyBufferSizeAnnotation, proxyBuffersNumberAnnotation, proxyBusyBuffersSizeAnnotation,
+	},
+	"ratelimit": {
+		limitRPSAnnotation, limitRPMAnnotation, limitConnectionsAnnotation,
+		limitBurstAnnotation, limitReqZoneAnnotation, limitWhitelistAnnotation,
+	},
+	"retry":        {proxyNextUpstreamAnnotation, proxyNextUpstreamTriesAnnotation, proxyNextUpstreamTimeoutAnnotation},
+	"rewrite":      {rewriteTargetAnnotation, appRootAnnotation},
+	"server-alias": {serverAliasAnnotation},
+	"source-range": {whitelistSourceRangeAnnotation, allowlistSourceRangeAnnotation},
+	"ssl-redirect": {sslRedirectAnnotation, forceSSLRedirectAnnotation, usePortInRedirectsAnnotation},
+	"timeout":      {proxyConnectTimeoutAnnotation, proxyReadTimeoutAnnotation, proxySendTimeoutAnnotation},
+	"tracing":      {enableOpentracingAnnotation, enableOpentelemetryAnnotation},
+}
+
+// supportedFeatureNames returns the names accepted by --enabled-features, sorted
+func supportedFeatureNames() []string {
+	return slices.Sorted(maps.Keys(featureAnnotations))
+}
+
+// parseEnabledFeatures splits the comma separated --enabled-features value.
+// An empty value enables all features and returns nil.
+func parseEnabledFeatures(value string) sets.Set[string] {
+	var features sets.Set[string]
+	for _, feature := range strings.Split(value, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			if features == nil {
+				features = sets.New[string]()
+			}
+			features.Insert(feature)
+		}
+	}
+	return features
+}
+
+// validateEnabledFeatures checks the --enabled-features value only names
+// known features
+func validateEnabledFeatures(value string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, feature := range sets.List(parseEnabledFeatures(value)) {
+		if _, ok := featureAnnotations[feature]; !ok {
+			errs = append(errs, field.NotSupported(path, feature, supportedFeatureNames()))
+		}
+	}
+	return errs
+}
+
+// removeDisabledFeatureAnnotations returns the Ingresses without the
+// annotations of the features left out of enabled, so neither the feature
+// parsers nor the resources generated from the IR act on them. An Info lists
+// the skipped annotations of each Ingress. Without the canary feature, canary
+// Ingresses are dropped instead, since without their annotations they would be
+// converted as primary Ingresses competing with the ones they split traffic
+// from. A nil enabled set keeps all annotations.
+func removeDisabledFeatureAnnotations(ingresses []networkingv1.Ingress, enabled sets.Set[string]) []networkingv1.Ingress {
+	if enabled == nil {
+		return ingresses
+	}
+
+	result := make([]networkingv1.Ingress, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		if !enabled.Has("canary") && isCanaryIngress(&ingress) {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("canary Ingress skipped by configuration (--%s-%s): the canary feature is disabled",
+					Name, EnabledFeaturesFlag),
+				&ingress)
+			continue
+		}
+
+		var skipped []string
+		for _, feature := range supportedFeatureNames() {
+			if enabled.Has(feature) {
+				continue
+			}
+			var annotations []string
+			for _, annotation := range featureAnnotations[feature] {
+				if _, ok := ingress.Annotations[annotation]; ok {
+					annotations = append(annotations, annotation)
+				}
+			}
+			if len(annotations) > 0 {
+				skipped = append(skipped, fmt.Sprintf("%s (%s)", feature, strings.Join(annotations, ", ")))
+			}
+		}
+		if len(skipped) == 0 {
+			result = append(result, ingress)
+			continue
+		}
+
+		ingress = *ingress.DeepCopy()
+		for _, feature := range supportedFeatureNames() {
+			if !enabled.Has(feature) {
+				for _, annotation := range featureAnnotations[feature] {
+					delete(ingress.Annotations, annotation)
+				}
+			}
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("annotations of disabled features were found but skipped by configuration (--%s-%s): %s",
+				Name, EnabledFeaturesFlag, strings.Join(skipped, "; ")),
+			&ingress)
+		result = append(result, ingress)
+	}
+	return result
+}
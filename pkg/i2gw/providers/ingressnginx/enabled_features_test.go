@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_EnabledFeatures(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				GatewayModeFlag:     "per-namespace",
+				EnabledFeaturesFlag: "timeout, headers",
+			},
+		},
+	}).(*Provider)
+	ingress := headersTestIngress(map[string]string{
+		limitRPSAnnotation:         "10",
+		proxyReadTimeoutAnnotation: "30",
+	})
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for _, extension := range gatewayResources.GatewayExtensions {
+		if strings.HasSuffix(extension.GetName(), "-ratelimit") {
+			t.Errorf("expected no ratelimit EnvoyFilter for the disabled ratelimit feature, got %s", extension.GetName())
+		}
+	}
+	timeoutSet := false
+	for _, route := range gatewayResources.HTTPRoutes {
+		for _, rule := range route.Spec.Rules {
+			if rule.Timeouts != nil {
+				timeoutSet = true
+			}
+		}
+	}
+	if !timeoutSet {
+		t.Errorf("expected the enabled timeout feature to set the HTTPRoute timeouts")
+	}
+	if ingress.Annotations[limitRPSAnnotation] != "10" {
+		t.Errorf("expected the stored Ingress to be left unmodified")
+	}
+
+	var infos []string
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification && strings.Contains(n.Message, "skipped by configuration") {
+			infos = append(infos, n.Message)
+		}
+	}
+	expected := "annotations of disabled features were found but skipped by configuration (--ingress-nginx-enabled-features): ratelimit (nginx.ingress.kubernetes.io/limit-rps)"
+	if len(infos) != 1 || infos[0] != expected {
+		t.Errorf("expected the info %q, got %q", expected, infos)
+	}
+}
+
+func TestValidateEnabledFeatures(t *testing.T) {
+	if errs := validateEnabledFeatures("timeout,ratelimit,rewrite", nil); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs := validateEnabledFeatures("timeout,geoip", nil); len(errs) != 1 {
+		t.Errorf("expected 1 error for the unknown feature, got %v", errs)
+	}
+}
+
+func TestToGatewayResources_EnabledFeaturesWithoutCanary(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				GatewayModeFlag:     "per-namespace",
+				EnabledFeaturesFlag: "timeout",
+			},
+		},
+	}).(*Provider)
+	primary := headersTestIngress(nil)
+	canary := headersTestIngress(map[string]string{
+		canaryAnnotation:       "true",
+		canaryWeightAnnotation: "20",
+	})
+	canary.Name = "test-ingress-canary"
+	canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "canary-service"
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: primary.Namespace, Name: primary.Name}: &primary,
+		{Namespace: canary.Namespace, Name: canary.Name}:   &canary,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for _, route := range gatewayResources.HTTPRoutes {
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if backendRef.Name == "canary-service" {
+					t.Errorf("expected the canary Ingress to be skipped, got a backendRef to %s in HTTPRoute %s", backendRef.Name, route.Name)
+				}
+			}
+		}
+	}
+
+	found := false
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification && strings.HasPrefix(n.Message, "canary Ingress skipped by configuration") {
+			found = true
+			if len(n.CallingObjects) != 1 || n.CallingObjects[0].GetName() != canary.Name {
+				t.Errorf("expected the info to reference the canary Ingress, got %v", n.CallingObjects)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected an info for the skipped canary Ingress")
+	}
+}
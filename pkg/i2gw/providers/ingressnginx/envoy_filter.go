@@ -17,78 +17,62 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
-	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
-// envoyFilterFeature is a no-op feature parser - the actual EnvoyFilter generation
-// happens in buildIstioEnvoyFilters during ToGatewayResources.
-// This parser exists to emit notifications about EnvoyFilter generation.
-func envoyFilterFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
-	var errs field.ErrorList
-
-	// Count EnvoyFilter-related annotations for notification purposes
-	for _, ing := range ingresses {
-		annotations := ing.GetAnnotations()
-		if annotations == nil {
-			continue
-		}
-
-		hasEnvoyConfig := false
-		details := []string{}
-
-		if _, ok := annotations["nginx.ingress.kubernetes.io/limit-rps"]; ok {
-			hasEnvoyConfig = true
-			details = append(details, "rate-limiting")
-		}
-		// Only report body-size if non-zero (0 means unlimited, no EnvoyFilter needed)
-		if v, ok := annotations["nginx.ingress.kubernetes.io/proxy-body-size"]; ok {
-			bodyBytes, _ := ParseBodySize(v)
-			if bodyBytes > 0 {
-				hasEnvoyConfig = true
-				details = append(details, "body-size")
-			}
-		}
-		// Note: proxy-buffering: "off" does NOT need an EnvoyFilter - Envoy streams by default
-		// which matches NGINX's "off" behavior. No notification needed.
-
-		// Check for auth-url which needs app-level handling
-		if _, ok := annotations["nginx.ingress.kubernetes.io/auth-url"]; ok {
-			hasEnvoyConfig = true
-			details = append(details, "external-auth")
-		}
-
-		if hasEnvoyConfig {
-			notify(notifications.WarningNotification, fmt.Sprintf("WARNING: Annotations requiring app-level handling detected: %s. These have no Gateway API equivalent - implement in your application.",
-				strings.Join(details, ", ")),
-				&ing,
-			)
+// buildIstioEnvoyFilters creates Istio EnvoyFilter resources from IR
+// and adds them to GatewayResources.GatewayExtensions, sorted by key. Only the
+// HTTPRoutes still generated get filters. The filters are only generated with
+// EmitEnvoyFiltersFlag, and never for Envoy Gateway classes since they are
+// Istio-specific. Each filter that is not generated is reported with a Warning.
+func buildIstioEnvoyFilters(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	routes := make(map[types.NamespacedName]intermediate.HTTPRouteContext, len(ir.HTTPRoutes))
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if _, ok := gatewayResources.HTTPRoutes[routeKey]; ok {
+			routes[routeKey] = routeCtx
 		}
 	}
+	ir.HTTPRoutes = routes
 
-	return errs
-}
-
-// buildIstioEnvoyFilters creates Istio EnvoyFilter resources from IR
-// and adds them to GatewayResources.GatewayExtensions
-func buildIstioEnvoyFilters(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
 	generator := &EnvoyFilterGenerator{GatewayConfig: gwConfig}
 	filters := generator.GenerateEnvoyFilters(ir)
 
-	for _, filter := range filters {
-		if filter != nil {
-			gatewayResources.GatewayExtensions = append(gatewayResources.GatewayExtensions, *filter)
+	for _, key := range slices.SortedFunc(maps.Keys(filters), compareNamespacedNames) {
+		filter := filters[key]
+		if filter == nil {
+			continue
+		}
+		if isEnvoyGatewayClass(gwConfig) {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s %s is Istio-specific and is not generated for the Envoy Gateway class %s", filter.GetKind(), key, gwConfig.GatewayClassName),
+				filter,
+			)
+			continue
+		}
+		if !gwConfig.EmitEnvoyFilters {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s %s is not generated, the annotations it converts have no Gateway API equivalent. "+
+					"Set --ingress-nginx-%s to generate it.", filter.GetKind(), key, EmitEnvoyFiltersFlag),
+				filter,
+			)
+			continue
 		}
+		gatewayResources.GatewayExtensions = append(gatewayResources.GatewayExtensions, *filter)
 	}
 }
 
@@ -107,6 +91,10 @@ type EnvoyFilterGenerator struct {
 func (g *EnvoyFilterGenerator) GenerateEnvoyFilters(ir intermediate.IR) map[types.NamespacedName]*unstructured.Unstructured {
 	filters := make(map[types.NamespacedName]*unstructured.Unstructured)
 
+	// The client-body-buffer-size limit is set on all the listeners of the
+	// Gateway, so the routes of a Gateway share a single value
+	bufferLimits := g.gatewayBufferLimits(ir)
+
 	// Process HTTPRoutes for rate limiting, body size, buffering configs
 	for routeKey, routeCtx := range ir.HTTPRoutes {
 		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
@@ -116,53 +104,233 @@ func (g *EnvoyFilterGenerator) GenerateEnvoyFilters(ir intermediate.IR) map[type
 		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
 
 		// Get the gateway reference based on mode
-		gwNamespace, gwName := g.GatewayConfig.GetGatewayRef(routeKey.Namespace)
+		gwConfig := g.GatewayConfig.ForRoute(routeCtx)
+		gwNamespace, gwName := g.GatewayConfig.GetRouteGatewayRef(routeKey, routeCtx)
 
 		// For centralized mode, EnvoyFilters go in the gateway namespace
 		filterNamespace := routeKey.Namespace
-		if g.GatewayConfig.IsCentralized() {
+		if gwConfig.IsCentralized() {
 			filterNamespace = gwNamespace
 		}
 
+		// Generate source range allow list EnvoyFilter if configured
+		if len(nginxIR.AllowSourceRanges) > 0 && g.GatewayConfig.SourceRangeAuthorizationPolicy {
+			// AuthorizationPolicies target Gateways in their own namespace.
+			// Istio enforces them before the EnvoyFilter HTTP filters, so
+			// no ordering with the rate limit is needed.
+			policyKey := types.NamespacedName{
+				Namespace: gwNamespace,
+				Name:      fmt.Sprintf("%s-%s-sourcerange", routeKey.Namespace, routeKey.Name),
+			}
+			filters[policyKey] = g.buildSourceRangeAuthorizationPolicy(
+				policyKey,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.AllowSourceRanges,
+			)
+		} else if len(nginxIR.AllowSourceRanges) > 0 {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-allowlist", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildAllowlistEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.AllowSourceRanges,
+			)
+		}
+
 		// Generate rate limit EnvoyFilter if configured
+		// A zero rate means disabled, never emit a token bucket with tokens_per_fill: 0
 		if nginxIR.RateLimitRPS > 0 {
 			filterKey := types.NamespacedName{
 				Namespace: filterNamespace,
 				Name:      fmt.Sprintf("%s-%s-ratelimit", routeKey.Namespace, routeKey.Name),
 			}
-			filters[filterKey] = g.buildRateLimitEnvoyFilter(
+			rateLimitFilter := g.buildRateLimitEnvoyFilter(
 				filterKey,
 				gwNamespace,
 				gwName,
 				nginxIR.RateLimitRPS,
 				nginxIR.RateLimitBurst,
 			)
+			if len(nginxIR.RateLimitExemptRanges) > 0 {
+				exemptRateLimitSourceRanges(rateLimitFilter, nginxIR.RateLimitExemptRanges)
+			}
+			filters[filterKey] = rateLimitFilter
 		}
 
 		// Generate body size EnvoyFilter if configured
 		// NGINX behavior: "0" means unlimited (no restriction), so skip EnvoyFilter in that case
-		if nginxIR.ProxyBodySize != "" {
+		if nginxIR.ProxyBodySize != "" || nginxIR.ClientBodyBufferBytes > 0 {
 			bodyBytes, _ := ParseBodySize(nginxIR.ProxyBodySize)
-			if bodyBytes > 0 { // Only generate EnvoyFilter if a limit is specified (non-zero)
+			if bodyBytes > 0 || nginxIR.ClientBodyBufferBytes > 0 { // Only generate EnvoyFilter if a limit is specified (non-zero)
 				filterKey := types.NamespacedName{
 					Namespace: filterNamespace,
 					Name:      fmt.Sprintf("%s-%s-bodysize", routeKey.Namespace, routeKey.Name),
 				}
+				var bufferBytes int64
+				if nginxIR.ClientBodyBufferBytes > 0 {
+					bufferBytes = bufferLimits[types.NamespacedName{Namespace: gwNamespace, Name: gwName}]
+				}
 				filters[filterKey] = g.buildBodySizeEnvoyFilter(
 					filterKey,
 					gwNamespace,
 					gwName,
 					bodyBytes,
+					bufferBytes,
+				)
+			}
+		}
+
+		// Generate max request headers EnvoyFilter for header buffer server-snippets
+		if nginxIR.MaxRequestHeadersKB > 0 {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-headerbuffers", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildMaxRequestHeadersEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				nginxIR.MaxRequestHeadersKB,
+			)
+		}
+
+		// Generate tracing EnvoyFilter if configured
+		if nginxIR.Tracing != nil {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-tracing", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildTracingEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				nginxIR.Tracing,
+			)
+		}
+
+		// Generate access log EnvoyFilter if configured
+		if nginxIR.AccessLogFormat != "" {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-accesslog", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildAccessLogEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.AccessLogFormat,
+			)
+		}
+
+		// Generate upstream HTTP version EnvoyFilter if configured
+		if nginxIR.UpstreamHTTPVersion != "" {
+			if clusters := routeBackendClusters(routeKey, routeCtx); len(clusters) > 0 {
+				filterKey := types.NamespacedName{
+					Namespace: filterNamespace,
+					Name:      fmt.Sprintf("%s-%s-httpversion", routeKey.Namespace, routeKey.Name),
+				}
+				filters[filterKey] = g.buildUpstreamHTTPVersionEnvoyFilter(
+					filterKey,
+					gwNamespace,
+					gwName,
+					clusters,
+				)
+			}
+		}
+
+		// Generate backend buffer size EnvoyFilter if configured
+		if nginxIR.ProxyBufferBytes > 0 {
+			if clusters := routeBackendClusters(routeKey, routeCtx); len(clusters) > 0 {
+				filterKey := types.NamespacedName{
+					Namespace: filterNamespace,
+					Name:      fmt.Sprintf("%s-%s-buffers", routeKey.Namespace, routeKey.Name),
+				}
+				filters[filterKey] = g.buildProxyBuffersEnvoyFilter(
+					filterKey,
+					gwNamespace,
+					gwName,
+					clusters,
+					nginxIR.ProxyBufferBytes,
 				)
 			}
 		}
 
+		// Generate custom error backend EnvoyFilter if configured
+		if nginxIR.CustomHTTPErrors != nil {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-customerrors", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildCustomHTTPErrorsEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.CustomHTTPErrors,
+			)
+		}
+
+		// Generate CORS EnvoyFilter if configured
+		if nginxIR.CORS != nil {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-cors", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildCORSEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.CORS,
+			)
+		}
+
+		// Generate Location rewrite EnvoyFilter if configured
+		if nginxIR.ProxyRedirect != nil {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-proxyredirect", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildProxyRedirectEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.ProxyRedirect,
+			)
+		}
+
 		// Note: proxy-buffering: "off" does NOT need an EnvoyFilter
 		// Envoy streams by default (no buffering), which matches NGINX's "off" behavior.
 
-		// Generate ext_authz EnvoyFilter if configured (per-namespace mode only)
-		// In per-namespace mode, the Gateway is namespace-scoped so ext_authz applies only to that namespace
-		if nginxIR.ExternalAuth != nil && nginxIR.ExternalAuth.URL != "" {
+		// Validate JWTs with a RequestAuthentication and an AuthorizationPolicy
+		// rather than calling an auth-url validating them. Both target the
+		// Gateway, so they are in its namespace.
+		if nginxIR.ExternalAuth != nil && nginxIR.ExternalAuth.JWTIssuer != "" {
+			requestAuthKey := types.NamespacedName{
+				Namespace: gwNamespace,
+				Name:      fmt.Sprintf("%s-%s-jwt", routeKey.Namespace, routeKey.Name),
+			}
+			policyKey := types.NamespacedName{
+				Namespace: gwNamespace,
+				Name:      fmt.Sprintf("%s-%s-jwt-required", routeKey.Namespace, routeKey.Name),
+			}
+			filters[requestAuthKey], filters[policyKey] = g.buildJWTAuthPolicy(
+				requestAuthKey,
+				policyKey,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.ExternalAuth,
+			)
+		} else if nginxIR.ExternalAuth != nil && nginxIR.ExternalAuth.URL != "" {
+			// Generate ext_authz EnvoyFilter if configured (per-namespace mode only)
+			// In per-namespace mode, the Gateway is namespace-scoped so ext_authz applies only to that namespace
 			filterKey := types.NamespacedName{
 				Namespace: filterNamespace,
 				Name:      fmt.Sprintf("%s-%s-extauthz", routeKey.Namespace, routeKey.Name),
@@ -171,43 +339,225 @@ func (g *EnvoyFilterGenerator) GenerateEnvoyFilters(ir intermediate.IR) map[type
 				filterKey,
 				gwNamespace,
 				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
 				nginxIR.ExternalAuth,
 			)
 		}
+
+		// Generate client certificate validation EnvoyFilter if configured
+		if nginxIR.ClientCertAuth != nil && nginxIR.ClientCertAuth.Secret != "" {
+			filterKey := types.NamespacedName{
+				Namespace: filterNamespace,
+				Name:      fmt.Sprintf("%s-%s-clientcert", routeKey.Namespace, routeKey.Name),
+			}
+			filters[filterKey] = g.buildClientCertEnvoyFilter(
+				filterKey,
+				gwNamespace,
+				gwName,
+				routeCtx.HTTPRoute.Spec.Hostnames,
+				nginxIR.ClientCertAuth,
+			)
+		}
+
+		// Order the filters patching the HTTP filter chain of the route, e.g.
+		// authenticate before rate limiting
+		orderRouteEnvoyFilters(filters, filterNamespace, routeKey)
+
+		if g.GatewayConfig.MergeEnvoyFilters {
+			mergeRouteEnvoyFilters(filters, filterNamespace, routeKey)
+		}
 	}
 
 	return filters
 }
 
-// buildRateLimitEnvoyFilter creates an EnvoyFilter for local rate limiting
-func (g *EnvoyFilterGenerator) buildRateLimitEnvoyFilter(
+// gatewayBufferLimits returns the per connection buffer limit of the listeners
+// of each Gateway, the smallest client-body-buffer-size of its routes, capped
+// to their proxy-body-size. A Gateway whose routes set different sizes is
+// reported with a Warning.
+func (g *EnvoyFilterGenerator) gatewayBufferLimits(ir intermediate.IR) map[types.NamespacedName]int64 {
+	limits := make(map[types.NamespacedName]int64)
+	for _, routeKey := range slices.SortedFunc(maps.Keys(ir.HTTPRoutes), compareNamespacedNames) {
+		routeCtx := ir.HTTPRoutes[routeKey]
+		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+		if nginxIR == nil || nginxIR.ClientBodyBufferBytes <= 0 {
+			continue
+		}
+
+		bufferBytes := nginxIR.ClientBodyBufferBytes
+		if bodyBytes, _ := ParseBodySize(nginxIR.ProxyBodySize); bodyBytes > 0 && bufferBytes > bodyBytes {
+			bufferBytes = bodyBytes
+		}
+		gwNamespace, gwName := g.GatewayConfig.GetRouteGatewayRef(routeKey, routeCtx)
+		gwKey := types.NamespacedName{Namespace: gwNamespace, Name: gwName}
+
+		existing := limits[gwKey]
+		if existing > 0 && existing != bufferBytes {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Gateway %s has routes with conflicting %s values (%d, %d bytes), the EnvoyFilters use the smallest",
+					gwKey, clientBodyBufferSizeAnnotation, existing, bufferBytes),
+				&routeCtx.HTTPRoute,
+			)
+		}
+		if existing == 0 || bufferBytes < existing {
+			limits[gwKey] = bufferBytes
+		}
+	}
+	return limits
+}
+
+// routeEnvoyFilterSuffixes lists the per-route EnvoyFilter name suffixes in the
+// order their patches must be applied when merged into a single EnvoyFilter,
+// the order of the routeEnvoyFilterPriorities of split EnvoyFilters.
+var routeEnvoyFilterSuffixes = []string{"allowlist", "clientcert", "extauthz", "ratelimit", "bodysize", "headerbuffers", "tracing", "accesslog", "httpversion", "buffers", "customerrors", "cors", "proxyredirect"}
+
+// mergeRouteEnvoyFilters replaces the EnvoyFilters generated for a route with a
+// single "<namespace>-<route>-envoyfilter" EnvoyFilter holding all of their
+// configPatches. Patches in one EnvoyFilter are applied in order, so the
+// priorities used to order split EnvoyFilters are dropped.
+func mergeRouteEnvoyFilters(filters map[types.NamespacedName]*unstructured.Unstructured, filterNamespace string, routeKey types.NamespacedName) {
+	var merged *unstructured.Unstructured
+	var patches []interface{}
+	var sources []string
+
+	for _, suffix := range routeEnvoyFilterSuffixes {
+		key := types.NamespacedName{
+			Namespace: filterNamespace,
+			Name:      fmt.Sprintf("%s-%s-%s", routeKey.Namespace, routeKey.Name, suffix),
+		}
+		filter, ok := filters[key]
+		if !ok {
+			continue
+		}
+		delete(filters, key)
+
+		if merged == nil {
+			merged = filter.DeepCopy()
+		}
+		filterPatches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+		patches = append(patches, filterPatches...)
+		if source := filter.GetAnnotations()["ingress2gateway.kubernetes.io/source"]; source != "" {
+			sources = append(sources, source)
+		}
+	}
+
+	if merged == nil {
+		return
+	}
+
+	mergedKey := types.NamespacedName{
+		Namespace: filterNamespace,
+		Name:      fmt.Sprintf("%s-%s-envoyfilter", routeKey.Namespace, routeKey.Name),
+	}
+	merged.SetName(mergedKey.Name)
+	merged.SetAnnotations(map[string]string{
+		"ingress2gateway.kubernetes.io/source": strings.Join(sources, ","),
+	})
+	unstructured.RemoveNestedField(merged.Object, "spec", "priority")
+	_ = unstructured.SetNestedSlice(merged.Object, patches, "spec", "configPatches")
+
+	filters[mergedKey] = merged
+}
+
+// routeEnvoyFilterPriorities are the EnvoyFilter priorities of the per-route
+// EnvoyFilters inserting HTTP filters before the router, by name suffix. Istio
+// applies EnvoyFilters with a lower priority first, and each insertion before
+// the router lands after the filters inserted earlier, so clients are checked
+// against the source range allow list, redirected to the auth-tls-error-page
+// without a valid client certificate and checked by ext_authz, before they are
+// rate limited and their request body is buffered. Denied clients don't
+// consume rate limit tokens, and limit-whitelist exemptions only apply to
+// allowed clients.
+var routeEnvoyFilterPriorities = []struct {
+	suffix   string
+	priority int64
+}{
+	{"allowlist", -20},
+	{"clientcert", -10},
+	{"extauthz", 10},
+	{"ratelimit", 20},
+	{"bodysize", 30},
+}
+
+const (
+	// extAuthzMaxRequestBytes is the maximum request body size buffered and
+	// forwarded to the auth service for POST auth checks.
+	extAuthzMaxRequestBytes = 8192
+
+	// defaultRateLimitBurstMultiplier is the default rate limit burst as a
+	// multiple of the rate, see RateLimitBurstMultiplierFlag.
+	defaultRateLimitBurstMultiplier = 5
+)
+
+// orderRouteEnvoyFilters sets the routeEnvoyFilterPriorities of the
+// EnvoyFilters of the route inserting HTTP filters when it has several of them.
+// Client certificates are validated during the TLS handshake, before any HTTP
+// filter, so the clientcert EnvoyFilter is only ordered when it inserts the
+// auth-tls-error-page Lua filter. A single EnvoyFilter keeps the default
+// priority.
+func orderRouteEnvoyFilters(filters map[types.NamespacedName]*unstructured.Unstructured, filterNamespace string, routeKey types.NamespacedName) {
+	type orderedFilter struct {
+		filter   *unstructured.Unstructured
+		priority int64
+	}
+	var ordered []orderedFilter
+	for _, entry := range routeEnvoyFilterPriorities {
+		key := types.NamespacedName{
+			Namespace: filterNamespace,
+			Name:      fmt.Sprintf("%s-%s-%s", routeKey.Namespace, routeKey.Name, entry.suffix),
+		}
+		if filter, ok := filters[key]; ok && insertsHTTPFilter(filter) {
+			ordered = append(ordered, orderedFilter{filter: filter, priority: entry.priority})
+		}
+	}
+	if len(ordered) < 2 {
+		return
+	}
+
+	for _, entry := range ordered {
+		_ = unstructured.SetNestedField(entry.filter.Object, entry.priority, "spec", "priority")
+	}
+}
+
+// insertsHTTPFilter checks if one of the configPatches of the EnvoyFilter
+// inserts an HTTP filter
+func insertsHTTPFilter(filter *unstructured.Unstructured) bool {
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	for _, patch := range patches {
+		patchMap, ok := patch.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		applyTo, _, _ := unstructured.NestedString(patchMap, "applyTo")
+		operation, _, _ := unstructured.NestedString(patchMap, "patch", "operation")
+		if applyTo == "HTTP_FILTER" && strings.HasPrefix(operation, "INSERT_") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMaxRequestHeadersEnvoyFilter creates an EnvoyFilter raising the max
+// request headers size of the Gateway's HTTP connection manager
+func (g *EnvoyFilterGenerator) buildMaxRequestHeadersEnvoyFilter(
 	key types.NamespacedName,
 	gatewayNamespace string,
 	gatewayName string,
-	rps int,
-	burst int,
+	headersKB int,
 ) *unstructured.Unstructured {
-	if burst == 0 {
-		burst = rps * 5 // Default burst to 5x RPS if not specified
-	}
-
-	filter := &unstructured.Unstructured{
+	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "networking.istio.io/v1alpha3",
 			"kind":       "EnvoyFilter",
 			"metadata": map[string]interface{}{
 				"name":      key.Name,
 				"namespace": key.Namespace,
-				"labels": map[string]interface{}{
-					"app.kubernetes.io/managed-by": "ingress2gateway",
-					"gateway-api-migration":        "true",
-				},
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
 				"annotations": map[string]interface{}{
-					"ingress2gateway.kubernetes.io/source": "nginx.ingress.kubernetes.io/limit-rps",
+					"ingress2gateway.kubernetes.io/source": serverSnippetAnnotation,
 				},
 			},
 			"spec": map[string]interface{}{
-				// Target the Gateway (either per-namespace or centralized)
 				"targetRefs": []interface{}{
 					map[string]interface{}{
 						"kind":      "Gateway",
@@ -218,46 +568,23 @@ func (g *EnvoyFilterGenerator) buildRateLimitEnvoyFilter(
 				},
 				"configPatches": []interface{}{
 					map[string]interface{}{
-						"applyTo": "HTTP_FILTER",
+						"applyTo": "NETWORK_FILTER",
 						"match": map[string]interface{}{
 							"context": "GATEWAY",
 							"listener": map[string]interface{}{
 								"filterChain": map[string]interface{}{
 									"filter": map[string]interface{}{
 										"name": "envoy.filters.network.http_connection_manager",
-										"subFilter": map[string]interface{}{
-											"name": "envoy.filters.http.router",
-										},
 									},
 								},
 							},
 						},
 						"patch": map[string]interface{}{
-							"operation": "INSERT_BEFORE",
+							"operation": "MERGE",
 							"value": map[string]interface{}{
-								"name": "envoy.filters.http.local_ratelimit",
 								"typed_config": map[string]interface{}{
-									"@type":       "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit",
-									"stat_prefix": "http_local_rate_limiter",
-									"token_bucket": map[string]interface{}{
-										"max_tokens":      burst,
-										"tokens_per_fill": rps,
-										"fill_interval":   "1s",
-									},
-									"filter_enabled": map[string]interface{}{
-										"runtime_key": "local_rate_limit_enabled",
-										"default_value": map[string]interface{}{
-											"numerator":   100,
-											"denominator": "HUNDRED",
-										},
-									},
-									"filter_enforced": map[string]interface{}{
-										"runtime_key": "local_rate_limit_enforced",
-										"default_value": map[string]interface{}{
-											"numerator":   100,
-											"denominator": "HUNDRED",
-										},
-									},
+									"@type":                  "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+									"max_request_headers_kb": int64(headersKB),
 								},
 							},
 						},
@@ -266,40 +593,26 @@ func (g *EnvoyFilterGenerator) buildRateLimitEnvoyFilter(
 			},
 		},
 	}
-
-	return filter
 }
 
-// buildBodySizeEnvoyFilter creates an EnvoyFilter to set max request body size.
-// The bodyBytes parameter should already be parsed and validated (> 0).
-// NGINX behavior: "0" means unlimited - callers should skip this function for 0 values.
-func (g *EnvoyFilterGenerator) buildBodySizeEnvoyFilter(
+// buildTracingEnvoyFilter creates an EnvoyFilter enabling tracing on the
+// Gateway's HTTP connection manager with the configured sampling
+func (g *EnvoyFilterGenerator) buildTracingEnvoyFilter(
 	key types.NamespacedName,
 	gatewayNamespace string,
 	gatewayName string,
-	bodyBytes int64,
+	tracing *intermediate.TracingConfig,
 ) *unstructured.Unstructured {
-
-	bytes := bodyBytes
-	if bytes <= 0 {
-		// This shouldn't happen if caller checks properly, but default to 1GB as fallback
-		bytes = 1024 * 1024 * 1024
-	}
-
-	filter := &unstructured.Unstructured{
+	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "networking.istio.io/v1alpha3",
 			"kind":       "EnvoyFilter",
 			"metadata": map[string]interface{}{
 				"name":      key.Name,
 				"namespace": key.Namespace,
-				"labels": map[string]interface{}{
-					"app.kubernetes.io/managed-by": "ingress2gateway",
-					"gateway-api-migration":        "true",
-				},
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
 				"annotations": map[string]interface{}{
-					"ingress2gateway.kubernetes.io/source":    "nginx.ingress.kubernetes.io/proxy-body-size",
-					"ingress2gateway.kubernetes.io/body-size": fmt.Sprintf("%d", bytes),
+					"ingress2gateway.kubernetes.io/source": enableOpentracingAnnotation + "," + enableOpentelemetryAnnotation,
 				},
 			},
 			"spec": map[string]interface{}{
@@ -329,66 +642,843 @@ func (g *EnvoyFilterGenerator) buildBodySizeEnvoyFilter(
 							"value": map[string]interface{}{
 								"typed_config": map[string]interface{}{
 									"@type": "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
-									"route_config": map[string]interface{}{
-										"max_direct_response_body_size_bytes": bytes,
-									},
-								},
-							},
-						},
-					},
-					// Also set on the route level for request body
-					map[string]interface{}{
-						"applyTo": "HTTP_FILTER",
-						"match": map[string]interface{}{
-							"context": "GATEWAY",
-							"listener": map[string]interface{}{
-								"filterChain": map[string]interface{}{
-									"filter": map[string]interface{}{
-										"name": "envoy.filters.network.http_connection_manager",
-										"subFilter": map[string]interface{}{
-											"name": "envoy.filters.http.router",
+									"tracing": map[string]interface{}{
+										"random_sampling": map[string]interface{}{
+											"value": tracing.SamplingPercentage,
 										},
 									},
 								},
 							},
 						},
-						"patch": map[string]interface{}{
-							"operation": "INSERT_BEFORE",
-							"value": map[string]interface{}{
-								"name": "envoy.filters.http.buffer",
-								"typed_config": map[string]interface{}{
-									"@type":             "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer",
-									"max_request_bytes": bytes,
-								},
-							},
-						},
 					},
 				},
 			},
 		},
 	}
-
-	return filter
 }
 
-// buildNoBufferingEnvoyFilter creates an EnvoyFilter to disable proxy buffering
-func (g *EnvoyFilterGenerator) buildNoBufferingEnvoyFilter(
+// buildAccessLogEnvoyFilter creates an EnvoyFilter adding an access log with
+// the format to the Gateway's HTTP connection manager. Access logs are
+// configured per connection manager, so the log is filtered on the route
+// hostnames to only log the requests of the route.
+func (g *EnvoyFilterGenerator) buildAccessLogEnvoyFilter(
 	key types.NamespacedName,
 	gatewayNamespace string,
 	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	logFormat string,
 ) *unstructured.Unstructured {
-
-	filter := &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"apiVersion": "networking.istio.io/v1alpha3",
-			"kind":       "EnvoyFilter",
+	accessLog := map[string]interface{}{
+		"name": "envoy.access_loggers.file",
+		"typed_config": map[string]interface{}{
+			"@type": "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog",
+			"path":  "/dev/stdout",
+			"log_format": map[string]interface{}{
+				"text_format_source": map[string]interface{}{
+					"inline_string": logFormat + "\n",
+				},
+			},
+		},
+	}
+	if len(hostnames) > 0 {
+		accessLog["filter"] = map[string]interface{}{
+			"header_filter": map[string]interface{}{
+				"header": map[string]interface{}{
+					"name": ":authority",
+					"string_match": map[string]interface{}{
+						"safe_regex": map[string]interface{}{
+							"regex": authorityRegex(hostnames),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": logFormatUpstreamAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": []interface{}{
+					map[string]interface{}{
+						"applyTo": "NETWORK_FILTER",
+						"match": map[string]interface{}{
+							"context": "GATEWAY",
+							"listener": map[string]interface{}{
+								"filterChain": map[string]interface{}{
+									"filter": map[string]interface{}{
+										"name": "envoy.filters.network.http_connection_manager",
+									},
+								},
+							},
+						},
+						"patch": map[string]interface{}{
+							"operation": "MERGE",
+							"value": map[string]interface{}{
+								"typed_config": map[string]interface{}{
+									"@type":      "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+									"access_log": []interface{}{accessLog},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildRateLimitEnvoyFilter creates an EnvoyFilter for local rate limiting
+func (g *EnvoyFilterGenerator) buildRateLimitEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	rps int,
+	burst int,
+) *unstructured.Unstructured {
+	// Burst maps to the token bucket max_tokens. Without a limit-burst-multiplier
+	// annotation it defaults to the configured multiple of the rate.
+	if burst == 0 {
+		burst = rps * cmp.Or(g.GatewayConfig.RateLimitBurstMultiplier, defaultRateLimitBurstMultiplier)
+	}
+
+	filter := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": "nginx.ingress.kubernetes.io/limit-rps",
+				},
+			},
+			"spec": map[string]interface{}{
+				// Target the Gateway (either per-namespace or centralized)
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": []interface{}{
+					map[string]interface{}{
+						"applyTo": "HTTP_FILTER",
+						"match": map[string]interface{}{
+							"context": "GATEWAY",
+							"listener": map[string]interface{}{
+								"filterChain": map[string]interface{}{
+									"filter": map[string]interface{}{
+										"name": "envoy.filters.network.http_connection_manager",
+										"subFilter": map[string]interface{}{
+											"name": "envoy.filters.http.router",
+										},
+									},
+								},
+							},
+						},
+						"patch": map[string]interface{}{
+							"operation": "INSERT_BEFORE",
+							"value": map[string]interface{}{
+								"name": "envoy.filters.http.local_ratelimit",
+								"typed_config": map[string]interface{}{
+									"@type":       "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit",
+									"stat_prefix": "http_local_rate_limiter",
+									"token_bucket": map[string]interface{}{
+										"max_tokens":      int64(burst),
+										"tokens_per_fill": int64(rps),
+										"fill_interval":   "1s",
+									},
+									"filter_enabled": map[string]interface{}{
+										"runtime_key": "local_rate_limit_enabled",
+										"default_value": map[string]interface{}{
+											"numerator":   int64(100),
+											"denominator": "HUNDRED",
+										},
+									},
+									"filter_enforced": map[string]interface{}{
+										"runtime_key": "local_rate_limit_enforced",
+										"default_value": map[string]interface{}{
+											"numerator":   int64(100),
+											"denominator": "HUNDRED",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return filter
+}
+
+// buildAllowlistEnvoyFilter creates an EnvoyFilter with an RBAC filter denying
+// requests for the route hostnames from clients outside of the source ranges.
+// Other hosts served by the Gateway are not restricted.
+func (g *EnvoyFilterGenerator) buildAllowlistEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	sourceRanges []string,
+) *unstructured.Unstructured {
+	var allowedIDs []interface{}
+	for _, sourceRange := range sourceRanges {
+		allowedIDs = append(allowedIDs, map[string]interface{}{
+			"remote_ip": cidrRange(sourceRange),
+		})
+	}
+
+	// Requests to any host are restricted by a route without hostnames
+	permission := map[string]interface{}{"any": true}
+	if len(hostnames) > 0 {
+		permission = map[string]interface{}{
+			"header": map[string]interface{}{
+				"name": ":authority",
+				"string_match": map[string]interface{}{
+					"safe_regex": map[string]interface{}{
+						"regex": authorityRegex(hostnames),
+					},
+				},
+			},
+		}
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": whitelistSourceRangeAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": []interface{}{
+					map[string]interface{}{
+						"applyTo": "HTTP_FILTER",
+						"match": map[string]interface{}{
+							"context": "GATEWAY",
+							"listener": map[string]interface{}{
+								"filterChain": map[string]interface{}{
+									"filter": map[string]interface{}{
+										"name": "envoy.filters.network.http_connection_manager",
+										"subFilter": map[string]interface{}{
+											"name": "envoy.filters.http.router",
+										},
+									},
+								},
+							},
+						},
+						"patch": map[string]interface{}{
+							"operation": "INSERT_BEFORE",
+							"value": map[string]interface{}{
+								"name": "envoy.filters.http.rbac",
+								"typed_config": map[string]interface{}{
+									"@type": "type.googleapis.com/envoy.extensions.filters.http.rbac.v3.RBAC",
+									"rules": map[string]interface{}{
+										"action": "DENY",
+										"policies": map[string]interface{}{
+											key.Name: map[string]interface{}{
+												"permissions": []interface{}{permission},
+												"principals": []interface{}{
+													map[string]interface{}{
+														"not_id": map[string]interface{}{
+															"or_ids": map[string]interface{}{
+																"ids": allowedIDs,
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildSourceRangeAuthorizationPolicy creates an Istio AuthorizationPolicy
+// attached to the Gateway, denying requests for the route hostnames from
+// clients outside of the source ranges. It is the higher-level alternative to
+// the RBAC filter of buildAllowlistEnvoyFilter, selected with
+// --ingress-nginx-source-range-authorization-policy.
+func (g *EnvoyFilterGenerator) buildSourceRangeAuthorizationPolicy(
+	key types.NamespacedName,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	sourceRanges []string,
+) *unstructured.Unstructured {
+	ipBlocks := make([]interface{}, 0, len(sourceRanges))
+	for _, sourceRange := range sourceRanges {
+		ipBlocks = append(ipBlocks, sourceRange)
+	}
+
+	rule := map[string]interface{}{
+		"from": []interface{}{
+			map[string]interface{}{
+				"source": map[string]interface{}{
+					"notRemoteIpBlocks": ipBlocks,
+				},
+			},
+		},
+	}
+	// Requests to any host are restricted by a route without hostnames. The
+	// Host header may carry a port, which Istio matches literally.
+	if len(hostnames) > 0 {
+		var hosts []interface{}
+		for _, hostname := range hostnames {
+			hosts = append(hosts, string(hostname), string(hostname)+":*")
+		}
+		rule["to"] = []interface{}{
+			map[string]interface{}{
+				"operation": map[string]interface{}{
+					"hosts": hosts,
+				},
+			},
+		}
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "security.istio.io/v1",
+			"kind":       "AuthorizationPolicy",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": whitelistSourceRangeAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":  "Gateway",
+						"group": "gateway.networking.k8s.io",
+						"name":  gatewayName,
+					},
+				},
+				"action": "DENY",
+				"rules":  []interface{}{rule},
+			},
+		},
+	}
+}
+
+// buildJWTAuthPolicy creates an Istio RequestAuthentication validating the JWTs
+// of the issuer of the auth config on the Gateway, and an AuthorizationPolicy
+// denying requests for the route hostnames without a valid JWT, since a
+// RequestAuthentication alone lets requests without a JWT through.
+func (g *EnvoyFilterGenerator) buildJWTAuthPolicy(
+	requestAuthKey types.NamespacedName,
+	policyKey types.NamespacedName,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	auth *intermediate.ExternalAuthConfig,
+) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	targetRefs := []interface{}{
+		map[string]interface{}{
+			"kind":  "Gateway",
+			"group": "gateway.networking.k8s.io",
+			"name":  gatewayName,
+		},
+	}
+	metadata := func(key types.NamespacedName) map[string]interface{} {
+		return map[string]interface{}{
+			"name":      key.Name,
+			"namespace": key.Namespace,
+			"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+			"annotations": map[string]interface{}{
+				"ingress2gateway.kubernetes.io/source": authURLAnnotation + "," + authJWTIssuerAnnotation,
+			},
+		}
+	}
+
+	// The backend receives the Authorization header, as with ingress-nginx
+	jwtRule := map[string]interface{}{
+		"issuer":               auth.JWTIssuer,
+		"forwardOriginalToken": true,
+	}
+	if auth.JWKSURI != "" {
+		jwtRule["jwksUri"] = auth.JWKSURI
+	}
+	requestAuthentication := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "security.istio.io/v1",
+			"kind":       "RequestAuthentication",
+			"metadata":   metadata(requestAuthKey),
+			"spec": map[string]interface{}{
+				"targetRefs": targetRefs,
+				"jwtRules":   []interface{}{jwtRule},
+			},
+		},
+	}
+
+	rule := map[string]interface{}{
+		"from": []interface{}{
+			map[string]interface{}{
+				"source": map[string]interface{}{
+					"notRequestPrincipals": []interface{}{"*"},
+				},
+			},
+		},
+	}
+	// Like buildSourceRangeAuthorizationPolicy, the Host header may carry a port
+	if len(hostnames) > 0 {
+		var hosts []interface{}
+		for _, hostname := range hostnames {
+			hosts = append(hosts, string(hostname), string(hostname)+":*")
+		}
+		rule["to"] = []interface{}{
+			map[string]interface{}{
+				"operation": map[string]interface{}{
+					"hosts": hosts,
+				},
+			},
+		}
+	}
+	authorizationPolicy := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "security.istio.io/v1",
+			"kind":       "AuthorizationPolicy",
+			"metadata":   metadata(policyKey),
+			"spec": map[string]interface{}{
+				"targetRefs": targetRefs,
+				"action":     "DENY",
+				"rules":      []interface{}{rule},
+			},
+		},
+	}
+
+	return requestAuthentication, authorizationPolicy
+}
+
+// exemptRateLimitSourceRanges wraps the local rate limit filter of the
+// EnvoyFilter in a matcher skipping it for clients in the source ranges
+func exemptRateLimitSourceRanges(filter *unstructured.Unstructured, sourceRanges []string) {
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) == 0 {
+		return
+	}
+	patch := patches[0].(map[string]interface{})
+	rateLimit, _, _ := unstructured.NestedMap(patch, "patch", "value")
+
+	var ranges []interface{}
+	for _, sourceRange := range sourceRanges {
+		ranges = append(ranges, cidrRange(sourceRange))
+	}
+
+	_ = unstructured.SetNestedField(patch, map[string]interface{}{
+		"name": rateLimit["name"],
+		"typed_config": map[string]interface{}{
+			"@type":            "type.googleapis.com/envoy.extensions.common.matching.v3.ExtensionWithMatcher",
+			"extension_config": rateLimit,
+			"xds_matcher": map[string]interface{}{
+				"matcher_tree": map[string]interface{}{
+					"input": map[string]interface{}{
+						"name": "source-ip",
+						"typed_config": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.matching.common_inputs.network.v3.SourceIPInput",
+						},
+					},
+					"custom_match": map[string]interface{}{
+						"name": "ip-matcher",
+						"typed_config": map[string]interface{}{
+							"@type": "type.googleapis.com/xds.type.matcher.v3.IPMatcher",
+							"range_matchers": []interface{}{
+								map[string]interface{}{
+									"ranges": ranges,
+									"on_match": map[string]interface{}{
+										"action": map[string]interface{}{
+											"name": "skip",
+											"typed_config": map[string]interface{}{
+												"@type": "type.googleapis.com/envoy.extensions.filters.common.matcher.action.v3.SkipFilter",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, "patch", "value")
+	patches[0] = patch
+	_ = unstructured.SetNestedSlice(filter.Object, patches, "spec", "configPatches")
+
+	annotations := filter.GetAnnotations()
+	annotations["ingress2gateway.kubernetes.io/source"] += "," + limitWhitelistAnnotation
+	filter.SetAnnotations(annotations)
+}
+
+// cidrRange converts a CIDR to an Envoy CidrRange
+func cidrRange(cidr string) map[string]interface{} {
+	address, prefixLen, _ := strings.Cut(cidr, "/")
+	length, _ := strconv.Atoi(prefixLen)
+	return map[string]interface{}{
+		"address_prefix": address,
+		"prefix_len":     int64(length),
+	}
+}
+
+// authorityRegex returns a regular expression matching the :authority header
+// of requests for the hostnames, with an optional port. A wildcard hostname
+// matches a single label.
+func authorityRegex(hostnames []gatewayv1.Hostname) string {
+	var alternatives []string
+	for _, hostname := range hostnames {
+		host := string(hostname)
+		prefix := ""
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			prefix = `[^.:]+\.`
+			host = suffix
+		}
+		alternatives = append(alternatives, prefix+regexp.QuoteMeta(host))
+	}
+	return "^(" + strings.Join(alternatives, "|") + ")(:[0-9]+)?$"
+}
+
+// buildBodySizeEnvoyFilter creates an EnvoyFilter to set max request body size
+// and the request body buffer size. The bodyBytes parameter, from
+// proxy-body-size, is the max size enforced by the buffer filter; NGINX "0"
+// means unlimited, so no buffer filter is added for 0. The bufferBytes
+// parameter, from client-body-buffer-size, is the part of the body read in
+// memory per connection, set as the per connection buffer limit of the
+// listeners and capped to the max size. At least one of them must be > 0.
+func (g *EnvoyFilterGenerator) buildBodySizeEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	bodyBytes int64,
+	bufferBytes int64,
+) *unstructured.Unstructured {
+
+	var configPatches []interface{}
+	var sources []string
+	annotations := map[string]interface{}{}
+
+	if bodyBytes > 0 {
+		sources = append(sources, proxyBodySizeAnnotation)
+		annotations["ingress2gateway.kubernetes.io/body-size"] = fmt.Sprintf("%d", bodyBytes)
+		configPatches = append(configPatches,
+			map[string]interface{}{
+				"applyTo": "NETWORK_FILTER",
+				"match": map[string]interface{}{
+					"context": "GATEWAY",
+					"listener": map[string]interface{}{
+						"filterChain": map[string]interface{}{
+							"filter": map[string]interface{}{
+								"name": "envoy.filters.network.http_connection_manager",
+							},
+						},
+					},
+				},
+				"patch": map[string]interface{}{
+					"operation": "MERGE",
+					"value": map[string]interface{}{
+						"typed_config": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+							"route_config": map[string]interface{}{
+								"max_direct_response_body_size_bytes": bodyBytes,
+							},
+						},
+					},
+				},
+			},
+			// Also set on the route level for request body
+			map[string]interface{}{
+				"applyTo": "HTTP_FILTER",
+				"match": map[string]interface{}{
+					"context": "GATEWAY",
+					"listener": map[string]interface{}{
+						"filterChain": map[string]interface{}{
+							"filter": map[string]interface{}{
+								"name": "envoy.filters.network.http_connection_manager",
+								"subFilter": map[string]interface{}{
+									"name": "envoy.filters.http.router",
+								},
+							},
+						},
+					},
+				},
+				"patch": map[string]interface{}{
+					"operation": "INSERT_BEFORE",
+					"value": map[string]interface{}{
+						"name": "envoy.filters.http.buffer",
+						"typed_config": map[string]interface{}{
+							"@type":             "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer",
+							"max_request_bytes": bodyBytes,
+						},
+					},
+				},
+			},
+		)
+	}
+
+	if bufferBytes > 0 {
+		// A buffer larger than the max body size would never fill up
+		if bodyBytes > 0 && bufferBytes > bodyBytes {
+			bufferBytes = bodyBytes
+		}
+		sources = append(sources, clientBodyBufferSizeAnnotation)
+		annotations["ingress2gateway.kubernetes.io/buffer-size"] = fmt.Sprintf("%d", bufferBytes)
+		configPatches = append(configPatches, map[string]interface{}{
+			"applyTo": "LISTENER",
+			"match": map[string]interface{}{
+				"context": "GATEWAY",
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"per_connection_buffer_limit_bytes": bufferBytes,
+				},
+			},
+		})
+	}
+	annotations["ingress2gateway.kubernetes.io/source"] = strings.Join(sources, ",")
+
+	filter := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":        key.Name,
+				"namespace":   key.Namespace,
+				"labels":      unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": annotations,
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": configPatches,
+			},
+		},
+	}
+
+	return filter
+}
+
+// routeBackendClusters returns the Istio cluster matches of the Service
+// backends of the route, sorted and without duplicates
+func routeBackendClusters(routeKey types.NamespacedName, routeCtx intermediate.HTTPRouteContext) []map[string]interface{} {
+	type backendKey struct {
+		service types.NamespacedName
+		port    int32
+	}
+	seen := make(map[backendKey]bool)
+	var backends []backendKey
+	for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			if !isServiceBackendRef(backendRef.BackendObjectReference) {
+				continue
+			}
+			key := backendKey{service: types.NamespacedName{Namespace: routeKey.Namespace, Name: string(backendRef.Name)}}
+			if backendRef.Namespace != nil {
+				key.service.Namespace = string(*backendRef.Namespace)
+			}
+			if backendRef.Port != nil {
+				key.port = int32(*backendRef.Port)
+			}
+			if !seen[key] {
+				seen[key] = true
+				backends = append(backends, key)
+			}
+		}
+	}
+	slices.SortFunc(backends, func(a, b backendKey) int {
+		return cmp.Or(compareNamespacedNames(a.service, b.service), cmp.Compare(a.port, b.port))
+	})
+
+	var clusters []map[string]interface{}
+	for _, backend := range backends {
+		cluster := map[string]interface{}{
+			"service": fmt.Sprintf("%s.%s.svc.cluster.local", backend.service.Name, backend.service.Namespace),
+		}
+		// Without a port, the patch applies to every port of the Service
+		if backend.port != 0 {
+			cluster["portNumber"] = int64(backend.port)
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// buildUpstreamHTTPVersionEnvoyFilter creates an EnvoyFilter setting explicit
+// HTTP/1.1 protocol options on the given clusters, so Envoy proxies to them
+// with HTTP/1.1 like proxy_http_version. Envoy has no HTTP/1.0 upstream codec.
+func (g *EnvoyFilterGenerator) buildUpstreamHTTPVersionEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	clusters []map[string]interface{},
+) *unstructured.Unstructured {
+	var configPatches []interface{}
+	for _, cluster := range clusters {
+		configPatches = append(configPatches, map[string]interface{}{
+			"applyTo": "CLUSTER",
+			"match": map[string]interface{}{
+				"context": "GATEWAY",
+				"cluster": cluster,
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"typed_extension_protocol_options": map[string]interface{}{
+						"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+							"explicit_http_config": map[string]interface{}{
+								"http_protocol_options": map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": proxyHTTPVersionAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": configPatches,
+			},
+		},
+	}
+}
+
+// buildProxyBuffersEnvoyFilter creates an EnvoyFilter setting the per
+// connection buffer limit of the backend clusters, the closest Envoy setting
+// to the nginx proxy buffers
+func (g *EnvoyFilterGenerator) buildProxyBuffersEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	clusters []map[string]interface{},
+	bufferBytes int64,
+) *unstructured.Unstructured {
+	var configPatches []interface{}
+	for _, cluster := range clusters {
+		configPatches = append(configPatches, map[string]interface{}{
+			"applyTo": "CLUSTER",
+			"match": map[string]interface{}{
+				"context": "GATEWAY",
+				"cluster": cluster,
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"per_connection_buffer_limit_bytes": bufferBytes,
+				},
+			},
+		})
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": proxyBuffersNumberAnnotation + "," + proxyBufferSizeAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": configPatches,
+			},
+		},
+	}
+}
+
+// buildNoBufferingEnvoyFilter creates an EnvoyFilter to disable proxy buffering
+func (g *EnvoyFilterGenerator) buildNoBufferingEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+) *unstructured.Unstructured {
+
+	filter := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
 			"metadata": map[string]interface{}{
 				"name":      key.Name,
 				"namespace": key.Namespace,
-				"labels": map[string]interface{}{
-					"app.kubernetes.io/managed-by": "ingress2gateway",
-					"gateway-api-migration":        "true",
-				},
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
 				"annotations": map[string]interface{}{
 					"ingress2gateway.kubernetes.io/source": "nginx.ingress.kubernetes.io/proxy-buffering",
 				},
@@ -437,23 +1527,91 @@ func (g *EnvoyFilterGenerator) buildExtAuthzEnvoyFilter(
 	key types.NamespacedName,
 	gatewayNamespace string,
 	gatewayName string,
+	hostnames []gatewayv1.Hostname,
 	authConfig *intermediate.ExternalAuthConfig,
 ) *unstructured.Unstructured {
 
-	// Build headers to pass to auth service
-	headersToUpstream := []interface{}{}
-	for _, header := range authConfig.ResponseHeaders {
-		headersToUpstream = append(headersToUpstream, map[string]interface{}{
-			"exact_match": header,
-		})
-	}
+	// Headers of the auth response set on the upstream request, overwriting
+	// the values sent by the client like the proxy_set_header of ingress-nginx
+	headersToUpstream := headerPatterns(authConfig.ResponseHeaders)
 
 	// Default headers if none specified
 	if len(headersToUpstream) == 0 {
-		headersToUpstream = []interface{}{
-			map[string]interface{}{"exact_match": "authorization"},
-			map[string]interface{}{"exact_match": "x-forwarded-user"},
-			map[string]interface{}{"exact_match": "x-forwarded-email"},
+		headersToUpstream = headerPatterns([]string{"authorization", "x-forwarded-user", "x-forwarded-email"})
+	}
+
+	// The HTTP service of ext_authz has no setting for the method of the
+	// auth check, which keeps the method of the client request, so
+	// auth-method only decides if the request body is forwarded
+	method := authConfig.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	// In-cluster auth services use the Istio outbound cluster of their Service
+	cluster, _ := authServiceCluster(authConfig.URL)
+
+	// Like ingress-nginx, tell the auth service where to send the client after
+	// signing in with auth-request-redirect
+	var headersToAuth []interface{}
+	if authConfig.RequestRedirect != "" {
+		headersToAuth = append(headersToAuth, map[string]interface{}{
+			"key": "x-auth-request-redirect", "value": authConfig.RequestRedirect,
+		})
+	}
+
+	authorizationResponse := map[string]interface{}{
+		"allowed_upstream_headers": map[string]interface{}{
+			"patterns": headersToUpstream,
+		},
+	}
+	if len(authConfig.ResponseHeaders) > 0 {
+		// A downstream copy of the auth-response-headers on allowed requests
+		authorizationResponse["allowed_client_headers_on_success"] = map[string]interface{}{
+			"patterns": headerPatterns(authConfig.ResponseHeaders),
+		}
+		// Denied requests redirected by the auth service keep the headers
+		// along with the Location of the redirect
+		if authConfig.RequestRedirect != "" {
+			authorizationResponse["allowed_client_headers"] = map[string]interface{}{
+				"patterns": headerPatterns(append([]string{"location"}, authConfig.ResponseHeaders...)),
+			}
+		}
+	}
+
+	authorizationRequest := map[string]interface{}{
+		"allowed_headers": map[string]interface{}{
+			"patterns": []interface{}{
+				map[string]interface{}{"exact": "authorization"},
+				map[string]interface{}{"exact": "cookie"},
+				map[string]interface{}{"prefix": "x-"},
+			},
+		},
+	}
+	if len(headersToAuth) > 0 {
+		authorizationRequest["headers_to_add"] = headersToAuth
+	}
+
+	typedConfig := map[string]interface{}{
+		"@type": "type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthz",
+		"http_service": map[string]interface{}{
+			"server_uri": map[string]interface{}{
+				"uri":     authConfig.URL,
+				"cluster": cluster,
+				"timeout": "5s",
+			},
+			"authorization_request":  authorizationRequest,
+			"authorization_response": authorizationResponse,
+		},
+		// Fail closed unless the route opted into auth-fail-open
+		"failure_mode_allow": authConfig.FailOpen,
+	}
+
+	// POST auth checks forward the request body so the auth service can inspect it
+	if method == "POST" {
+		typedConfig["with_request_body"] = map[string]interface{}{
+			"max_request_bytes":     int64(extAuthzMaxRequestBytes),
+			"allow_partial_message": true,
 		}
 	}
 
@@ -464,13 +1622,11 @@ func (g *EnvoyFilterGenerator) buildExtAuthzEnvoyFilter(
 			"metadata": map[string]interface{}{
 				"name":      key.Name,
 				"namespace": key.Namespace,
-				"labels": map[string]interface{}{
-					"app.kubernetes.io/managed-by": "ingress2gateway",
-					"gateway-api-migration":        "true",
-				},
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
 				"annotations": map[string]interface{}{
-					"ingress2gateway.kubernetes.io/source":   "nginx.ingress.kubernetes.io/auth-url",
-					"ingress2gateway.kubernetes.io/auth-url": authConfig.URL,
+					"ingress2gateway.kubernetes.io/source":      "nginx.ingress.kubernetes.io/auth-url",
+					"ingress2gateway.kubernetes.io/auth-url":    authConfig.URL,
+					"ingress2gateway.kubernetes.io/auth-method": method,
 				},
 			},
 			"spec": map[string]interface{}{
@@ -501,31 +1657,540 @@ func (g *EnvoyFilterGenerator) buildExtAuthzEnvoyFilter(
 						"patch": map[string]interface{}{
 							"operation": "INSERT_BEFORE",
 							"value": map[string]interface{}{
-								"name": "envoy.filters.http.ext_authz",
-								"typed_config": map[string]interface{}{
-									"@type": "type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthz",
-									"http_service": map[string]interface{}{
-										"server_uri": map[string]interface{}{
-											"uri":     authConfig.URL,
-											"cluster": "outbound|80||ext-authz-service", // This may need adjustment based on actual service
-											"timeout": "5s",
-										},
-										"authorization_request": map[string]interface{}{
-											"allowed_headers": map[string]interface{}{
-												"patterns": []interface{}{
-													map[string]interface{}{"exact": "authorization"},
-													map[string]interface{}{"exact": "cookie"},
-													map[string]interface{}{"prefix": "x-"},
-												},
-											},
-										},
-										"authorization_response": map[string]interface{}{
-											"allowed_upstream_headers": map[string]interface{}{
-												"patterns": headersToUpstream,
-											},
+								"name":         "envoy.filters.http.ext_authz",
+								"typed_config": typedConfig,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if authConfig.SigninURL != "" {
+		patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+		patches = append(patches, extAuthzSigninPatch(hostnames, authConfig))
+		_ = unstructured.SetNestedSlice(filter.Object, patches, "spec", "configPatches")
+		annotations := filter.GetAnnotations()
+		annotations["ingress2gateway.kubernetes.io/auth-signin"] = authConfig.SigninURL
+		filter.SetAnnotations(annotations)
+	}
+
+	return filter
+}
+
+// extAuthzSigninPatch returns a config patch inserting a Lua filter before
+// ext_authz that turns the 401 responses of the auth service for the route
+// hostnames into a 302 redirect to the auth-signin URL, like the error_page of
+// ingress-nginx. The original request URL is passed in the
+// auth-signin-redirect-param query parameter. The filter is before ext_authz
+// to see the responses ext_authz sends for denied requests.
+func extAuthzSigninPatch(hostnames []gatewayv1.Hostname, authConfig *intermediate.ExternalAuthConfig) map[string]interface{} {
+	redirectParam := authConfig.SigninRedirectParam
+	if redirectParam == "" {
+		redirectParam = defaultSigninRedirectParam
+	}
+	location, appendRequest := authSigninLocation(authConfig.SigninURL, redirectParam)
+
+	luaCode := fmt.Sprintf(`local signin = %s
+local append_request = %t
+%s
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  local authority = headers:get(":authority") or ""
+  if not route_matches(authority) then
+    return
+  end
+  local location = signin
+  if append_request then
+    local scheme = headers:get("x-forwarded-proto") or headers:get(":scheme") or "http"
+    local uri = string.gsub(headers:get(":path") or "/", "[^%%w%%-%%._~]", function(c)
+      return string.format("%%%%%%02X", string.byte(c))
+    end)
+    location = location .. scheme .. "://" .. authority .. uri
+  end
+  request_handle:streamInfo():dynamicMetadata():set("ingress2gateway.auth_signin", "location", location)
+end
+
+function envoy_on_response(response_handle)
+  if response_handle:headers():get(":status") ~= "401" then
+    return
+  end
+  local metadata = response_handle:streamInfo():dynamicMetadata():get("ingress2gateway.auth_signin")
+  if metadata == nil then
+    return
+  end
+  response_handle:headers():replace(":status", "302")
+  response_handle:headers():replace("location", metadata["location"])
+end
+`, strconv.Quote(location), appendRequest, luaRouteMatcher(hostnames))
+
+	return map[string]interface{}{
+		"applyTo": "HTTP_FILTER",
+		"match": map[string]interface{}{
+			"context": "GATEWAY",
+			"listener": map[string]interface{}{
+				"filterChain": map[string]interface{}{
+					"filter": map[string]interface{}{
+						"name": "envoy.filters.network.http_connection_manager",
+						"subFilter": map[string]interface{}{
+							"name": "envoy.filters.http.ext_authz",
+						},
+					},
+				},
+			},
+		},
+		"patch": map[string]interface{}{
+			"operation": "INSERT_BEFORE",
+			"value": map[string]interface{}{
+				"name": "envoy.filters.http.lua",
+				"typed_config": map[string]interface{}{
+					"@type":       "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+					"inline_code": luaCode,
+				},
+			},
+		},
+	}
+}
+
+// headerPatterns returns case-insensitive exact string matchers for the header
+// names
+func headerPatterns(headers []string) []interface{} {
+	patterns := make([]interface{}, 0, len(headers))
+	for _, header := range headers {
+		patterns = append(patterns, map[string]interface{}{
+			"exact":       strings.ToLower(header),
+			"ignore_case": true,
+		})
+	}
+	return patterns
+}
+
+// buildClientCertEnvoyFilter creates an EnvoyFilter that requires and validates
+// client certificates on the Gateway's TLS filter chains of the route hostnames.
+// A route without hostnames validates them on every filter chain of the Gateway.
+func (g *EnvoyFilterGenerator) buildClientCertEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	certConfig *intermediate.ClientCertAuthConfig,
+) *unstructured.Unstructured {
+
+	// Only "on" rejects connections without a valid client certificate;
+	// "optional" and "optional_no_ca" request but do not require one.
+	requireClientCert := certConfig.VerifyClient == "on"
+
+	// Combine the CA bundle served by SDS with the nginx verify-depth setting
+	defaultValidationContext := map[string]interface{}{}
+	if certConfig.VerifyDepth > 0 {
+		defaultValidationContext["max_verify_depth"] = int64(certConfig.VerifyDepth)
+	}
+
+	// With an error page, the TLS handshake must succeed for clients without a
+	// valid certificate so they can be redirected at the HTTP level instead
+	redirectOnFailure := redirectsToClientCertErrorPage(certConfig)
+	if redirectOnFailure {
+		requireClientCert = false
+		defaultValidationContext["trust_chain_verification"] = "ACCEPT_UNTRUSTED"
+	}
+
+	// The filter chains of the HTTPS listeners are selected by SNI, plaintext
+	// chains have none and are left unchanged
+	var filterChainMatches []interface{}
+	for _, hostname := range hostnames {
+		filterChainMatches = append(filterChainMatches, map[string]interface{}{
+			"context": "GATEWAY",
+			"listener": map[string]interface{}{
+				"filterChain": map[string]interface{}{
+					"sni": string(hostname),
+				},
+			},
+		})
+	}
+	if len(filterChainMatches) == 0 {
+		filterChainMatches = []interface{}{map[string]interface{}{"context": "GATEWAY"}}
+	}
+
+	var configPatches []interface{}
+	for _, match := range filterChainMatches {
+		configPatches = append(configPatches, map[string]interface{}{
+			"applyTo": "FILTER_CHAIN",
+			"match":   match,
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"transport_socket": map[string]interface{}{
+						"name": "envoy.transport_sockets.tls",
+						"typed_config": map[string]interface{}{
+							"@type":                      "type.googleapis.com/envoy.extensions.transport_sockets.tls.v3.DownstreamTlsContext",
+							"require_client_certificate": requireClientCert,
+							"common_tls_context": map[string]interface{}{
+								"combined_validation_context": map[string]interface{}{
+									"default_validation_context": maps.Clone(defaultValidationContext),
+									"validation_context_sds_secret_config": map[string]interface{}{
+										"name": "kubernetes://" + certConfig.Secret,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	annotations := map[string]interface{}{
+		"ingress2gateway.kubernetes.io/source":          "nginx.ingress.kubernetes.io/auth-tls-secret",
+		"ingress2gateway.kubernetes.io/auth-tls-secret": certConfig.Secret,
+	}
+	if redirectOnFailure {
+		configPatches = append(configPatches, clientCertErrorPagePatch(hostnames, certConfig))
+		annotations["ingress2gateway.kubernetes.io/auth-tls-error-page"] = certConfig.ErrorPage
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":        key.Name,
+				"namespace":   key.Namespace,
+				"labels":      unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": annotations,
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": configPatches,
+			},
+		},
+	}
+}
+
+// redirectsToClientCertErrorPage returns true if clients failing certificate
+// verification are redirected to the auth-tls-error-page. Like ingress-nginx,
+// this only applies when verification is enforced ("on") or requested ("optional").
+func redirectsToClientCertErrorPage(certConfig *intermediate.ClientCertAuthConfig) bool {
+	if certConfig.ErrorPage == "" {
+		return false
+	}
+	return certConfig.VerifyClient == "on" || certConfig.VerifyClient == "optional"
+}
+
+// clientCertErrorPagePatch returns a config patch inserting a Lua filter that
+// responds with a 302 redirect to the error page to the HTTPS requests of the
+// route hostnames failing client certificate verification. Like ingress-nginx,
+// "optional" only redirects clients presenting a certificate that fails
+// verification. Requests for the error page path itself are never redirected.
+func clientCertErrorPagePatch(hostnames []gatewayv1.Hostname, certConfig *intermediate.ClientCertAuthConfig) map[string]interface{} {
+	errorPagePath := certConfig.ErrorPage
+	if u, err := url.Parse(certConfig.ErrorPage); err == nil {
+		errorPagePath = u.Path
+	}
+
+	luaCode := fmt.Sprintf(`%s
+local error_page = %s
+local error_page_path = %s
+local require_certificate = %t
+
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  if not route_matches(headers:get(":authority")) then
+    return
+  end
+  -- Plaintext requests are not subject to client certificate verification
+  local ssl = request_handle:streamInfo():downstreamSslConnection()
+  if ssl == nil or ssl:peerCertificateValidated() then
+    return
+  end
+  if not require_certificate and not ssl:peerCertificatePresented() then
+    return
+  end
+  local path = string.gsub(headers:get(":path") or "", "%%?.*$", "")
+  if path == error_page_path then
+    return
+  end
+  request_handle:respond({[":status"] = "302", ["location"] = error_page}, "")
+end
+`,
+		luaRouteMatcher(hostnames),
+		strconv.Quote(certConfig.ErrorPage),
+		strconv.Quote(errorPagePath),
+		certConfig.VerifyClient == "on",
+	)
+
+	return map[string]interface{}{
+		"applyTo": "HTTP_FILTER",
+		"match": map[string]interface{}{
+			"context": "GATEWAY",
+			"listener": map[string]interface{}{
+				"filterChain": map[string]interface{}{
+					"filter": map[string]interface{}{
+						"name": "envoy.filters.network.http_connection_manager",
+						"subFilter": map[string]interface{}{
+							"name": "envoy.filters.http.router",
+						},
+					},
+				},
+			},
+		},
+		"patch": map[string]interface{}{
+			"operation": "INSERT_BEFORE",
+			"value": map[string]interface{}{
+				"name": "envoy.filters.http.lua",
+				"typed_config": map[string]interface{}{
+					"@type":       "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+					"inline_code": luaCode,
+				},
+			},
+		},
+	}
+}
+
+// buildCustomHTTPErrorsEnvoyFilter creates an EnvoyFilter inserting a Lua
+// filter that replaces the responses of the route with a custom-http-errors
+// status by the response of the error backend, like ingress-nginx does with
+// its default-backend. The local_reply_config of the connection manager only
+// rewrites replies generated by Envoy itself and cannot fetch a page from a
+// service, so the Lua response_map sends each status to the error backend
+// cluster with the X-Code, X-Format, X-Original-URI, X-Namespace and
+// X-Ingress-Name headers of ingress-nginx. The status is kept.
+func (g *EnvoyFilterGenerator) buildCustomHTTPErrorsEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	errorsConfig *intermediate.CustomHTTPErrorsConfig,
+) *unstructured.Unstructured {
+	cluster := fmt.Sprintf("outbound|%d||%s.%s.svc.cluster.local", errorsConfig.Port, errorsConfig.Service, errorsConfig.Namespace)
+
+	var responseMap strings.Builder
+	for _, code := range errorsConfig.Codes {
+		fmt.Fprintf(&responseMap, "  [%d] = %s,\n", code, strconv.Quote(cluster))
+	}
+
+	luaCode := fmt.Sprintf(`local response_map = {
+%s}
+%s
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  if route_matches(headers:get(":authority")) then
+    local metadata = request_handle:streamInfo():dynamicMetadata()
+    metadata:set("ingress2gateway.custom_http_errors", "uri", headers:get(":path") or "/")
+    metadata:set("ingress2gateway.custom_http_errors", "format", headers:get("accept") or "text/html")
+  end
+end
+
+function envoy_on_response(response_handle)
+  local request = response_handle:streamInfo():dynamicMetadata():get("ingress2gateway.custom_http_errors")
+  if request == nil then
+    return
+  end
+  local status = response_handle:headers():get(":status")
+  local cluster = response_map[tonumber(status)]
+  if cluster == nil then
+    return
+  end
+  local headers, body = response_handle:httpCall(cluster, {
+    [":method"] = "GET",
+    [":path"] = request["uri"],
+    [":authority"] = %s,
+    ["x-code"] = status,
+    ["x-format"] = request["format"],
+    ["x-original-uri"] = request["uri"],
+    ["x-namespace"] = %s,
+    ["x-ingress-name"] = %s,
+  }, "", 5000)
+  response_handle:headers():replace("content-type", headers["content-type"] or "text/html")
+  response_handle:body(true):setBytes(body or "")
+end
+`,
+		responseMap.String(),
+		luaRouteMatcher(hostnames),
+		strconv.Quote(fmt.Sprintf("%s.%s.svc.cluster.local", errorsConfig.Service, errorsConfig.Namespace)),
+		strconv.Quote(errorsConfig.Namespace),
+		strconv.Quote(errorsConfig.IngressName),
+	)
+
+	return g.buildLuaEnvoyFilter(key, gatewayNamespace, gatewayName, customHTTPErrorsAnnotation, luaCode)
+}
+
+// buildProxyRedirectEnvoyFilter creates an EnvoyFilter inserting a Lua filter
+// that rewrites the Location header of the responses of the route like nginx
+// proxy_redirect: a literal From prefix is replaced by To, while a From
+// pattern replaces the whole Location by To, with ${1} to ${9} expanded to
+// the captures of the pattern. The $host, $http_host and $scheme variables are
+// recorded from the request.
+func (g *EnvoyFilterGenerator) buildProxyRedirectEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	redirect *intermediate.ProxyRedirectConfig,
+) *unstructured.Unstructured {
+	luaCode := fmt.Sprintf(`local from = %s
+local to = %s
+local regex = %t
+%s
+function envoy_on_request(request_handle)
+  local headers = request_handle:headers()
+  local authority = headers:get(":authority") or ""
+  if route_matches(authority) then
+    local metadata = request_handle:streamInfo():dynamicMetadata()
+    metadata:set("ingress2gateway.proxy_redirect", "http_host", authority)
+    metadata:set("ingress2gateway.proxy_redirect", "host", (string.gsub(authority, ":%%d+$", "")))
+    metadata:set("ingress2gateway.proxy_redirect", "scheme", headers:get("x-forwarded-proto") or headers:get(":scheme") or "http")
+  end
+end
+
+local function expand(value, variables)
+  return (string.gsub(value, "%%${([%%w_]+)}", function(name)
+    return variables[name] or ""
+  end))
+end
+
+function envoy_on_response(response_handle)
+  local variables = response_handle:streamInfo():dynamicMetadata():get("ingress2gateway.proxy_redirect")
+  if variables == nil then
+    return
+  end
+  local location = response_handle:headers():get("location")
+  if location == nil then
+    return
+  end
+  if regex then
+    local match = {string.find(location, from)}
+    if match[1] == nil then
+      return
+    end
+    local captures = {}
+    for name, value in pairs(variables) do
+      captures[name] = value
+    end
+    for i = 3, #match do
+      captures[tostring(i - 2)] = match[i]
+    end
+    response_handle:headers():replace("location", expand(to, captures))
+    return
+  end
+  local prefix = expand(from, variables)
+  if string.sub(location, 1, #prefix) == prefix then
+    response_handle:headers():replace("location", expand(to, variables) .. string.sub(location, #prefix + 1))
+  end
+end
+`,
+		strconv.Quote(redirect.From),
+		strconv.Quote(redirect.To),
+		redirect.Regex,
+		luaRouteMatcher(hostnames),
+	)
+
+	return g.buildLuaEnvoyFilter(key, gatewayNamespace, gatewayName, proxyRedirectFromAnnotation, luaCode)
+}
+
+// luaRouteMatcher returns the Lua code of a route_matches(authority) function
+// matching the requests for the route hostnames. Lua filters are shared by
+// the Gateway's connection manager, so they must only act on the requests of
+// their route. A route without hostnames matches all requests.
+func luaRouteMatcher(hostnames []gatewayv1.Hostname) string {
+	var exactHosts, wildcardSuffixes strings.Builder
+	for _, hostname := range hostnames {
+		host := strings.ToLower(string(hostname))
+		if suffix, ok := strings.CutPrefix(host, "*"); ok {
+			fmt.Fprintf(&wildcardSuffixes, "  %s,\n", strconv.Quote(suffix))
+			continue
+		}
+		fmt.Fprintf(&exactHosts, "  [%s] = true,\n", strconv.Quote(host))
+	}
+
+	return fmt.Sprintf(`local match_all_hosts = %t
+local exact_hosts = {
+%s}
+local wildcard_suffixes = {
+%s}
+
+local function route_matches(authority)
+  if match_all_hosts then
+    return true
+  end
+  local host = string.lower(string.gsub(authority or "", ":%%d+$", ""))
+  if exact_hosts[host] then
+    return true
+  end
+  for _, suffix in ipairs(wildcard_suffixes) do
+    if #host > #suffix and string.sub(host, -#suffix) == suffix then
+      return true
+    end
+  end
+  return false
+end
+`,
+		len(hostnames) == 0,
+		exactHosts.String(),
+		wildcardSuffixes.String(),
+	)
+}
+
+// buildLuaEnvoyFilter creates an EnvoyFilter inserting a Lua filter with the
+// given code before the router of the Gateway's connection manager
+func (g *EnvoyFilterGenerator) buildLuaEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	source string,
+	luaCode string,
+) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": source,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": []interface{}{
+					map[string]interface{}{
+						"applyTo": "HTTP_FILTER",
+						"match": map[string]interface{}{
+							"context": "GATEWAY",
+							"listener": map[string]interface{}{
+								"filterChain": map[string]interface{}{
+									"filter": map[string]interface{}{
+										"name": "envoy.filters.network.http_connection_manager",
+										"subFilter": map[string]interface{}{
+											"name": "envoy.filters.http.router",
 										},
 									},
-									"failure_mode_allow": false,
+								},
+							},
+						},
+						"patch": map[string]interface{}{
+							"operation": "INSERT_BEFORE",
+							"value": map[string]interface{}{
+								"name": "envoy.filters.http.lua",
+								"typed_config": map[string]interface{}{
+									"@type":       "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua",
+									"inline_code": luaCode,
 								},
 							},
 						},
@@ -534,8 +2199,99 @@ func (g *EnvoyFilterGenerator) buildExtAuthzEnvoyFilter(
 			},
 		},
 	}
+}
 
-	return filter
+// buildCORSEnvoyFilter creates an EnvoyFilter setting the CORS policy of the
+// virtual hosts of the route hostnames, handled by the CORS filter of the
+// Gateway. Exact origins use an exact matcher and regex origins a safe_regex
+// matcher in allow_origin_string_match. A route without hostnames sets the
+// policy of all the virtual hosts of the Gateway.
+func (g *EnvoyFilterGenerator) buildCORSEnvoyFilter(
+	key types.NamespacedName,
+	gatewayNamespace string,
+	gatewayName string,
+	hostnames []gatewayv1.Hostname,
+	cors *intermediate.CORSConfig,
+) *unstructured.Unstructured {
+	origins := make([]interface{}, 0, len(cors.AllowOrigins))
+	for _, origin := range cors.AllowOrigins {
+		if origin.Regex {
+			origins = append(origins, map[string]interface{}{
+				"safe_regex": map[string]interface{}{"regex": origin.Value},
+			})
+			continue
+		}
+		origins = append(origins, map[string]interface{}{"exact": origin.Value})
+	}
+
+	corsPolicy := map[string]interface{}{
+		"@type":                     "type.googleapis.com/envoy.extensions.filters.http.cors.v3.CorsPolicy",
+		"allow_origin_string_match": origins,
+		"allow_methods":             cors.AllowMethods,
+		"allow_headers":             cors.AllowHeaders,
+		"allow_credentials":         cors.AllowCredentials,
+		"max_age":                   strconv.Itoa(cors.MaxAge),
+	}
+	if cors.ExposeHeaders != "" {
+		corsPolicy["expose_headers"] = cors.ExposeHeaders
+	}
+
+	vhostMatches := []map[string]interface{}{{}}
+	if len(hostnames) > 0 {
+		vhostMatches = nil
+		for _, hostname := range hostnames {
+			vhostMatches = append(vhostMatches, map[string]interface{}{"domainName": string(hostname)})
+		}
+	}
+
+	patches := make([]interface{}, 0, len(vhostMatches))
+	for _, vhostMatch := range vhostMatches {
+		routeConfiguration := map[string]interface{}{}
+		if len(vhostMatch) > 0 {
+			routeConfiguration["vhost"] = vhostMatch
+		}
+		patches = append(patches, map[string]interface{}{
+			"applyTo": "VIRTUAL_HOST",
+			"match": map[string]interface{}{
+				"context":            "GATEWAY",
+				"routeConfiguration": routeConfiguration,
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"typed_per_filter_config": map[string]interface{}{
+						"envoy.filters.http.cors": corsPolicy,
+					},
+				},
+			},
+		})
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata": map[string]interface{}{
+				"name":      key.Name,
+				"namespace": key.Namespace,
+				"labels":    unstructuredLabels(g.GatewayConfig.resourceLabels()),
+				"annotations": map[string]interface{}{
+					"ingress2gateway.kubernetes.io/source": enableCORSAnnotation,
+				},
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"kind":      "Gateway",
+						"group":     "gateway.networking.k8s.io",
+						"name":      gatewayName,
+						"namespace": gatewayNamespace,
+					},
+				},
+				"configPatches": patches,
+			},
+		},
+	}
 }
 
 // GetEnvoyFilterGVK returns the GroupVersionKind for EnvoyFilter
@@ -0,0 +1,775 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// httpFilterChain returns the HTTP filters of the Gateway after applying the
+// HTTP_FILTER patches of the EnvoyFilters like Istio: EnvoyFilters by
+// increasing priority, then in the given order, and the patches of each one in
+// order, starting from a chain with the router only
+func httpFilterChain(filters ...*unstructured.Unstructured) []string {
+	filters = slices.Clone(filters)
+	slices.SortStableFunc(filters, func(a, b *unstructured.Unstructured) int {
+		priorityA, _, _ := unstructured.NestedInt64(a.Object, "spec", "priority")
+		priorityB, _, _ := unstructured.NestedInt64(b.Object, "spec", "priority")
+		return int(priorityA - priorityB)
+	})
+
+	chain := []string{"envoy.filters.http.router"}
+	for _, filter := range filters {
+		patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+		for _, patch := range patches {
+			patchMap := patch.(map[string]interface{})
+			if applyTo, _, _ := unstructured.NestedString(patchMap, "applyTo"); applyTo != "HTTP_FILTER" {
+				continue
+			}
+			subFilter, _, _ := unstructured.NestedString(patchMap, "match", "listener", "filterChain", "filter", "subFilter", "name")
+			operation, _, _ := unstructured.NestedString(patchMap, "patch", "operation")
+			name, _, _ := unstructured.NestedString(patchMap, "patch", "value", "name")
+			i := slices.Index(chain, subFilter)
+			switch {
+			case operation == "INSERT_FIRST":
+				chain = slices.Insert(chain, 0, name)
+			case operation == "INSERT_BEFORE" && i >= 0:
+				chain = slices.Insert(chain, i, name)
+			case operation == "INSERT_AFTER" && i >= 0:
+				chain = slices.Insert(chain, i+1, name)
+			}
+		}
+	}
+	return chain
+}
+
+func TestGenerateEnvoyFilters_AuthOrdering(t *testing.T) {
+	externalAuth := &intermediate.ExternalAuthConfig{
+		URL:    "https://auth.example.com/verify",
+		Method: "GET",
+	}
+	testCases := []struct {
+		name             string
+		nginxIR          *intermediate.IngressNginxHTTPRouteIR
+		expectPriorities bool
+		expectedChain    []string
+	}{
+		{
+			name: "client cert and external auth on same route",
+			nginxIR: &intermediate.IngressNginxHTTPRouteIR{
+				ClientCertAuth: &intermediate.ClientCertAuthConfig{
+					Secret:       "default/ca-secret",
+					VerifyClient: "on",
+					VerifyDepth:  2,
+				},
+				ExternalAuth: externalAuth,
+			},
+			// The client certificate is validated during the TLS handshake
+			expectedChain: []string{"envoy.filters.http.ext_authz", "envoy.filters.http.router"},
+		},
+		{
+			name: "client cert error page and external auth on same route",
+			nginxIR: &intermediate.IngressNginxHTTPRouteIR{
+				ClientCertAuth: &intermediate.ClientCertAuthConfig{
+					Secret:       "default/ca-secret",
+					VerifyClient: "on",
+					ErrorPage:    "https://example.com/cert-error",
+				},
+				ExternalAuth: externalAuth,
+			},
+			expectPriorities: true,
+			expectedChain:    []string{"envoy.filters.http.lua", "envoy.filters.http.ext_authz", "envoy.filters.http.router"},
+		},
+		{
+			name: "external auth only",
+			nginxIR: &intermediate.IngressNginxHTTPRouteIR{
+				ExternalAuth: externalAuth,
+			},
+			expectedChain: []string{"envoy.filters.http.ext_authz", "envoy.filters.http.router"},
+		},
+		{
+			name: "client cert only",
+			nginxIR: &intermediate.IngressNginxHTTPRouteIR{
+				ClientCertAuth: &intermediate.ClientCertAuthConfig{
+					Secret:       "default/ca-secret",
+					VerifyClient: "optional",
+				},
+			},
+			expectedChain: []string{"envoy.filters.http.router"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routeKey := types.NamespacedName{Namespace: "default", Name: "my-route"}
+			ir := intermediate.IR{
+				HTTPRoutes: map[types.NamespacedName]intermediate.HTTPRouteContext{
+					routeKey: {
+						HTTPRoute: gatewayv1.HTTPRoute{
+							ObjectMeta: metav1.ObjectMeta{Name: routeKey.Name, Namespace: routeKey.Namespace},
+						},
+						ProviderSpecificIR: intermediate.ProviderSpecificHTTPRouteIR{
+							IngressNginx: tc.nginxIR,
+						},
+					},
+				},
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filters := generator.GenerateEnvoyFilters(ir)
+
+			// Apply ext_authz first unless the priorities order the filters
+			var routeFilters []*unstructured.Unstructured
+			for _, suffix := range []string{"extauthz", "clientcert"} {
+				filter := filters[types.NamespacedName{Namespace: "default", Name: "default-my-route-" + suffix}]
+				if filter == nil {
+					continue
+				}
+				routeFilters = append(routeFilters, filter)
+				if _, found, _ := unstructured.NestedInt64(filter.Object, "spec", "priority"); found != tc.expectPriorities {
+					t.Errorf("expected a priority on %s: %v, got: %v", filter.GetName(), tc.expectPriorities, found)
+				}
+			}
+
+			if chain := httpFilterChain(routeFilters...); !reflect.DeepEqual(tc.expectedChain, chain) {
+				t.Errorf("expected HTTP filters %v, got %v", tc.expectedChain, chain)
+			}
+		})
+	}
+}
+
+func TestGenerateEnvoyFilters_ExtAuthzBeforeRateLimit(t *testing.T) {
+	routeKey := types.NamespacedName{Namespace: "default", Name: "my-route"}
+	ir := intermediate.IR{
+		HTTPRoutes: map[types.NamespacedName]intermediate.HTTPRouteContext{
+			routeKey: {
+				HTTPRoute: gatewayv1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{Name: routeKey.Name, Namespace: routeKey.Namespace},
+				},
+				ProviderSpecificIR: intermediate.ProviderSpecificHTTPRouteIR{
+					IngressNginx: &intermediate.IngressNginxHTTPRouteIR{
+						ExternalAuth: &intermediate.ExternalAuthConfig{
+							URL:    "https://auth.example.com/verify",
+							Method: "GET",
+						},
+						RateLimitRPS:  10,
+						ProxyBodySize: "1m",
+					},
+				},
+			},
+		},
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace", RateLimitBurstMultiplier: 1}}
+	filters := generator.GenerateEnvoyFilters(ir)
+
+	// Apply the EnvoyFilters in the reverse order, the priorities must order them
+	var routeFilters []*unstructured.Unstructured
+	for _, suffix := range []string{"bodysize", "ratelimit", "extauthz"} {
+		filter := filters[types.NamespacedName{Namespace: "default", Name: "default-my-route-" + suffix}]
+		if filter == nil {
+			t.Fatalf("expected a %s EnvoyFilter, got %d filters", suffix, len(filters))
+		}
+		routeFilters = append(routeFilters, filter)
+	}
+
+	// ext_authz runs before local_ratelimit, which runs before the body buffer
+	expectedChain := []string{
+		"envoy.filters.http.ext_authz",
+		"envoy.filters.http.local_ratelimit",
+		"envoy.filters.http.buffer",
+		"envoy.filters.http.router",
+	}
+	if chain := httpFilterChain(routeFilters...); !reflect.DeepEqual(expectedChain, chain) {
+		t.Errorf("expected HTTP filters %v, got %v", expectedChain, chain)
+	}
+
+	// The priorities don't depend on the generation
+	again := generator.GenerateEnvoyFilters(ir)
+	for key, filter := range filters {
+		if !reflect.DeepEqual(filter, again[key]) {
+			t.Errorf("expected the %s EnvoyFilter to be generated identically", key)
+		}
+	}
+}
+
+func TestBuildExtAuthzEnvoyFilter_Method(t *testing.T) {
+	testCases := []struct {
+		name               string
+		method             string
+		expectsRequestBody bool
+	}{
+		{
+			name:   "GET does not forward request body",
+			method: "GET",
+		},
+		{
+			name:               "POST forwards request body",
+			method:             "POST",
+			expectsRequestBody: true,
+		},
+		{
+			name: "empty method defaults to GET",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.buildExtAuthzEnvoyFilter(
+				types.NamespacedName{Namespace: "default", Name: "default-my-route-extauthz"},
+				"default",
+				"default-gateway",
+				nil,
+				&intermediate.ExternalAuthConfig{URL: "https://auth.example.com/verify", Method: tc.method},
+			)
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			if len(patches) != 1 {
+				t.Fatalf("expected 1 config patch, got %d", len(patches))
+			}
+			typedConfig, found, err := unstructured.NestedMap(patches[0].(map[string]interface{}), "patch", "value", "typed_config")
+			if err != nil || !found {
+				t.Fatalf("expected typed_config, found: %v, err: %v", found, err)
+			}
+
+			// ext_authz has no setting for the method of the auth request
+			headersToAdd, _, _ := unstructured.NestedSlice(typedConfig, "http_service", "authorization_request", "headers_to_add")
+			if len(headersToAdd) > 0 {
+				t.Errorf("expected no headers_to_add, got %v", headersToAdd)
+			}
+
+			_, hasBody := typedConfig["with_request_body"]
+			if hasBody != tc.expectsRequestBody {
+				t.Errorf("expected with_request_body: %v, got: %v", tc.expectsRequestBody, hasBody)
+			}
+			if tc.expectsRequestBody {
+				maxBytes, _, _ := unstructured.NestedInt64(typedConfig, "with_request_body", "max_request_bytes")
+				if maxBytes != extAuthzMaxRequestBytes {
+					t.Errorf("expected max_request_bytes %d, got %d", extAuthzMaxRequestBytes, maxBytes)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRateLimitEnvoyFilter_BurstMultiplier(t *testing.T) {
+	testCases := []struct {
+		name           string
+		multiplier     int
+		burst          int
+		expectedTokens int64
+	}{
+		{
+			name:           "default multiplier",
+			expectedTokens: 50,
+		},
+		{
+			name:           "configured multiplier",
+			multiplier:     2,
+			expectedTokens: 20,
+		},
+		{
+			name:           "explicit burst ignores multiplier",
+			multiplier:     2,
+			burst:          15,
+			expectedTokens: 15,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{
+				Mode:                     "per-namespace",
+				RateLimitBurstMultiplier: tc.multiplier,
+			}}
+			filter := generator.buildRateLimitEnvoyFilter(
+				types.NamespacedName{Namespace: "default", Name: "default-my-route-ratelimit"},
+				"default-gateway",
+				"default-gateway",
+				10,
+				tc.burst,
+			)
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			if len(patches) != 1 {
+				t.Fatalf("expected 1 config patch, got %d", len(patches))
+			}
+			tokenBucket, found, err := unstructured.NestedMap(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "token_bucket")
+			if err != nil || !found {
+				t.Fatalf("expected token_bucket, found: %v, err: %v", found, err)
+			}
+			if tokenBucket["max_tokens"] != tc.expectedTokens {
+				t.Errorf("expected max_tokens %d, got %v", tc.expectedTokens, tokenBucket["max_tokens"])
+			}
+			if tokenBucket["tokens_per_fill"] != int64(10) {
+				t.Errorf("expected tokens_per_fill 10, got %v", tokenBucket["tokens_per_fill"])
+			}
+		})
+	}
+}
+
+func TestGenerateEnvoyFilters_Merge(t *testing.T) {
+	routeKey := types.NamespacedName{Namespace: "default", Name: "my-route"}
+	ir := intermediate.IR{
+		HTTPRoutes: map[types.NamespacedName]intermediate.HTTPRouteContext{
+			routeKey: {
+				HTTPRoute: gatewayv1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{Name: routeKey.Name, Namespace: routeKey.Namespace},
+				},
+				ProviderSpecificIR: intermediate.ProviderSpecificHTTPRouteIR{
+					IngressNginx: &intermediate.IngressNginxHTTPRouteIR{
+						RateLimitRPS:  10,
+						ProxyBodySize: "8m",
+						ExternalAuth: &intermediate.ExternalAuthConfig{
+							URL:    "https://auth.example.com/verify",
+							Method: "GET",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		merge           bool
+		expectedFilters int
+	}{
+		{
+			name:            "split by default",
+			expectedFilters: 3,
+		},
+		{
+			name:            "merged into one EnvoyFilter",
+			merge:           true,
+			expectedFilters: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace", MergeEnvoyFilters: tc.merge}}
+			filters := generator.GenerateEnvoyFilters(ir)
+			if len(filters) != tc.expectedFilters {
+				t.Fatalf("expected %d EnvoyFilters, got %d", tc.expectedFilters, len(filters))
+			}
+			if !tc.merge {
+				return
+			}
+
+			merged := filters[types.NamespacedName{Namespace: "default", Name: "default-my-route-envoyfilter"}]
+			if merged == nil {
+				t.Fatalf("expected merged EnvoyFilter default/default-my-route-envoyfilter, got %v", filters)
+			}
+
+			// The patches of the merged EnvoyFilter are applied in order
+			expectedChain := []string{
+				"envoy.filters.http.ext_authz",
+				"envoy.filters.http.local_ratelimit",
+				"envoy.filters.http.buffer",
+				"envoy.filters.http.router",
+			}
+			if chain := httpFilterChain(merged); !reflect.DeepEqual(expectedChain, chain) {
+				t.Errorf("expected HTTP filters %v, got %v", expectedChain, chain)
+			}
+		})
+	}
+}
+
+func TestBuildExtAuthzEnvoyFilter_FailureMode(t *testing.T) {
+	testCases := []struct {
+		name             string
+		failOpen         string
+		expectFailOpen   bool
+		expectParseError bool
+	}{
+		{
+			name: "fail-closed by default",
+		},
+		{
+			name:           "auth-fail-open flips failure_mode_allow",
+			failOpen:       "true",
+			expectFailOpen: true,
+		},
+		{
+			name:     "explicit fail-closed",
+			failOpen: "false",
+		},
+		{
+			name:             "invalid value causes error",
+			failOpen:         "sometimes",
+			expectParseError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotations := map[string]string{authURLAnnotation: "https://auth.example.com/verify"}
+			if tc.failOpen != "" {
+				annotations[authFailOpenAnnotation] = tc.failOpen
+			}
+			ing := headersTestIngress(annotations)
+
+			authConfig, errs := parseExternalAuthConfig(&ing)
+			if (len(errs) > 0) != tc.expectParseError {
+				t.Fatalf("expected parse error: %v, got: %v", tc.expectParseError, errs)
+			}
+			if tc.expectParseError {
+				return
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.buildExtAuthzEnvoyFilter(
+				types.NamespacedName{Namespace: "default", Name: "default-my-route-extauthz"},
+				"default",
+				"default-gateway",
+				nil,
+				authConfig,
+			)
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			failOpen, found, err := unstructured.NestedBool(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "failure_mode_allow")
+			if err != nil || !found {
+				t.Fatalf("expected failure_mode_allow, found: %v, err: %v", found, err)
+			}
+			if failOpen != tc.expectFailOpen {
+				t.Errorf("expected failure_mode_allow %v, got %v", tc.expectFailOpen, failOpen)
+			}
+		})
+	}
+}
+
+func TestBuildClientCertEnvoyFilter_ErrorPage(t *testing.T) {
+	testCases := []struct {
+		name             string
+		verifyClient     string
+		errorPage        string
+		expectRequire    bool
+		expectRedirect   bool
+		expectWarning    bool
+		expectParseError bool
+	}{
+		{
+			name:          "no error page",
+			expectRequire: true,
+		},
+		{
+			name:           "error page on the same host",
+			errorPage:      "/cert-error",
+			expectRedirect: true,
+		},
+		{
+			name:           "external error page",
+			errorPage:      "https://errors.example.com/cert",
+			expectRedirect: true,
+			expectWarning:  true,
+		},
+		{
+			name:           "optional verification only redirects presented certificates",
+			verifyClient:   "optional",
+			errorPage:      "/cert-error",
+			expectRedirect: true,
+		},
+		{
+			name:          "error page ignored without verification",
+			verifyClient:  "off",
+			errorPage:     "/cert-error",
+			expectWarning: true,
+		},
+		{
+			name:             "invalid error page causes error",
+			errorPage:        "ftp://errors.example.com/cert",
+			expectParseError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			annotations := map[string]string{authTLSSecretAnnotation: "default/ca-secret"}
+			if tc.verifyClient != "" {
+				annotations[authTLSVerifyClientAnnotation] = tc.verifyClient
+			}
+			if tc.errorPage != "" {
+				annotations[authTLSErrorPageAnnotation] = tc.errorPage
+			}
+			ing := headersTestIngress(annotations)
+
+			certConfig, errs := parseClientCertAuthConfig(&ing)
+			if (len(errs) > 0) != tc.expectParseError {
+				t.Fatalf("expected parse error: %v, got: %v", tc.expectParseError, errs)
+			}
+			if tc.expectParseError {
+				return
+			}
+
+			if certConfig.ErrorPage != "" {
+				notifyClientCertErrorPage(&ing, certConfig)
+			}
+			gotWarning := false
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "auth-tls-error-page") {
+					gotWarning = true
+				}
+			}
+			if gotWarning != tc.expectWarning {
+				t.Errorf("expected error page warning: %v, got: %v", tc.expectWarning, gotWarning)
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.buildClientCertEnvoyFilter(
+				types.NamespacedName{Namespace: "default", Name: "default-my-route-clientcert"},
+				"default",
+				"default-gateway",
+				[]gatewayv1.Hostname{"example.com"},
+				certConfig,
+			)
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			requireClientCert, _, _ := unstructured.NestedBool(patches[0].(map[string]interface{}),
+				"patch", "value", "transport_socket", "typed_config", "require_client_certificate")
+			if requireClientCert != tc.expectRequire {
+				t.Errorf("expected require_client_certificate %v, got %v", tc.expectRequire, requireClientCert)
+			}
+
+			if !tc.expectRedirect {
+				if len(patches) != 1 {
+					t.Errorf("expected 1 config patch, got %d", len(patches))
+				}
+				return
+			}
+
+			if len(patches) != 2 {
+				t.Fatalf("expected 2 config patches, got %d", len(patches))
+			}
+			trustChain, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}),
+				"patch", "value", "transport_socket", "typed_config", "common_tls_context",
+				"combined_validation_context", "default_validation_context", "trust_chain_verification")
+			if trustChain != "ACCEPT_UNTRUSTED" {
+				t.Errorf("expected trust_chain_verification ACCEPT_UNTRUSTED, got %q", trustChain)
+			}
+			luaCode, _, _ := unstructured.NestedString(patches[1].(map[string]interface{}), "patch", "value", "typed_config", "inline_code")
+			errorPageURL, _ := url.Parse(tc.errorPage)
+			for _, expected := range []string{
+				`local error_page = "` + tc.errorPage + `"`,
+				`local error_page_path = "` + errorPageURL.Path + `"`,
+				fmt.Sprintf("local require_certificate = %t", tc.verifyClient != "optional"),
+				`["example.com"] = true`,
+				"if not route_matches(headers:get(\":authority\")) then",
+				"if ssl == nil or ssl:peerCertificateValidated() then",
+				`request_handle:respond({[":status"] = "302", ["location"] = error_page}, "")`,
+			} {
+				if !strings.Contains(luaCode, expected) {
+					t.Errorf("expected the Lua code to contain %q, got:\n%s", expected, luaCode)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildClientCertEnvoyFilter_FilterChains(t *testing.T) {
+	testCases := []struct {
+		name            string
+		hostnames       []gatewayv1.Hostname
+		expectedMatches []interface{}
+	}{
+		{
+			name:      "TLS filter chains of the route hostnames",
+			hostnames: []gatewayv1.Hostname{"example.com", "*.example.org"},
+			expectedMatches: []interface{}{
+				map[string]interface{}{
+					"context":  "GATEWAY",
+					"listener": map[string]interface{}{"filterChain": map[string]interface{}{"sni": "example.com"}},
+				},
+				map[string]interface{}{
+					"context":  "GATEWAY",
+					"listener": map[string]interface{}{"filterChain": map[string]interface{}{"sni": "*.example.org"}},
+				},
+			},
+		},
+		{
+			name:            "every filter chain without hostnames",
+			expectedMatches: []interface{}{map[string]interface{}{"context": "GATEWAY"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.buildClientCertEnvoyFilter(
+				types.NamespacedName{Namespace: "default", Name: "default-my-route-clientcert"},
+				"default",
+				"default-gateway",
+				tc.hostnames,
+				&intermediate.ClientCertAuthConfig{Secret: "default/ca-secret", VerifyClient: "on"},
+			)
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			var matches []interface{}
+			for _, patch := range patches {
+				patch := patch.(map[string]interface{})
+				if patch["applyTo"] != "FILTER_CHAIN" {
+					t.Errorf("unexpected %v config patch", patch["applyTo"])
+				}
+				matches = append(matches, patch["match"])
+			}
+			if diff := cmp.Diff(tc.expectedMatches, matches); diff != "" {
+				t.Errorf("unexpected filter chain matches (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientCertAuthFeature_HostlessWarning(t *testing.T) {
+	testCases := []struct {
+		name          string
+		host          string
+		expectWarning bool
+	}{
+		{name: "ingress with a host", host: "example.com"},
+		{name: "ingress without a host", expectWarning: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingress := headersTestIngress(map[string]string{authTLSSecretAnnotation: "default/ca-secret"})
+			ingress.Spec.Rules[0].Host = tc.host
+			ingresses := []networkingv1.Ingress{ingress}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := clientCertAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var warned bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "every TLS filter chain") {
+					warned = true
+				}
+			}
+			if warned != tc.expectWarning {
+				t.Errorf("expected Gateway-wide client cert Warning: %v, got: %v", tc.expectWarning, warned)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_EnvoyFilters(t *testing.T) {
+	testCases := []struct {
+		name             string
+		gatewayClass     string
+		emitEnvoyFilters bool
+		expectedFilters  []string
+		expectedWarning  string
+	}{
+		{
+			name:             "istio class",
+			gatewayClass:     "istio",
+			emitEnvoyFilters: true,
+			expectedFilters: []string{
+				"default-" + common.RouteName("test-ingress", "example.com") + "-ratelimit",
+				"default-" + common.RouteName("test-ingress", "example.com") + "-bodysize",
+				"default-" + common.RouteName("test-ingress", "example.com") + "-cors",
+			},
+		},
+		{
+			name:            "istio class without emit-envoyfilters",
+			gatewayClass:    "istio",
+			expectedWarning: "Set --ingress-nginx-emit-envoyfilters to generate it",
+		},
+		{
+			name:             "envoy gateway class",
+			gatewayClass:     "envoy-gateway",
+			emitEnvoyFilters: true,
+			expectedWarning:  "is not generated for the Envoy Gateway class",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {GatewayClassFlag: tc.gatewayClass, EmitEnvoyFiltersFlag: strconv.FormatBool(tc.emitEnvoyFilters)},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(map[string]string{
+				limitRPSAnnotation:      "10",
+				proxyBodySizeAnnotation: "10m",
+				enableCORSAnnotation:    "true",
+			})
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var filters []string
+			for _, extension := range gatewayResources.GatewayExtensions {
+				if extension.GetKind() == "EnvoyFilter" {
+					filters = append(filters, extension.GetName())
+				}
+			}
+			if !slices.Equal(filters, slices.Sorted(slices.Values(tc.expectedFilters))) {
+				t.Errorf("expected EnvoyFilters %v in the GatewayExtensions, got %v", tc.expectedFilters, filters)
+			}
+
+			if tc.expectedWarning == "" {
+				return
+			}
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, tc.expectedWarning) {
+					warnings++
+				}
+			}
+			if warnings != 3 {
+				t.Errorf("expected 3 warnings for the EnvoyFilters not generated, got %d", warnings)
+			}
+		})
+	}
+}
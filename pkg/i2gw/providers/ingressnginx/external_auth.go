@@ -18,6 +18,9 @@ package ingressnginx
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
@@ -29,70 +32,119 @@ import (
 
 const (
 	// External authentication annotations
-	authURLAnnotation             = "nginx.ingress.kubernetes.io/auth-url"
-	authMethodAnnotation          = "nginx.ingress.kubernetes.io/auth-method"
-	authSigninAnnotation          = "nginx.ingress.kubernetes.io/auth-signin"
-	authResponseHeadersAnnotation = "nginx.ingress.kubernetes.io/auth-response-headers"
-	authRequestRedirectAnnotation = "nginx.ingress.kubernetes.io/auth-request-redirect"
-	authCacheKeyAnnotation        = "nginx.ingress.kubernetes.io/auth-cache-key"
-	authCacheDurationAnnotation   = "nginx.ingress.kubernetes.io/auth-cache-duration"
-	authSnippetAnnotation         = "nginx.ingress.kubernetes.io/auth-snippet"
+	authURLAnnotation                 = "nginx.ingress.kubernetes.io/auth-url"
+	authMethodAnnotation              = "nginx.ingress.kubernetes.io/auth-method"
+	authSigninAnnotation              = "nginx.ingress.kubernetes.io/auth-signin"
+	authSigninRedirectParamAnnotation = "nginx.ingress.kubernetes.io/auth-signin-redirect-param"
+	authResponseHeadersAnnotation     = "nginx.ingress.kubernetes.io/auth-response-headers"
+	authRequestRedirectAnnotation     = "nginx.ingress.kubernetes.io/auth-request-redirect"
+	authCacheKeyAnnotation            = "nginx.ingress.kubernetes.io/auth-cache-key"
+	authCacheDurationAnnotation       = "nginx.ingress.kubernetes.io/auth-cache-duration"
+	authSnippetAnnotation             = "nginx.ingress.kubernetes.io/auth-snippet"
+
+	// authFailOpenAnnotation lets requests through when the auth service is
+	// unreachable. ingress-nginx has no equivalent, so this is an
+	// ingress2gateway convention; the default remains fail-closed.
+	authFailOpenAnnotation = "ingress2gateway.kubernetes.io/auth-fail-open"
+
+	// authJWTIssuerAnnotation marks the auth-url as a JWT validation endpoint
+	// for tokens of this issuer, so the Gateway validates the JWTs itself
+	// with a RequestAuthentication instead of calling the auth service.
+	// authJWKSURIAnnotation optionally sets the URL of the issuer's keys.
+	// Both are ingress2gateway conventions.
+	authJWTIssuerAnnotation = "ingress2gateway.kubernetes.io/auth-jwt-issuer"
+	authJWKSURIAnnotation   = "ingress2gateway.kubernetes.io/auth-jwt-jwks-uri"
+
+	// externalAuthPlaceholderCluster is the ext_authz cluster of auth services
+	// outside of the cluster, which must be replaced by the cluster of a
+	// ServiceEntry for the auth host
+	externalAuthPlaceholderCluster = "outbound|80||ext-authz-service"
+
+	// defaultSigninRedirectParam is the query parameter of the auth-signin
+	// URL carrying the original request URL in ingress-nginx
+	defaultSigninRedirectParam = "rd"
 )
 
+// signinRedirectParamRegex matches the query parameter names
+// auth-signin-redirect-param can set
+var signinRedirectParamRegex = regexp.MustCompile(`^[A-Za-z0-9_.~-]+$`)
+
 // externalAuthFeature parses external authentication annotations and stores them in the IR.
 // These settings map to Gateway API SecurityPolicy.extAuth (implementation-specific).
 func externalAuthFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
 	var errs field.ErrorList
 
 	for _, ing := range ingresses {
-		config := parseExternalAuthConfig(&ing)
-		if config == nil {
+		config, parseErrs := parseExternalAuthConfig(&ing)
+		if len(parseErrs) > 0 {
+			errs = append(errs, parseErrs...)
 			continue
 		}
-
-		// Get or create the ingress-nginx IR for this route
-		key := types.NamespacedName{
-			Namespace: ing.Namespace,
-			Name:      ing.Name,
+		if config == nil {
+			continue
 		}
 
-		routeKey := findHTTPRouteKey(ir, key)
-		if routeKey == (types.NamespacedName{}) {
+		// Store the config on every route built from this ingress
+		updated := updateIngressRoutes(ir, &ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.ExternalAuth = config
+		})
+		if updated == 0 {
 			continue
 		}
 
-		routeCtx := ir.HTTPRoutes[routeKey]
-		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
-			routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+		if config.JWTIssuer != "" {
+			notifyJWTAuth(&ing, config)
+			continue
 		}
-
-		routeCtx.ProviderSpecificIR.IngressNginx.ExternalAuth = config
-		ir.HTTPRoutes[routeKey] = routeCtx
-
 		notify(notifications.InfoNotification,
 			fmt.Sprintf("External auth config stored in IR (URL: %s). Requires SecurityPolicy to apply.", config.URL),
 			&ing,
 		)
+		if strings.TrimSpace(ing.Annotations[authMethodAnnotation]) != "" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s %s cannot be converted: the ext_authz filter has no setting for the method of the auth request, so the auth service receives the method of the client request. "+
+					"The request body is forwarded to the auth service with POST",
+					authMethodAnnotation, config.Method),
+				&ing,
+			)
+		}
+		if _, inMesh := authServiceCluster(config.URL); !inMesh {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s %s is not an in-cluster Service (<service>.<namespace>.svc.cluster.local), so the ext_authz EnvoyFilter uses the placeholder cluster %q. "+
+					"Define a ServiceEntry for the auth host and set the cluster to its outbound cluster",
+					authURLAnnotation, config.URL, externalAuthPlaceholderCluster),
+				&ing,
+			)
+		}
 	}
 
 	return errs
 }
 
 // parseExternalAuthConfig extracts external auth configuration from ingress annotations
-func parseExternalAuthConfig(ing *networkingv1.Ingress) *intermediate.ExternalAuthConfig {
+func parseExternalAuthConfig(ing *networkingv1.Ingress) (*intermediate.ExternalAuthConfig, field.ErrorList) {
 	annotations := ing.GetAnnotations()
 	if annotations == nil {
-		return nil
+		return nil, nil
 	}
 
 	// Check if auth-url is set - this is required for external auth
-	authURL := annotations[authURLAnnotation]
+	authURL := strings.TrimSpace(annotations[authURLAnnotation])
 	if authURL == "" {
-		return nil
+		return nil, nil
+	}
+
+	normalizedURL, err := normalizeAuthURL(authURL)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(
+			field.NewPath("metadata", "annotations", authURLAnnotation),
+			authURL,
+			err.Error(),
+		)}
 	}
 
 	config := &intermediate.ExternalAuthConfig{
-		URL: authURL,
+		URL: normalizedURL,
 	}
 
 	// Parse auth-method (default: GET)
@@ -102,16 +154,23 @@ func parseExternalAuthConfig(ing *networkingv1.Ingress) *intermediate.ExternalAu
 	}
 	config.Method = strings.ToUpper(method)
 
-	// Parse auth-signin
+	// Parse auth-signin and the query parameter carrying the request URL
 	config.SigninURL = annotations[authSigninAnnotation]
+	config.SigninRedirectParam = defaultSigninRedirectParam
+	if param := strings.TrimSpace(annotations[authSigninRedirectParamAnnotation]); param != "" {
+		if !signinRedirectParamRegex.MatchString(param) {
+			return nil, field.ErrorList{field.Invalid(
+				field.NewPath("metadata", "annotations", authSigninRedirectParamAnnotation),
+				param,
+				"must be a query parameter name of letters, digits, '_', '.', '~' or '-'",
+			)}
+		}
+		config.SigninRedirectParam = param
+	}
 
 	// Parse auth-response-headers (comma-separated list)
 	if headers := annotations[authResponseHeadersAnnotation]; headers != "" {
-		headerList := strings.Split(headers, ",")
-		for i, h := range headerList {
-			headerList[i] = strings.TrimSpace(h)
-		}
-		config.ResponseHeaders = headerList
+		config.ResponseHeaders = parseHeaderList(headers)
 	}
 
 	// Parse auth-request-redirect
@@ -123,13 +182,144 @@ func parseExternalAuthConfig(ing *networkingv1.Ingress) *intermediate.ExternalAu
 	// Parse auth-cache-duration
 	config.CacheDuration = annotations[authCacheDurationAnnotation]
 
+	// Parse auth-fail-open (default: fail-closed)
+	if failOpen := annotations[authFailOpenAnnotation]; failOpen != "" {
+		val, err := strconv.ParseBool(failOpen)
+		if err != nil {
+			return nil, field.ErrorList{field.Invalid(
+				field.NewPath("metadata", "annotations", authFailOpenAnnotation),
+				failOpen,
+				"must be true or false",
+			)}
+		}
+		config.FailOpen = val
+		if val {
+			notify(notifications.WarningNotification,
+				"auth-fail-open is set: requests are allowed WITHOUT authentication while the auth service is unavailable",
+				ing,
+			)
+		}
+	}
+
+	// Parse the JWT issuer and keys, validated by the Gateway instead of the
+	// auth service
+	config.JWTIssuer = strings.TrimSpace(annotations[authJWTIssuerAnnotation])
+	if jwksURI := strings.TrimSpace(annotations[authJWKSURIAnnotation]); jwksURI != "" {
+		if config.JWTIssuer == "" {
+			return nil, field.ErrorList{field.Required(
+				field.NewPath("metadata", "annotations", authJWTIssuerAnnotation),
+				fmt.Sprintf("required with %s", authJWKSURIAnnotation),
+			)}
+		}
+		if u, err := url.Parse(jwksURI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, field.ErrorList{field.Invalid(
+				field.NewPath("metadata", "annotations", authJWKSURIAnnotation),
+				jwksURI,
+				"must be an absolute http(s) URL",
+			)}
+		}
+		config.JWKSURI = jwksURI
+	}
+
 	// Check for auth-snippet (not directly supported, just note it)
 	if snippet := annotations[authSnippetAnnotation]; snippet != "" {
-		notify(notifications.WarningNotification,
+		notifyWithCode(notifications.WarningNotification, notifications.UnsupportedSnippet,
 			"auth-snippet annotation detected. Custom auth snippets are not supported in Gateway API and may require EnvoyPatchPolicy.",
 			ing,
 		)
 	}
 
-	return config
+	return config, nil
+}
+
+// notifyJWTAuth reports the conversion of an auth-url validating JWTs to a
+// RequestAuthentication, and the auth settings it drops
+func notifyJWTAuth(ing *networkingv1.Ingress, config *intermediate.ExternalAuthConfig) {
+	notify(notifications.InfoNotification,
+		fmt.Sprintf("%s %s validates JWTs of issuer %s: the Gateway validates them with a RequestAuthentication and an AuthorizationPolicy "+
+			"denying requests without a valid JWT, instead of calling the auth service", authURLAnnotation, config.URL, config.JWTIssuer),
+		ing,
+	)
+	var dropped []string
+	if len(config.ResponseHeaders) > 0 {
+		dropped = append(dropped, authResponseHeadersAnnotation)
+	}
+	if config.SigninURL != "" {
+		dropped = append(dropped, authSigninAnnotation)
+	}
+	if config.FailOpen {
+		dropped = append(dropped, authFailOpenAnnotation)
+	}
+	if len(dropped) > 0 {
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("%s cannot be converted with JWT validation by the Gateway", strings.Join(dropped, ", ")),
+			ing,
+		)
+	}
+}
+
+// normalizeAuthURL validates that the auth-url is an absolute http(s) URL with a host
+// and strips trailing slashes from its path
+func normalizeAuthURL(authURL string) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid auth-url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid auth-url scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid auth-url, missing host")
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return u.String(), nil
+}
+
+// authServiceCluster returns the Envoy cluster of the auth service. An auth-url
+// pointing at an in-cluster Service uses the outbound cluster Istio creates for
+// the Service port, so no ServiceEntry or hand-built cluster is needed. Other
+// hosts get externalAuthPlaceholderCluster and false.
+func authServiceCluster(authURL string) (string, bool) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return externalAuthPlaceholderCluster, false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if strings.HasSuffix(host, ".svc") {
+		host += ".cluster.local"
+	}
+	// The host must be <service>.<namespace>.svc.cluster.local
+	labels := strings.Split(host, ".")
+	if len(labels) != 5 || !strings.HasSuffix(host, ".svc.cluster.local") || labels[0] == "" || labels[1] == "" {
+		return externalAuthPlaceholderCluster, false
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return fmt.Sprintf("outbound|%s||%s", port, host), true
+}
+
+// authSigninLocation returns the Location of the redirects to the auth-signin
+// URL, built like ingress-nginx: the original request URL is appended as the
+// redirect parameter, unless the URL already sets it. appendRequest is false
+// when the Location is the URL as is.
+func authSigninLocation(signinURL, redirectParam string) (location string, appendRequest bool) {
+	u, err := url.Parse(signinURL)
+	if err != nil {
+		return signinURL, false
+	}
+	query := u.Query()
+	if len(query) == 0 {
+		return signinURL + "?" + redirectParam + "=", true
+	}
+	if query.Get(redirectParam) != "" {
+		return signinURL, false
+	}
+	return signinURL + "&" + redirectParam + "=", true
 }
@@ -0,0 +1,434 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseExternalAuthConfig_URL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		authURL     string
+		expectedURL string
+		expectError bool
+	}{
+		{
+			name:        "valid https URL",
+			authURL:     "https://auth.example.com/verify",
+			expectedURL: "https://auth.example.com/verify",
+		},
+		{
+			name:        "trailing slashes are trimmed",
+			authURL:     "http://auth.default.svc.cluster.local:8080/verify//",
+			expectedURL: "http://auth.default.svc.cluster.local:8080/verify",
+		},
+		{
+			name:        "scheme-less URL causes error",
+			authURL:     "auth.example.com/verify",
+			expectError: true,
+		},
+		{
+			name:        "unsupported scheme causes error",
+			authURL:     "grpc://auth.example.com",
+			expectError: true,
+		},
+		{
+			name:        "garbage causes error",
+			authURL:     "%%not a url%%",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ingress",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"nginx.ingress.kubernetes.io/auth-url": tc.authURL,
+					},
+				},
+			}
+
+			config, errs := parseExternalAuthConfig(ing)
+			if tc.expectError {
+				if len(errs) == 0 {
+					t.Fatalf("expected error for auth-url %q, got config %+v", tc.authURL, config)
+				}
+				if config != nil {
+					t.Errorf("expected no config on error, got %+v", config)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if config == nil {
+				t.Fatalf("expected config, got nil")
+			}
+			if config.URL != tc.expectedURL {
+				t.Errorf("expected URL %q, got %q", tc.expectedURL, config.URL)
+			}
+		})
+	}
+}
+
+func TestExternalAuth_InClusterService(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{
+		authURLAnnotation: "http://oauth2-proxy.auth.svc.cluster.local:4180/oauth2/auth",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := externalAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			t.Errorf("unexpected warning for an in-cluster auth service: %s", n.Message)
+		}
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-extauthz"}]
+	if filter == nil {
+		t.Fatalf("expected an extauthz EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	cluster, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}),
+		"patch", "value", "typed_config", "http_service", "server_uri", "cluster")
+	if expected := "outbound|4180||oauth2-proxy.auth.svc.cluster.local"; cluster != expected {
+		t.Errorf("expected ext_authz cluster %q, got %q", expected, cluster)
+	}
+}
+
+func TestExternalAuth_ResponseHeaders(t *testing.T) {
+	ingress := headersTestIngress(map[string]string{
+		authURLAnnotation:             "http://oauth2-proxy.auth.svc.cluster.local:4180/oauth2/auth",
+		authResponseHeadersAnnotation: "X-Auth-Request-User, X-Auth-Request-Email,,x-auth-request-user",
+		authRequestRedirectAnnotation: "https://app.example.com/callback",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := externalAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-extauthz"}]
+	if filter == nil {
+		t.Fatalf("expected an extauthz EnvoyFilter, got %d filters", len(filters))
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	httpService, _, _ := unstructured.NestedMap(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "http_service")
+
+	responseHeaders := []interface{}{
+		map[string]interface{}{"exact": "x-auth-request-user", "ignore_case": true},
+		map[string]interface{}{"exact": "x-auth-request-email", "ignore_case": true},
+	}
+	// Each listed header is set on the upstream request and copied downstream
+	for _, name := range []string{"allowed_upstream_headers", "allowed_client_headers_on_success"} {
+		patterns, _, _ := unstructured.NestedSlice(httpService, "authorization_response", name, "patterns")
+		if diff := cmp.Diff(responseHeaders, patterns); diff != "" {
+			t.Errorf("unexpected %s (-want +got):\n%s", name, diff)
+		}
+	}
+	// and kept on the redirects of the auth service
+	clientHeaders, _, _ := unstructured.NestedSlice(httpService, "authorization_response", "allowed_client_headers", "patterns")
+	expectedClientHeaders := append([]interface{}{map[string]interface{}{"exact": "location", "ignore_case": true}}, responseHeaders...)
+	if diff := cmp.Diff(expectedClientHeaders, clientHeaders); diff != "" {
+		t.Errorf("unexpected allowed_client_headers (-want +got):\n%s", diff)
+	}
+
+	headersToAdd, _, _ := unstructured.NestedSlice(httpService, "authorization_request", "headers_to_add")
+	expectedHeadersToAdd := []interface{}{
+		map[string]interface{}{"key": "x-auth-request-redirect", "value": "https://app.example.com/callback"},
+	}
+	if diff := cmp.Diff(expectedHeadersToAdd, headersToAdd); diff != "" {
+		t.Errorf("unexpected headers_to_add (-want +got):\n%s", diff)
+	}
+}
+
+func TestExternalAuth_MethodWarning(t *testing.T) {
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		expectsWarning bool
+	}{
+		{
+			name:        "default method",
+			annotations: map[string]string{authURLAnnotation: "http://auth.auth.svc.cluster.local/verify"},
+		},
+		{
+			name: "explicit method",
+			annotations: map[string]string{
+				authURLAnnotation:    "http://auth.auth.svc.cluster.local/verify",
+				authMethodAnnotation: "POST",
+			},
+			expectsWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := externalAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var warned bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, authMethodAnnotation) {
+					warned = true
+				}
+			}
+			if warned != tc.expectsWarning {
+				t.Errorf("expected %s warning: %v, got: %v", authMethodAnnotation, tc.expectsWarning, warned)
+			}
+		})
+	}
+}
+
+func TestExternalAuth_SigninRedirectParam(t *testing.T) {
+	ingress := headersTestIngress(map[string]string{
+		authURLAnnotation:                 "http://oauth2-proxy.auth.svc.cluster.local:4180/oauth2/auth",
+		authSigninAnnotation:              "https://auth.example.com/oauth2/start",
+		authSigninRedirectParamAnnotation: "return_to",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := externalAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-extauthz"}]
+	if filter == nil {
+		t.Fatalf("expected an extauthz EnvoyFilter, got %d filters", len(filters))
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) != 2 {
+		t.Fatalf("expected the ext_authz and sign-in config patches, got %d", len(patches))
+	}
+	signinPatch := patches[1].(map[string]interface{})
+	subFilter, _, _ := unstructured.NestedString(signinPatch, "match", "listener", "filterChain", "filter", "subFilter", "name")
+	if subFilter != "envoy.filters.http.ext_authz" {
+		t.Errorf("expected the sign-in filter to be inserted before ext_authz, got %q", subFilter)
+	}
+	luaCode, _, _ := unstructured.NestedString(signinPatch, "patch", "value", "typed_config", "inline_code")
+	for _, expected := range []string{
+		`local signin = "https://auth.example.com/oauth2/start?return_to="`,
+		"local append_request = true",
+		`string.format("%%%02X", string.byte(c))`,
+		`response_handle:headers():replace(":status", "302")`,
+	} {
+		if !strings.Contains(luaCode, expected) {
+			t.Errorf("expected the Lua code to contain %q, got:\n%s", expected, luaCode)
+		}
+	}
+}
+
+func TestAuthSigninLocation(t *testing.T) {
+	testCases := []struct {
+		signinURL      string
+		redirectParam  string
+		expected       string
+		expectedAppend bool
+	}{
+		{
+			signinURL:      "https://auth.example.com/start",
+			redirectParam:  "rd",
+			expected:       "https://auth.example.com/start?rd=",
+			expectedAppend: true,
+		},
+		{
+			signinURL:      "https://auth.example.com/start?client=app",
+			redirectParam:  "return_to",
+			expected:       "https://auth.example.com/start?client=app&return_to=",
+			expectedAppend: true,
+		},
+		{
+			signinURL:     "https://auth.example.com/start?return_to=https://app.example.com",
+			redirectParam: "return_to",
+			expected:      "https://auth.example.com/start?return_to=https://app.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		location, appendRequest := authSigninLocation(tc.signinURL, tc.redirectParam)
+		if location != tc.expected || appendRequest != tc.expectedAppend {
+			t.Errorf("authSigninLocation(%q, %q) = (%q, %v), expected (%q, %v)",
+				tc.signinURL, tc.redirectParam, location, appendRequest, tc.expected, tc.expectedAppend)
+		}
+	}
+}
+
+func TestParseExternalAuthConfig_InvalidSigninRedirectParam(t *testing.T) {
+	ing := headersTestIngress(map[string]string{
+		authURLAnnotation:                 "https://auth.example.com/verify",
+		authSigninAnnotation:              "https://auth.example.com/start",
+		authSigninRedirectParamAnnotation: "return to",
+	})
+	if _, errs := parseExternalAuthConfig(&ing); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
+
+func TestExternalAuth_JWT(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{
+		authURLAnnotation:       "http://jwt-validator.auth.svc.cluster.local/validate",
+		authJWTIssuerAnnotation: "https://issuer.example.com",
+		authJWKSURIAnnotation:   "https://issuer.example.com/.well-known/jwks.json",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := externalAuthFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	resources := make(map[string]*unstructured.Unstructured)
+	for _, resource := range generator.GenerateEnvoyFilters(ir) {
+		resources[resource.GetKind()] = resource
+	}
+	if resources["EnvoyFilter"] != nil {
+		t.Errorf("expected no ext_authz EnvoyFilter, got %s", resources["EnvoyFilter"].GetName())
+	}
+
+	requestAuthentication := resources["RequestAuthentication"]
+	if requestAuthentication == nil {
+		t.Fatalf("expected a RequestAuthentication, got %v", resources)
+	}
+	jwtRules, _, _ := unstructured.NestedSlice(requestAuthentication.Object, "spec", "jwtRules")
+	expectedJWTRules := []interface{}{
+		map[string]interface{}{
+			"issuer":               "https://issuer.example.com",
+			"jwksUri":              "https://issuer.example.com/.well-known/jwks.json",
+			"forwardOriginalToken": true,
+		},
+	}
+	if diff := cmp.Diff(expectedJWTRules, jwtRules); diff != "" {
+		t.Errorf("unexpected jwtRules (-want +got):\n%s", diff)
+	}
+
+	authorizationPolicy := resources["AuthorizationPolicy"]
+	if authorizationPolicy == nil {
+		t.Fatalf("expected an AuthorizationPolicy, got %v", resources)
+	}
+	if authorizationPolicy.GetNamespace() != requestAuthentication.GetNamespace() {
+		t.Errorf("expected the policies in the same namespace, got %s and %s",
+			requestAuthentication.GetNamespace(), authorizationPolicy.GetNamespace())
+	}
+	action, _, _ := unstructured.NestedString(authorizationPolicy.Object, "spec", "action")
+	rules, _, _ := unstructured.NestedSlice(authorizationPolicy.Object, "spec", "rules")
+	expectedRules := []interface{}{
+		map[string]interface{}{
+			"from": []interface{}{
+				map[string]interface{}{"source": map[string]interface{}{"notRequestPrincipals": []interface{}{"*"}}},
+			},
+			"to": []interface{}{
+				map[string]interface{}{"operation": map[string]interface{}{"hosts": []interface{}{"example.com", "example.com:*"}}},
+			},
+		},
+	}
+	if action != "DENY" {
+		t.Errorf("expected a DENY AuthorizationPolicy, got %q", action)
+	}
+	if diff := cmp.Diff(expectedRules, rules); diff != "" {
+		t.Errorf("unexpected AuthorizationPolicy rules (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuthServiceCluster(t *testing.T) {
+	testCases := []struct {
+		authURL         string
+		expectedCluster string
+		expectedInMesh  bool
+	}{
+		{
+			authURL:         "http://auth.default.svc.cluster.local:8080/verify",
+			expectedCluster: "outbound|8080||auth.default.svc.cluster.local",
+			expectedInMesh:  true,
+		},
+		{
+			authURL:         "https://auth.default.svc/verify",
+			expectedCluster: "outbound|443||auth.default.svc.cluster.local",
+			expectedInMesh:  true,
+		},
+		{
+			authURL:         "https://auth.example.com/verify",
+			expectedCluster: externalAuthPlaceholderCluster,
+		},
+		{
+			authURL:         "http://svc.cluster.local/verify",
+			expectedCluster: externalAuthPlaceholderCluster,
+		},
+	}
+
+	for _, tc := range testCases {
+		cluster, inMesh := authServiceCluster(tc.authURL)
+		if cluster != tc.expectedCluster || inMesh != tc.expectedInMesh {
+			t.Errorf("authServiceCluster(%q) = (%q, %v), expected (%q, %v)",
+				tc.authURL, cluster, inMesh, tc.expectedCluster, tc.expectedInMesh)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// applyPreservedAddresses sets the spec.addresses of each generated Gateway to
+// the status.loadBalancer addresses of the Ingresses whose routes attach to
+// it, so the Gateway requests the IPs and hostnames clients already use.
+func applyPreservedAddresses(gatewayResources *i2gw.GatewayResources, ir intermediate.IR, gwConfig GatewayConfig) {
+	ingressesByGateway := make(map[types.NamespacedName][]*networkingv1.Ingress)
+	for _, routeKey := range slices.SortedFunc(maps.Keys(gatewayResources.HTTPRoutes), compareNamespacedNames) {
+		route := gatewayResources.HTTPRoutes[routeKey]
+		for _, parentRef := range route.Spec.ParentRefs {
+			gatewayKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				gatewayKey.Namespace = string(*parentRef.Namespace)
+			}
+			ingressesByGateway[gatewayKey] = append(ingressesByGateway[gatewayKey], routeSourceIngresses(ir.HTTPRoutes[routeKey])...)
+		}
+	}
+
+	for gatewayKey, gateway := range gatewayResources.Gateways {
+		addresses := ingressLoadBalancerAddresses(ingressesByGateway[gatewayKey])
+		if len(addresses) == 0 {
+			continue
+		}
+		gateway.Spec.Addresses = addresses
+		gatewayResources.Gateways[gatewayKey] = gateway
+
+		var values []string
+		hasHostname := false
+		for _, address := range addresses {
+			values = append(values, address.Value)
+			if *address.Type == gatewayv1.HostnameAddressType {
+				hasHostname = true
+			}
+		}
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("Gateway %s requests the Ingress load balancer addresses %s. They are only assigned once the ingress-nginx load balancer releases them, "+
+				"and the Gateway is not programmed until then", gatewayKey, strings.Join(values, ", ")),
+			&gateway,
+		)
+		if len(addresses) > 1 {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Gateway %s requests %d addresses from Ingresses with different load balancers; implementations may only support one", gatewayKey, len(addresses)),
+				&gateway,
+			)
+		}
+		if hasHostname {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Gateway %s requests Hostname addresses, which are usually assigned by the cloud load balancer and cannot be requested by most implementations", gatewayKey),
+				&gateway,
+			)
+		}
+	}
+
+	if gwConfig.IsCentralized() {
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("Ingress load balancer addresses are only set on generated Gateways; set them on the centralized Gateway %s/%s directly",
+				gwConfig.Namespace, gwConfig.Name),
+			nil,
+		)
+	}
+}
+
+// ingressLoadBalancerAddresses returns the Gateway addresses of the
+// status.loadBalancer ingress points of the Ingresses, without duplicates
+func ingressLoadBalancerAddresses(ingresses []*networkingv1.Ingress) []gatewayv1.GatewaySpecAddress {
+	var addresses []gatewayv1.GatewaySpecAddress
+	add := func(addressType gatewayv1.AddressType, value string) {
+		if value == "" || slices.ContainsFunc(addresses, func(a gatewayv1.GatewaySpecAddress) bool { return a.Value == value }) {
+			return
+		}
+		addresses = append(addresses, gatewayv1.GatewaySpecAddress{Type: ptr.To(addressType), Value: value})
+	}
+
+	for _, ing := range ingresses {
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			add(gatewayv1.IPAddressType, lb.IP)
+			add(gatewayv1.HostnameAddressType, lb.Hostname)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestToGatewayResources_PreserveAddresses(t *testing.T) {
+	testCases := []struct {
+		name              string
+		preserveAddresses string
+		expectedAddresses []gatewayv1.GatewaySpecAddress
+	}{
+		{
+			name:              "addresses preserved",
+			preserveAddresses: "true",
+			expectedAddresses: []gatewayv1.GatewaySpecAddress{
+				{Type: ptr.To(gatewayv1.IPAddressType), Value: "203.0.113.10"},
+			},
+		},
+		{
+			name:              "disabled by default",
+			preserveAddresses: "false",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:       "per-namespace",
+						PreserveAddressesFlag: tc.preserveAddresses,
+					},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(nil)
+			ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{
+				{IP: "203.0.113.10"},
+			}
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if len(gatewayResources.Gateways) != 1 {
+				t.Fatalf("expected 1 Gateway, got %d", len(gatewayResources.Gateways))
+			}
+			for key, gateway := range gatewayResources.Gateways {
+				if diff := cmp.Diff(tc.expectedAddresses, gateway.Spec.Addresses); diff != "" {
+					t.Errorf("Gateway %s: unexpected addresses (-want +got):\n%s", key, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// knownGatewayClassControllers maps well-known GatewayClass names to the
+// controllerName of the implementation that serves them
+var knownGatewayClassControllers = map[string]string{
+	"istio":         "istio.io/gateway-controller",
+	"envoy-gateway": "gateway.envoyproxy.io/gatewayclass-controller",
+	"eg":            "gateway.envoyproxy.io/gatewayclass-controller",
+}
+
+// gatewayClassController returns the controllerName for the configured GatewayClass.
+// An explicit override takes precedence over the well-known controller for the class.
+func gatewayClassController(gwConfig GatewayConfig) string {
+	if gwConfig.GatewayClassController != "" {
+		return gwConfig.GatewayClassController
+	}
+	return knownGatewayClassControllers[gwConfig.GatewayClassName]
+}
+
+// buildGatewayClass generates a GatewayClass for the configured gateway class so
+// the output does not depend on a pre-existing GatewayClass in the cluster.
+// GatewayClasses already present in the output are left untouched.
+func buildGatewayClass(gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	if gwConfig.GatewayClassName == "" {
+		return
+	}
+
+	key := types.NamespacedName{Name: gwConfig.GatewayClassName}
+	if _, exists := gatewayResources.GatewayClasses[key]; exists {
+		return
+	}
+
+	controller := gatewayClassController(gwConfig)
+	if controller == "" {
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("Unknown controller for GatewayClass %q, skipping GatewayClass generation. "+
+				"Set --ingress-nginx-%s to emit it.", gwConfig.GatewayClassName, GatewayClassControllerFlag),
+			nil,
+		)
+		return
+	}
+
+	if gatewayResources.GatewayClasses == nil {
+		gatewayResources.GatewayClasses = make(map[types.NamespacedName]gatewayv1.GatewayClass)
+	}
+
+	gatewayResources.GatewayClasses[key] = gatewayv1.GatewayClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "gateway.networking.k8s.io/v1",
+			Kind:       "GatewayClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   gwConfig.GatewayClassName,
+			Labels: gwConfig.resourceLabels(),
+		},
+		Spec: gatewayv1.GatewayClassSpec{
+			ControllerName: gatewayv1.GatewayController(controller),
+		},
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_EmitGatewayClass(t *testing.T) {
+	testCases := []struct {
+		name               string
+		flags              map[string]string
+		expectGatewayClass bool
+		expectedName       string
+		expectedController string
+	}{
+		{
+			name: "istio GatewayClass",
+			flags: map[string]string{
+				EmitGatewayClassFlag: "true",
+			},
+			expectGatewayClass: true,
+			expectedName:       "istio",
+			expectedController: "istio.io/gateway-controller",
+		},
+		{
+			name: "envoy-gateway GatewayClass",
+			flags: map[string]string{
+				EmitGatewayClassFlag: "true",
+				GatewayClassFlag:     "envoy-gateway",
+			},
+			expectGatewayClass: true,
+			expectedName:       "envoy-gateway",
+			expectedController: "gateway.envoyproxy.io/gatewayclass-controller",
+		},
+		{
+			name: "controller override",
+			flags: map[string]string{
+				EmitGatewayClassFlag:       "true",
+				GatewayClassFlag:           "custom",
+				GatewayClassControllerFlag: "example.com/gateway-controller",
+			},
+			expectGatewayClass: true,
+			expectedName:       "custom",
+			expectedController: "example.com/gateway-controller",
+		},
+		{
+			name: "unknown class without controller is skipped",
+			flags: map[string]string{
+				EmitGatewayClassFlag: "true",
+				GatewayClassFlag:     "custom",
+			},
+		},
+		{
+			name:  "not emitted by default",
+			flags: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags},
+			}).(*Provider)
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if !tc.expectGatewayClass {
+				if len(gatewayResources.GatewayClasses) != 0 {
+					t.Errorf("expected no GatewayClasses, got %v", gatewayResources.GatewayClasses)
+				}
+				return
+			}
+
+			gatewayClass, ok := gatewayResources.GatewayClasses[types.NamespacedName{Name: tc.expectedName}]
+			if !ok {
+				t.Fatalf("expected GatewayClass %q, got %v", tc.expectedName, gatewayResources.GatewayClasses)
+			}
+			if string(gatewayClass.Spec.ControllerName) != tc.expectedController {
+				t.Errorf("expected controllerName %q, got %q", tc.expectedController, gatewayClass.Spec.ControllerName)
+			}
+		})
+	}
+}
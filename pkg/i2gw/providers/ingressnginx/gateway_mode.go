@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// gatewayModeAnnotation overrides --gateway-mode for the routes of a single
+	// Ingress, so Ingresses can be migrated to a centralized Gateway
+	// incrementally. This is an ingress2gateway convention.
+	gatewayModeAnnotation = "ingress2gateway.kubernetes.io/gateway-mode"
+
+	centralizedGatewayMode  = "centralized"
+	perNamespaceGatewayMode = "per-namespace"
+)
+
+// gatewayModeFeature stores the gateway-mode annotation of the source Ingresses
+// of each HTTPRoute in the IR. If the Ingresses sharing a route disagree, a
+// Warning is emitted and the global gateway mode is used.
+func gatewayModeFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		mode, ok := ing.Annotations[gatewayModeAnnotation]
+		if !ok || mode == centralizedGatewayMode || mode == perNamespaceGatewayMode {
+			continue
+		}
+		errs = append(errs, field.Invalid(
+			field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations", gatewayModeAnnotation),
+			mode,
+			fmt.Sprintf("must be %s or %s", centralizedGatewayMode, perNamespaceGatewayMode),
+		))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		modes := make(map[string][]string)
+		for _, sources := range routeCtx.RuleBackendSources {
+			for _, source := range sources {
+				if source.Ingress == nil {
+					continue
+				}
+				if mode := source.Ingress.Annotations[gatewayModeAnnotation]; mode != "" && !slices.Contains(modes[mode], source.Ingress.Name) {
+					modes[mode] = append(modes[mode], source.Ingress.Name)
+				}
+			}
+		}
+
+		switch len(modes) {
+		case 0:
+			continue
+		case 1:
+			if routeCtx.ProviderSpecificIR.IngressNginx == nil {
+				routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+			}
+			for mode := range modes {
+				routeCtx.ProviderSpecificIR.IngressNginx.GatewayMode = mode
+			}
+			ir.HTTPRoutes[routeKey] = routeCtx
+		default:
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Ingresses sharing HTTPRoute %s/%s disagree on %s (%s: %s, %s: %s); the --%s-%s mode is used",
+					routeKey.Namespace, routeKey.Name, gatewayModeAnnotation,
+					centralizedGatewayMode, strings.Join(modes[centralizedGatewayMode], ", "),
+					perNamespaceGatewayMode, strings.Join(modes[perNamespaceGatewayMode], ", "),
+					Name, GatewayModeFlag),
+				&routeCtx.HTTPRoute)
+		}
+	}
+
+	return errs
+}
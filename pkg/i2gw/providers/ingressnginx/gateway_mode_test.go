@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_GatewayModeAnnotation(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: perNamespaceGatewayMode},
+		},
+	}).(*Provider)
+
+	perNamespace := headersTestIngress(nil)
+	centralized := headersTestIngress(map[string]string{gatewayModeAnnotation: centralizedGatewayMode})
+	centralized.Name = "central-ingress"
+	centralized.Spec.Rules[0].Host = "central.example.com"
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: perNamespace.Namespace, Name: perNamespace.Name}: &perNamespace,
+		{Namespace: centralized.Namespace, Name: centralized.Name}:   &centralized,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	perNamespaceGateway := types.NamespacedName{Namespace: "default-gateway", Name: "default-gateway"}
+	if len(gatewayResources.Gateways) != 1 {
+		t.Errorf("expected only Gateway %s, got %v", perNamespaceGateway, gatewayResources.Gateways)
+	}
+	if _, ok := gatewayResources.Gateways[perNamespaceGateway]; !ok {
+		t.Errorf("expected Gateway %s to be generated", perNamespaceGateway)
+	}
+
+	expectedParents := map[string]types.NamespacedName{
+		common.RouteName("test-ingress", "example.com"):            perNamespaceGateway,
+		common.RouteName("central-ingress", "central.example.com"): {Namespace: DefaultGatewayNamespace, Name: DefaultGatewayName},
+	}
+	for routeName, expectedParent := range expectedParents {
+		route, ok := gatewayResources.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: routeName}]
+		if !ok {
+			t.Fatalf("HTTPRoute %s not found", routeName)
+		}
+		if len(route.Spec.ParentRefs) != 1 {
+			t.Fatalf("HTTPRoute %s: expected 1 parentRef, got %d", routeName, len(route.Spec.ParentRefs))
+		}
+		parentRef := route.Spec.ParentRefs[0]
+		parent := types.NamespacedName{Name: string(parentRef.Name)}
+		if parentRef.Namespace != nil {
+			parent.Namespace = string(*parentRef.Namespace)
+		}
+		if parent != expectedParent {
+			t.Errorf("HTTPRoute %s: expected parent %s, got %s", routeName, expectedParent, parent)
+		}
+	}
+}
+
+func TestGatewayModeFeature_InvalidAnnotation(t *testing.T) {
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{gatewayModeAnnotation: "shared"})}
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+
+	errs = gatewayModeFeature(ingresses, nil, &ir)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if expectedField := "ingress.default.test-ingress.metadata.annotations." + gatewayModeAnnotation; errs[0].Field != expectedField {
+		t.Errorf("expected error on field %q, got %q", expectedField, errs[0].Field)
+	}
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// Header manipulation annotations
+	proxyHideHeadersAnnotation      = "nginx.ingress.kubernetes.io/proxy-hide-headers"
+	connectionProxyHeaderAnnotation = "nginx.ingress.kubernetes.io/connection-proxy-header"
+	proxySetHeadersAnnotation       = "nginx.ingress.kubernetes.io/proxy-set-headers"
+	customHeadersAnnotation         = "nginx.ingress.kubernetes.io/custom-headers"
+	upstreamVhostAnnotation         = "nginx.ingress.kubernetes.io/upstream-vhost"
+)
+
+// headersFeature processes header manipulation annotations and adds
+// RequestHeaderModifier / ResponseHeaderModifier filters to the HTTPRoute rules
+// generated from the annotated Ingress.
+func headersFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+
+		// proxy-hide-headers lists response headers to strip before returning to the client
+		if headers := parseHeaderList(ing.Annotations[proxyHideHeadersAnnotation]); len(headers) > 0 {
+			filter := gatewayv1.HTTPRouteFilter{
+				Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+				ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Remove: headers,
+				},
+			}
+			if applyFilterToIngressRules(ir, ing, filter) > 0 {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("proxy-hide-headers converted to ResponseHeaderModifier removing: %s", strings.Join(headers, ", ")),
+					ing,
+				)
+			}
+		}
+
+		// connection-proxy-header overrides the Connection header sent to the backend
+		if value := strings.TrimSpace(ing.Annotations[connectionProxyHeaderAnnotation]); value != "" {
+			filter := gatewayv1.HTTPRouteFilter{
+				Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set: []gatewayv1.HTTPHeader{
+						{Name: "Connection", Value: value},
+					},
+				},
+			}
+			if applyFilterToIngressRules(ir, ing, filter) > 0 && strings.EqualFold(value, "keep-alive") {
+				notify(notifications.InfoNotification,
+					"connection-proxy-header 'keep-alive' converted to a Connection header; note that Envoy manages upstream connection reuse itself and does not need this header to keep connections alive",
+					ing,
+				)
+			}
+		}
+	}
+
+	return errs
+}
+
+// proxySetHeadersFeature returns a feature parser that resolves the ConfigMap
+// referenced by proxy-set-headers and sets its entries as request headers
+// toward the backend. ConfigMaps are not part of the FeatureParser arguments,
+// so they are bound when the parser is created.
+func proxySetHeadersFeature(configMaps map[types.NamespacedName]*apiv1.ConfigMap) i2gw.FeatureParser {
+	return configMapHeadersFeature(configMaps, proxySetHeadersAnnotation, gatewayv1.HTTPRouteFilterRequestHeaderModifier)
+}
+
+// customHeadersFeature returns a feature parser that resolves the ConfigMap
+// referenced by custom-headers and sets its entries as response headers.
+// ingress-nginx adds them with more_set_headers, which replaces a header of the
+// same name sent by the backend, so they map to Set rather than Add.
+func customHeadersFeature(configMaps map[types.NamespacedName]*apiv1.ConfigMap) i2gw.FeatureParser {
+	return configMapHeadersFeature(configMaps, customHeadersAnnotation, gatewayv1.HTTPRouteFilterResponseHeaderModifier)
+}
+
+// upstreamVhostFeature returns a feature parser converting upstream-vhost, the
+// Host header sent to the backends, to a RequestHeaderModifier setting Host on
+// the rules generated from the Ingress. Gateway API leaves it to the
+// implementations whether a header modifier can change the Host: Istio
+// rewrites the authority of the request, while others may ignore it and
+// require a URLRewrite filter with a hostname, so a Warning is emitted unless
+// the GatewayClass controller is Istio's. The controller is bound when the
+// parser is created.
+func upstreamVhostFeature(gatewayClassController string) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		var errs field.ErrorList
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+			vhost := strings.TrimSpace(ing.Annotations[upstreamVhostAnnotation])
+			if vhost == "" {
+				continue
+			}
+			if strings.Contains(vhost, "$") {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s %q uses NGINX variables, which cannot be converted; the Host header of the client is sent to the backends", upstreamVhostAnnotation, vhost),
+					ing,
+				)
+				continue
+			}
+			if msg := validateUpstreamVhost(vhost); msg != "" {
+				annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+				errs = append(errs, field.Invalid(annotationsPath.Key(upstreamVhostAnnotation), vhost, msg))
+				continue
+			}
+
+			filter := gatewayv1.HTTPRouteFilter{
+				Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set: []gatewayv1.HTTPHeader{
+						{Name: "Host", Value: vhost},
+					},
+				},
+			}
+			if applyFilterToIngressRules(ir, ing, filter) == 0 {
+				continue
+			}
+			if gatewayClassController == knownGatewayClassControllers["istio"] {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("%s converted to a RequestHeaderModifier setting the Host header to %q, which Istio applies as the authority of the requests to the backends", upstreamVhostAnnotation, vhost),
+					ing,
+				)
+			} else {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s converted to a RequestHeaderModifier setting the Host header to %q; some Gateway API implementations do not rewrite the Host with header modifiers and require a URLRewrite filter with hostname %q instead", upstreamVhostAnnotation, vhost, vhost),
+					ing,
+				)
+			}
+		}
+
+		return errs
+	}
+}
+
+// validateUpstreamVhost checks that the upstream-vhost is a hostname or an IP
+// address, with an optional port, and returns the validation message if not
+func validateUpstreamVhost(vhost string) string {
+	host := vhost
+	if h, _, ok := splitHostPort(vhost); ok {
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return strings.Join(validation.IsDNS1123Subdomain(strings.ToLower(host)), "; ")
+}
+
+// configMapHeadersFeature returns a feature parser setting the entries of the
+// ConfigMap referenced by the annotation with a header modifier filter of the
+// given type.
+func configMapHeadersFeature(configMaps map[types.NamespacedName]*apiv1.ConfigMap, annotation string, filterType gatewayv1.HTTPRouteFilterType) i2gw.FeatureParser {
+	annotationName := strings.TrimPrefix(annotation, "nginx.ingress.kubernetes.io/")
+
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		var errs field.ErrorList
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+			ref := strings.TrimSpace(ing.Annotations[annotation])
+			if ref == "" {
+				continue
+			}
+
+			// The ConfigMap is referenced as "<namespace>/<name>" or by name in the Ingress namespace
+			configMapKey := types.NamespacedName{Namespace: ing.Namespace, Name: ref}
+			if namespace, name, found := strings.Cut(ref, "/"); found {
+				configMapKey = types.NamespacedName{Namespace: namespace, Name: name}
+			}
+
+			configMap, ok := configMaps[configMapKey]
+			if !ok {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s ConfigMap %s not found, headers will not be set", annotationName, configMapKey),
+					ing,
+				)
+				continue
+			}
+
+			headers, skipped := configMapHeaders(configMap)
+			if len(skipped) > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s ConfigMap %s: headers using NGINX variables cannot be converted and were skipped: %s",
+						annotationName, configMapKey, strings.Join(skipped, ", ")),
+					ing,
+				)
+			}
+			if len(headers) == 0 {
+				continue
+			}
+
+			// nginx overwrites headers of the same name, so the entries are Set, not Added
+			filter := gatewayv1.HTTPRouteFilter{Type: filterType}
+			if filterType == gatewayv1.HTTPRouteFilterResponseHeaderModifier {
+				filter.ResponseHeaderModifier = &gatewayv1.HTTPHeaderFilter{Set: headers}
+			} else {
+				filter.RequestHeaderModifier = &gatewayv1.HTTPHeaderFilter{Set: headers}
+			}
+			if applyFilterToIngressRules(ir, ing, filter) > 0 {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("%s ConfigMap %s converted to %s setting %d header(s)", annotationName, configMapKey, filterType, len(headers)),
+					ing,
+				)
+			}
+		}
+
+		return errs
+	}
+}
+
+// configMapHeaders returns the ConfigMap entries as headers sorted by
+// name, along with the names of entries whose values reference NGINX variables
+func configMapHeaders(configMap *apiv1.ConfigMap) ([]gatewayv1.HTTPHeader, []string) {
+	var headers []gatewayv1.HTTPHeader
+	var skipped []string
+
+	for _, name := range slices.Sorted(maps.Keys(configMap.Data)) {
+		value := configMap.Data[name]
+		if strings.Contains(value, "$") {
+			skipped = append(skipped, name)
+			continue
+		}
+		headers = append(headers, gatewayv1.HTTPHeader{
+			Name:  gatewayv1.HTTPHeaderName(name),
+			Value: value,
+		})
+	}
+
+	return headers, skipped
+}
+
+// parseHeaderList parses a comma-separated list of header names, dropping
+// empty entries and duplicates
+func parseHeaderList(value string) []string {
+	var headers []string
+	seen := make(map[string]bool)
+	for _, h := range strings.Split(value, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" || seen[strings.ToLower(h)] {
+			continue
+		}
+		seen[strings.ToLower(h)] = true
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// applyFilterToIngressRules adds the filter to every HTTPRoute rule that has a
+// backend contributed by the given Ingress. It returns the number of rules updated.
+func applyFilterToIngressRules(ir *intermediate.IR, ing *networkingv1.Ingress, filter gatewayv1.HTTPRouteFilter) int {
+	updated := 0
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace {
+			continue
+		}
+		changed := false
+		for ruleIdx, sources := range routeCtx.RuleBackendSources {
+			if ruleIdx >= len(routeCtx.HTTPRoute.Spec.Rules) || !sourcesContainIngress(sources, ing) {
+				continue
+			}
+			rule := &routeCtx.HTTPRoute.Spec.Rules[ruleIdx]
+			rule.Filters = mergeHTTPRouteFilter(rule.Filters, filter)
+			changed = true
+			updated++
+		}
+		if changed {
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+	}
+	return updated
+}
+
+// sourcesContainIngress checks if any of the backend sources came from the given Ingress
+func sourcesContainIngress(sources []intermediate.BackendSource, ing *networkingv1.Ingress) bool {
+	for _, source := range sources {
+		if source.Ingress != nil && source.Ingress.Namespace == ing.Namespace && source.Ingress.Name == ing.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeHTTPRouteFilter appends the filter to the list. Header modifier filters are
+// merged into an existing filter of the same type, since Gateway API allows at most
+// one filter of each header modifier type per rule.
+func mergeHTTPRouteFilter(filters []gatewayv1.HTTPRouteFilter, filter gatewayv1.HTTPRouteFilter) []gatewayv1.HTTPRouteFilter {
+	for i := range filters {
+		if filters[i].Type != filter.Type {
+			continue
+		}
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			filters[i].RequestHeaderModifier = mergeHeaderFilter(filters[i].RequestHeaderModifier, filter.RequestHeaderModifier)
+			return filters
+		case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			filters[i].ResponseHeaderModifier = mergeHeaderFilter(filters[i].ResponseHeaderModifier, filter.ResponseHeaderModifier)
+			return filters
+		}
+	}
+	return append(filters, filter)
+}
+
+// mergeHeaderFilter merges the Set, Add and Remove entries of two header filters.
+// Entries already present in existing take precedence, except that a Set entry
+// replaces an Add entry of the same name: nginx overwrites headers, so a header
+// set by one annotation must not be appended to by another.
+func mergeHeaderFilter(existing, addition *gatewayv1.HTTPHeaderFilter) *gatewayv1.HTTPHeaderFilter {
+	if existing == nil {
+		return addition
+	}
+	if addition == nil {
+		return existing
+	}
+	merged := existing.DeepCopy()
+	merged.Set = mergeHeaders(merged.Set, addition.Set)
+	merged.Add = mergeHeaders(merged.Add, addition.Add)
+	merged.Add = slices.DeleteFunc(merged.Add, func(header gatewayv1.HTTPHeader) bool {
+		return slices.ContainsFunc(merged.Set, func(set gatewayv1.HTTPHeader) bool {
+			return strings.EqualFold(string(set.Name), string(header.Name))
+		})
+	})
+	if len(merged.Add) == 0 {
+		merged.Add = nil
+	}
+	for _, name := range addition.Remove {
+		found := false
+		for _, r := range merged.Remove {
+			if strings.EqualFold(r, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Remove = append(merged.Remove, name)
+		}
+	}
+	return merged
+}
+
+// mergeHeaders appends the headers in addition whose names are not already in existing
+func mergeHeaders(existing, addition []gatewayv1.HTTPHeader) []gatewayv1.HTTPHeader {
+	for _, header := range addition {
+		found := false
+		for _, e := range existing {
+			if strings.EqualFold(string(e.Name), string(header.Name)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, header)
+		}
+	}
+	return existing
+}
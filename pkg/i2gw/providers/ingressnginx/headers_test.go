@@ -0,0 +1,504 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func headersTestIngress(annotations map[string]string) networkingv1.Ingress {
+	return networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ingress",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: strPtr("nginx"),
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: pathTypePtr(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "my-service",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestHeadersFeature_ProxyHideHeaders(t *testing.T) {
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		expectedRemove []string
+	}{
+		{
+			name: "listed headers are removed from responses",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-hide-headers": "X-Powered-By, Server",
+			},
+			expectedRemove: []string{"X-Powered-By", "Server"},
+		},
+		{
+			name: "empty and duplicate entries are dropped",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-hide-headers": "Server,, server ,X-Debug",
+			},
+			expectedRemove: []string{"Server", "X-Debug"},
+		},
+		{
+			name:        "no annotation adds no filter",
+			annotations: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := headersFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found", routeKey)
+			}
+
+			for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+				var modifier *gatewayv1.HTTPHeaderFilter
+				for _, filter := range rule.Filters {
+					if filter.Type == gatewayv1.HTTPRouteFilterResponseHeaderModifier {
+						modifier = filter.ResponseHeaderModifier
+					}
+				}
+
+				if len(tc.expectedRemove) == 0 {
+					if modifier != nil {
+						t.Errorf("expected no ResponseHeaderModifier, got %+v", modifier)
+					}
+					continue
+				}
+				if modifier == nil {
+					t.Fatalf("expected ResponseHeaderModifier filter on rule")
+				}
+				if len(modifier.Remove) != len(tc.expectedRemove) {
+					t.Fatalf("expected Remove %v, got %v", tc.expectedRemove, modifier.Remove)
+				}
+				for i, header := range tc.expectedRemove {
+					if modifier.Remove[i] != header {
+						t.Errorf("expected Remove[%d] = %q, got %q", i, header, modifier.Remove[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMergeHTTPRouteFilter(t *testing.T) {
+	filters := []gatewayv1.HTTPRouteFilter{
+		{
+			Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{Remove: []string{"Server"}},
+		},
+	}
+
+	filters = mergeHTTPRouteFilter(filters, gatewayv1.HTTPRouteFilter{
+		Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+		ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{Remove: []string{"server", "X-Powered-By"}},
+	})
+
+	if len(filters) != 1 {
+		t.Fatalf("expected filters to be merged into 1, got %d", len(filters))
+	}
+	remove := filters[0].ResponseHeaderModifier.Remove
+	if len(remove) != 2 || remove[0] != "Server" || remove[1] != "X-Powered-By" {
+		t.Errorf("expected Remove [Server X-Powered-By], got %v", remove)
+	}
+}
+
+func TestHeadersFeature_ConnectionProxyHeader(t *testing.T) {
+	testCases := []struct {
+		name          string
+		annotations   map[string]string
+		expectedValue string
+	}{
+		{
+			name: "keep-alive sets Connection header",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/connection-proxy-header": "keep-alive",
+			},
+			expectedValue: "keep-alive",
+		},
+		{
+			name: "close sets Connection header",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/connection-proxy-header": "close",
+			},
+			expectedValue: "close",
+		},
+		{
+			name:        "no annotation adds no filter",
+			annotations: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := headersFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found", routeKey)
+			}
+
+			for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+				var modifier *gatewayv1.HTTPHeaderFilter
+				for _, filter := range rule.Filters {
+					if filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+						modifier = filter.RequestHeaderModifier
+					}
+				}
+
+				if tc.expectedValue == "" {
+					if modifier != nil {
+						t.Errorf("expected no RequestHeaderModifier, got %+v", modifier)
+					}
+					continue
+				}
+				if modifier == nil || len(modifier.Set) != 1 {
+					t.Fatalf("expected RequestHeaderModifier with one Set entry, got %+v", modifier)
+				}
+				if modifier.Set[0].Name != "Connection" || modifier.Set[0].Value != tc.expectedValue {
+					t.Errorf("expected Set Connection: %s, got %s: %s", tc.expectedValue, modifier.Set[0].Name, modifier.Set[0].Value)
+				}
+			}
+		})
+	}
+}
+
+func TestHeadersFeature_ProxySetHeaders(t *testing.T) {
+	configMaps := map[types.NamespacedName]*apiv1.ConfigMap{
+		{Namespace: "default", Name: "custom-headers"}: {
+			ObjectMeta: metav1.ObjectMeta{Name: "custom-headers", Namespace: "default"},
+			Data: map[string]string{
+				"X-Team":      "payments",
+				"X-Env":       "prod",
+				"X-Client-IP": "$remote_addr",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expectedSet []gatewayv1.HTTPHeader
+	}{
+		{
+			name: "ConfigMap in the Ingress namespace",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-set-headers": "custom-headers",
+			},
+			expectedSet: []gatewayv1.HTTPHeader{
+				{Name: "X-Env", Value: "prod"},
+				{Name: "X-Team", Value: "payments"},
+			},
+		},
+		{
+			name: "ConfigMap referenced by namespace and name",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-set-headers": "default/custom-headers",
+			},
+			expectedSet: []gatewayv1.HTTPHeader{
+				{Name: "X-Env", Value: "prod"},
+				{Name: "X-Team", Value: "payments"},
+			},
+		},
+		{
+			name: "missing ConfigMap adds no filter",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-set-headers": "missing",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := proxySetHeadersFeature(configMaps)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found", routeKey)
+			}
+
+			for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+				var modifier *gatewayv1.HTTPHeaderFilter
+				for _, filter := range rule.Filters {
+					if filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+						modifier = filter.RequestHeaderModifier
+					}
+				}
+
+				if tc.expectedSet == nil {
+					if modifier != nil {
+						t.Errorf("expected no RequestHeaderModifier, got %+v", modifier)
+					}
+					continue
+				}
+				if modifier == nil {
+					t.Fatalf("expected RequestHeaderModifier, got none")
+				}
+				if !reflect.DeepEqual(modifier.Set, tc.expectedSet) {
+					t.Errorf("expected Set %+v, got %+v", tc.expectedSet, modifier.Set)
+				}
+			}
+		})
+	}
+}
+
+func TestHeadersFeature_CustomHeaders(t *testing.T) {
+	configMaps := map[types.NamespacedName]*apiv1.ConfigMap{
+		{Namespace: "default", Name: "response-headers"}: {
+			ObjectMeta: metav1.ObjectMeta{Name: "response-headers", Namespace: "default"},
+			Data: map[string]string{
+				"Cache-Control":   "no-store",
+				"X-Frame-Options": "DENY",
+			},
+		},
+	}
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{
+		"nginx.ingress.kubernetes.io/custom-headers": "response-headers",
+	})}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := customHeadersFeature(configMaps)(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	routeCtx, ok := ir.HTTPRoutes[routeKey]
+	if !ok {
+		t.Fatalf("HTTPRoute %s not found", routeKey)
+	}
+
+	expectedSet := []gatewayv1.HTTPHeader{
+		{Name: "Cache-Control", Value: "no-store"},
+		{Name: "X-Frame-Options", Value: "DENY"},
+	}
+	for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+		var modifier *gatewayv1.HTTPHeaderFilter
+		for _, filter := range rule.Filters {
+			if filter.Type == gatewayv1.HTTPRouteFilterResponseHeaderModifier {
+				modifier = filter.ResponseHeaderModifier
+			}
+		}
+		if modifier == nil {
+			t.Fatalf("expected ResponseHeaderModifier, got none")
+		}
+		// Headers sent by the backend are overwritten, as with more_set_headers
+		if !reflect.DeepEqual(modifier.Set, expectedSet) {
+			t.Errorf("expected Set %+v, got %+v", expectedSet, modifier.Set)
+		}
+		if len(modifier.Add) > 0 {
+			t.Errorf("expected no Add entries, got %+v", modifier.Add)
+		}
+	}
+}
+
+func TestUpstreamVhostFeature(t *testing.T) {
+	testCases := []struct {
+		name             string
+		vhost            string
+		controller       string
+		expectedHost     string
+		expectedWarnings int
+		expectError      bool
+	}{
+		{
+			name:         "Host set with Istio",
+			vhost:        "backend.internal.example.com",
+			controller:   knownGatewayClassControllers["istio"],
+			expectedHost: "backend.internal.example.com",
+		},
+		{
+			name:             "Host set with a warning for other implementations",
+			vhost:            "backend.internal:8080",
+			controller:       knownGatewayClassControllers["envoy-gateway"],
+			expectedHost:     "backend.internal:8080",
+			expectedWarnings: 1,
+		},
+		{
+			name:             "NGINX variables are not converted",
+			vhost:            "$service_name.internal",
+			controller:       knownGatewayClassControllers["istio"],
+			expectedWarnings: 1,
+		},
+		{
+			name:        "invalid host causes error",
+			vhost:       "backend_internal",
+			controller:  knownGatewayClassControllers["istio"],
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{upstreamVhostAnnotation: tc.vhost})}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = upstreamVhostFeature(tc.controller)(ingresses, nil, &ir)
+			if tc.expectError != (len(errs) > 0) {
+				t.Fatalf("expected error: %v, got %v", tc.expectError, errs)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			for _, rule := range ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules {
+				var modifier *gatewayv1.HTTPHeaderFilter
+				for _, filter := range rule.Filters {
+					if filter.Type == gatewayv1.HTTPRouteFilterRequestHeaderModifier {
+						modifier = filter.RequestHeaderModifier
+					}
+				}
+				if tc.expectedHost == "" {
+					if modifier != nil {
+						t.Errorf("expected no RequestHeaderModifier, got %+v", modifier)
+					}
+					continue
+				}
+				expectedSet := []gatewayv1.HTTPHeader{{Name: "Host", Value: tc.expectedHost}}
+				if modifier == nil || !reflect.DeepEqual(modifier.Set, expectedSet) {
+					t.Errorf("expected RequestHeaderModifier setting %+v, got %+v", expectedSet, modifier)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeHeaderFilter_SetAndAdd(t *testing.T) {
+	testCases := []struct {
+		name        string
+		existing    *gatewayv1.HTTPHeaderFilter
+		addition    *gatewayv1.HTTPHeaderFilter
+		expectedSet []gatewayv1.HTTPHeader
+		expectedAdd []gatewayv1.HTTPHeader
+	}{
+		{
+			name:        "Set replaces an earlier Add of the same header",
+			existing:    &gatewayv1.HTTPHeaderFilter{Add: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "a"}}},
+			addition:    &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "x-team", Value: "b"}}},
+			expectedSet: []gatewayv1.HTTPHeader{{Name: "x-team", Value: "b"}},
+		},
+		{
+			name:        "later Add of a Set header is dropped",
+			existing:    &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "a"}}},
+			addition:    &gatewayv1.HTTPHeaderFilter{Add: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "b"}}},
+			expectedSet: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "a"}},
+		},
+		{
+			name:        "first Set of a duplicated header wins",
+			existing:    &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "Connection", Value: "close"}}},
+			addition:    &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "Connection", Value: "keep-alive"}}},
+			expectedSet: []gatewayv1.HTTPHeader{{Name: "Connection", Value: "close"}},
+		},
+		{
+			name:        "Add of other headers is kept",
+			existing:    &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "a"}}},
+			addition:    &gatewayv1.HTTPHeaderFilter{Add: []gatewayv1.HTTPHeader{{Name: "X-Trace", Value: "1"}}},
+			expectedSet: []gatewayv1.HTTPHeader{{Name: "X-Team", Value: "a"}},
+			expectedAdd: []gatewayv1.HTTPHeader{{Name: "X-Trace", Value: "1"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged := mergeHeaderFilter(tc.existing, tc.addition)
+			if !reflect.DeepEqual(merged.Set, tc.expectedSet) {
+				t.Errorf("expected Set %+v, got %+v", tc.expectedSet, merged.Set)
+			}
+			if !reflect.DeepEqual(merged.Add, tc.expectedAdd) {
+				t.Errorf("expected Add %+v, got %+v", tc.expectedAdd, merged.Add)
+			}
+		})
+	}
+}
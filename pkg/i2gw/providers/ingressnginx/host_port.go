@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// listenerPortHintAnnotation is set on the HTTPRoutes of Ingress hosts that
+// carried a port, to the port the route was probably meant to be served on
+const listenerPortHintAnnotation = "ingress2gateway.kubernetes.io/listener-port-hint"
+
+// hostPorts maps the Ingresses to the ports stripped from their rule hosts
+type hostPorts map[types.NamespacedName]map[string]int32
+
+// stripHostPorts removes the port of the rule and TLS hosts that mistakenly
+// include one (example.com:8080), since a Gateway API Hostname cannot contain
+// a port. Each stripped host is reported with a Warning, and the ports of the
+// rule hosts are returned as listener hints.
+func stripHostPorts(ingresses []networkingv1.Ingress) ([]networkingv1.Ingress, hostPorts) {
+	ports := hostPorts{}
+	stripped := make([]networkingv1.Ingress, 0, len(ingresses))
+
+	for _, ingress := range ingresses {
+		if !hasHostWithPort(&ingress) {
+			stripped = append(stripped, ingress)
+			continue
+		}
+
+		ingress = *ingress.DeepCopy()
+		ingressKey := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+		for i := range ingress.Spec.Rules {
+			rule := &ingress.Spec.Rules[i]
+			host, port, ok := splitHostPort(rule.Host)
+			if !ok {
+				continue
+			}
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("rule host %q includes a port, which Gateway API hostnames cannot contain. The port is removed and recorded in the %s annotation of the HTTPRoute; "+
+					"the Gateway listeners use ports 80 and 443, add a listener on port %d if clients use it",
+					rule.Host, listenerPortHintAnnotation, port),
+				&ingress)
+			rule.Host = host
+			if ports[ingressKey] == nil {
+				ports[ingressKey] = map[string]int32{}
+			}
+			ports[ingressKey][host] = port
+		}
+		for i := range ingress.Spec.TLS {
+			for j, tlsHost := range ingress.Spec.TLS[i].Hosts {
+				if host, _, ok := splitHostPort(tlsHost); ok {
+					notify(notifications.WarningNotification,
+						fmt.Sprintf("TLS host %q includes a port, which is removed", tlsHost),
+						&ingress)
+					ingress.Spec.TLS[i].Hosts[j] = host
+				}
+			}
+		}
+		stripped = append(stripped, ingress)
+	}
+
+	return stripped, ports
+}
+
+// hasHostWithPort returns true if a rule or TLS host of the Ingress has a port
+func hasHostWithPort(ingress *networkingv1.Ingress) bool {
+	for _, rule := range ingress.Spec.Rules {
+		if _, _, ok := splitHostPort(rule.Host); ok {
+			return true
+		}
+	}
+	for _, tls := range ingress.Spec.TLS {
+		if slices.ContainsFunc(tls.Hosts, func(host string) bool {
+			_, _, ok := splitHostPort(host)
+			return ok
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort splits a host:port with a valid port. Hosts without a port,
+// including IPv6 addresses, are left to the hostname validation.
+func splitHostPort(hostPort string) (string, int32, bool) {
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil || host == "" {
+		return "", 0, false
+	}
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, false
+	}
+	return host, int32(port), true
+}
+
+// applyListenerPortHints records the ports stripped from the rule hosts in the
+// IR of the routes generated for the hosts
+func applyListenerPortHints(ir *intermediate.IR, ingresses []networkingv1.Ingress, ports hostPorts) {
+	for i := range ingresses {
+		ing := &ingresses[i]
+		for host, port := range ports[types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}] {
+			for routeKey, routeCtx := range ir.HTTPRoutes {
+				if routeKey.Namespace != ing.Namespace || !routeContainsIngress(routeCtx, ing) ||
+					!slices.Contains(routeCtx.HTTPRoute.Spec.Hostnames, gatewayv1.Hostname(host)) {
+					continue
+				}
+				if routeCtx.ProviderSpecificIR.IngressNginx == nil {
+					routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+				}
+				routeCtx.ProviderSpecificIR.IngressNginx.ListenerPortHint = port
+				ir.HTTPRoutes[routeKey] = routeCtx
+			}
+		}
+	}
+}
+
+// applyListenerPortHintAnnotations sets the listenerPortHintAnnotation on the
+// HTTPRoutes with a listener port hint
+func applyListenerPortHintAnnotations(gatewayResources *i2gw.GatewayResources, ir intermediate.IR) {
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+		route, ok := gatewayResources.HTTPRoutes[routeKey]
+		if nginxIR == nil || nginxIR.ListenerPortHint == 0 || !ok {
+			continue
+		}
+		route.Annotations = withAnnotation(route.Annotations, listenerPortHintAnnotation, strconv.Itoa(int(nginxIR.ListenerPortHint)))
+		gatewayResources.HTTPRoutes[routeKey] = route
+	}
+}
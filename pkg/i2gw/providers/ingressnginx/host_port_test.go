@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestToGatewayResources_HostWithPort(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(nil)
+	ingress.Spec.Rules[0].Host = "example.com:8080"
+	ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com:8080"}, SecretName: "example-com-tls"}}
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+		},
+	}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	route, ok := gatewayResources.HTTPRoutes[routeKey]
+	if !ok {
+		t.Fatalf("expected HTTPRoute %s, got %v", routeKey, gatewayResources.HTTPRoutes)
+	}
+	if diff := cmp.Diff([]gatewayv1.Hostname{"example.com"}, route.Spec.Hostnames); diff != "" {
+		t.Errorf("unexpected hostnames (-want +got):\n%s", diff)
+	}
+	if hint := route.Annotations[listenerPortHintAnnotation]; hint != "8080" {
+		t.Errorf("expected listener port hint 8080, got %q", hint)
+	}
+
+	gateway := gatewayResources.Gateways[types.NamespacedName{Namespace: "default", Name: "default-gateway"}]
+	var httpsListener *gatewayv1.Listener
+	for i, listener := range gateway.Spec.Listeners {
+		if listener.Protocol == gatewayv1.HTTPSProtocolType {
+			httpsListener = &gateway.Spec.Listeners[i]
+		}
+	}
+	if httpsListener == nil || httpsListener.Hostname == nil || *httpsListener.Hostname != "example.com" {
+		t.Errorf("expected an HTTPS listener for example.com, got %+v", gateway.Spec.Listeners)
+	}
+
+	warnings := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification && strings.Contains(n.Message, `"example.com:8080" includes a port`) {
+			warnings++
+		}
+	}
+	if warnings != 2 {
+		t.Errorf("expected 2 warnings for the rule and TLS hosts with a port, got %d", warnings)
+	}
+}
+
+func TestSplitHostPort(t *testing.T) {
+	testCases := []struct {
+		hostPort     string
+		expectedHost string
+		expectedPort int32
+		expectedOK   bool
+	}{
+		{hostPort: "example.com:8080", expectedHost: "example.com", expectedPort: 8080, expectedOK: true},
+		{hostPort: "*.example.com:443", expectedHost: "*.example.com", expectedPort: 443, expectedOK: true},
+		{hostPort: "example.com"},
+		{hostPort: "example.com:http"},
+		{hostPort: "example.com:70000"},
+		{hostPort: "2001:db8::1"},
+		{hostPort: ""},
+	}
+
+	for _, tc := range testCases {
+		host, port, ok := splitHostPort(tc.hostPort)
+		if host != tc.expectedHost || port != tc.expectedPort || ok != tc.expectedOK {
+			t.Errorf("splitHostPort(%q) = (%q, %d, %v), expected (%q, %d, %v)",
+				tc.hostPort, host, port, ok, tc.expectedHost, tc.expectedPort, tc.expectedOK)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// parseIngressClasses splits the comma separated --ingress-class value
+func parseIngressClasses(value string) sets.Set[string] {
+	classes := sets.New[string]()
+	for _, class := range strings.Split(value, ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			classes.Insert(class)
+		}
+	}
+	return classes
+}
+
+// applyIngressClassAnnotations records the ingress class of the Ingresses the
+// generated resources come from in their i2gw.SourceIngressClassAnnotationKey
+// annotation, so the output can be split by class. Resources built from the
+// Ingresses of several classes, like a Gateway shared by their routes, are
+// left without the annotation.
+func applyIngressClassAnnotations(gatewayResources *i2gw.GatewayResources, ir intermediate.IR) {
+	routeClasses := make(map[types.NamespacedName]sets.Set[string])
+	serviceClasses := make(map[types.NamespacedName]sets.Set[string])
+	addClasses := func(m map[types.NamespacedName]sets.Set[string], key types.NamespacedName, classes sets.Set[string]) {
+		if m[key] == nil {
+			m[key] = sets.New[string]()
+		}
+		m[key] = m[key].Union(classes)
+	}
+
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		classes := sets.New[string]()
+		for _, ing := range routeSourceIngresses(routeCtx) {
+			classes.Insert(common.GetIngressClass(*ing))
+		}
+		routeClasses[routeKey] = classes
+
+		for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if !isServiceBackendRef(backendRef.BackendObjectReference) {
+					continue
+				}
+				serviceKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(backendRef.Name)}
+				if backendRef.Namespace != nil {
+					serviceKey.Namespace = string(*backendRef.Namespace)
+				}
+				addClasses(serviceClasses, serviceKey, classes)
+			}
+		}
+	}
+
+	gatewayClasses := make(map[types.NamespacedName]sets.Set[string])
+	for routeKey, route := range gatewayResources.HTTPRoutes {
+		classes, ok := routeClasses[routeKey]
+		if !ok {
+			// Generated routes, like SSL redirects, are named after their route
+			classes = routeClasses[types.NamespacedName{Namespace: routeKey.Namespace, Name: strings.TrimSuffix(routeKey.Name, "-redirect")}]
+		}
+		if class, ok := singleClass(classes); ok {
+			route.Annotations = withAnnotation(route.Annotations, i2gw.SourceIngressClassAnnotationKey, class)
+			gatewayResources.HTTPRoutes[routeKey] = route
+		}
+
+		for _, parentRef := range route.Spec.ParentRefs {
+			gatewayKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				gatewayKey.Namespace = string(*parentRef.Namespace)
+			}
+			addClasses(gatewayClasses, gatewayKey, classes)
+		}
+	}
+
+	for gatewayKey, gateway := range gatewayResources.Gateways {
+		if class, ok := singleClass(gatewayClasses[gatewayKey]); ok {
+			gateway.Annotations = withAnnotation(gateway.Annotations, i2gw.SourceIngressClassAnnotationKey, class)
+			gatewayResources.Gateways[gatewayKey] = gateway
+		}
+	}
+
+	for policyKey, policy := range gatewayResources.BackendTLSPolicies {
+		classes := sets.New[string]()
+		for _, targetRef := range policy.Spec.TargetRefs {
+			classes = classes.Union(serviceClasses[types.NamespacedName{Namespace: policyKey.Namespace, Name: string(targetRef.Name)}])
+		}
+		if class, ok := singleClass(classes); ok {
+			policy.Annotations = withAnnotation(policy.Annotations, i2gw.SourceIngressClassAnnotationKey, class)
+			gatewayResources.BackendTLSPolicies[policyKey] = policy
+		}
+	}
+
+	// DestinationRules are generated per Service, for the host of the Service,
+	// and BackendTrafficPolicies per HTTPRoute, which they target
+	for i := range gatewayResources.GatewayExtensions {
+		extension := &gatewayResources.GatewayExtensions[i]
+		var classes sets.Set[string]
+		switch extension.GetKind() {
+		case "DestinationRule":
+			host, _, _ := unstructured.NestedString(extension.Object, "spec", "host")
+			name, rest, _ := strings.Cut(host, ".")
+			namespace, _, _ := strings.Cut(rest, ".")
+			classes = serviceClasses[types.NamespacedName{Namespace: namespace, Name: name}]
+		case "BackendTrafficPolicy":
+			targetRefs, _, _ := unstructured.NestedSlice(extension.Object, "spec", "targetRefs")
+			for _, targetRef := range targetRefs {
+				name, _, _ := unstructured.NestedString(targetRef.(map[string]interface{}), "name")
+				classes = classes.Union(routeClasses[types.NamespacedName{Namespace: extension.GetNamespace(), Name: name}])
+			}
+		default:
+			continue
+		}
+		if class, ok := singleClass(classes); ok {
+			extension.SetAnnotations(withAnnotation(extension.GetAnnotations(), i2gw.SourceIngressClassAnnotationKey, class))
+		}
+	}
+}
+
+// singleClass returns the class if the set holds exactly one class
+func singleClass(classes sets.Set[string]) (string, bool) {
+	if classes.Len() != 1 {
+		return "", false
+	}
+	return classes.UnsortedList()[0], true
+}
+
+// withAnnotation returns a copy of the annotations with the annotation set, so
+// maps shared between resources are not modified
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	updated := make(map[string]string, len(annotations)+1)
+	maps.Copy(updated, annotations)
+	updated[key] = value
+	return updated
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_SplitByIngressClass(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				NginxIngressClassFlag:        "class-a, class-b",
+				GatewayModeFlag:              "per-namespace",
+				KeepOriginalGatewayNamesFlag: "true",
+				SkipReferenceGrantFlag:       "false",
+			},
+		},
+	}).(*Provider)
+	newIngress := func(name, host, class string) networkingv1.Ingress {
+		ing := headersTestIngress(nil)
+		ing.Name = name
+		ing.Spec.IngressClassName = strPtr(class)
+		ing.Spec.Rules[0].Host = host
+		return ing
+	}
+	ingressA := newIngress("ingress-a", "a.example.com", "class-a")
+	ingressB := newIngress("ingress-b", "b.example.com", "class-b")
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingressA.Namespace, Name: ingressA.Name}: &ingressA,
+		{Namespace: ingressB.Namespace, Name: ingressB.Name}: &ingressB,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	bundles := i2gw.SplitByIngressClass([]i2gw.GatewayResources{gatewayResources})
+	got := make(map[string][]string)
+	for _, bundle := range bundles {
+		for key := range bundle.Resources.Gateways {
+			got[bundle.Name] = append(got[bundle.Name], "Gateway "+key.String())
+		}
+		for key := range bundle.Resources.HTTPRoutes {
+			got[bundle.Name] = append(got[bundle.Name], "HTTPRoute "+key.String())
+		}
+		for key := range bundle.Resources.ReferenceGrants {
+			got[bundle.Name] = append(got[bundle.Name], "ReferenceGrant "+key.String())
+		}
+	}
+
+	// The ReferenceGrant is used by the routes of both classes
+	expected := map[string][]string{
+		i2gw.SharedBundleName: {"ReferenceGrant default-gateway/allow-routes-from-default"},
+		"class-a":             {"Gateway default-gateway/class-a", "HTTPRoute default/ingress-a-a-example-com"},
+		"class-b":             {"Gateway default-gateway/class-b", "HTTPRoute default/ingress-b-b-example-com"},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected bundles (-want +got):\n%s", diff)
+	}
+}
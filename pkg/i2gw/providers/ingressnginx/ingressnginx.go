@@ -17,13 +17,18 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -47,6 +52,14 @@ const (
 	// GatewayNameFlag specifies the name of the centralized gateway
 	// Default: "platform-gateway"
 	GatewayNameFlag = "gateway-name"
+
+	// GatewayNamespaceTemplateFlag specifies the per-namespace gateway namespace/name pattern
+	// Default: "{ns}-gateway"
+	GatewayNamespaceTemplateFlag = "gateway-namespace-template"
+
+	// GatewayInServiceNamespaceFlag places per-namespace Gateways in the service namespace
+	// itself instead of a dedicated gateway namespace
+	GatewayInServiceNamespaceFlag = "gateway-in-service-namespace"
 	
 	// OwnerFlag specifies the owner name for sectionName in parentRefs
 	OwnerFlag = "owner"
@@ -57,21 +70,101 @@ const (
 	// SkipReferenceGrantFlag skips generation of ReferenceGrant resources
 	// Default: true (platform team handles ReferenceGrants via Helm)
 	SkipReferenceGrantFlag = "skip-reference-grant"
+
+	// GatewayClassFlag specifies the GatewayClass name set on generated Gateways
+	GatewayClassFlag = "gateway-class"
+
+	// EmitGatewayClassFlag generates a GatewayClass resource for the configured class
+	EmitGatewayClassFlag = "emit-gatewayclass"
+
+	// GatewayClassControllerFlag overrides the controllerName of the emitted GatewayClass
+	// Default: derived from the GatewayClass name (e.g. istio -> istio.io/gateway-controller)
+	GatewayClassControllerFlag = "gatewayclass-controller"
+
+	// RateLimitBurstMultiplierFlag sets the burst (EnvoyFilter max_tokens) as a multiple of
+	// the rate limit when no limit-burst-multiplier annotation is set
+	RateLimitBurstMultiplierFlag = "ratelimit-burst-multiplier"
+
+	// EmitEnvoyFiltersFlag generates the Istio EnvoyFilters, AuthorizationPolicies and
+	// RequestAuthentications of the annotations without a Gateway API equivalent
+	EmitEnvoyFiltersFlag = "emit-envoyfilters"
+
+	// MergeEnvoyFiltersFlag merges the EnvoyFilters generated for a route into a single
+	// EnvoyFilter with multiple configPatches
+	MergeEnvoyFiltersFlag = "merge-envoyfilters"
+
+	// DefaultTLSSecretFlag specifies the certificate used by TLS hosts without a secret
+	// of their own, in the form [namespace/]name
+	DefaultTLSSecretFlag = "default-tls-secret"
+
+	// ExperimentalChannelFlag allows generating resources that need the experimental
+	// Gateway API channel, such as RegularExpression path matches for use-regex
+	ExperimentalChannelFlag = "experimental-channel"
+
+	// StrictTimeoutMappingFlag maps proxy-connect-timeout to an Istio DestinationRule
+	// connectTimeout instead of approximating it with the HTTPRoute backendRequest timeout
+	StrictTimeoutMappingFlag = "strict-timeout-mapping"
+
+	// SSLRedirectStatusFlag sets the status code of generated HTTP to HTTPS redirects
+	SSLRedirectStatusFlag = "ssl-redirect-status"
+
+	// GatewayAnnotationsFlag adds annotations to generated Gateways, as key=value,...
+	GatewayAnnotationsFlag = "gateway-annotations"
+
+	// GatewayLabelsFlag adds labels to generated Gateways, as key=value,...
+	GatewayLabelsFlag = "gateway-labels"
+
+	// KeepOriginalGatewayNamesFlag keeps the names of the Gateways generated from the
+	// ingress classes when they are moved to the per-namespace gateway namespaces
+	KeepOriginalGatewayNamesFlag = "keep-original-gateway-names"
+
+	// ResourceLabelsFlag adds or overrides labels of the other generated resources,
+	// as key=value,...
+	ResourceLabelsFlag = "resource-labels"
+
+	// PreserveAddressesFlag requests the load balancer addresses of the Ingresses
+	// for the Gateways their routes attach to
+	PreserveAddressesFlag = "preserve-addresses"
+
+	// EnabledFeaturesFlag limits the converted annotations to those of the listed
+	// features, separated by commas. All features are enabled when empty
+	EnabledFeaturesFlag = "enabled-features"
+
+	// SourceRangeAuthorizationPolicyFlag converts whitelist-source-range to an Istio
+	// AuthorizationPolicy attached to the Gateway instead of an RBAC EnvoyFilter
+	SourceRangeAuthorizationPolicyFlag = "source-range-authorization-policy"
+
+	// TracingSamplerRatioFlag sets the ratio of requests traced when tracing is
+	// enabled, like otel-sampler-ratio in the ingress-nginx ConfigMap
+	TracingSamplerRatioFlag = "tracing-sampler-ratio"
 	
 	// Default values - Centralized gateway is the default
-	DefaultGatewayMode         = "centralized"
-	DefaultGatewayNamespace    = "ionianshared"
-	DefaultGatewayName         = "platform-gateway"
-	DefaultOwner               = ""
-	DefaultCAConfigMap         = "ca-ame-nginx"
-	DefaultSkipReferenceGrant  = "true"
+	DefaultGatewayMode               = "centralized"
+	DefaultGatewayNamespace          = "ionianshared"
+	DefaultGatewayName               = "platform-gateway"
+	DefaultOwner                     = ""
+	DefaultCAConfigMap               = "ca-ame-nginx"
+	DefaultSkipReferenceGrant        = "true"
+	DefaultGatewayClass              = "istio"
+	DefaultEmitGatewayClass          = "false"
+	DefaultGatewayInServiceNamespace = "false"
+	DefaultRateLimitBurstMultiplier  = "5"
+	DefaultEmitEnvoyFilters          = "false"
+	DefaultMergeEnvoyFilters         = "false"
+	DefaultExperimentalChannel       = "false"
+	DefaultStrictTimeoutMapping      = "false"
+	DefaultSSLRedirectStatus         = "301"
+	DefaultKeepOriginalGatewayNames  = "false"
+	DefaultPreserveAddresses         = "false"
+	DefaultSourceRangeAuthzPolicy    = "false"
+	DefaultTracingSamplerRatio       = ""
 )
 
 func init() {
 	i2gw.ProviderConstructorByName[Name] = NewProvider
 	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
 		Name:         "ingress-class",
-		Description:  "The name of the ingress class to select. Defaults to 'tag-ingress'",
+		Description:  "The names of the ingress classes to select, separated by commas. Defaults to 'tag-ingress'",
 		DefaultValue: NginxIngressClass,
 	})
 	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
@@ -89,6 +182,16 @@ func init() {
 		Description:  "Name of the centralized gateway (only used when gateway-mode=centralized)",
 		DefaultValue: DefaultGatewayName,
 	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayNamespaceTemplateFlag,
+		Description:  "Gateway namespace pattern for per-namespace mode, where {ns} is the service namespace. Either '<namespace>' used for both namespace and name (e.g. '{ns}-gw') or '<namespace>/<name>' (e.g. 'gateways/{ns}')",
+		DefaultValue: common.DefaultGatewayNamespaceTemplate,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayInServiceNamespaceFlag,
+		Description:  "Place per-namespace Gateways in the service namespace instead of a dedicated gateway namespace, so no cross-namespace ReferenceGrants are needed (only used when gateway-mode=per-namespace)",
+		DefaultValue: DefaultGatewayInServiceNamespace,
+	})
 	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
 		Name:         OwnerFlag,
 		Description:  "Owner name for sectionName in parentRefs",
@@ -104,6 +207,96 @@ func init() {
 		Description:  "Skip generation of ReferenceGrant resources (default: true, platform team handles via Helm)",
 		DefaultValue: DefaultSkipReferenceGrant,
 	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayClassFlag,
+		Description:  "GatewayClass name used by generated Gateways (e.g. 'istio' or 'envoy-gateway')",
+		DefaultValue: DefaultGatewayClass,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         EmitGatewayClassFlag,
+		Description:  "Generate a GatewayClass resource for the configured gateway class so the output is self-contained",
+		DefaultValue: DefaultEmitGatewayClass,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayClassControllerFlag,
+		Description:  "controllerName for the emitted GatewayClass (defaults to the known controller for the gateway class)",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         RateLimitBurstMultiplierFlag,
+		Description:  "Rate limit burst (EnvoyFilter token bucket max_tokens) as a multiple of the rate, used when the limit-burst-multiplier annotation is not set. Must be at least 1",
+		DefaultValue: DefaultRateLimitBurstMultiplier,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         EmitEnvoyFiltersFlag,
+		Description:  "Generate Istio EnvoyFilters, AuthorizationPolicies and RequestAuthentications for the annotations without a Gateway API equivalent (rate limit, body size, auth, ...)",
+		DefaultValue: DefaultEmitEnvoyFilters,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         MergeEnvoyFiltersFlag,
+		Description:  "Merge the EnvoyFilters generated for a route (rate limit, body size, auth) into a single EnvoyFilter with multiple configPatches",
+		DefaultValue: DefaultMergeEnvoyFilters,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         DefaultTLSSecretFlag,
+		Description:  "Secret ([namespace/]name) used for TLS hosts without a secretName, like the ingress-nginx default SSL certificate. Also adds a fallback HTTPS listener to generated Gateways",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         ExperimentalChannelFlag,
+		Description:  "Generate resources that require the experimental Gateway API channel, such as RegularExpression path matches for use-regex and retries for proxy-next-upstream",
+		DefaultValue: DefaultExperimentalChannel,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         StrictTimeoutMappingFlag,
+		Description:  "Map proxy-connect-timeout to an Istio DestinationRule connectTimeout instead of approximating it with the HTTPRoute backendRequest timeout",
+		DefaultValue: DefaultStrictTimeoutMapping,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         SSLRedirectStatusFlag,
+		Description:  "Status code of the generated HTTP to HTTPS redirects: 301, 302, 307 or 308. Use 307 or 308 to preserve the request method",
+		DefaultValue: DefaultSSLRedirectStatus,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayAnnotationsFlag,
+		Description:  "Annotations added to every generated Gateway, as a comma-separated list of key=value pairs (e.g. 'networking.istio.io/service-type=ClusterIP')",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayLabelsFlag,
+		Description:  "Labels added to every generated Gateway, as a comma-separated list of key=value pairs (e.g. 'istio.io/rev=stable')",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         KeepOriginalGatewayNamesFlag,
+		Description:  "Keep the Gateway names generated from the ingress classes (e.g. 'nginx') in per-namespace mode, only moving the Gateways to the gateway namespace",
+		DefaultValue: DefaultKeepOriginalGatewayNames,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         ResourceLabelsFlag,
+		Description:  "Labels added to the generated HTTPRoutes, BackendTLSPolicies, ReferenceGrants, EnvoyFilters and other resources besides Gateways, as a comma-separated list of key=value pairs. They override the default 'app.kubernetes.io/managed-by=ingress2gateway' and 'gateway-api-migration=true' labels with the same key",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         PreserveAddressesFlag,
+		Description:  "Set the spec.addresses of each generated Gateway to the status.loadBalancer IPs and hostnames of the Ingresses whose routes attach to it, so the Gateway requests the same addresses",
+		DefaultValue: DefaultPreserveAddresses,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         EnabledFeaturesFlag,
+		Description:  fmt.Sprintf("Only convert the annotations of these features, separated by commas, to stage the migration. The annotations of the other features are skipped with an INFO notification. All features are enabled when empty. Supported features are %v", supportedFeatureNames()),
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         SourceRangeAuthorizationPolicyFlag,
+		Description:  "Convert whitelist-source-range to an Istio AuthorizationPolicy attached to the Gateway instead of an RBAC EnvoyFilter. Requires an Istio gateway class",
+		DefaultValue: DefaultSourceRangeAuthzPolicy,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         TracingSamplerRatioFlag,
+		Description:  "Ratio of requests traced (0.0-1.0) for the Ingresses with enable-opentracing or enable-opentelemetry, like otel-sampler-ratio in the ingress-nginx ConfigMap. All requests are traced when empty",
+		DefaultValue: DefaultTracingSamplerRatio,
+	})
 }
 
 // GatewayConfig holds gateway deployment configuration
@@ -114,29 +307,168 @@ type GatewayConfig struct {
 	Namespace string
 	// Name is the gateway name (for centralized mode, e.g., platform-gateway)
 	Name string
+	// NamespaceTemplate is the gateway namespace pattern for per-namespace mode (e.g., {ns}-gateway)
+	NamespaceTemplate string
+	// GatewayInServiceNamespace places per-namespace Gateways in the service namespace itself
+	GatewayInServiceNamespace bool
 	// Owner is the owner name for sectionName in parentRefs
 	Owner string
 	// SkipReferenceGrant skips ReferenceGrant generation (platform team handles via Helm)
 	SkipReferenceGrant bool
+	// GatewayClassName is the GatewayClass referenced by generated Gateways
+	GatewayClassName string
+	// EmitGatewayClass generates a GatewayClass resource for GatewayClassName
+	EmitGatewayClass bool
+	// GatewayClassController overrides the controllerName of the emitted GatewayClass
+	GatewayClassController string
+	// RateLimitBurstMultiplier is the default burst as a multiple of the rate limit
+	RateLimitBurstMultiplier int
+	// EmitEnvoyFilters generates the Istio resources of the annotations without
+	// a Gateway API equivalent
+	EmitEnvoyFilters bool
+	// MergeEnvoyFilters generates a single EnvoyFilter per route instead of one per feature
+	MergeEnvoyFilters bool
+	// DefaultTLSSecret is the [namespace/]name of the certificate for TLS hosts without a secret
+	DefaultTLSSecret string
+	// ExperimentalChannel allows generating experimental Gateway API resources and fields
+	ExperimentalChannel bool
+	// StrictTimeoutMapping generates DestinationRules for connect timeouts
+	StrictTimeoutMapping bool
+	// SSLRedirectStatus is the status code of HTTP to HTTPS redirects
+	SSLRedirectStatus int
+	// GatewayAnnotations are key=value pairs added to the annotations of generated Gateways
+	GatewayAnnotations string
+	// GatewayLabels are key=value pairs added to the labels of generated Gateways
+	GatewayLabels string
+	// KeepOriginalGatewayNames keeps the generated Gateway names in per-namespace mode
+	KeepOriginalGatewayNames bool
+	// ResourceLabels are key=value pairs added to the labels of the other generated resources
+	ResourceLabels string
+	// PreserveAddresses sets the Ingress load balancer addresses on the generated Gateways
+	PreserveAddresses bool
+	// EnabledFeatures are the comma separated features whose annotations are converted,
+	// all of them if empty
+	EnabledFeatures string
+	// SourceRangeAuthorizationPolicy converts source range allow lists to Istio
+	// AuthorizationPolicies instead of RBAC EnvoyFilters
+	SourceRangeAuthorizationPolicy bool
+	// TracingSamplerRatio is the ratio of requests traced, all of them if empty
+	TracingSamplerRatio string
 }
 
 // IsCentralized returns true if using centralized gateway mode
 func (c GatewayConfig) IsCentralized() bool {
-	return c.Mode == "centralized"
+	return c.Mode == centralizedGatewayMode
+}
+
+// ForRoute returns the gateway configuration for the route, with the mode
+// overridden by the gateway-mode annotation of its source Ingresses
+func (c GatewayConfig) ForRoute(routeCtx intermediate.HTTPRouteContext) GatewayConfig {
+	if nginxIR := routeCtx.ProviderSpecificIR.IngressNginx; nginxIR != nil && nginxIR.GatewayMode != "" {
+		c.Mode = nginxIR.GatewayMode
+	}
+	return c
+}
+
+// ForIngress returns the configuration in the gateway mode of an Ingress,
+// which the gateway-mode annotation overrides like for the routes
+func (c GatewayConfig) ForIngress(ing *networkingv1.Ingress) GatewayConfig {
+	if mode := ing.Annotations[gatewayModeAnnotation]; mode != "" {
+		c.Mode = mode
+	}
+	return c
 }
 
 // GetGatewayRef returns the gateway reference for a given service namespace
 // Gateway namespace patterns:
 // - Centralized: single "platform-gateway" in ionianshared (or configured namespace)
-// - Per-namespace: dedicated "<service>-gateway" namespace with Gateway named "<service>-gateway"
+// - Per-namespace: expanded NamespaceTemplate, by default "<service>-gateway"/"<service>-gateway"
+// - Per-namespace in service namespace: Gateway named as above, in the service namespace
 func (c GatewayConfig) GetGatewayRef(serviceNamespace string) (namespace, name string) {
 	if c.IsCentralized() {
 		return c.Namespace, c.Name
 	}
-	// Per-namespace mode: dedicated gateway namespace
-	// Namespace: <service>-gateway, Gateway name: <service>-gateway
-	gatewayNS := fmt.Sprintf("%s-gateway", serviceNamespace)
-	return gatewayNS, gatewayNS
+	namespace, name = common.ExpandGatewayNamespaceTemplate(cmp.Or(c.NamespaceTemplate, common.DefaultGatewayNamespaceTemplate), serviceNamespace)
+	if c.GatewayInServiceNamespace {
+		return serviceNamespace, name
+	}
+	return namespace, name
+}
+
+// GetRouteGatewayRef returns the gateway reference for an HTTPRoute in its
+// gateway mode. With KeepOriginalGatewayNames, a per-namespace Gateway keeps the
+// name the route was generated with and is only moved to the gateway namespace.
+func (c GatewayConfig) GetRouteGatewayRef(routeKey types.NamespacedName, routeCtx intermediate.HTTPRouteContext) (namespace, name string) {
+	c = c.ForRoute(routeCtx)
+	namespace, name = c.GetGatewayRef(routeKey.Namespace)
+	if c.KeepOriginalGatewayNames && !c.IsCentralized() {
+		name = cmp.Or(originalGatewayName(routeCtx.HTTPRoute), name)
+	}
+	return namespace, name
+}
+
+// GetIngressGatewayRef returns the gateway reference for an Ingress without
+// HTTPRoutes in its gateway mode, like GetRouteGatewayRef. originalName is the
+// name of the Gateway the Ingress listeners were generated in.
+func (c GatewayConfig) GetIngressGatewayRef(ing *networkingv1.Ingress, originalName string) (namespace, name string) {
+	c = c.ForIngress(ing)
+	namespace, name = c.GetGatewayRef(ing.Namespace)
+	if c.KeepOriginalGatewayNames && !c.IsCentralized() {
+		name = cmp.Or(originalName, name)
+	}
+	return namespace, name
+}
+
+// originalGatewayName returns the name of the Gateway the route was generated
+// with, named after the ingress class
+func originalGatewayName(route gatewayv1.HTTPRoute) string {
+	for _, parentRef := range route.Spec.ParentRefs {
+		if parentRef.Kind == nil || *parentRef.Kind == "Gateway" {
+			return string(parentRef.Name)
+		}
+	}
+	return ""
+}
+
+// Validate checks the gateway configuration flags
+func (c GatewayConfig) Validate() field.ErrorList {
+	var errs field.ErrorList
+
+	if !c.IsCentralized() && c.NamespaceTemplate != "" {
+		errs = append(errs, common.ValidateGatewayNamespaceTemplate(c.NamespaceTemplate, field.NewPath(Name, GatewayNamespaceTemplateFlag))...)
+	}
+	if c.DefaultTLSSecret != "" {
+		if ref := defaultTLSSecretRef(c.DefaultTLSSecret); ref.Name == "" || strings.Contains(string(ref.Name), "/") || (ref.Namespace != nil && *ref.Namespace == "") {
+			errs = append(errs, field.Invalid(field.NewPath(Name, DefaultTLSSecretFlag), c.DefaultTLSSecret, "must be in the form [namespace/]name"))
+		}
+	}
+	if !slices.Contains(sslRedirectStatusCodes, c.SSLRedirectStatus) {
+		errs = append(errs, field.NotSupported(field.NewPath(Name, SSLRedirectStatusFlag), c.SSLRedirectStatus, sslRedirectStatusCodeStrings()))
+	}
+	if _, annotationErrs := common.ParseGatewayAnnotations(c.GatewayAnnotations, field.NewPath(Name, GatewayAnnotationsFlag)); len(annotationErrs) > 0 {
+		errs = append(errs, annotationErrs...)
+	}
+	if _, labelErrs := common.ParseGatewayLabels(c.GatewayLabels, field.NewPath(Name, GatewayLabelsFlag)); len(labelErrs) > 0 {
+		errs = append(errs, labelErrs...)
+	}
+	if _, labelErrs := common.ParseLabels(c.ResourceLabels, field.NewPath(Name, ResourceLabelsFlag)); len(labelErrs) > 0 {
+		errs = append(errs, labelErrs...)
+	}
+	errs = append(errs, validateEnabledFeatures(c.EnabledFeatures, field.NewPath(Name, EnabledFeaturesFlag))...)
+	if c.SourceRangeAuthorizationPolicy && gatewayClassController(c) != knownGatewayClassControllers["istio"] {
+		errs = append(errs, field.Invalid(field.NewPath(Name, SourceRangeAuthorizationPolicyFlag), c.SourceRangeAuthorizationPolicy,
+			fmt.Sprintf("requires an Istio gateway class, got %q", c.GatewayClassName)))
+	}
+	if c.TracingSamplerRatio != "" {
+		if _, err := parseTracingSamplerRatio(c.TracingSamplerRatio); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath(Name, TracingSamplerRatioFlag), c.TracingSamplerRatio, err.Error()))
+		}
+	}
+	if c.RateLimitBurstMultiplier < 1 {
+		errs = append(errs, field.Invalid(field.NewPath(Name, RateLimitBurstMultiplierFlag), c.RateLimitBurstMultiplier, "must be an integer of at least 1"))
+	}
+
+	return errs
 }
 
 // Provider implements the i2gw.Provider interface.
@@ -150,9 +482,12 @@ type Provider struct {
 // NewProvider constructs and returns the ingress-nginx implementation of i2gw.Provider.
 func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 	gwConfig := GatewayConfig{
-		Mode:      DefaultGatewayMode,
-		Namespace: DefaultGatewayNamespace,
-		Name:      DefaultGatewayName,
+		Mode:                     DefaultGatewayMode,
+		Namespace:                DefaultGatewayNamespace,
+		Name:                     DefaultGatewayName,
+		GatewayClassName:         DefaultGatewayClass,
+		RateLimitBurstMultiplier: defaultRateLimitBurstMultiplier,
+		SSLRedirectStatus:        defaultSSLRedirectStatus,
 	}
 	
 	// Read provider-specific flags
@@ -167,6 +502,12 @@ func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 			if name, ok := flags[GatewayNameFlag]; ok && name != "" {
 				gwConfig.Name = name
 			}
+			if template, ok := flags[GatewayNamespaceTemplateFlag]; ok && template != "" {
+				gwConfig.NamespaceTemplate = template
+			}
+			if inServiceNS, ok := flags[GatewayInServiceNamespaceFlag]; ok {
+				gwConfig.GatewayInServiceNamespace = inServiceNS == "true"
+			}
 			if owner, ok := flags[OwnerFlag]; ok && owner != "" {
 				gwConfig.Owner = owner
 			}
@@ -175,13 +516,76 @@ func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 			} else {
 				gwConfig.SkipReferenceGrant = true // Default to true
 			}
+			if class, ok := flags[GatewayClassFlag]; ok && class != "" {
+				gwConfig.GatewayClassName = class
+			}
+			if emit, ok := flags[EmitGatewayClassFlag]; ok {
+				gwConfig.EmitGatewayClass = emit == "true"
+			}
+			if controller, ok := flags[GatewayClassControllerFlag]; ok && controller != "" {
+				gwConfig.GatewayClassController = controller
+			}
+			if multiplier, ok := flags[RateLimitBurstMultiplierFlag]; ok && multiplier != "" {
+				// Unparsable values are left as 0 and rejected by Validate
+				gwConfig.RateLimitBurstMultiplier, _ = strconv.Atoi(multiplier)
+			}
+			if emit, ok := flags[EmitEnvoyFiltersFlag]; ok {
+				gwConfig.EmitEnvoyFilters = emit == "true"
+			}
+			if merge, ok := flags[MergeEnvoyFiltersFlag]; ok {
+				gwConfig.MergeEnvoyFilters = merge == "true"
+			}
+			if secret, ok := flags[DefaultTLSSecretFlag]; ok {
+				gwConfig.DefaultTLSSecret = secret
+			}
+			if experimental, ok := flags[ExperimentalChannelFlag]; ok {
+				gwConfig.ExperimentalChannel = experimental == "true"
+			}
+			if status, ok := flags[SSLRedirectStatusFlag]; ok && status != "" {
+				// Unparsable values are left as 0 and rejected by Validate
+				gwConfig.SSLRedirectStatus, _ = strconv.Atoi(status)
+			}
+			if strict, ok := flags[StrictTimeoutMappingFlag]; ok {
+				gwConfig.StrictTimeoutMapping = strict == "true"
+			}
+			if annotations, ok := flags[GatewayAnnotationsFlag]; ok {
+				gwConfig.GatewayAnnotations = annotations
+			}
+			if labels, ok := flags[GatewayLabelsFlag]; ok {
+				gwConfig.GatewayLabels = labels
+			}
+			if keepNames, ok := flags[KeepOriginalGatewayNamesFlag]; ok {
+				gwConfig.KeepOriginalGatewayNames = keepNames == "true"
+			}
+			if labels, ok := flags[ResourceLabelsFlag]; ok {
+				gwConfig.ResourceLabels = labels
+			}
+			if preserve, ok := flags[PreserveAddressesFlag]; ok {
+				gwConfig.PreserveAddresses = preserve == "true"
+			}
+			if features, ok := flags[EnabledFeaturesFlag]; ok {
+				gwConfig.EnabledFeatures = features
+			}
+			if authzPolicy, ok := flags[SourceRangeAuthorizationPolicyFlag]; ok {
+				gwConfig.SourceRangeAuthorizationPolicy = authzPolicy == "true"
+			}
+			if ratio, ok := flags[TracingSamplerRatioFlag]; ok {
+				gwConfig.TracingSamplerRatio = ratio
+			}
 		}
 	}
 	
+	converter := newResourcesToIRConverter()
+	converter.experimentalChannel = gwConfig.ExperimentalChannel
+	converter.strictTimeoutMapping = gwConfig.StrictTimeoutMapping
+	converter.tracingSamplerRatio = gwConfig.TracingSamplerRatio
+	converter.gatewayClassController = gatewayClassController(gwConfig)
+	converter.enabledFeatures = parseEnabledFeatures(gwConfig.EnabledFeatures)
+
 	return &Provider{
 		storage:                newResourcesStorage(),
 		resourceReader:         newResourceReader(conf),
-		resourcesToIRConverter: newResourcesToIRConverter(),
+		resourcesToIRConverter: converter,
 		gatewayConfig:          gwConfig,
 	}
 }
@@ -193,6 +597,13 @@ func (p *Provider) ToIR() (intermediate.IR, field.ErrorList) {
 }
 
 func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources, field.ErrorList) {
+	if errs := p.gatewayConfig.Validate(); len(errs) != 0 {
+		return i2gw.GatewayResources{}, errs
+	}
+	if errs := validateIR(ir); len(errs) != 0 {
+		return i2gw.GatewayResources{}, errs
+	}
+
 	gatewayResources, errs := common.ToGatewayResources(ir)
 	if len(errs) != 0 {
 		return i2gw.GatewayResources{}, errs
@@ -200,12 +611,37 @@ func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources
 	
 	// Transform Gateways based on gateway mode
 	p.transformGatewaysForMode(&gatewayResources, ir)
+
+	// Keep a single catch-all defaultBackend route per Gateway
+	dropConflictingDefaultBackendRoutes(&gatewayResources, ir, p.gatewayConfig)
+
+	// Fill in certificates for TLS hosts without a secret
+	applyDefaultTLSSecret(&gatewayResources, p.gatewayConfig)
+
+	// Report route hostnames that no listener of their Gateway matches
+	validateListenerHostnames(&gatewayResources)
+
+	// Add the configured infrastructure annotations and labels to the Gateways
+	applyGatewayMetadata(&gatewayResources, p.gatewayConfig)
+
+	// Request the load balancer addresses of the Ingresses (opt-in)
+	if p.gatewayConfig.PreserveAddresses {
+		applyPreservedAddresses(&gatewayResources, ir, p.gatewayConfig)
+	}
+
+	// Let routes from the service namespaces attach to the generated Gateways
+	common.AllowCrossNamespaceRoutes(&gatewayResources)
+	
+	// Generate a GatewayClass for the configured class (opt-in)
+	if p.gatewayConfig.EmitGatewayClass {
+		buildGatewayClass(&gatewayResources, p.gatewayConfig)
+	}
 	
 	// Generate SSL redirect HTTPRoutes
 	buildSSLRedirectRoutes(ir, &gatewayResources, p.gatewayConfig)
-	
-	// Build Istio EnvoyFilters for implementation-specific features
-	// buildIstioEnvoyFilters(ir, &gatewayResources, p.gatewayConfig)
+
+	// Generate DestinationRules for connect timeouts (opt-in) and GRPC backends
+	buildDestinationRules(ir, &gatewayResources, p.gatewayConfig)
 	
 	// Generate ReferenceGrants for cross-namespace routing (unless skipped)
 	// By default, platform team handles ReferenceGrants via istio-meshless-helm chart
@@ -218,9 +654,34 @@ func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources
 			nil,
 		)
 	}
+
+	// Drop the empty HTTPRoutes and Gateways, and the references to them
+	pruneEmptyResources(&gatewayResources)
+
+	// Build Istio EnvoyFilters for implementation-specific features
+	buildIstioEnvoyFilters(ir, &gatewayResources, p.gatewayConfig)
+
+	// Combine the traffic settings of each route in a BackendTrafficPolicy
+	// for Envoy Gateway classes
+	if isEnvoyGatewayClass(p.gatewayConfig) {
+		buildBackendTrafficPolicies(ir, &gatewayResources, p.gatewayConfig)
+	}
 	
+	// Label the routes and policies converted from the Ingresses like the
+	// resources generated above
+	applyResourceLabels(&gatewayResources, p.gatewayConfig)
+
+	// Record the ports stripped from the Ingress hosts on their routes
+	applyListenerPortHintAnnotations(&gatewayResources, ir)
+
+	// Record the ingress class of the resources, to split the output by class
+	applyIngressClassAnnotations(&gatewayResources, ir)
+
 	// Emit centralized mode warnings for auth annotations
 	p.emitCentralizedModeWarnings(ir)
+
+	// Summarize the notifications of the conversion per Ingress
+	emitMigrationSummary(p.storage.Ingresses.List(), ir)
 	
 	return gatewayResources, nil
 }
@@ -228,12 +689,9 @@ func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources
 // emitCentralizedModeWarnings emits warnings for auth annotations when using centralized gateway mode
 // In centralized mode, auth EnvoyFilters apply to the shared platform Gateway affecting ALL services
 func (p *Provider) emitCentralizedModeWarnings(ir intermediate.IR) {
-	if !p.gatewayConfig.IsCentralized() {
-		return // No warnings needed for per-namespace mode
-	}
-	
 	for _, routeCtx := range ir.HTTPRoutes {
-		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
+		// No warnings needed for per-namespace mode
+		if routeCtx.ProviderSpecificIR.IngressNginx == nil || !p.gatewayConfig.ForRoute(routeCtx).IsCentralized() {
 			continue
 		}
 		
@@ -266,81 +724,104 @@ func (p *Provider) emitCentralizedModeWarnings(ir intermediate.IR) {
 // transformGatewaysForMode transforms the generated Gateways based on the gateway mode.
 // In per-namespace mode, each service namespace gets its own Gateway in a dedicated gateway namespace.
 // In centralized mode, all routes use a single pre-provisioned platform Gateway (no Gateway generated).
+// The mode of a route can be overridden with the gateway-mode annotation on its source Ingresses.
 func (p *Provider) transformGatewaysForMode(gatewayResources *i2gw.GatewayResources, ir intermediate.IR) {
-	if p.gatewayConfig.Mode == "centralized" {
-		// For centralized mode, the platform-gateway is pre-provisioned by the platform team.
-		// We only need to update HTTPRoutes to reference it - do NOT generate Gateway resources.
-		centralizedGatewayKey := types.NamespacedName{
-			Namespace: p.gatewayConfig.Namespace,
-			Name:      p.gatewayConfig.Name,
-		}
-		
-		// Update all HTTPRoutes to reference the centralized gateway
-		for oldKey := range gatewayResources.Gateways {
-			p.updateHTTPRouteParentRefs(gatewayResources, oldKey, centralizedGatewayKey)
+	// Group HTTPRoutes by the Gateway they attach to in their mode
+	routesByGateway := make(map[types.NamespacedName][]types.NamespacedName)
+	perNamespaceGateways := make(map[types.NamespacedName]bool)
+	for routeKey := range gatewayResources.HTTPRoutes {
+		gwConfig := p.gatewayConfig.ForRoute(ir.HTTPRoutes[routeKey])
+		gwNamespace, gwName := p.gatewayConfig.GetRouteGatewayRef(routeKey, ir.HTTPRoutes[routeKey])
+		gwKey := types.NamespacedName{Namespace: gwNamespace, Name: gwName}
+		routesByGateway[gwKey] = append(routesByGateway[gwKey], routeKey)
+		if !gwConfig.IsCentralized() {
+			perNamespaceGateways[gwKey] = true
 		}
-		
-		// Clear the Gateways map - centralized gateway is pre-provisioned, not generated
-		gatewayResources.Gateways = make(map[types.NamespacedName]gatewayv1.Gateway)
-		return
 	}
-	
-	// Per-namespace mode (default): Create dedicated gateway namespaces
-	// Group HTTPRoutes by their source namespace
-	namespaceRoutes := make(map[string][]types.NamespacedName)
-	for routeKey := range gatewayResources.HTTPRoutes {
-		namespaceRoutes[routeKey.Namespace] = append(namespaceRoutes[routeKey.Namespace], routeKey)
+
+	// Gateways with the listeners of TLS-only Ingresses are needed even though
+	// no route attaches to them
+	for oldKey, gwCtx := range ir.Gateways {
+		if gwCtx.ProviderSpecificIR.IngressNginx == nil {
+			continue
+		}
+		for _, ing := range gwCtx.ProviderSpecificIR.IngressNginx.TLSOnlyIngresses {
+			if gwConfig := p.gatewayConfig.ForIngress(ing); gwConfig.IsCentralized() {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("the HTTPS listeners of the TLS-only Ingress are not generated in centralized mode, add its certificates to the Gateway %s/%s",
+						gwConfig.Namespace, gwConfig.Name),
+					ing,
+				)
+				continue
+			}
+			gwNamespace, gwName := p.gatewayConfig.GetIngressGatewayRef(ing, oldKey.Name)
+			perNamespaceGateways[types.NamespacedName{Namespace: gwNamespace, Name: gwName}] = true
+		}
 	}
 	
-	// Create a new Gateway for each namespace that has routes
+	// For centralized mode, the platform-gateway is pre-provisioned by the platform team.
+	// Routes only need to reference it - do NOT generate Gateway resources.
+	// For per-namespace mode, create a new Gateway for each namespace that has routes
 	newGateways := make(map[types.NamespacedName]gatewayv1.Gateway)
-	oldToNewGateway := make(map[types.NamespacedName]types.NamespacedName)
-	
-	for namespace := range namespaceRoutes {
-		// Get the gateway reference for this namespace
-		gwNamespace, gwName := p.gatewayConfig.GetGatewayRef(namespace)
-		newKey := types.NamespacedName{
-			Namespace: gwNamespace,
-			Name:      gwName,
-		}
-		
+	for newKey := range perNamespaceGateways {
 		// Find an existing gateway to use as template (take listeners from all gateways)
 		var templateGateway *gatewayv1.Gateway
-		for oldKey, gw := range gatewayResources.Gateways {
+		for _, gw := range gatewayResources.Gateways {
 			if templateGateway == nil {
-				gwCopy := gw.DeepCopy()
-				templateGateway = gwCopy
-				oldToNewGateway[oldKey] = newKey
+				templateGateway = gw.DeepCopy()
 			} else {
 				// Merge listeners from other gateways
 				templateGateway.Spec.Listeners = append(templateGateway.Spec.Listeners, gw.Spec.Listeners...)
-				oldToNewGateway[oldKey] = newKey
 			}
 		}
 		
 		if templateGateway != nil {
 			// Update the gateway with per-namespace naming
-			templateGateway.Namespace = gwNamespace
-			templateGateway.Name = gwName
-			// Use istio as the gateway class for Istio deployments
-			istioClass := gatewayv1.ObjectName("istio")
-			templateGateway.Spec.GatewayClassName = istioClass
+			templateGateway.Namespace = newKey.Namespace
+			templateGateway.Name = newKey.Name
+			// Use the configured gateway class (istio by default)
+			templateGateway.Spec.GatewayClassName = gatewayv1.ObjectName(p.gatewayConfig.GatewayClassName)
 			newGateways[newKey] = *templateGateway
 		}
 	}
 	
-	// Update HTTPRoutes to reference the new gateways
-	for oldKey, newKey := range oldToNewGateway {
-		p.updateHTTPRouteParentRefs(gatewayResources, oldKey, newKey)
+	// Update HTTPRoutes to reference their new gateways
+	for newKey, routeKeys := range routesByGateway {
+		for oldKey := range gatewayResources.Gateways {
+			p.updateHTTPRouteParentRefs(gatewayResources, routeKeys, oldKey, newKey)
+		}
 	}
 	
 	gatewayResources.Gateways = newGateways
 }
 
-// updateHTTPRouteParentRefs updates HTTPRoute parentRefs from old gateway to new gateway
+// applyGatewayMetadata adds the --gateway-annotations and --gateway-labels to the
+// generated Gateways. The pre-provisioned centralized Gateway is not generated,
+// so the metadata has to be set on it by the platform team.
+func applyGatewayMetadata(gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	if gwConfig.GatewayAnnotations == "" && gwConfig.GatewayLabels == "" {
+		return
+	}
+
+	// Both values were checked by GatewayConfig.Validate
+	annotations, _ := common.ParseGatewayAnnotations(gwConfig.GatewayAnnotations, nil)
+	labels, _ := common.ParseGatewayLabels(gwConfig.GatewayLabels, nil)
+	common.ApplyGatewayMetadata(gatewayResources, annotations, labels)
+
+	if gwConfig.IsCentralized() {
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("Gateway annotations and labels are only added to generated Gateways; set them on the centralized Gateway %s/%s directly",
+				gwConfig.Namespace, gwConfig.Name),
+			nil,
+		)
+	}
+}
+
+// updateHTTPRouteParentRefs updates the parentRefs of the given HTTPRoutes from old gateway to new gateway
 // When owner is specified, creates two parentRefs: one for http and one for https-{owner}
-func (p *Provider) updateHTTPRouteParentRefs(gatewayResources *i2gw.GatewayResources, oldGw, newGw types.NamespacedName) {
-	for routeKey, route := range gatewayResources.HTTPRoutes {
+func (p *Provider) updateHTTPRouteParentRefs(gatewayResources *i2gw.GatewayResources, routeKeys []types.NamespacedName, oldGw, newGw types.NamespacedName) {
+	for _, routeKey := range routeKeys {
+		route := gatewayResources.HTTPRoutes[routeKey]
 		updated := false
 		var newParentRefs []gatewayv1.ParentReference
 		
@@ -0,0 +1,336 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGatewayConfig_NamespaceTemplate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		template          string
+		expectedNamespace string
+		expectedName      string
+	}{
+		{
+			name:              "default template",
+			expectedNamespace: "team-a-gateway",
+			expectedName:      "team-a-gateway",
+		},
+		{
+			name:              "custom suffix",
+			template:          "{ns}-gw",
+			expectedNamespace: "team-a-gw",
+			expectedName:      "team-a-gw",
+		},
+		{
+			name:              "shared gateway namespace",
+			template:          "gateways/{ns}",
+			expectedNamespace: "gateways",
+			expectedName:      "team-a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:              "per-namespace",
+						GatewayNamespaceTemplateFlag: tc.template,
+					},
+				},
+			}).(*Provider)
+
+			namespace, name := provider.gatewayConfig.GetGatewayRef("team-a")
+			if namespace != tc.expectedNamespace || name != tc.expectedName {
+				t.Errorf("expected gateway %s/%s, got %s/%s", tc.expectedNamespace, tc.expectedName, namespace, name)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_InvalidNamespaceTemplate(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				GatewayModeFlag:              "per-namespace",
+				GatewayNamespaceTemplateFlag: "{ns}_Gateway",
+			},
+		},
+	}).(*Provider)
+
+	_, errs := provider.ToGatewayResources(intermediate.IR{})
+	if len(errs) == 0 {
+		t.Fatalf("expected an error for an invalid gateway namespace template")
+	}
+	if errs[0].Field != "ingress-nginx.gateway-namespace-template" {
+		t.Errorf("expected error on the template flag, got %q", errs[0].Field)
+	}
+}
+
+func TestGatewayConfig_ValidateBurstMultiplier(t *testing.T) {
+	testCases := []struct {
+		multiplier  string
+		expectValid bool
+	}{
+		{multiplier: "", expectValid: true},
+		{multiplier: "1", expectValid: true},
+		{multiplier: "2", expectValid: true},
+		{multiplier: "0", expectValid: false},
+		{multiplier: "-3", expectValid: false},
+		{multiplier: "two", expectValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.multiplier, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {RateLimitBurstMultiplierFlag: tc.multiplier},
+				},
+			}).(*Provider)
+
+			errs := provider.gatewayConfig.Validate()
+			if tc.expectValid && len(errs) > 0 {
+				t.Errorf("expected multiplier %q to be valid, got %v", tc.multiplier, errs)
+			}
+			if !tc.expectValid && len(errs) == 0 {
+				t.Errorf("expected multiplier %q to be invalid", tc.multiplier)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_GatewayInServiceNamespace(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		inServiceNamespace      string
+		expectedGateway         types.NamespacedName
+		expectedReferenceGrants int
+	}{
+		{
+			name:                    "dedicated gateway namespace",
+			inServiceNamespace:      "false",
+			expectedGateway:         types.NamespacedName{Namespace: "default-gateway", Name: "default-gateway"},
+			expectedReferenceGrants: 1,
+		},
+		{
+			name:                    "gateway in service namespace",
+			inServiceNamespace:      "true",
+			expectedGateway:         types.NamespacedName{Namespace: "default", Name: "default-gateway"},
+			expectedReferenceGrants: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:               "per-namespace",
+						GatewayInServiceNamespaceFlag: tc.inServiceNamespace,
+						SkipReferenceGrantFlag:        "false",
+					},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(nil)
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if _, ok := gatewayResources.Gateways[tc.expectedGateway]; !ok {
+				t.Errorf("expected Gateway %s, got %v", tc.expectedGateway, gatewayResources.Gateways)
+			}
+			for _, route := range gatewayResources.HTTPRoutes {
+				for _, parentRef := range route.Spec.ParentRefs {
+					if parentRef.Namespace == nil || string(*parentRef.Namespace) != tc.expectedGateway.Namespace {
+						t.Errorf("expected parentRef in namespace %s, got %v", tc.expectedGateway.Namespace, parentRef.Namespace)
+					}
+				}
+			}
+			if len(gatewayResources.ReferenceGrants) != tc.expectedReferenceGrants {
+				t.Errorf("expected %d ReferenceGrants, got %d", tc.expectedReferenceGrants, len(gatewayResources.ReferenceGrants))
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_GatewayMetadata(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				GatewayModeFlag:        "per-namespace",
+				GatewayAnnotationsFlag: "networking.istio.io/service-type=ClusterIP,team=platform",
+				GatewayLabelsFlag:      "istio.io/rev=stable",
+			},
+		},
+	}).(*Provider)
+	ingress := headersTestIngress(nil)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if len(gatewayResources.Gateways) == 0 {
+		t.Fatalf("expected a generated Gateway")
+	}
+	for key, gateway := range gatewayResources.Gateways {
+		if gateway.Annotations["networking.istio.io/service-type"] != "ClusterIP" || gateway.Annotations["team"] != "platform" {
+			t.Errorf("Gateway %s: expected the configured annotations, got %v", key, gateway.Annotations)
+		}
+		if gateway.Labels["istio.io/rev"] != "stable" {
+			t.Errorf("Gateway %s: expected the configured labels, got %v", key, gateway.Labels)
+		}
+	}
+}
+
+func TestGatewayConfig_ValidateGatewayMetadata(t *testing.T) {
+	testCases := []struct {
+		name          string
+		flags         map[string]string
+		expectedField string
+	}{
+		{
+			name:  "valid",
+			flags: map[string]string{GatewayAnnotationsFlag: "a=b c", GatewayLabelsFlag: "env=prod"},
+		},
+		{
+			name:          "annotation without value",
+			flags:         map[string]string{GatewayAnnotationsFlag: "team"},
+			expectedField: "ingress-nginx.gateway-annotations",
+		},
+		{
+			name:          "invalid label value",
+			flags:         map[string]string{GatewayLabelsFlag: "env=prod us"},
+			expectedField: "ingress-nginx.gateway-labels",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags},
+			}).(*Provider)
+
+			errs := provider.gatewayConfig.Validate()
+			if tc.expectedField == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tc.expectedField {
+				t.Errorf("expected one error on %s, got %v", tc.expectedField, errs)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_KeepOriginalGatewayNames(t *testing.T) {
+	testCases := []struct {
+		name            string
+		keepNames       string
+		expectedGateway types.NamespacedName
+	}{
+		{
+			name:            "renamed after the gateway namespace",
+			keepNames:       "false",
+			expectedGateway: types.NamespacedName{Namespace: "default-gateway", Name: "default-gateway"},
+		},
+		{
+			name:            "original name kept",
+			keepNames:       "true",
+			expectedGateway: types.NamespacedName{Namespace: "default-gateway", Name: "nginx"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:              "per-namespace",
+						KeepOriginalGatewayNamesFlag: tc.keepNames,
+						SkipReferenceGrantFlag:       "false",
+					},
+				},
+			}).(*Provider)
+			ingress := headersTestIngress(map[string]string{
+				"nginx.ingress.kubernetes.io/force-ssl-redirect": "true",
+			})
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if len(gatewayResources.Gateways) != 1 {
+				t.Fatalf("expected 1 Gateway, got %v", gatewayResources.Gateways)
+			}
+			if _, ok := gatewayResources.Gateways[tc.expectedGateway]; !ok {
+				t.Errorf("expected Gateway %s, got %v", tc.expectedGateway, gatewayResources.Gateways)
+			}
+
+			// The converted route and its SSL redirect route both attach to the Gateway
+			if len(gatewayResources.HTTPRoutes) != 2 {
+				t.Fatalf("expected an HTTPRoute and its redirect route, got %d", len(gatewayResources.HTTPRoutes))
+			}
+			for routeKey, route := range gatewayResources.HTTPRoutes {
+				for _, parentRef := range route.Spec.ParentRefs {
+					if parentRef.Namespace == nil || string(*parentRef.Namespace) != tc.expectedGateway.Namespace || string(parentRef.Name) != tc.expectedGateway.Name {
+						t.Errorf("HTTPRoute %s: expected parentRef %s, got %v/%s", routeKey, tc.expectedGateway, parentRef.Namespace, parentRef.Name)
+					}
+				}
+			}
+			for _, grant := range gatewayResources.ReferenceGrants {
+				if len(grant.Spec.To) != 1 || grant.Spec.To[0].Name == nil || string(*grant.Spec.To[0].Name) != tc.expectedGateway.Name {
+					t.Errorf("expected ReferenceGrant %s to allow Gateway %s, got %+v", grant.Name, tc.expectedGateway.Name, grant.Spec.To)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validateListenerHostnames checks that every hostname of the HTTPRoutes
+// attached to the generated Gateways intersects the hostname of at least one
+// listener the route can attach to, e.g. the listeners merged into a
+// per-namespace Gateway or the sections named in its parentRefs. Requests for
+// other hostnames are not routed by the Gateway, so each one is reported with
+// a Warning. The pre-provisioned centralized Gateway is not generated, so the
+// routes attached to it are not checked.
+func validateListenerHostnames(gatewayResources *i2gw.GatewayResources) {
+	for routeKey, route := range gatewayResources.HTTPRoutes {
+		if len(route.Spec.Hostnames) == 0 {
+			continue
+		}
+		for _, parentRef := range route.Spec.ParentRefs {
+			gwKey := parentRefGatewayKey(parentRef, route.Namespace)
+			gateway, ok := gatewayResources.Gateways[gwKey]
+			if !ok {
+				continue
+			}
+
+			listeners := slices.DeleteFunc(slices.Clone(gateway.Spec.Listeners), func(listener gatewayv1.Listener) bool {
+				return parentRef.SectionName != nil && listener.Name != *parentRef.SectionName
+			})
+			for _, hostname := range route.Spec.Hostnames {
+				if slices.ContainsFunc(listeners, func(listener gatewayv1.Listener) bool {
+					return listener.Hostname == nil || hostnamesIntersect(string(*listener.Hostname), string(hostname))
+				}) {
+					continue
+				}
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("hostname %q of HTTPRoute %s matches no listener of Gateway %s%s, requests for it are not routed",
+						hostname, routeKey, gwKey, sectionNameSuffix(parentRef.SectionName)),
+					&route,
+				)
+			}
+		}
+	}
+}
+
+// hostnamesIntersect returns true if a listener hostname and a route hostname
+// match a common host, following the Gateway API hostname matching rules: a
+// wildcard matches hosts with one or more additional leading labels.
+func hostnamesIntersect(listenerHostname, routeHostname string) bool {
+	if listenerHostname == "" || strings.EqualFold(listenerHostname, routeHostname) {
+		return true
+	}
+	matchesWildcard := func(wildcard, hostname string) bool {
+		suffix, ok := strings.CutPrefix(strings.ToLower(wildcard), "*")
+		return ok && len(hostname) > len(suffix) && strings.HasSuffix(strings.ToLower(hostname), suffix)
+	}
+	return matchesWildcard(listenerHostname, routeHostname) || matchesWildcard(routeHostname, listenerHostname)
+}
+
+// sectionNameSuffix describes the listener section a parentRef selects
+func sectionNameSuffix(sectionName *gatewayv1.SectionName) string {
+	if sectionName == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (section %s)", *sectionName)
+}
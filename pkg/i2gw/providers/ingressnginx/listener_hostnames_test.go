@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestValidateListenerHostnames(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	gwKey := types.NamespacedName{Namespace: "default-gateway", Name: "default-gateway"}
+	routeKey := types.NamespacedName{Namespace: "default", Name: "route"}
+	gatewayResources := i2gw.GatewayResources{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			gwKey: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: gwKey.Namespace, Name: gwKey.Name},
+				Spec: gatewayv1.GatewaySpec{
+					Listeners: []gatewayv1.Listener{
+						{Name: "example-com-http", Hostname: ptr.To[gatewayv1.Hostname]("example.com")},
+						{Name: "wildcard-example-org-http", Hostname: ptr.To[gatewayv1.Hostname]("*.example.org")},
+					},
+				},
+			},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			routeKey: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: routeKey.Namespace, Name: routeKey.Name},
+				Spec: gatewayv1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayv1.CommonRouteSpec{
+						ParentRefs: []gatewayv1.ParentReference{{
+							Namespace: ptr.To(gatewayv1.Namespace(gwKey.Namespace)),
+							Name:      gatewayv1.ObjectName(gwKey.Name),
+						}},
+					},
+					Hostnames: []gatewayv1.Hostname{"example.com", "api.example.org", "uncovered.example.net"},
+				},
+			},
+		},
+	}
+
+	validateListenerHostnames(&gatewayResources)
+
+	var warnings []string
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			warnings = append(warnings, n.Message)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"uncovered.example.net"`) {
+		t.Errorf("expected 1 warning for uncovered.example.net, got %q", warnings)
+	}
+}
+
+func TestHostnamesIntersect(t *testing.T) {
+	testCases := []struct {
+		listenerHostname string
+		routeHostname    string
+		expected         bool
+	}{
+		{listenerHostname: "example.com", routeHostname: "example.com", expected: true},
+		{listenerHostname: "", routeHostname: "example.com", expected: true},
+		{listenerHostname: "*.example.com", routeHostname: "a.b.example.com", expected: true},
+		{listenerHostname: "*.example.com", routeHostname: "example.com", expected: false},
+		{listenerHostname: "api.example.com", routeHostname: "*.example.com", expected: true},
+		{listenerHostname: "example.com", routeHostname: "other.com", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if got := hostnamesIntersect(tc.listenerHostname, tc.routeHostname); got != tc.expected {
+			t.Errorf("hostnamesIntersect(%q, %q) = %t, expected %t", tc.listenerHostname, tc.routeHostname, got, tc.expected)
+		}
+	}
+}
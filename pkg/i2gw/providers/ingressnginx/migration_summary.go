@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// migrationStatus is how completely an Ingress was migrated
+type migrationStatus int
+
+const (
+	// fullyMigrated Ingresses only got INFO notifications
+	fullyMigrated migrationStatus = iota
+	// partiallyMigrated Ingresses got WARNING notifications
+	partiallyMigrated
+	// migrationBlocked Ingresses got ERROR notifications
+	migrationBlocked
+)
+
+// migrationSummary counts the Ingresses by migration status, and the Ingresses
+// with warnings or errors by the annotation they are about
+type migrationSummary struct {
+	statuses map[types.NamespacedName]migrationStatus
+	warnings map[string]map[types.NamespacedName]bool
+	errors   map[string]map[types.NamespacedName]bool
+}
+
+// emitMigrationSummary emits an INFO notification summarizing the notifications
+// sent so far per Ingress, to gauge migration readiness at a glance.
+// Notifications about an HTTPRoute count for the Ingresses it was built from.
+func emitMigrationSummary(ingresses []networkingv1.Ingress, ir intermediate.IR) {
+	if len(ingresses) == 0 {
+		return
+	}
+	summary := buildMigrationSummary(ingresses, ir, notifications.NotificationAggr.ProviderNotifications(Name))
+	notify(notifications.InfoNotification, summary.String(), nil)
+}
+
+func buildMigrationSummary(ingresses []networkingv1.Ingress, ir intermediate.IR, sent []notifications.Notification) migrationSummary {
+	summary := migrationSummary{
+		statuses: make(map[types.NamespacedName]migrationStatus, len(ingresses)),
+		warnings: make(map[string]map[types.NamespacedName]bool),
+		errors:   make(map[string]map[types.NamespacedName]bool),
+	}
+	ingressesByKey := make(map[types.NamespacedName]*networkingv1.Ingress, len(ingresses))
+	for i := range ingresses {
+		key := types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}
+		ingressesByKey[key] = &ingresses[i]
+		summary.statuses[key] = fullyMigrated
+	}
+
+	for _, n := range sent {
+		status, byAnnotation := partiallyMigrated, summary.warnings
+		switch n.Type {
+		case notifications.WarningNotification:
+		case notifications.ErrorNotification:
+			status, byAnnotation = migrationBlocked, summary.errors
+		default:
+			continue
+		}
+
+		for _, key := range notificationIngresses(n, ir) {
+			ing, ok := ingressesByKey[key]
+			if !ok {
+				continue
+			}
+			summary.statuses[key] = max(summary.statuses[key], status)
+
+			annotations := notificationAnnotations(n.Message, ing)
+			if len(annotations) == 0 {
+				annotations = []string{"other"}
+			}
+			for _, annotation := range annotations {
+				if byAnnotation[annotation] == nil {
+					byAnnotation[annotation] = make(map[types.NamespacedName]bool)
+				}
+				byAnnotation[annotation][key] = true
+			}
+		}
+	}
+
+	return summary
+}
+
+// notificationIngresses returns the Ingresses a notification is about: the
+// Ingresses among its calling objects, and the source Ingresses of its
+// HTTPRoutes.
+func notificationIngresses(n notifications.Notification, ir intermediate.IR) []types.NamespacedName {
+	var keys []types.NamespacedName
+	for _, obj := range n.CallingObjects {
+		if obj == nil {
+			continue
+		}
+		key := client.ObjectKeyFromObject(obj)
+		switch obj.(type) {
+		case *networkingv1.Ingress:
+			keys = append(keys, key)
+		case *gatewayv1.HTTPRoute:
+			routeCtx, ok := ir.HTTPRoutes[key]
+			if !ok {
+				continue
+			}
+			for _, ing := range routeSourceIngresses(routeCtx) {
+				keys = append(keys, types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name})
+			}
+		}
+	}
+	return keys
+}
+
+// notificationAnnotations returns the annotations of the Ingress the message
+// mentions, by full or short name (without the nginx.ingress.kubernetes.io/
+// prefix), in their short form
+func notificationAnnotations(message string, ing *networkingv1.Ingress) []string {
+	var annotations []string
+	for _, annotation := range slices.Sorted(maps.Keys(ing.Annotations)) {
+		_, short, found := strings.Cut(annotation, "/")
+		if !found {
+			short = annotation
+		}
+		if mentionsName(message, short) {
+			annotations = append(annotations, short)
+		}
+	}
+	return annotations
+}
+
+// mentionsName returns true if the message contains the name as a whole word,
+// so that e.g. ssl-redirect does not match force-ssl-redirect
+func mentionsName(message, name string) bool {
+	pattern := `(^|[^a-z0-9-])` + regexp.QuoteMeta(name) + `($|[^a-z0-9-])`
+	return regexp.MustCompile(pattern).MatchString(message)
+}
+
+// count returns the number of Ingresses with the status
+func (s migrationSummary) count(status migrationStatus) int {
+	count := 0
+	for _, st := range s.statuses {
+		if st == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (s migrationSummary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "MIGRATION SUMMARY - %d Ingresses: %d fully migrated, %d partially migrated (warnings), %d blocked (errors)",
+		len(s.statuses), s.count(fullyMigrated), s.count(partiallyMigrated), s.count(migrationBlocked))
+	if len(s.errors) > 0 {
+		sb.WriteString("\nIngresses with errors by annotation: " + formatAnnotationCounts(s.errors))
+	}
+	if len(s.warnings) > 0 {
+		sb.WriteString("\nIngresses with warnings by annotation: " + formatAnnotationCounts(s.warnings))
+	}
+	return sb.String()
+}
+
+// formatAnnotationCounts formats the number of Ingresses per annotation,
+// sorted by annotation with "other" last
+func formatAnnotationCounts(byAnnotation map[string]map[types.NamespacedName]bool) string {
+	annotations := slices.Sorted(maps.Keys(byAnnotation))
+	if i := slices.Index(annotations, "other"); i >= 0 {
+		annotations = append(slices.Delete(annotations, i, i+1), "other")
+	}
+
+	var counts []string
+	for _, annotation := range annotations {
+		counts = append(counts, fmt.Sprintf("%s: %d", annotation, len(byAnnotation[annotation])))
+	}
+	return strings.Join(counts, ", ")
+}
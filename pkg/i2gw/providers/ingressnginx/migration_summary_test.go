@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_MigrationSummary(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	newIngress := func(name, host string, annotations map[string]string) networkingv1.Ingress {
+		ing := headersTestIngress(annotations)
+		ing.Name = name
+		ing.Spec.Rules[0].Host = host
+		return ing
+	}
+	ingresses := []networkingv1.Ingress{
+		newIngress("clean", "clean.example.com", nil),
+		newIngress("mirrored", "mirrored.example.com", map[string]string{
+			mirrorTargetAnnotation: "http://mirror-service/$request_uri",
+			mirrorHostAnnotation:   "mirror.example.com",
+		}),
+		newIngress("snippet", "snippet.example.com", map[string]string{
+			serverSnippetAnnotation: "location /admin { deny all; }",
+		}),
+		newIngress("other-snippet", "other-snippet.example.com", map[string]string{
+			serverSnippetAnnotation: "location /internal { deny all; }",
+			mirrorTargetAnnotation:  "http://mirror-service/$request_uri",
+			mirrorHostAnnotation:    "mirror.example.com",
+		}),
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+	ingressMap := make(map[types.NamespacedName]*networkingv1.Ingress, len(ingresses))
+	for i := range ingresses {
+		ingressMap[types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}] = &ingresses[i]
+	}
+	provider.storage.Ingresses.FromMap(ingressMap)
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	if _, errs := provider.ToGatewayResources(ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var summaries []string
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification && strings.HasPrefix(n.Message, "MIGRATION SUMMARY") {
+			summaries = append(summaries, n.Message)
+		}
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("expected 1 migration summary, got %d", len(summaries))
+	}
+
+	expectedLines := []string{
+		"MIGRATION SUMMARY - 4 Ingresses: 1 fully migrated, 1 partially migrated (warnings), 2 blocked (errors)",
+		"Ingresses with errors by annotation: server-snippet: 2",
+		"Ingresses with warnings by annotation: mirror-host: 2",
+	}
+	if got := summaries[0]; got != strings.Join(expectedLines, "\n") {
+		t.Errorf("unexpected migration summary:\n%s\nexpected:\n%s", got, strings.Join(expectedLines, "\n"))
+	}
+}
+
+func TestMentionsName(t *testing.T) {
+	testCases := []struct {
+		message  string
+		name     string
+		expected bool
+	}{
+		{message: "ssl-redirect is not supported", name: "ssl-redirect", expected: true},
+		{message: "force-ssl-redirect is not supported", name: "ssl-redirect", expected: false},
+		{message: "MIGRATION BLOCKER - nginx.ingress.kubernetes.io/server-snippet", name: "server-snippet", expected: true},
+		{message: "server-snippets are not supported", name: "server-snippet", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if got := mentionsName(tc.message, tc.name); got != tc.expected {
+			t.Errorf("mentionsName(%q, %q) = %v, expected %v", tc.message, tc.name, got, tc.expected)
+		}
+	}
+}
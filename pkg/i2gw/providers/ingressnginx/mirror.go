@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// Mirror annotations
+	mirrorTargetAnnotation      = "nginx.ingress.kubernetes.io/mirror-target"
+	mirrorRequestBodyAnnotation = "nginx.ingress.kubernetes.io/mirror-request-body"
+	mirrorHostAnnotation        = "nginx.ingress.kubernetes.io/mirror-host"
+
+	// mirrorPercentageAnnotation sets the percentage of requests mirrored
+	// (0-100, default 100). ingress-nginx always mirrors every request, so the
+	// per-Ingress value is an ingress2gateway convention.
+	mirrorPercentageAnnotation = "ingress2gateway.kubernetes.io/mirror-percentage"
+)
+
+// mirrorPercentageRegex matches a percentage with an optional decimal part
+var mirrorPercentageRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]{1,4})?$`)
+
+// mirrorFeature converts mirror-target to a RequestMirror filter on the
+// HTTPRoute rules generated from the annotated Ingress. Only targets resolving
+// to a Kubernetes Service can be converted, since the filter references a
+// backend rather than a URL.
+func mirrorFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		target := strings.TrimSpace(ing.Annotations[mirrorTargetAnnotation])
+		if target == "" {
+			continue
+		}
+
+		mirror, parseErrs := parseMirrorPercentage(ing.Annotations[mirrorPercentageAnnotation])
+		if len(parseErrs) > 0 {
+			errs = append(errs, parseErrs...)
+			continue
+		}
+
+		backendRef, err := mirrorTargetBackendRef(target, ing.Namespace)
+		if err != nil {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("mirror-target %q cannot be converted to a RequestMirror filter: %v", target, err),
+				ing,
+			)
+			continue
+		}
+		mirror.BackendRef = backendRef
+
+		filter := gatewayv1.HTTPRouteFilter{
+			Type:          gatewayv1.HTTPRouteFilterRequestMirror,
+			RequestMirror: mirror,
+		}
+		if applyFilterToIngressRules(ir, ing, filter) == 0 {
+			continue
+		}
+
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("mirror-target converted to RequestMirror filter to service %s:%d mirroring %s of requests",
+				backendRef.Name, *backendRef.Port, formatMirrorPercentage(mirror)),
+			ing,
+		)
+		if backendRef.Namespace != nil {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("mirror-target service %s is in namespace %s; a ReferenceGrant in that namespace must allow HTTPRoutes from namespace %s",
+					backendRef.Name, *backendRef.Namespace, ing.Namespace),
+				ing,
+			)
+		}
+		// Gateway API mirrors the request as is, body included
+		if value, ok := ing.Annotations[mirrorRequestBodyAnnotation]; ok && !parseOnOff(value) {
+			notify(notifications.WarningNotification,
+				"mirror-request-body is off, but RequestMirror filters always mirror the request body",
+				ing,
+			)
+		}
+		if strings.TrimSpace(ing.Annotations[mirrorHostAnnotation]) != "" {
+			notify(notifications.WarningNotification,
+				"mirror-host cannot be converted: RequestMirror filters keep the Host header of the original request",
+				ing,
+			)
+		}
+	}
+
+	return errs
+}
+
+// parseMirrorPercentage returns a RequestMirror filter mirroring the given
+// percentage of requests. Whole percentages use Percent, and percentages with
+// a decimal part a Fraction of 100 scaled by a power of ten. An empty value
+// mirrors every request.
+func parseMirrorPercentage(value string) (*gatewayv1.HTTPRequestMirrorFilter, field.ErrorList) {
+	mirror := &gatewayv1.HTTPRequestMirrorFilter{}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return mirror, nil
+	}
+
+	invalid := field.ErrorList{field.Invalid(
+		field.NewPath("metadata", "annotations", mirrorPercentageAnnotation),
+		value,
+		"must be a number between 0 and 100 with at most 4 decimal places",
+	)}
+	if !mirrorPercentageRegex.MatchString(value) {
+		return nil, invalid
+	}
+
+	whole, decimals, _ := strings.Cut(value, ".")
+	numerator, err := strconv.ParseInt(whole+decimals, 10, 32)
+	denominator := int64(100)
+	for range decimals {
+		denominator *= 10
+	}
+	if err != nil || numerator > denominator {
+		return nil, invalid
+	}
+
+	switch {
+	case numerator == denominator:
+		// Mirroring every request is the default
+	case decimals == "" || numerator%(denominator/100) == 0:
+		mirror.Percent = ptr.To(int32(numerator / (denominator / 100)))
+	default:
+		mirror.Fraction = &gatewayv1.Fraction{
+			Numerator:   int32(numerator),
+			Denominator: ptr.To(int32(denominator)),
+		}
+	}
+	return mirror, nil
+}
+
+// formatMirrorPercentage describes the share of requests the filter mirrors
+func formatMirrorPercentage(mirror *gatewayv1.HTTPRequestMirrorFilter) string {
+	switch {
+	case mirror.Percent != nil:
+		return fmt.Sprintf("%d%%", *mirror.Percent)
+	case mirror.Fraction != nil:
+		return fmt.Sprintf("%d/%d", mirror.Fraction.Numerator, *mirror.Fraction.Denominator)
+	default:
+		return "100%"
+	}
+}
+
+// mirrorTargetBackendRef resolves the mirror-target URL to a Service. The host
+// must be a Service name in the Ingress namespace or a cluster-local Service
+// DNS name (<name>.<namespace>.svc[.cluster.local]). The port defaults to the
+// port of the URL scheme.
+func mirrorTargetBackendRef(target, namespace string) (gatewayv1.BackendObjectReference, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return gatewayv1.BackendObjectReference{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return gatewayv1.BackendObjectReference{}, fmt.Errorf("URL scheme must be http or https")
+	}
+	// nginx appends $request_uri to mirror the original path, which is what
+	// Gateway API does, so only other paths are lost
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" && path != "$request_uri" {
+		return gatewayv1.BackendObjectReference{}, fmt.Errorf("RequestMirror filters keep the original request path, so path %q cannot be converted", u.Path)
+	}
+
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	labels := strings.Split(host, ".")
+	serviceName, serviceNamespace := labels[0], namespace
+	switch {
+	case len(labels) == 1:
+	case len(labels) == 3 && labels[2] == "svc",
+		len(labels) == 5 && strings.Join(labels[2:], ".") == "svc.cluster.local":
+		serviceNamespace = labels[1]
+	default:
+		return gatewayv1.BackendObjectReference{}, fmt.Errorf("host %q is not a Kubernetes Service; use <name>.<namespace>.svc.cluster.local to mirror to a Service", host)
+	}
+	if len(validation.IsDNS1035Label(serviceName)) > 0 || len(validation.IsDNS1123Label(serviceNamespace)) > 0 {
+		return gatewayv1.BackendObjectReference{}, fmt.Errorf("host %q is not a valid Service DNS name", host)
+	}
+
+	port := int32(80)
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if p := u.Port(); p != "" {
+		parsed, err := strconv.ParseInt(p, 10, 32)
+		if err != nil || parsed < 1 || parsed > 65535 {
+			return gatewayv1.BackendObjectReference{}, fmt.Errorf("invalid port %q", p)
+		}
+		port = int32(parsed)
+	}
+
+	backendRef := gatewayv1.BackendObjectReference{
+		Name: gatewayv1.ObjectName(serviceName),
+		Port: ptr.To(gatewayv1.PortNumber(port)),
+	}
+	if serviceNamespace != namespace {
+		backendRef.Namespace = ptr.To(gatewayv1.Namespace(serviceNamespace))
+	}
+	return backendRef, nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestMirrorFeature(t *testing.T) {
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		expectedMirror *gatewayv1.HTTPRequestMirrorFilter
+		expectedErrors int
+	}{
+		{
+			name: "service in the Ingress namespace mirrors every request",
+			annotations: map[string]string{
+				mirrorTargetAnnotation: "http://mirror-service/$request_uri",
+			},
+			expectedMirror: &gatewayv1.HTTPRequestMirrorFilter{
+				BackendRef: gatewayv1.BackendObjectReference{
+					Name: "mirror-service",
+					Port: ptr.To(gatewayv1.PortNumber(80)),
+				},
+			},
+		},
+		{
+			name: "10% of requests mirrored to a service in another namespace",
+			annotations: map[string]string{
+				mirrorTargetAnnotation:     "https://mirror-service.shadow.svc.cluster.local:8443/$request_uri",
+				mirrorPercentageAnnotation: "10",
+			},
+			expectedMirror: &gatewayv1.HTTPRequestMirrorFilter{
+				BackendRef: gatewayv1.BackendObjectReference{
+					Name:      "mirror-service",
+					Namespace: ptr.To(gatewayv1.Namespace("shadow")),
+					Port:      ptr.To(gatewayv1.PortNumber(8443)),
+				},
+				Percent: ptr.To(int32(10)),
+			},
+		},
+		{
+			name: "decimal percentage uses a fraction",
+			annotations: map[string]string{
+				mirrorTargetAnnotation:     "http://mirror-service.default.svc",
+				mirrorPercentageAnnotation: "0.5",
+			},
+			expectedMirror: &gatewayv1.HTTPRequestMirrorFilter{
+				BackendRef: gatewayv1.BackendObjectReference{
+					Name: "mirror-service",
+					Port: ptr.To(gatewayv1.PortNumber(80)),
+				},
+				Fraction: &gatewayv1.Fraction{Numerator: 5, Denominator: ptr.To(int32(1000))},
+			},
+		},
+		{
+			name: "external host is not converted",
+			annotations: map[string]string{
+				mirrorTargetAnnotation: "https://test.env.com/$request_uri",
+			},
+		},
+		{
+			name: "percentage over 100 is invalid",
+			annotations: map[string]string{
+				mirrorTargetAnnotation:     "http://mirror-service",
+				mirrorPercentageAnnotation: "150",
+			},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			errs = mirrorFeature(ingresses, nil, &ir)
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("expected %d errors, got %d: %v", tc.expectedErrors, len(errs), errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			rule := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules[0]
+
+			var mirror *gatewayv1.HTTPRequestMirrorFilter
+			for _, filter := range rule.Filters {
+				if filter.Type == gatewayv1.HTTPRouteFilterRequestMirror {
+					mirror = filter.RequestMirror
+				}
+			}
+			if diff := cmp.Diff(tc.expectedMirror, mirror); diff != "" {
+				t.Errorf("unexpected RequestMirror filter (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseMirrorPercentage(t *testing.T) {
+	testCases := []struct {
+		value            string
+		expectedPercent  *int32
+		expectedFraction *gatewayv1.Fraction
+		expectError      bool
+	}{
+		{value: ""},
+		{value: "100"},
+		{value: "100.00"},
+		{value: "0", expectedPercent: ptr.To(int32(0))},
+		{value: "25.0", expectedPercent: ptr.To(int32(25))},
+		{value: "12.5", expectedFraction: &gatewayv1.Fraction{Numerator: 125, Denominator: ptr.To(int32(1000))}},
+		{value: "0.01", expectedFraction: &gatewayv1.Fraction{Numerator: 1, Denominator: ptr.To(int32(10000))}},
+		{value: "100.5", expectError: true},
+		{value: "-10", expectError: true},
+		{value: "10%", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			mirror, errs := parseMirrorPercentage(tc.value)
+			if tc.expectError {
+				if len(errs) == 0 {
+					t.Fatalf("expected an error for %q", tc.value)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if diff := cmp.Diff(tc.expectedPercent, mirror.Percent); diff != "" {
+				t.Errorf("unexpected Percent (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedFraction, mirror.Fraction); diff != "" {
+				t.Errorf("unexpected Fraction (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
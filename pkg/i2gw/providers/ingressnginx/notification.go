@@ -25,3 +25,9 @@ func notify(mType notifications.MessageType, message string, callingObject ...cl
 	newNotification := notifications.NewNotification(mType, message, callingObject...)
 	notifications.NotificationAggr.DispatchNotification(newNotification, string(Name))
 }
+
+// notifyWithCode dispatches a notification tagged with an ErrorCode
+func notifyWithCode(mType notifications.MessageType, code notifications.ErrorCode, message string, callingObject ...client.Object) {
+	newNotification := notifications.NewNotificationWithCode(mType, code, message, callingObject...)
+	notifications.NotificationAggr.DispatchNotification(newNotification, string(Name))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	proxyBufferSizeAnnotation      = "nginx.ingress.kubernetes.io/proxy-buffer-size"
+	proxyBuffersNumberAnnotation   = "nginx.ingress.kubernetes.io/proxy-buffers-number"
+	proxyBusyBuffersSizeAnnotation = "nginx.ingress.kubernetes.io/proxy-busy-buffers-size"
+
+	// ingress-nginx defaults for proxy_buffer_size and proxy_buffers
+	defaultProxyBufferSize    = 4 * 1024
+	defaultProxyBuffersNumber = 4
+)
+
+// proxyBuffersFeature converts proxy-buffer-size and proxy-buffers-number to
+// the total response buffer of the routes generated from the Ingress, number ×
+// size, which an EnvoyFilter sets as the per connection buffer limit of the
+// backend clusters. Envoy has no busy buffers, so proxy-busy-buffers-size is
+// only checked against the other two like nginx does, with a Warning when the
+// values are inconsistent.
+func proxyBuffersFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		sizeValue := strings.TrimSpace(ing.Annotations[proxyBufferSizeAnnotation])
+		numberValue := strings.TrimSpace(ing.Annotations[proxyBuffersNumberAnnotation])
+		busyValue := strings.TrimSpace(ing.Annotations[proxyBusyBuffersSizeAnnotation])
+		if sizeValue == "" && numberValue == "" && busyValue == "" {
+			continue
+		}
+		annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+
+		size := int64(defaultProxyBufferSize)
+		if sizeValue != "" {
+			parsed, err := ParseBodySize(sizeValue)
+			if err != nil || parsed <= 0 {
+				errs = append(errs, field.Invalid(annotationsPath.Key(proxyBufferSizeAnnotation), sizeValue, "must be a positive size, e.g. 8k"))
+				continue
+			}
+			size = parsed
+		}
+		number := int64(defaultProxyBuffersNumber)
+		if numberValue != "" {
+			parsed, err := strconv.ParseInt(numberValue, 10, 32)
+			if err != nil || parsed <= 0 {
+				errs = append(errs, field.Invalid(annotationsPath.Key(proxyBuffersNumberAnnotation), numberValue, "must be a positive integer"))
+				continue
+			}
+			number = parsed
+		}
+		total := number * size
+
+		if busyValue != "" {
+			busy, err := ParseBodySize(busyValue)
+			if err != nil || busy <= 0 {
+				errs = append(errs, field.Invalid(annotationsPath.Key(proxyBusyBuffersSizeAnnotation), busyValue, "must be a positive size, e.g. 16k"))
+				continue
+			}
+			// nginx rejects these values when loading the configuration
+			switch {
+			case busy < size:
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s (%d bytes) is smaller than %s (%d bytes), which nginx rejects",
+						proxyBusyBuffersSizeAnnotation, busy, proxyBufferSizeAnnotation, size), ing)
+			case busy > total-size:
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s (%d bytes) must be less than the size of all buffers minus one buffer (%d bytes), which nginx rejects",
+						proxyBusyBuffersSizeAnnotation, busy, total-size), ing)
+			}
+		}
+
+		// proxy-busy-buffers-size alone does not change the buffer size
+		if sizeValue == "" && numberValue == "" {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s has no Envoy equivalent and is ignored", proxyBusyBuffersSizeAnnotation), ing)
+			continue
+		}
+
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.ProxyBufferBytes = total
+		})
+		if updated == 0 {
+			continue
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s and %s converted to an EnvoyFilter setting a per connection buffer limit of %d bytes (%d × %d) on the backend clusters",
+				proxyBuffersNumberAnnotation, proxyBufferSizeAnnotation, total, number, size), ing)
+	}
+
+	return errs
+}
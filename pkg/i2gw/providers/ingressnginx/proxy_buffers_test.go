@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProxyBuffersFeature(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		expectedBytes    int64
+		expectedWarnings int
+		expectError      bool
+	}{
+		{
+			name: "total buffer from number and size",
+			annotations: map[string]string{
+				proxyBuffersNumberAnnotation: "8",
+				proxyBufferSizeAnnotation:    "16k",
+			},
+			expectedBytes: 8 * 16 * 1024,
+		},
+		{
+			name:          "default buffer size",
+			annotations:   map[string]string{proxyBuffersNumberAnnotation: "2"},
+			expectedBytes: 2 * 4 * 1024,
+		},
+		{
+			name: "busy buffers larger than all buffers minus one",
+			annotations: map[string]string{
+				proxyBuffersNumberAnnotation:   "4",
+				proxyBufferSizeAnnotation:      "8k",
+				proxyBusyBuffersSizeAnnotation: "32k",
+			},
+			expectedBytes:    4 * 8 * 1024,
+			expectedWarnings: 1,
+		},
+		{
+			name:        "invalid number",
+			annotations: map[string]string{proxyBuffersNumberAnnotation: "many"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = proxyBuffersFeature(ingresses, nil, &ir)
+			if tc.expectError != (len(errs) > 0) {
+				t.Fatalf("expected error: %v, got %v", tc.expectError, errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			var bufferBytes int64
+			if nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx; nginxIR != nil {
+				bufferBytes = nginxIR.ProxyBufferBytes
+			}
+			if bufferBytes != tc.expectedBytes {
+				t.Errorf("expected a total buffer of %d bytes, got %d", tc.expectedBytes, bufferBytes)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.GenerateEnvoyFilters(ir)[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-buffers"}]
+			if tc.expectedBytes == 0 {
+				if filter != nil {
+					t.Errorf("expected no buffers EnvoyFilter, got %s", filter.GetName())
+				}
+				return
+			}
+			if filter == nil {
+				t.Fatalf("expected a buffers EnvoyFilter")
+			}
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			limit, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}), "patch", "value", "per_connection_buffer_limit_bytes")
+			if limit != tc.expectedBytes {
+				t.Errorf("expected per_connection_buffer_limit_bytes %d, got %d", tc.expectedBytes, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const proxyHTTPVersionAnnotation = "nginx.ingress.kubernetes.io/proxy-http-version"
+
+// proxyHTTPVersionFeature parses proxy-http-version and stores it in the IR of
+// the routes generated from the Ingress. The routes get an EnvoyFilter setting
+// explicit HTTP/1 protocol options on the clusters of their backends, so Envoy
+// does not upgrade the connections to HTTP/2.
+func proxyHTTPVersionFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		version := strings.TrimSpace(ing.Annotations[proxyHTTPVersionAnnotation])
+		if version == "" {
+			continue
+		}
+		if version != "1.0" && version != "1.1" {
+			errs = append(errs, field.Invalid(
+				field.NewPath("metadata", "annotations", proxyHTTPVersionAnnotation),
+				version,
+				"invalid proxy-http-version value, must be one of: 1.0, 1.1",
+			))
+			continue
+		}
+
+		// GRPC backends are proxied with grpc_pass, which always uses HTTP/2
+		if protocol := strings.ToUpper(strings.TrimSpace(ing.Annotations[backendProtocolAnnotation])); protocol == "GRPC" || protocol == "GRPCS" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s is ignored for %s backends, which use HTTP/2", proxyHTTPVersionAnnotation, protocol),
+				ing,
+			)
+			continue
+		}
+
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.UpstreamHTTPVersion = version
+		})
+		if updated == 0 {
+			continue
+		}
+
+		if version == "1.0" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s: \"1.0\" cannot be converted, Envoy proxies to backends with HTTP/1.1 and does not downgrade to HTTP/1.0. "+
+					"The generated EnvoyFilter keeps the backends on HTTP/1.1; backends that only support HTTP/1.0 may need explicit configuration, "+
+					"and keep-alive connections to them are not disabled", proxyHTTPVersionAnnotation),
+				ing,
+			)
+			continue
+		}
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("%s: \"1.1\" converted to an EnvoyFilter setting HTTP/1.1 protocol options on the backend clusters", proxyHTTPVersionAnnotation),
+			ing,
+		)
+	}
+
+	return errs
+}
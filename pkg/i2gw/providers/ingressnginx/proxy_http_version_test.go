@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProxyHTTPVersionFeature(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		expectedVersion  string
+		expectedWarnings int
+		expectedErrors   int
+	}{
+		{
+			name:            "1.1",
+			annotations:     map[string]string{proxyHTTPVersionAnnotation: "1.1"},
+			expectedVersion: "1.1",
+		},
+		{
+			name:             "1.0 is kept on HTTP/1.1 with a warning",
+			annotations:      map[string]string{proxyHTTPVersionAnnotation: "1.0"},
+			expectedVersion:  "1.0",
+			expectedWarnings: 1,
+		},
+		{
+			name:           "invalid version",
+			annotations:    map[string]string{proxyHTTPVersionAnnotation: "2.0"},
+			expectedErrors: 1,
+		},
+		{
+			name: "GRPC backend",
+			annotations: map[string]string{
+				proxyHTTPVersionAnnotation: "1.1",
+				backendProtocolAnnotation:  "GRPC",
+			},
+			expectedWarnings: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = proxyHTTPVersionFeature(ingresses, nil, &ir)
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			version := ""
+			if nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx; nginxIR != nil {
+				version = nginxIR.UpstreamHTTPVersion
+			}
+			if version != tc.expectedVersion {
+				t.Errorf("expected upstream HTTP version %q, got %q", tc.expectedVersion, version)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestUpstreamHTTPVersionEnvoyFilter(t *testing.T) {
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{proxyHTTPVersionAnnotation: "1.1"})}
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := proxyHTTPVersionFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-httpversion"}]
+	if filter == nil {
+		t.Fatalf("expected an httpversion EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	expectedPatches := []interface{}{
+		map[string]interface{}{
+			"applyTo": "CLUSTER",
+			"match": map[string]interface{}{
+				"context": "GATEWAY",
+				"cluster": map[string]interface{}{
+					"service":    "my-service.default.svc.cluster.local",
+					"portNumber": int64(80),
+				},
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"typed_extension_protocol_options": map[string]interface{}{
+						"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+							"explicit_http_config": map[string]interface{}{
+								"http_protocol_options": map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedPatches, patches); diff != "" {
+		t.Errorf("unexpected configPatches (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	proxyRedirectFromAnnotation = "nginx.ingress.kubernetes.io/proxy-redirect-from"
+	proxyRedirectToAnnotation   = "nginx.ingress.kubernetes.io/proxy-redirect-to"
+)
+
+// proxyRedirectVariables are the nginx variables proxy-redirect-from and
+// proxy-redirect-to can use, expanded from the request by the Lua filter
+var proxyRedirectVariables = []string{"host", "http_host", "scheme"}
+
+// proxyRedirectVariableRegex matches the nginx variables and regex captures
+// of a proxy-redirect value, e.g. $host, ${scheme} or $1
+var proxyRedirectVariableRegex = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*|[1-9]))`)
+
+// proxyRedirectFeature converts proxy-redirect-from and proxy-redirect-to to a
+// rewrite of the Location response headers, stored in the IR of the routes
+// generated from the Ingress. Like nginx proxy_redirect, a literal from
+// replaces the matching prefix of the Location, while a from starting with "~"
+// (or "~*" to ignore case) is a regular expression replacing the whole
+// Location with the to value and its captures. HTTPRoute header filters can
+// only set the whole header regardless of its value, so the rewrite is done by
+// a Lua filter. "off", the default, and "default" leave the Location of the
+// backends unchanged.
+func proxyRedirectFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		from := strings.TrimSpace(ing.Annotations[proxyRedirectFromAnnotation])
+		if from == "" || from == "off" || from == "default" {
+			continue
+		}
+		annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+		to := strings.TrimSpace(ing.Annotations[proxyRedirectToAnnotation])
+		if to == "" {
+			errs = append(errs, field.Required(annotationsPath.Key(proxyRedirectToAnnotation), "must be set with "+proxyRedirectFromAnnotation))
+			continue
+		}
+
+		redirect, annotation, err := parseProxyRedirect(from, to)
+		if err != nil {
+			errs = append(errs, field.Invalid(annotationsPath.Key(annotation), ing.Annotations[annotation], err.Error()))
+			continue
+		}
+
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			nginxIR.ProxyRedirect = redirect
+		})
+		if updated > 0 {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s converted to an EnvoyFilter rewriting the Location response headers of the route hostnames with a Lua filter", proxyRedirectFromAnnotation),
+				ing,
+			)
+		}
+	}
+
+	return errs
+}
+
+// parseProxyRedirect parses the from and to values of proxy_redirect. Regular
+// expressions are translated to Lua patterns, the only ones the Lua filter can
+// match. Only the proxyRedirectVariables can be used, and not in regular
+// expressions, whose captures can be used in to. On error, the annotation at
+// fault is returned.
+func parseProxyRedirect(from, to string) (*intermediate.ProxyRedirectConfig, string, error) {
+	expr, regex := strings.CutPrefix(from, "~")
+	redirect := &intermediate.ProxyRedirectConfig{Regex: regex}
+
+	var err error
+	if redirect.To, err = normalizeProxyRedirectVariables(to, regex); err != nil {
+		return nil, proxyRedirectToAnnotation, err
+	}
+	if !regex {
+		if redirect.From, err = normalizeProxyRedirectVariables(from, false); err != nil {
+			return nil, proxyRedirectFromAnnotation, err
+		}
+		return redirect, "", nil
+	}
+
+	expr, caseInsensitive := strings.CutPrefix(expr, "*")
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, proxyRedirectFromAnnotation, fmt.Errorf("invalid regular expression: %v", err)
+	}
+	if redirect.From, err = regexToLuaPattern(expr, caseInsensitive); err != nil {
+		return nil, proxyRedirectFromAnnotation, fmt.Errorf("the regular expression cannot be converted to a Lua pattern: %v", err)
+	}
+	return redirect, "", nil
+}
+
+// normalizeProxyRedirectVariables rewrites the variables of a proxy-redirect
+// value as ${name}, checking they are proxyRedirectVariables, or captures if
+// captures is set
+func normalizeProxyRedirectVariables(value string, captures bool) (string, error) {
+	var err error
+	normalized := proxyRedirectVariableRegex.ReplaceAllStringFunc(value, func(variable string) string {
+		match := proxyRedirectVariableRegex.FindStringSubmatch(variable)
+		name := match[1] + match[2]
+		isCapture := len(name) == 1 && name[0] >= '1' && name[0] <= '9'
+		if !slices.Contains(proxyRedirectVariables, name) && (!isCapture || !captures) && err == nil {
+			err = fmt.Errorf("unsupported variable %s, only $%s can be converted", variable, strings.Join(proxyRedirectVariables, ", $"))
+		}
+		return "${" + name + "}"
+	})
+	return normalized, err
+}
+
+// luaPatternMagicCharacters are the characters with a special meaning in Lua
+// patterns, which must be escaped with % to match literally
+const luaPatternMagicCharacters = `^$()%.[]*+-?`
+
+// regexToLuaPattern translates a PCRE expression to a Lua pattern. Lua
+// patterns have no alternation, repetition counts, quantified groups nor
+// anchors other than a leading ^ and trailing $, so expressions using them are
+// rejected. caseInsensitive matches the letters in both cases.
+func regexToLuaPattern(expr string, caseInsensitive bool) (string, error) {
+	var pattern strings.Builder
+	// quantifiable is set when the last item is a single character or class
+	quantifiable := false
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch c {
+		case '^':
+			if i != 0 {
+				return "", fmt.Errorf("^ is only supported at the start")
+			}
+			pattern.WriteByte(c)
+			quantifiable = false
+		case '$':
+			if i != len(expr)-1 {
+				return "", fmt.Errorf("$ is only supported at the end")
+			}
+			pattern.WriteByte(c)
+			quantifiable = false
+		case '(':
+			if strings.HasPrefix(expr[i:], "(?") {
+				return "", fmt.Errorf("non-capturing groups and lookarounds are not supported")
+			}
+			pattern.WriteByte(c)
+			quantifiable = false
+		case ')':
+			pattern.WriteByte(c)
+			quantifiable = false
+		case '|':
+			return "", fmt.Errorf("alternations are not supported")
+		case '{':
+			return "", fmt.Errorf("repetition counts are not supported")
+		case '*', '+', '?':
+			if !quantifiable {
+				return "", fmt.Errorf("%c is only supported after a character or a class", c)
+			}
+			lazy := i+1 < len(expr) && expr[i+1] == '?'
+			switch {
+			case lazy && c == '*':
+				pattern.WriteByte('-')
+				i++
+			case lazy:
+				return "", fmt.Errorf("lazy %c? is not supported", c)
+			default:
+				pattern.WriteByte(c)
+			}
+			quantifiable = false
+		case '.':
+			pattern.WriteByte(c)
+			quantifiable = true
+		case '\\':
+			if i+1 == len(expr) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			class, err := luaEscapeClass(expr[i])
+			if err != nil {
+				return "", err
+			}
+			pattern.WriteString(class)
+			quantifiable = true
+		case '[':
+			end := characterClassEnd(expr, i)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class, err := luaCharacterClass(expr[i+1:end], caseInsensitive)
+			if err != nil {
+				return "", err
+			}
+			pattern.WriteString(class)
+			i = end
+			quantifiable = true
+		default:
+			pattern.WriteString(luaLiteral(c, caseInsensitive))
+			quantifiable = true
+		}
+	}
+
+	return pattern.String(), nil
+}
+
+// characterClassEnd returns the index of the ] closing the character class
+// opened at start, -1 if there is none. A ] right after the opening [ or [^
+// is a literal.
+func characterClassEnd(expr string, start int) int {
+	i := start + 1
+	if i < len(expr) && expr[i] == '^' {
+		i++
+	}
+	if i < len(expr) && expr[i] == ']' {
+		i++
+	}
+	for ; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// luaEscapeClass translates an escaped character of a PCRE expression to Lua
+func luaEscapeClass(c byte) (string, error) {
+	switch c {
+	case 'd', 'D', 's', 'S':
+		return "%" + string(c), nil
+	case 'w':
+		return "[%w_]", nil
+	case 'W':
+		return "[^%w_]", nil
+	}
+	if isASCIILetterOrDigit(c) {
+		return "", fmt.Errorf(`\%c is not supported`, c)
+	}
+	return luaLiteral(c, false), nil
+}
+
+// luaCharacterClass translates the content of a PCRE character class to a Lua
+// set. With caseInsensitive, letters are only supported outside of ranges.
+func luaCharacterClass(class string, caseInsensitive bool) (string, error) {
+	var set strings.Builder
+	set.WriteByte('[')
+	if negated, ok := strings.CutPrefix(class, "^"); ok {
+		set.WriteByte('^')
+		class = negated
+	}
+	for i := 0; i < len(class); i++ {
+		c := class[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(class) {
+				return "", fmt.Errorf("trailing backslash in character class")
+			}
+			i++
+			switch escaped := class[i]; escaped {
+			case 'd', 'D', 's', 'S':
+				set.WriteString("%" + string(escaped))
+			case 'w':
+				set.WriteString("%w_")
+			default:
+				if isASCIILetterOrDigit(escaped) {
+					return "", fmt.Errorf(`\%c is not supported in character classes`, escaped)
+				}
+				set.WriteString("%" + string(escaped))
+			}
+		case c == '-' && i > 0 && i+1 < len(class):
+			if caseInsensitive && (isASCIILetter(class[i-1]) || isASCIILetter(class[i+1])) {
+				return "", fmt.Errorf("letter ranges are not supported when ignoring case")
+			}
+			set.WriteByte('-')
+		case c == '[':
+			return "", fmt.Errorf("POSIX classes are not supported")
+		case isASCIILetter(c) && caseInsensitive:
+			set.WriteString(strings.ToLower(string(c)) + strings.ToUpper(string(c)))
+		case strings.IndexByte(luaPatternMagicCharacters, c) >= 0:
+			set.WriteString("%" + string(c))
+		default:
+			set.WriteByte(c)
+		}
+	}
+	set.WriteByte(']')
+	return set.String(), nil
+}
+
+// luaLiteral returns the Lua pattern matching the character literally
+func luaLiteral(c byte, caseInsensitive bool) string {
+	if caseInsensitive && isASCIILetter(c) {
+		return "[" + strings.ToLower(string(c)) + strings.ToUpper(string(c)) + "]"
+	}
+	if strings.IndexByte(luaPatternMagicCharacters, c) >= 0 {
+		return "%" + string(c)
+	}
+	return string(c)
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIILetterOrDigit(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProxyRedirectFeature_Regex(t *testing.T) {
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{
+		proxyRedirectFromAnnotation: `~^http://[^/]+:8080(/.*)$`,
+		proxyRedirectToAnnotation:   "https://$host$1",
+	})}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := proxyRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	expectedConfig := &intermediate.ProxyRedirectConfig{
+		From:  `^http://[^/]+:8080(/.*)$`,
+		To:    "https://${host}${1}",
+		Regex: true,
+	}
+	nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+	if nginxIR == nil {
+		t.Fatalf("expected the Location rewrite in the route IR")
+	}
+	if diff := cmp.Diff(expectedConfig, nginxIR.ProxyRedirect); diff != "" {
+		t.Fatalf("unexpected Location rewrite (-want +got):\n%s", diff)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-proxyredirect"}]
+	if filter == nil {
+		t.Fatalf("expected a proxyredirect EnvoyFilter, got %d filters", len(filters))
+	}
+
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	luaCode, _, _ := unstructured.NestedString(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "inline_code")
+	for _, expected := range []string{
+		`local from = "^http://[^/]+:8080(/.*)$"`,
+		`local to = "https://${host}${1}"`,
+		"local regex = true",
+		`["example.com"] = true,`,
+		`response_handle:headers():replace("location", expand(to, captures))`,
+	} {
+		if !strings.Contains(luaCode, expected) {
+			t.Errorf("expected the Lua code to contain %q, got:\n%s", expected, luaCode)
+		}
+	}
+}
+
+func TestParseProxyRedirect(t *testing.T) {
+	testCases := []struct {
+		name               string
+		from               string
+		to                 string
+		expectedConfig     *intermediate.ProxyRedirectConfig
+		expectedAnnotation string
+	}{
+		{
+			name:           "literal prefix",
+			from:           "http://backend:8080/",
+			to:             "https://$host/",
+			expectedConfig: &intermediate.ProxyRedirectConfig{From: "http://backend:8080/", To: "https://${host}/"},
+		},
+		{
+			name:           "case insensitive regex",
+			from:           `~*^http://app\.local(/.*)`,
+			to:             "$scheme://${http_host}$1",
+			expectedConfig: &intermediate.ProxyRedirectConfig{From: `^[hH][tT][tT][pP]://[aA][pP][pP]%.[lL][oO][cC][aA][lL](/.*)`, To: "${scheme}://${http_host}${1}", Regex: true},
+		},
+		{
+			name:               "unsupported variable",
+			from:               "http://backend/",
+			to:                 "https://$server_name/",
+			expectedAnnotation: proxyRedirectToAnnotation,
+		},
+		{
+			name:               "captures without regex",
+			from:               "http://backend/",
+			to:                 "https://example.com$1",
+			expectedAnnotation: proxyRedirectToAnnotation,
+		},
+		{
+			name:               "alternation",
+			from:               "~^http://(a|b)/",
+			to:                 "/",
+			expectedAnnotation: proxyRedirectFromAnnotation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, annotation, err := parseProxyRedirect(tc.from, tc.to)
+			if (err != nil) != (tc.expectedAnnotation != "") || annotation != tc.expectedAnnotation {
+				t.Fatalf("expected error on %q, got %q: %v", tc.expectedAnnotation, annotation, err)
+			}
+			if diff := cmp.Diff(tc.expectedConfig, config); diff != "" {
+				t.Errorf("unexpected Location rewrite (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegexToLuaPattern(t *testing.T) {
+	testCases := []struct {
+		expr        string
+		expected    string
+		expectError bool
+	}{
+		{expr: `^/api/(\d+)/?$`, expected: `^/api/(%d+)/?$`},
+		{expr: `^https?://[\w.-]+(/.*)`, expected: `^https?://[%w_%.%-]+(/.*)`},
+		{expr: `^/(.*?)%`, expected: `^/(.-)%%`},
+		{expr: `[^]a]`, expected: `[^%]a]`},
+		{expr: `^/(api)+`, expectError: true},
+		{expr: `^/a{2}`, expectError: true},
+		{expr: `^/(?:a)`, expectError: true},
+		{expr: `\bword`, expectError: true},
+		{expr: `/a/^`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		pattern, err := regexToLuaPattern(tc.expr, false)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("regexToLuaPattern(%q): expected error, got %q", tc.expr, pattern)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("regexToLuaPattern(%q): unexpected error: %v", tc.expr, err)
+		} else if pattern != tc.expected {
+			t.Errorf("regexToLuaPattern(%q) = %q, expected %q", tc.expr, pattern, tc.expected)
+		}
+	}
+}
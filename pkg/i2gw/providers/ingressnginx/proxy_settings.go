@@ -33,6 +33,7 @@ const (
 	proxyBufferingAnnotation       = "nginx.ingress.kubernetes.io/proxy-buffering"
 	proxyRequestBufferingAnnotation = "nginx.ingress.kubernetes.io/proxy-request-buffering"
 	loadBalanceAnnotation          = "nginx.ingress.kubernetes.io/load-balance"
+	clientBodyBufferSizeAnnotation = "nginx.ingress.kubernetes.io/client-body-buffer-size"
 )
 
 // proxySettingsFeature parses proxy settings annotations and stores them in the IR.
@@ -46,34 +47,64 @@ func proxySettingsFeature(ingresses []networkingv1.Ingress, _ map[types.Namespac
 			continue
 		}
 
-		// Get or create the ingress-nginx IR for this route
-		key := types.NamespacedName{
-			Namespace: ing.Namespace,
-			Name:      ing.Name,
+		// client-body-buffer-size is the part of the request body held in
+		// memory, which must fit in the max body size of proxy-body-size
+		var bufferBytes int64
+		if config.ClientBodyBufferSize != "" {
+			annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+			parsed, err := ParseBodySize(config.ClientBodyBufferSize)
+			if err != nil || parsed <= 0 {
+				errs = append(errs, field.Invalid(annotationsPath.Key(clientBodyBufferSizeAnnotation), config.ClientBodyBufferSize, "must be a positive size, e.g. 16k"))
+			} else {
+				bufferBytes = parsed
+			}
+			if bodyBytes, err := ParseBodySize(config.ProxyBodySize); err == nil && bodyBytes > 0 && bufferBytes > bodyBytes {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s (%d bytes) is larger than %s (%d bytes), the buffer is limited to the max body size",
+						clientBodyBufferSizeAnnotation, bufferBytes, proxyBodySizeAnnotation, bodyBytes),
+					&ing,
+				)
+			}
 		}
 
-		routeKey := findHTTPRouteKey(ir, key)
-		if routeKey == (types.NamespacedName{}) {
+		// Store the settings on every route built from this ingress
+		updated := updateIngressRoutes(ir, &ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			if config.ProxyBodySize != "" {
+				nginxIR.ProxyBodySize = config.ProxyBodySize
+			}
+			if config.ProxyBuffering != nil {
+				nginxIR.ProxyBuffering = config.ProxyBuffering
+			}
+			if config.ProxyRequestBuffering != nil {
+				nginxIR.ProxyRequestBuffering = config.ProxyRequestBuffering
+			}
+			if bufferBytes > 0 {
+				nginxIR.ClientBodyBufferBytes = bufferBytes
+			}
+		})
+		if updated == 0 {
 			continue
 		}
 
-		routeCtx := ir.HTTPRoutes[routeKey]
-		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
-			routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
-		}
-
 		// Set proxy body size
 		if config.ProxyBodySize != "" {
-			routeCtx.ProviderSpecificIR.IngressNginx.ProxyBodySize = config.ProxyBodySize
 			notify(notifications.InfoNotification,
 				fmt.Sprintf("proxy-body-size '%s' stored in IR. Requires BackendTrafficPolicy to apply.", config.ProxyBodySize),
 				&ing,
 			)
 		}
 
+		// Set client body buffer size
+		if bufferBytes > 0 {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("client-body-buffer-size '%s' converted to an EnvoyFilter setting a per connection buffer limit of %d bytes on all the listeners of the Gateway, "+
+					"so it applies to every route of the Gateway, not only those of this Ingress", config.ClientBodyBufferSize, bufferBytes),
+				&ing,
+			)
+		}
+
 		// Set proxy buffering
 		if config.ProxyBuffering != nil {
-			routeCtx.ProviderSpecificIR.IngressNginx.ProxyBuffering = config.ProxyBuffering
 			notify(notifications.InfoNotification,
 				fmt.Sprintf("proxy-buffering '%v' stored in IR. Requires BackendTrafficPolicy to apply.", *config.ProxyBuffering),
 				&ing,
@@ -82,14 +113,11 @@ func proxySettingsFeature(ingresses []networkingv1.Ingress, _ map[types.Namespac
 
 		// Set proxy request buffering
 		if config.ProxyRequestBuffering != nil {
-			routeCtx.ProviderSpecificIR.IngressNginx.ProxyRequestBuffering = config.ProxyRequestBuffering
 			notify(notifications.InfoNotification,
 				fmt.Sprintf("proxy-request-buffering '%v' stored in IR. Requires BackendTrafficPolicy to apply.", *config.ProxyRequestBuffering),
 				&ing,
 			)
 		}
-
-		ir.HTTPRoutes[routeKey] = routeCtx
 	}
 
 	// Also check for load balancing algorithm on services
@@ -105,6 +133,7 @@ func proxySettingsFeature(ingresses []networkingv1.Ingress, _ map[types.Namespac
 		}
 
 		// Apply to all services referenced by this ingress
+		seen := make(map[types.NamespacedName]bool)
 		for _, rule := range ing.Spec.Rules {
 			if rule.HTTP == nil {
 				continue
@@ -118,21 +147,23 @@ func proxySettingsFeature(ingresses []networkingv1.Ingress, _ map[types.Namespac
 					Namespace: ing.Namespace,
 					Name:      path.Backend.Service.Name,
 				}
-
-				if svcCtx, exists := ir.Services[svcKey]; exists {
-					if svcCtx.IngressNginx == nil {
-						svcCtx.IngressNginx = &intermediate.IngressNginxServiceIR{}
-					}
-					svcCtx.IngressNginx.LoadBalanceAlgorithm = lbAlgorithm
-					ir.Services[svcKey] = svcCtx
-
-					notify(notifications.WarningNotification,
-						fmt.Sprintf("load-balance '%s' requires manual configuration for service %s.\n"+
-							"For Istio: Create DestinationRule with trafficPolicy.loadBalancer.simple: LEAST_REQUEST\n"+
-							"For Envoy Gateway: Create BackendTrafficPolicy with loadBalancer settings", lbAlgorithm, svcKey.Name),
-						&ing,
-					)
+				if seen[svcKey] {
+					continue
 				}
+				seen[svcKey] = true
+
+				// Other fields of the Service IR, like the backend protocol,
+				// may come from other Ingresses and are kept
+				updateServiceIR(ir, svcKey, func(svcIR *intermediate.IngressNginxServiceIR) {
+					mergeServiceIRField(&svcIR.LoadBalanceAlgorithm, lbAlgorithm, loadBalanceAnnotation, svcKey, &ing)
+				})
+
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("load-balance '%s' requires manual configuration for service %s.\n"+
+						"For Istio: Create DestinationRule with trafficPolicy.loadBalancer.simple: LEAST_REQUEST\n"+
+						"For Envoy Gateway: Create BackendTrafficPolicy with loadBalancer settings", lbAlgorithm, svcKey.Name),
+					&ing,
+				)
 			}
 		}
 	}
@@ -143,6 +174,7 @@ func proxySettingsFeature(ingresses []networkingv1.Ingress, _ map[types.Namespac
 // proxySettingsConfig holds parsed proxy settings
 type proxySettingsConfig struct {
 	ProxyBodySize         string
+	ClientBodyBufferSize  string
 	ProxyBuffering        *bool
 	ProxyRequestBuffering *bool
 }
@@ -163,6 +195,12 @@ func parseProxySettings(ing *networkingv1.Ingress) *proxySettingsConfig {
 		hasConfig = true
 	}
 
+	// Parse client-body-buffer-size
+	if bufferSize := strings.TrimSpace(annotations[clientBodyBufferSizeAnnotation]); bufferSize != "" {
+		config.ClientBodyBufferSize = bufferSize
+		hasConfig = true
+	}
+
 	// Parse proxy-buffering
 	if buffering := annotations[proxyBufferingAnnotation]; buffering != "" {
 		val := parseOnOff(buffering)
@@ -190,16 +228,22 @@ func parseOnOff(value string) bool {
 	return v == "on" || v == "true" || v == "1"
 }
 
-// findHTTPRouteKey finds the HTTPRoute key for a given ingress
-func findHTTPRouteKey(ir *intermediate.IR, ingressKey types.NamespacedName) types.NamespacedName {
-	// The common converter creates HTTPRoutes with names based on the ingress host
-	// We need to find the route that corresponds to this ingress
-	for routeKey := range ir.HTTPRoutes {
-		if routeKey.Namespace == ingressKey.Namespace {
-			// For simplicity, return the first route in the same namespace
-			// In practice, this might need more sophisticated matching
-			return routeKey
+// updateIngressRoutes calls update with the ingress-nginx IR of every HTTPRoute
+// built from the ingress, and returns the number of routes updated.
+// Ingresses sharing a host are merged into a single HTTPRoute named after one of
+// them, so routes are matched by their backend sources rather than by name.
+func updateIngressRoutes(ir *intermediate.IR, ing *networkingv1.Ingress, update func(*intermediate.IngressNginxHTTPRouteIR)) int {
+	updated := 0
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace || !routeContainsIngress(routeCtx, ing) {
+			continue
+		}
+		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
+			routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
 		}
+		update(routeCtx.ProviderSpecificIR.IngressNginx)
+		ir.HTTPRoutes[routeKey] = routeCtx
+		updated++
 	}
-	return types.NamespacedName{}
+	return updated
 }
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProxySettingsFeature_ClientBodyBufferSize(t *testing.T) {
+	testCases := []struct {
+		name                string
+		annotations         map[string]string
+		expectedMaxBytes    int64
+		expectedBufferBytes int64
+		expectedWarnings    int
+		expectError         bool
+	}{
+		{
+			name: "buffer size within the max body size",
+			annotations: map[string]string{
+				proxyBodySizeAnnotation:        "10m",
+				clientBodyBufferSizeAnnotation: "64k",
+			},
+			expectedMaxBytes:    10 * 1024 * 1024,
+			expectedBufferBytes: 64 * 1024,
+			expectedWarnings:    1,
+		},
+		{
+			name: "buffer size capped to the max body size",
+			annotations: map[string]string{
+				proxyBodySizeAnnotation:        "1m",
+				clientBodyBufferSizeAnnotation: "8m",
+			},
+			expectedMaxBytes:    1024 * 1024,
+			expectedBufferBytes: 1024 * 1024,
+			expectedWarnings:    2,
+		},
+		{
+			name: "buffer size with unlimited body size",
+			annotations: map[string]string{
+				proxyBodySizeAnnotation:        "0",
+				clientBodyBufferSizeAnnotation: "16k",
+			},
+			expectedBufferBytes: 16 * 1024,
+			expectedWarnings:    1,
+		},
+		{
+			name:        "invalid buffer size",
+			annotations: map[string]string{clientBodyBufferSizeAnnotation: "large"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = proxySettingsFeature(ingresses, nil, &ir)
+			if tc.expectError != (len(errs) > 0) {
+				t.Fatalf("expected error: %v, got %v", tc.expectError, errs)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.GenerateEnvoyFilters(ir)[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-bodysize"}]
+			if tc.expectedMaxBytes == 0 && tc.expectedBufferBytes == 0 {
+				if filter != nil {
+					t.Errorf("expected no bodysize EnvoyFilter, got %s", filter.GetName())
+				}
+				return
+			}
+			if filter == nil {
+				t.Fatalf("expected a bodysize EnvoyFilter")
+			}
+
+			var maxBytes, bufferBytes int64
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			for _, patch := range patches {
+				patch := patch.(map[string]interface{})
+				switch patch["applyTo"] {
+				case "HTTP_FILTER":
+					maxBytes, _, _ = unstructured.NestedInt64(patch, "patch", "value", "typed_config", "max_request_bytes")
+				case "LISTENER":
+					bufferBytes, _, _ = unstructured.NestedInt64(patch, "patch", "value", "per_connection_buffer_limit_bytes")
+				}
+			}
+			if maxBytes != tc.expectedMaxBytes {
+				t.Errorf("expected max_request_bytes %d, got %d", tc.expectedMaxBytes, maxBytes)
+			}
+			if bufferBytes != tc.expectedBufferBytes {
+				t.Errorf("expected per_connection_buffer_limit_bytes %d, got %d", tc.expectedBufferBytes, bufferBytes)
+			}
+		})
+	}
+}
+
+func TestGenerateEnvoyFilters_ConflictingClientBodyBufferSizes(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	small := headersTestIngress(map[string]string{clientBodyBufferSizeAnnotation: "16k"})
+	small.Name = "small-buffer"
+	large := headersTestIngress(map[string]string{clientBodyBufferSizeAnnotation: "64k"})
+	large.Name = "large-buffer"
+	large.Spec.Rules[0].Host = "other.example.com"
+	ingresses := []networkingv1.Ingress{large, small}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := proxySettingsFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filters := generator.GenerateEnvoyFilters(ir)
+	for _, routeName := range []string{common.RouteName(small.Name, "example.com"), common.RouteName(large.Name, "other.example.com")} {
+		filter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeName + "-bodysize"}]
+		if filter == nil {
+			t.Fatalf("expected a bodysize EnvoyFilter for route %s", routeName)
+		}
+		patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+		bufferBytes, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}), "patch", "value", "per_connection_buffer_limit_bytes")
+		if bufferBytes != 16*1024 {
+			t.Errorf("expected the smallest per_connection_buffer_limit_bytes %d for route %s, got %d", 16*1024, routeName, bufferBytes)
+		}
+	}
+
+	conflicts := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "conflicting "+clientBodyBufferSizeAnnotation) {
+			conflicts++
+		}
+	}
+	if conflicts != 1 {
+		t.Errorf("expected 1 conflicting client-body-buffer-size Warning, got %d", conflicts)
+	}
+}
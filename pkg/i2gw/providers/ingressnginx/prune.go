@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// pruneEmptyResources drops the Gateways without listeners and the HTTPRoutes
+// without rules, which degenerate Ingresses can produce and which are invalid
+// on apply. The parentRefs to the pruned Gateways are removed from the
+// HTTPRoutes, pruning the routes left without any, and the generated
+// ReferenceGrants not used by any remaining route are dropped. An Info is
+// emitted for each pruned resource.
+func pruneEmptyResources(gatewayResources *i2gw.GatewayResources) {
+	prunedGateways := sets.New[types.NamespacedName]()
+	for gwKey, gateway := range gatewayResources.Gateways {
+		if len(gateway.Spec.Listeners) > 0 {
+			continue
+		}
+		delete(gatewayResources.Gateways, gwKey)
+		prunedGateways.Insert(gwKey)
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("Gateway %s has no listeners and is not generated", gwKey),
+			&gateway,
+		)
+	}
+
+	for routeKey, route := range gatewayResources.HTTPRoutes {
+		parentRefs := slices.DeleteFunc(slices.Clone(route.Spec.ParentRefs), func(parentRef gatewayv1.ParentReference) bool {
+			return prunedGateways.Has(parentRefGatewayKey(parentRef, route.Namespace))
+		})
+		reason := ""
+		switch {
+		case len(route.Spec.Rules) == 0:
+			reason = "has no rules"
+		case len(parentRefs) == 0 && len(route.Spec.ParentRefs) > 0:
+			reason = "only references Gateways without listeners"
+		}
+		if reason != "" {
+			delete(gatewayResources.HTTPRoutes, routeKey)
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("HTTPRoute %s %s and is not generated", routeKey, reason),
+				&route,
+			)
+			continue
+		}
+		if len(parentRefs) < len(route.Spec.ParentRefs) {
+			route.Spec.ParentRefs = parentRefs
+			gatewayResources.HTTPRoutes[routeKey] = route
+		}
+	}
+
+	pruneUnusedReferenceGrants(gatewayResources, prunedGateways)
+}
+
+// pruneUnusedReferenceGrants drops the ReferenceGrants to Gateways that no
+// longer allow any of the HTTPRoutes to attach, because the Gateways or the
+// routes were pruned or dropped by an earlier step. ReferenceGrants to other
+// kinds are kept.
+func pruneUnusedReferenceGrants(gatewayResources *i2gw.GatewayResources, prunedGateways sets.Set[types.NamespacedName]) {
+	for grantKey, grant := range gatewayResources.ReferenceGrants {
+		if !slices.ContainsFunc(grant.Spec.To, func(to gatewayv1beta1.ReferenceGrantTo) bool { return to.Kind == "Gateway" }) ||
+			slices.ContainsFunc(grant.Spec.To, func(to gatewayv1beta1.ReferenceGrantTo) bool { return to.Kind != "Gateway" }) {
+			continue
+		}
+		to := slices.DeleteFunc(slices.Clone(grant.Spec.To), func(to gatewayv1beta1.ReferenceGrantTo) bool {
+			return to.Name != nil && prunedGateways.Has(types.NamespacedName{Namespace: grantKey.Namespace, Name: string(*to.Name)})
+		})
+
+		if len(to) == 0 || !referenceGrantUsed(gatewayResources, grantKey.Namespace, grant.Spec.From, to) {
+			delete(gatewayResources.ReferenceGrants, grantKey)
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("ReferenceGrant %s is not used by any generated HTTPRoute and is not generated", grantKey),
+				&grant,
+			)
+			continue
+		}
+		if len(to) < len(grant.Spec.To) {
+			grant.Spec.To = to
+			gatewayResources.ReferenceGrants[grantKey] = grant
+		}
+	}
+}
+
+// referenceGrantUsed checks if an HTTPRoute from one of the namespaces of a
+// ReferenceGrant references one of its Gateways in the grant namespace
+func referenceGrantUsed(gatewayResources *i2gw.GatewayResources, grantNamespace string, from []gatewayv1beta1.ReferenceGrantFrom, to []gatewayv1beta1.ReferenceGrantTo) bool {
+	for _, route := range gatewayResources.HTTPRoutes {
+		if !slices.ContainsFunc(from, func(from gatewayv1beta1.ReferenceGrantFrom) bool {
+			return from.Kind == "HTTPRoute" && string(from.Namespace) == route.Namespace
+		}) {
+			continue
+		}
+		for _, parentRef := range route.Spec.ParentRefs {
+			gwKey := parentRefGatewayKey(parentRef, route.Namespace)
+			if gwKey.Namespace != grantNamespace {
+				continue
+			}
+			if slices.ContainsFunc(to, func(to gatewayv1beta1.ReferenceGrantTo) bool {
+				return to.Name == nil || string(*to.Name) == gwKey.Name
+			}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parentRefGatewayKey returns the Gateway a parentRef of a route in the given
+// namespace references, or an empty key if it references another kind
+func parentRefGatewayKey(parentRef gatewayv1.ParentReference, routeNamespace string) types.NamespacedName {
+	if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+		return types.NamespacedName{}
+	}
+	gwKey := types.NamespacedName{Namespace: routeNamespace, Name: string(parentRef.Name)}
+	if parentRef.Namespace != nil {
+		gwKey.Namespace = string(*parentRef.Namespace)
+	}
+	return gwKey
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestPruneEmptyResources(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	sharedGwKey := types.NamespacedName{Namespace: "istio-system", Name: "shared"}
+	emptyGwKey := types.NamespacedName{Namespace: "istio-system", Name: "empty"}
+	emptyRouteKey := types.NamespacedName{Namespace: "team-a", Name: "empty-route"}
+	routeKey := types.NamespacedName{Namespace: "team-b", Name: "route"}
+	orphanRouteKey := types.NamespacedName{Namespace: "team-c", Name: "orphan-route"}
+
+	parentRef := func(gwKey types.NamespacedName) gatewayv1.ParentReference {
+		return gatewayv1.ParentReference{Namespace: ptr.To(gatewayv1.Namespace(gwKey.Namespace)), Name: gatewayv1.ObjectName(gwKey.Name)}
+	}
+	route := func(routeKey types.NamespacedName, rules []gatewayv1.HTTPRouteRule, gwKeys ...types.NamespacedName) gatewayv1.HTTPRoute {
+		httpRoute := gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: routeKey.Namespace, Name: routeKey.Name},
+			Spec:       gatewayv1.HTTPRouteSpec{Rules: rules},
+		}
+		for _, gwKey := range gwKeys {
+			httpRoute.Spec.ParentRefs = append(httpRoute.Spec.ParentRefs, parentRef(gwKey))
+		}
+		return httpRoute
+	}
+	grant := func(fromNamespace string, gwNames ...string) gatewayv1beta1.ReferenceGrant {
+		referenceGrant := gatewayv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: sharedGwKey.Namespace, Name: "allow-routes-from-" + fromNamespace},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: gatewayv1.Namespace(fromNamespace)}},
+			},
+		}
+		for _, name := range gwNames {
+			referenceGrant.Spec.To = append(referenceGrant.Spec.To, gatewayv1beta1.ReferenceGrantTo{
+				Group: gatewayv1.GroupName, Kind: "Gateway", Name: ptr.To(gatewayv1.ObjectName(name)),
+			})
+		}
+		return referenceGrant
+	}
+	rules := []gatewayv1.HTTPRouteRule{{}}
+
+	gatewayResources := i2gw.GatewayResources{
+		Gateways: map[types.NamespacedName]gatewayv1.Gateway{
+			sharedGwKey: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: sharedGwKey.Namespace, Name: sharedGwKey.Name},
+				Spec:       gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}}},
+			},
+			emptyGwKey: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: emptyGwKey.Namespace, Name: emptyGwKey.Name},
+			},
+		},
+		HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{
+			emptyRouteKey:  route(emptyRouteKey, nil, sharedGwKey),
+			routeKey:       route(routeKey, rules, sharedGwKey, emptyGwKey),
+			orphanRouteKey: route(orphanRouteKey, rules, emptyGwKey),
+		},
+		ReferenceGrants: map[types.NamespacedName]gatewayv1beta1.ReferenceGrant{
+			{Namespace: sharedGwKey.Namespace, Name: "allow-routes-from-team-a"}: grant("team-a", sharedGwKey.Name),
+			{Namespace: sharedGwKey.Namespace, Name: "allow-routes-from-team-b"}: grant("team-b", sharedGwKey.Name, emptyGwKey.Name),
+			{Namespace: sharedGwKey.Namespace, Name: "allow-routes-from-team-c"}: grant("team-c", emptyGwKey.Name),
+		},
+	}
+
+	pruneEmptyResources(&gatewayResources)
+
+	if _, ok := gatewayResources.Gateways[emptyGwKey]; ok {
+		t.Errorf("expected Gateway %s without listeners to be pruned", emptyGwKey)
+	}
+	if _, ok := gatewayResources.Gateways[sharedGwKey]; !ok {
+		t.Errorf("expected Gateway %s to be kept", sharedGwKey)
+	}
+	for _, prunedKey := range []types.NamespacedName{emptyRouteKey, orphanRouteKey} {
+		if _, ok := gatewayResources.HTTPRoutes[prunedKey]; ok {
+			t.Errorf("expected HTTPRoute %s to be pruned", prunedKey)
+		}
+	}
+	if parentRefs := gatewayResources.HTTPRoutes[routeKey].Spec.ParentRefs; len(parentRefs) != 1 || parentRefs[0].Name != gatewayv1.ObjectName(sharedGwKey.Name) {
+		t.Errorf("expected only the parentRef to %s to be kept, got %+v", sharedGwKey, parentRefs)
+	}
+
+	if len(gatewayResources.ReferenceGrants) != 1 {
+		t.Fatalf("expected 1 ReferenceGrant to be kept, got %d", len(gatewayResources.ReferenceGrants))
+	}
+	keptGrant, ok := gatewayResources.ReferenceGrants[types.NamespacedName{Namespace: sharedGwKey.Namespace, Name: "allow-routes-from-team-b"}]
+	if !ok {
+		t.Fatalf("expected the ReferenceGrant of team-b to be kept, got %v", gatewayResources.ReferenceGrants)
+	}
+	if len(keptGrant.Spec.To) != 1 || *keptGrant.Spec.To[0].Name != gatewayv1.ObjectName(sharedGwKey.Name) {
+		t.Errorf("expected the ReferenceGrant of team-b to only allow %s, got %+v", sharedGwKey, keptGrant.Spec.To)
+	}
+
+	infos := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification {
+			infos++
+		}
+	}
+	// 1 Gateway, 2 HTTPRoutes and 2 ReferenceGrants
+	if infos != 5 {
+		t.Errorf("expected 5 infos for the pruned resources, got %d", infos)
+	}
+}
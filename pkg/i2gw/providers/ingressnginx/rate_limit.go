@@ -19,7 +19,9 @@ package ingressnginx
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
@@ -52,32 +54,41 @@ func rateLimitFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedNa
 			continue
 		}
 
-		// Get or create the ingress-nginx IR for this route
-		key := types.NamespacedName{
-			Namespace: ing.Namespace,
-			Name:      ing.Name,
-		}
-
-		routeKey := findHTTPRouteKey(ir, key)
-		if routeKey == (types.NamespacedName{}) {
+		// A zero rate would yield a token bucket that never refills, so treat it
+		// as "no rate limit" instead of generating a filter that blocks all traffic
+		if config.RPS == 0 && (ing.Annotations[limitRPSAnnotation] == "0" || ing.Annotations[limitRPMAnnotation] == "0") {
+			notify(notifications.InfoNotification,
+				"Rate limit of 0 is treated as disabled, no rate limiting will be applied",
+				&ing,
+			)
 			continue
 		}
 
-		routeCtx := ir.HTTPRoutes[routeKey]
-		if routeCtx.ProviderSpecificIR.IngressNginx == nil {
-			routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+		// Set rate limiting config on every route built from this ingress
+		updated := updateIngressRoutes(ir, &ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			if config.RPS > 0 {
+				nginxIR.RateLimitRPS = config.RPS
+				nginxIR.RateLimitSource = config.Source
+			}
+			if config.Burst > 0 {
+				nginxIR.RateLimitBurst = config.Burst
+			}
+			nginxIR.RateLimitNoDelay = config.NoDelay
+		})
+		if updated == 0 {
+			continue
 		}
 
-		// Set rate limiting config
-		if config.RPS > 0 {
-			routeCtx.ProviderSpecificIR.IngressNginx.RateLimitRPS = config.RPS
-		}
-		if config.Burst > 0 {
-			routeCtx.ProviderSpecificIR.IngressNginx.RateLimitBurst = config.Burst
+		// The Envoy token bucket never queues requests, so a zone burst without
+		// nodelay is served immediately instead of being delayed like in nginx
+		if config.Zone != "" && config.Burst > 0 && !config.NoDelay {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("limit-req-zone burst=%d without nodelay: requests over the rate are delayed by NGINX, "+
+					"but the generated token bucket serves up to %d of them immediately and rejects the rest", config.Burst, config.Burst),
+				&ing,
+			)
 		}
 
-		ir.HTTPRoutes[routeKey] = routeCtx
-
 		notify(notifications.InfoNotification,
 			fmt.Sprintf("Rate limiting config (RPS: %d, Burst: %d) stored in IR. Requires BackendTrafficPolicy to apply.", config.RPS, config.Burst),
 			&ing,
@@ -93,7 +104,10 @@ type rateLimitConfig struct {
 	RPM   int
 	Connections int
 	Burst int
+	NoDelay bool
 	Zone  string
+	// Source is the annotation RPS was converted from
+	Source string
 }
 
 // parseRateLimitConfig extracts rate limiting configuration from ingress annotations
@@ -118,6 +132,7 @@ func parseRateLimitConfig(ing *networkingv1.Ingress) (*rateLimitConfig, field.Er
 			))
 		} else {
 			config.RPS = val
+			config.Source = limitRPSAnnotation
 			hasConfig = true
 		}
 	}
@@ -139,6 +154,7 @@ func parseRateLimitConfig(ing *networkingv1.Ingress) (*rateLimitConfig, field.Er
 				if config.RPS == 0 && val > 0 {
 					config.RPS = 1 // minimum 1 RPS
 				}
+				config.Source = limitRPMAnnotation
 			}
 			hasConfig = true
 		}
@@ -185,10 +201,12 @@ func parseRateLimitConfig(ing *networkingv1.Ingress) (*rateLimitConfig, field.Er
 		if parseErr == nil && rateConfig.RPS > 0 {
 			if config.RPS == 0 {
 				config.RPS = rateConfig.RPS
+				config.Source = limitReqZoneAnnotation
 			}
 			if config.Burst == 0 && rateConfig.Burst > 0 {
 				config.Burst = rateConfig.Burst
 			}
+			config.NoDelay = rateConfig.NoDelay
 		}
 	}
 
@@ -199,8 +217,8 @@ func parseRateLimitConfig(ing *networkingv1.Ingress) (*rateLimitConfig, field.Er
 	return config, errs
 }
 
-// parseZoneRate extracts rate and burst from a limit-req-zone annotation
-// Format: "$binary_remote_addr zone=rate_limit:10m rate=1000r/s"
+// parseZoneRate extracts rate, burst and nodelay from a limit-req-zone annotation
+// Format: "$binary_remote_addr zone=rate_limit:10m rate=1000r/s burst=2000 nodelay"
 func parseZoneRate(zone string) (*rateLimitConfig, error) {
 	config := &rateLimitConfig{}
 
@@ -225,5 +243,15 @@ func parseZoneRate(zone string) (*rateLimitConfig, error) {
 		}
 	}
 
+	// Extract burst and nodelay
+	burstRegex := regexp.MustCompile(`burst=(\d+)`)
+	if matches := burstRegex.FindStringSubmatch(zone); len(matches) > 1 {
+		burst, err := strconv.Atoi(matches[1])
+		if err == nil {
+			config.Burst = burst
+		}
+	}
+	config.NoDelay = slices.Contains(strings.Fields(zone), "nodelay")
+
 	return config, nil
 }
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRateLimitFeature_ZeroRate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		annotations       map[string]string
+		expectedRPS       int
+		expectDisabledMsg bool
+	}{
+		{
+			name:              "limit-rps of 0 is disabled",
+			annotations:       map[string]string{limitRPSAnnotation: "0"},
+			expectDisabledMsg: true,
+		},
+		{
+			name:              "limit-rpm of 0 is disabled",
+			annotations:       map[string]string{limitRPMAnnotation: "0"},
+			expectDisabledMsg: true,
+		},
+		{
+			name:        "non-zero rate is applied",
+			annotations: map[string]string{limitRPSAnnotation: "10"},
+			expectedRPS: 10,
+		},
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := rateLimitFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var rps int
+			if nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx; nginxIR != nil {
+				rps = nginxIR.RateLimitRPS
+			}
+			if rps != tc.expectedRPS {
+				t.Errorf("expected RateLimitRPS %d, got %d", tc.expectedRPS, rps)
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			var rateLimitFilters int
+			for filterKey := range generator.GenerateEnvoyFilters(ir) {
+				if strings.HasSuffix(filterKey.Name, "-ratelimit") {
+					rateLimitFilters++
+				}
+			}
+			if expected := min(tc.expectedRPS, 1); rateLimitFilters != expected {
+				t.Errorf("expected %d rate limit EnvoyFilters, got %d", expected, rateLimitFilters)
+			}
+
+			var hasDisabledMsg bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.InfoNotification && strings.Contains(n.Message, "treated as disabled") {
+					hasDisabledMsg = true
+				}
+			}
+			if hasDisabledMsg != tc.expectDisabledMsg {
+				t.Errorf("expected disabled Info notification: %v, got: %v", tc.expectDisabledMsg, hasDisabledMsg)
+			}
+		})
+	}
+}
+
+func TestParseZoneRate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		zone            string
+		expectedRPS     int
+		expectedBurst   int
+		expectedNoDelay bool
+	}{
+		{
+			name:        "rate only",
+			zone:        "$binary_remote_addr zone=rate_limit:10m rate=100r/s",
+			expectedRPS: 100,
+		},
+		{
+			name:          "rate and burst",
+			zone:          "$binary_remote_addr zone=rate_limit:10m rate=100r/s burst=200",
+			expectedRPS:   100,
+			expectedBurst: 200,
+		},
+		{
+			name:            "rate, burst and nodelay",
+			zone:            "$binary_remote_addr zone=rate_limit:10m rate=600r/m burst=50 nodelay",
+			expectedRPS:     10,
+			expectedBurst:   50,
+			expectedNoDelay: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := parseZoneRate(tc.zone)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.RPS != tc.expectedRPS || config.Burst != tc.expectedBurst || config.NoDelay != tc.expectedNoDelay {
+				t.Errorf("expected RPS %d, Burst %d, NoDelay %v, got RPS %d, Burst %d, NoDelay %v",
+					tc.expectedRPS, tc.expectedBurst, tc.expectedNoDelay, config.RPS, config.Burst, config.NoDelay)
+			}
+		})
+	}
+}
+
+func TestRateLimitFeature_ZoneBurst(t *testing.T) {
+	testCases := []struct {
+		name            string
+		zone            string
+		expectedNoDelay bool
+		expectWarning   bool
+	}{
+		{
+			name:            "burst with nodelay",
+			zone:            "$binary_remote_addr zone=rate_limit:10m rate=100r/s burst=200 nodelay",
+			expectedNoDelay: true,
+		},
+		{
+			name:          "burst without nodelay",
+			zone:          "$binary_remote_addr zone=rate_limit:10m rate=100r/s burst=200",
+			expectWarning: true,
+		},
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{limitReqZoneAnnotation: tc.zone})}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := rateLimitFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+			if nginxIR == nil {
+				t.Fatalf("expected ingress-nginx IR on route %s", routeKey)
+			}
+			if nginxIR.RateLimitRPS != 100 || nginxIR.RateLimitBurst != 200 {
+				t.Errorf("expected RateLimitRPS 100 and RateLimitBurst 200, got %d and %d", nginxIR.RateLimitRPS, nginxIR.RateLimitBurst)
+			}
+			if nginxIR.RateLimitNoDelay != tc.expectedNoDelay {
+				t.Errorf("expected RateLimitNoDelay %v, got %v", tc.expectedNoDelay, nginxIR.RateLimitNoDelay)
+			}
+
+			var hasWarning bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "without nodelay") {
+					hasWarning = true
+				}
+			}
+			if hasWarning != tc.expectWarning {
+				t.Errorf("expected nodelay Warning: %v, got: %v", tc.expectWarning, hasWarning)
+			}
+		})
+	}
+}
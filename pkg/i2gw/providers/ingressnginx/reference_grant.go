@@ -40,16 +40,13 @@ import (
 // The ReferenceGrant allows:
 // - HTTPRoutes from service namespaces to reference their Gateway
 func buildCrossNamespaceReferenceGrants(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
-	// Collect unique service namespaces from HTTPRoutes
-	serviceNamespaces := make(map[string]bool)
-	for routeKey := range ir.HTTPRoutes {
-		serviceNamespaces[routeKey.Namespace] = true
-	}
+	// Create a ReferenceGrant in the gateway namespace for each service namespace.
+	// Routes are visited individually since their gateway mode may be overridden.
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		serviceNS := routeKey.Namespace
 
-	// Create a ReferenceGrant in the gateway namespace for each service namespace
-	for serviceNS := range serviceNamespaces {
 		// Get the gateway namespace and name for this service
-		gatewayNS, gatewayName := gwConfig.GetGatewayRef(serviceNS)
+		gatewayNS, gatewayName := gwConfig.GetRouteGatewayRef(routeKey, routeCtx)
 		
 		// Skip if route is in the same namespace as its gateway (no cross-namespace ref needed)
 		if serviceNS == gatewayNS {
@@ -60,6 +57,9 @@ func buildCrossNamespaceReferenceGrants(ir intermediate.IR, gatewayResources *i2
 			Namespace: gatewayNS,
 			Name:      "allow-routes-from-" + serviceNS,
 		}
+		if _, exists := gatewayResources.ReferenceGrants[grantKey]; exists {
+			continue
+		}
 
 		grant := gatewayv1beta1.ReferenceGrant{
 			TypeMeta: metav1.TypeMeta{
@@ -69,10 +69,7 @@ func buildCrossNamespaceReferenceGrants(ir intermediate.IR, gatewayResources *i2
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      grantKey.Name,
 				Namespace: grantKey.Namespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/managed-by": "ingress2gateway",
-					"gateway-api-migration":        "true",
-				},
+				Labels: gwConfig.resourceLabels(),
 				Annotations: map[string]string{
 					"ingress2gateway.kubernetes.io/source-namespace": serviceNS,
 					"ingress2gateway.kubernetes.io/description":      "Allows HTTPRoutes from " + serviceNS + " to reference Gateway " + gatewayName,
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const useRegexAnnotation = "nginx.ingress.kubernetes.io/use-regex"
+
+// regexPathFeature returns a feature parser for the use-regex annotation.
+// The RegularExpression path match type is only in the experimental Gateway
+// API channel, so regex paths are converted only if experimentalChannel is
+// set. Otherwise, or if a path is not a valid RE2 expression, use-regex is
+// reported as a migration blocker. Like in ingress-nginx, Exact paths are
+// always matched exactly, so they are left unchanged.
+func regexPathFeature(experimentalChannel bool) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		var errs field.ErrorList
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+			if ing.Annotations[useRegexAnnotation] != "true" || !hasRegexPaths(ing) {
+				continue
+			}
+			// Paths only capturing their end for rewrite-target are converted
+			// to PathPrefix matches by rewriteTargetFeature
+			if _, ok := capturePrefixRewrites(ing); ok {
+				continue
+			}
+
+			if !experimentalChannel {
+				notifyRegexBlocker(ing, fmt.Sprintf("Set --%s-%s to convert regex paths to RegularExpression path matches.", Name, ExperimentalChannelFlag))
+				continue
+			}
+
+			if invalid := invalidRegexPaths(ing); len(invalid) > 0 {
+				notifyRegexBlocker(ing, fmt.Sprintf("Paths that are not valid RE2 expressions: %s", strings.Join(invalid, ", ")))
+				continue
+			}
+
+			if convertRegexPaths(ir, ing) > 0 {
+				notify(notifications.WarningNotification,
+					"use-regex paths converted to RegularExpression path matches, which require the experimental Gateway API channel. "+
+						"NGINX matches regex paths case-insensitively from the start of the path; the generated expressions match the full path, so \".*\" is appended unless the path ends with \"$\".",
+					ing,
+				)
+			}
+		}
+
+		return errs
+	}
+}
+
+// notifyRegexBlocker reports use-regex on the Ingress as a migration blocker
+func notifyRegexBlocker(ing *networkingv1.Ingress, reason string) {
+	notifyWithCode(notifications.ErrorNotification, notifications.UnsupportedRegexPath,
+		fmt.Sprintf("MIGRATION BLOCKER - %s\n%s\n%s",
+			useRegexAnnotation, strings.TrimSpace(appLevelAnnotations[useRegexAnnotation]), reason),
+		ing,
+	)
+}
+
+// isRegexPath returns true if use-regex applies to the path. ingress-nginx
+// generates an exact location for Exact paths, ignoring use-regex.
+func isRegexPath(path networkingv1.HTTPIngressPath) bool {
+	return path.PathType == nil || *path.PathType != networkingv1.PathTypeExact
+}
+
+// hasRegexPaths returns true if use-regex applies to any path of the Ingress
+func hasRegexPaths(ing *networkingv1.Ingress) bool {
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP != nil && slices.ContainsFunc(rule.HTTP.Paths, isRegexPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidRegexPaths returns the paths of the Ingress that are not valid RE2
+// expressions, the syntax used by Envoy for regex matching.
+func invalidRegexPaths(ing *networkingv1.Ingress) []string {
+	var invalid []string
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if !isRegexPath(path) {
+				continue
+			}
+			if _, err := regexp.Compile(path.Path); err != nil {
+				invalid = append(invalid, path.Path)
+			}
+		}
+	}
+	return invalid
+}
+
+// convertRegexPaths sets a RegularExpression path match on the HTTPRoute rules
+// built from the non-Exact Ingress paths, and returns the number of matches
+// updated.
+func convertRegexPaths(ir *intermediate.IR, ing *networkingv1.Ingress) int {
+	regexType := gatewayv1.PathMatchRegularExpression
+	updated := 0
+
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace {
+			continue
+		}
+
+		changed := false
+		for ruleIdx := range routeCtx.HTTPRoute.Spec.Rules {
+			if ruleIdx >= len(routeCtx.RuleBackendSources) || !sourcesContainIngress(routeCtx.RuleBackendSources[ruleIdx], ing) {
+				continue
+			}
+			rule := &routeCtx.HTTPRoute.Spec.Rules[ruleIdx]
+			for matchIdx := range rule.Matches {
+				pathMatch := rule.Matches[matchIdx].Path
+				if pathMatch == nil || pathMatch.Value == nil ||
+					(pathMatch.Type != nil && *pathMatch.Type == gatewayv1.PathMatchExact) {
+					continue
+				}
+				value := regexPathValue(*pathMatch.Value)
+				pathMatch.Type = &regexType
+				pathMatch.Value = &value
+				changed = true
+				updated++
+			}
+		}
+
+		if changed {
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+	}
+
+	return updated
+}
+
+// regexPathValue converts an NGINX regex location, which matches from the
+// start of the path, to an expression matching the full path.
+func regexPathValue(path string) string {
+	path = strings.TrimPrefix(path, "^")
+	if trimmed, ok := strings.CutSuffix(path, "$"); ok {
+		return trimmed
+	}
+	return path + ".*"
+}
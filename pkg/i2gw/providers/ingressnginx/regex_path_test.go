@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestRegexPathFeature(t *testing.T) {
+	testCases := []struct {
+		name                string
+		path                string
+		pathType            networkingv1.PathType
+		experimentalChannel bool
+		expectedType        gatewayv1.PathMatchType
+		expectedValue       string
+		expectBlocker       bool
+		expectWarning       bool
+	}{
+		{
+			name:          "regex path without experimental channel",
+			path:          "/api/v[0-9]+",
+			expectedType:  gatewayv1.PathMatchPathPrefix,
+			expectedValue: "/api/v[0-9]+",
+			expectBlocker: true,
+		},
+		{
+			name:                "regex path with experimental channel",
+			path:                "/api/v[0-9]+",
+			experimentalChannel: true,
+			expectedType:        gatewayv1.PathMatchRegularExpression,
+			expectedValue:       "/api/v[0-9]+.*",
+			expectWarning:       true,
+		},
+		{
+			name:                "anchored regex path with experimental channel",
+			path:                "/api/v[0-9]+/users$",
+			experimentalChannel: true,
+			expectedType:        gatewayv1.PathMatchRegularExpression,
+			expectedValue:       "/api/v[0-9]+/users",
+			expectWarning:       true,
+		},
+		{
+			name:                "non-RE2 regex path with experimental channel",
+			path:                "/api/(?!internal)",
+			experimentalChannel: true,
+			expectedType:        gatewayv1.PathMatchPathPrefix,
+			expectedValue:       "/api/(?!internal)",
+			expectBlocker:       true,
+		},
+		{
+			name:                "exact path is not a regex",
+			path:                "/api/v1/status",
+			pathType:            networkingv1.PathTypeExact,
+			experimentalChannel: true,
+			expectedType:        gatewayv1.PathMatchExact,
+			expectedValue:       "/api/v1/status",
+		},
+		{
+			name:          "exact path is not a blocker without experimental channel",
+			path:          "/api/(?!internal)",
+			pathType:      networkingv1.PathTypeExact,
+			expectedType:  gatewayv1.PathMatchExact,
+			expectedValue: "/api/(?!internal)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingress := headersTestIngress(map[string]string{useRegexAnnotation: "true"})
+			ingress.Spec.Rules[0].HTTP.Paths[0].Path = tc.path
+			if tc.pathType != "" {
+				ingress.Spec.Rules[0].HTTP.Paths[0].PathType = &tc.pathType
+			}
+			ingresses := []networkingv1.Ingress{ingress}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := regexPathFeature(tc.experimentalChannel)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			pathMatch := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules[0].Matches[0].Path
+			if *pathMatch.Type != tc.expectedType {
+				t.Errorf("expected path match type %s, got %s", tc.expectedType, *pathMatch.Type)
+			}
+			if *pathMatch.Value != tc.expectedValue {
+				t.Errorf("expected path match value %q, got %q", tc.expectedValue, *pathMatch.Value)
+			}
+
+			var hasBlocker, hasWarning bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				switch {
+				case n.Type == notifications.ErrorNotification && strings.Contains(n.Message, useRegexAnnotation):
+					hasBlocker = true
+				case n.Type == notifications.WarningNotification && strings.Contains(n.Message, "experimental Gateway API channel"):
+					hasWarning = true
+				}
+			}
+			if hasBlocker != tc.expectBlocker {
+				t.Errorf("expected use-regex blocker: %v, got: %v", tc.expectBlocker, hasBlocker)
+			}
+			if hasWarning != tc.expectWarning {
+				t.Errorf("expected experimental channel Warning: %v, got: %v", tc.expectWarning, hasWarning)
+			}
+		})
+	}
+}
+
+func TestToIR_ExactPathWithRegexPaths(t *testing.T) {
+	ingress := headersTestIngress(map[string]string{useRegexAnnotation: "true"})
+	paths := ingress.Spec.Rules[0].HTTP.Paths
+	paths[0].Path = "/api/v[0-9]+"
+	exact := paths[0]
+	exact.Path = "/api/v1/status"
+	exact.PathType = pathTypePtr(networkingv1.PathTypeExact)
+	ingress.Spec.Rules[0].HTTP.Paths = append(paths, exact)
+
+	key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {ExperimentalChannelFlag: "true"},
+		},
+	})
+	provider.(*Provider).storage.Ingresses = OrderedIngressMap{
+		ingressNames:   []types.NamespacedName{key},
+		ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{key: &ingress},
+	}
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	rules := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	// The Exact path is the most specific, so it comes first
+	expected := []gatewayv1.HTTPPathMatch{
+		{Type: ptrTo(gatewayv1.PathMatchExact), Value: ptrTo("/api/v1/status")},
+		{Type: ptrTo(gatewayv1.PathMatchRegularExpression), Value: ptrTo("/api/v[0-9]+.*")},
+	}
+	for i, rule := range rules {
+		pathMatch := rule.Matches[0].Path
+		if *pathMatch.Type != *expected[i].Type || *pathMatch.Value != *expected[i].Value {
+			t.Errorf("rule %d: expected %s %q, got %s %q", i, *expected[i].Type, *expected[i].Value, *pathMatch.Type, *pathMatch.Value)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"maps"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+)
+
+// defaultResourceLabels returns the labels marking resources generated by the
+// migration
+func defaultResourceLabels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/managed-by": "ingress2gateway",
+		"gateway-api-migration":        "true",
+	}
+}
+
+// resourceLabels returns the labels of the generated resources other than
+// Gateways: the default labels, added to or overridden by --resource-labels.
+// The returned map is a new map the caller may modify.
+func (c GatewayConfig) resourceLabels() map[string]string {
+	labels := defaultResourceLabels()
+	// The value was checked by GatewayConfig.Validate
+	custom, _ := common.ParseLabels(c.ResourceLabels, nil)
+	maps.Copy(labels, custom)
+	return labels
+}
+
+// unstructuredLabels converts labels to the type used by unstructured objects
+func unstructuredLabels(labels map[string]string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		converted[key] = value
+	}
+	return converted
+}
+
+// applyResourceLabels adds the resource labels to the HTTPRoutes and
+// BackendTLSPolicies, which are converted from the Ingresses rather than built
+// by this provider with the labels already set.
+func applyResourceLabels(gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	labels := gwConfig.resourceLabels()
+
+	for key, route := range gatewayResources.HTTPRoutes {
+		route.Labels = mergeLabels(route.Labels, labels)
+		gatewayResources.HTTPRoutes[key] = route
+	}
+	for key, policy := range gatewayResources.BackendTLSPolicies {
+		policy.Labels = mergeLabels(policy.Labels, labels)
+		gatewayResources.BackendTLSPolicies[key] = policy
+	}
+}
+
+// mergeLabels returns a copy of existing with the labels added, so maps shared
+// between resources are not modified
+func mergeLabels(existing, labels map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(labels))
+	maps.Copy(merged, existing)
+	maps.Copy(merged, labels)
+	return merged
+}
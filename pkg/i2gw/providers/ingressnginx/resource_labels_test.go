@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToGatewayResources_ResourceLabels(t *testing.T) {
+	expectedLabels := map[string]string{
+		"app.kubernetes.io/managed-by": "ingress2gateway",
+		"gateway-api-migration":        "wave-2",
+		"team":                         "platform",
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {
+				GatewayModeFlag:        "per-namespace",
+				SkipReferenceGrantFlag: "false",
+				ResourceLabelsFlag:     "team=platform,gateway-api-migration=wave-2",
+			},
+		},
+	}).(*Provider)
+	ingress := headersTestIngress(map[string]string{
+		"nginx.ingress.kubernetes.io/force-ssl-redirect": "true",
+		backendProtocolAnnotation:                        "HTTPS",
+		"nginx.ingress.kubernetes.io/limit-rps":          "10",
+	})
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// The converted route and its SSL redirect route
+	if len(gatewayResources.HTTPRoutes) != 2 {
+		t.Fatalf("expected an HTTPRoute and its redirect route, got %d", len(gatewayResources.HTTPRoutes))
+	}
+	for key, route := range gatewayResources.HTTPRoutes {
+		if diff := cmp.Diff(expectedLabels, route.Labels); diff != "" {
+			t.Errorf("HTTPRoute %s: unexpected labels (-want +got):\n%s", key, diff)
+		}
+	}
+
+	if len(gatewayResources.BackendTLSPolicies) != 1 {
+		t.Fatalf("expected 1 BackendTLSPolicy, got %d", len(gatewayResources.BackendTLSPolicies))
+	}
+	for key, policy := range gatewayResources.BackendTLSPolicies {
+		if diff := cmp.Diff(expectedLabels, policy.Labels); diff != "" {
+			t.Errorf("BackendTLSPolicy %s: unexpected labels (-want +got):\n%s", key, diff)
+		}
+	}
+
+	if len(gatewayResources.ReferenceGrants) != 1 {
+		t.Fatalf("expected 1 ReferenceGrant, got %d", len(gatewayResources.ReferenceGrants))
+	}
+	for key, grant := range gatewayResources.ReferenceGrants {
+		if diff := cmp.Diff(expectedLabels, grant.Labels); diff != "" {
+			t.Errorf("ReferenceGrant %s: unexpected labels (-want +got):\n%s", key, diff)
+		}
+	}
+
+	// The Gateway keeps its own labels
+	for key, gateway := range gatewayResources.Gateways {
+		if _, ok := gateway.Labels["team"]; ok {
+			t.Errorf("Gateway %s: expected resource labels not to be added, got %v", key, gateway.Labels)
+		}
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: provider.gatewayConfig}
+	filters := generator.GenerateEnvoyFilters(ir)
+	if len(filters) == 0 {
+		t.Fatalf("expected an EnvoyFilter for limit-rps")
+	}
+	for key, filter := range filters {
+		if diff := cmp.Diff(expectedLabels, filter.GetLabels()); diff != "" {
+			t.Errorf("EnvoyFilter %s: unexpected labels (-want +got):\n%s", key, diff)
+		}
+	}
+}
+
+func TestGatewayConfig_ValidateResourceLabels(t *testing.T) {
+	gwConfig := GatewayConfig{
+		Mode:                     centralizedGatewayMode,
+		SSLRedirectStatus:        defaultSSLRedirectStatus,
+		RateLimitBurstMultiplier: 1,
+		ResourceLabels:           "team=platform team",
+	}
+
+	errs := gwConfig.Validate()
+	if len(errs) != 1 || errs[0].Field != Name+"."+ResourceLabelsFlag {
+		t.Errorf("expected an error for %s, got %v", ResourceLabelsFlag, errs)
+	}
+}
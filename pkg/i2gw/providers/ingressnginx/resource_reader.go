@@ -20,14 +20,16 @@ import (
 	"context"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // converter implements the i2gw.CustomResourceReader interface.
 type resourceReader struct {
-	conf         *i2gw.ProviderConf
-	ingressClass string
+	conf           *i2gw.ProviderConf
+	ingressClasses sets.Set[string]
 }
 
 // newResourceReader returns a resourceReader instance.
@@ -39,15 +41,15 @@ func newResourceReader(conf *i2gw.ProviderConf) *resourceReader {
 	}
 
 	return &resourceReader{
-		conf:         conf,
-		ingressClass: ingressClass,
+		conf:           conf,
+		ingressClasses: parseIngressClasses(ingressClass),
 	}
 }
 
 func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, sets.New(r.ingressClass))
+	ingresses, err := common.ReadIngressesFromCluster(ctx, r.conf.Client, r.ingressClasses)
 	if err != nil {
 		return nil, err
 	}
@@ -58,13 +60,31 @@ func (r *resourceReader) readResourcesFromCluster(ctx context.Context) (*storage
 		return nil, err
 	}
 	storage.ServicePorts = common.GroupServicePortsByPortName(services)
+
+	configMaps, err := common.ReadConfigMapsFromCluster(ctx, r.conf.Client)
+	if err != nil {
+		return nil, err
+	}
+	storage.ConfigMaps = configMaps
+
+	// Listing Secrets is often not allowed, in which case the Secrets
+	// referenced by annotations are not checked
+	secrets, err := common.ReadSecretNamesFromCluster(ctx, r.conf.Client)
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	if err != nil {
+		notify(notifications.WarningNotification,
+			"not allowed to list Secrets, the Secrets referenced by annotations are not checked", nil)
+	}
+	storage.Secrets = secrets
 	return storage, nil
 }
 
 func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error) {
 	storage := newResourcesStorage()
 
-	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, sets.New(r.ingressClass))
+	ingresses, err := common.ReadIngressesFromFile(filename, r.conf.Namespace, r.ingressClasses)
 	if err != nil {
 		return nil, err
 	}
@@ -75,5 +95,17 @@ func (r *resourceReader) readResourcesFromFile(filename string) (*storage, error
 		return nil, err
 	}
 	storage.ServicePorts = common.GroupServicePortsByPortName(services)
+
+	configMaps, err := common.ReadConfigMapsFromFile(filename, r.conf.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	storage.ConfigMaps = configMaps
+
+	secrets, err := common.ReadSecretNamesFromFile(filename, r.conf.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	storage.Secrets = secrets
 	return storage, nil
 }
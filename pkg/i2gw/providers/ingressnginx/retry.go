@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	proxyNextUpstreamAnnotation        = "nginx.ingress.kubernetes.io/proxy-next-upstream"
+	proxyNextUpstreamTriesAnnotation   = "nginx.ingress.kubernetes.io/proxy-next-upstream-tries"
+	proxyNextUpstreamTimeoutAnnotation = "nginx.ingress.kubernetes.io/proxy-next-upstream-timeout"
+
+	// Defaults of the proxy-next-upstream annotations in ingress-nginx
+	defaultProxyNextUpstream      = "error timeout"
+	defaultProxyNextUpstreamTries = 3
+)
+
+// proxyNextUpstreamConnectionErrors are the proxy-next-upstream conditions
+// covered by the retries on connection errors of Gateway API retries
+var proxyNextUpstreamConnectionErrors = []string{"error", "timeout"}
+
+// retryConfig holds the retries of an Ingress, parsed from the
+// proxy-next-upstream annotations
+type retryConfig struct {
+	// retry is nil when proxy-next-upstream is "off"
+	retry *gatewayv1.HTTPRouteRetry
+	// perTryTimeout is the proxy-next-upstream-timeout, 0 if unlimited
+	perTryTimeout time.Duration
+	// unmapped are the proxy-next-upstream conditions without an equivalent
+	unmapped []string
+}
+
+// retryFeature returns a feature parser converting the proxy-next-upstream,
+// proxy-next-upstream-tries and proxy-next-upstream-timeout annotations to
+// the retry of the HTTPRoute rules generated from the Ingress. Retries are
+// only in the experimental Gateway API channel, so they are converted only if
+// experimentalChannel is set, otherwise a Warning is emitted.
+func retryFeature(experimentalChannel bool) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		var errs field.ErrorList
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+			config, parseErrs := parseRetryConfig(ing.Annotations,
+				field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations"))
+			if len(parseErrs) > 0 {
+				errs = append(errs, parseErrs...)
+				continue
+			}
+			if config == nil {
+				continue
+			}
+
+			if !experimentalChannel {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("proxy-next-upstream annotations are not converted, HTTPRoute retries require the experimental Gateway API channel. Set --%s-%s to convert them",
+						Name, ExperimentalChannelFlag),
+					ing,
+				)
+				continue
+			}
+
+			if applyRetryConfig(ir, ing, config) == 0 {
+				continue
+			}
+			if len(config.unmapped) > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s conditions %s have no HTTPRoute retry equivalent and are ignored",
+						proxyNextUpstreamAnnotation, strings.Join(config.unmapped, ", ")),
+					ing,
+				)
+			}
+			notify(notifications.WarningNotification,
+				"proxy-next-upstream annotations converted to HTTPRoute retries, which require the experimental Gateway API channel. "+
+					"Unlike nginx, the Gateway may also retry non-idempotent requests",
+				ing,
+			)
+		}
+
+		return errs
+	}
+}
+
+// parseRetryConfig parses the proxy-next-upstream annotations, using the
+// ingress-nginx defaults for the ones that are not set. It returns nil if none
+// of them is set.
+func parseRetryConfig(annotations map[string]string, path *field.Path) (*retryConfig, field.ErrorList) {
+	conditions := strings.TrimSpace(annotations[proxyNextUpstreamAnnotation])
+	tries := strings.TrimSpace(annotations[proxyNextUpstreamTriesAnnotation])
+	timeout := strings.TrimSpace(annotations[proxyNextUpstreamTimeoutAnnotation])
+	if conditions == "" && tries == "" && timeout == "" {
+		return nil, nil
+	}
+
+	var errs field.ErrorList
+	config := &retryConfig{retry: &gatewayv1.HTTPRouteRetry{}}
+
+	// nginx counts the first try, Gateway API only the retries. 0 tries is
+	// unlimited in nginx, leaving the number of attempts to the implementation.
+	triesCount := defaultProxyNextUpstreamTries
+	if tries != "" {
+		val, err := strconv.Atoi(tries)
+		if err != nil || val < 0 {
+			errs = append(errs, field.Invalid(path.Key(proxyNextUpstreamTriesAnnotation), tries, "must be a non-negative number"))
+		}
+		triesCount = val
+	}
+	if triesCount > 0 {
+		config.retry.Attempts = ptr.To(triesCount - 1)
+	}
+
+	// 0 is unlimited in nginx
+	if timeout != "" {
+		val, err := strconv.Atoi(timeout)
+		if err != nil || val < 0 {
+			errs = append(errs, field.Invalid(path.Key(proxyNextUpstreamTimeoutAnnotation), timeout, "must be a non-negative number of seconds"))
+		}
+		config.perTryTimeout = time.Duration(val) * time.Second
+	}
+
+	if conditions == "" {
+		conditions = defaultProxyNextUpstream
+	}
+	for _, condition := range strings.Fields(conditions) {
+		switch {
+		case condition == "off":
+			config.retry = nil
+			return config, errs
+		case slices.Contains(proxyNextUpstreamConnectionErrors, condition):
+			// Gateway API implementations retry on connection errors
+		case strings.HasPrefix(condition, "http_"):
+			code, err := strconv.Atoi(strings.TrimPrefix(condition, "http_"))
+			if err != nil || code < 400 || code > 599 {
+				errs = append(errs, field.Invalid(path.Key(proxyNextUpstreamAnnotation), conditions, fmt.Sprintf("unknown condition %q", condition)))
+				continue
+			}
+			config.retry.Codes = append(config.retry.Codes, gatewayv1.HTTPRouteRetryStatusCode(code))
+		default:
+			config.unmapped = append(config.unmapped, condition)
+		}
+	}
+
+	return config, errs
+}
+
+// applyRetryConfig sets the retry on every HTTPRoute rule generated from the
+// Ingress. proxy-next-upstream "off" disables the retries of the
+// implementation with 0 attempts. The proxy-next-upstream-timeout bounds each
+// try with the backendRequest timeout, which is lowered to fit in the request
+// timeout of the rule. It returns the number of rules updated.
+func applyRetryConfig(ir *intermediate.IR, ing *networkingv1.Ingress, config *retryConfig) int {
+	retry := config.retry
+	if retry == nil {
+		retry = &gatewayv1.HTTPRouteRetry{Attempts: ptr.To(0)}
+	}
+
+	updated := 0
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace {
+			continue
+		}
+		changed := false
+		for ruleIdx, sources := range routeCtx.RuleBackendSources {
+			if ruleIdx >= len(routeCtx.HTTPRoute.Spec.Rules) || !sourcesContainIngress(sources, ing) {
+				continue
+			}
+			rule := &routeCtx.HTTPRoute.Spec.Rules[ruleIdx]
+			rule.Retry = retry.DeepCopy()
+			if config.retry != nil && config.perTryTimeout > 0 {
+				setPerTryTimeout(rule, config.perTryTimeout)
+			}
+			changed = true
+			updated++
+		}
+		if changed {
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+	}
+	return updated
+}
+
+// setPerTryTimeout sets the backendRequest timeout of the rule to the per-try
+// timeout, unless the rule already has a shorter one. Gateway API requires the
+// backendRequest timeout to be no more than the request timeout.
+func setPerTryTimeout(rule *gatewayv1.HTTPRouteRule, perTryTimeout time.Duration) {
+	if rule.Timeouts == nil {
+		rule.Timeouts = &gatewayv1.HTTPRouteTimeouts{}
+	}
+	backendRequest := gatewayv1.Duration(fmt.Sprintf("%ds", int(perTryTimeout.Seconds())))
+	for _, limit := range []*gatewayv1.Duration{rule.Timeouts.BackendRequest, rule.Timeouts.Request} {
+		if limit == nil {
+			continue
+		}
+		if d, err := time.ParseDuration(string(*limit)); err == nil && d > 0 && d < perTryTimeout {
+			perTryTimeout, backendRequest = d, *limit
+		}
+	}
+	rule.Timeouts.BackendRequest = ptr.To(backendRequest)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestRetryFeature(t *testing.T) {
+	testCases := []struct {
+		name                string
+		annotations         map[string]string
+		experimentalChannel bool
+		expectedRetry       *gatewayv1.HTTPRouteRetry
+		expectedTimeouts    *gatewayv1.HTTPRouteTimeouts
+		expectError         bool
+	}{
+		{
+			name: "per-try timeout populates the retry config",
+			annotations: map[string]string{
+				proxyNextUpstreamAnnotation:        "error timeout http_502 http_503",
+				proxyNextUpstreamTriesAnnotation:   "4",
+				proxyNextUpstreamTimeoutAnnotation: "5",
+			},
+			experimentalChannel: true,
+			expectedRetry:       &gatewayv1.HTTPRouteRetry{Attempts: ptr.To(3), Codes: []gatewayv1.HTTPRouteRetryStatusCode{502, 503}},
+			expectedTimeouts:    &gatewayv1.HTTPRouteTimeouts{BackendRequest: ptr.To[gatewayv1.Duration]("5s")},
+		},
+		{
+			name: "per-try timeout fits in the request timeout",
+			annotations: map[string]string{
+				proxyNextUpstreamTimeoutAnnotation: "30",
+				proxyReadTimeoutAnnotation:         "10",
+			},
+			experimentalChannel: true,
+			expectedRetry:       &gatewayv1.HTTPRouteRetry{Attempts: ptr.To(2)},
+			expectedTimeouts: &gatewayv1.HTTPRouteTimeouts{
+				Request:        ptr.To[gatewayv1.Duration]("10s"),
+				BackendRequest: ptr.To[gatewayv1.Duration]("10s"),
+			},
+		},
+		{
+			name: "zero tries and timeout are unlimited",
+			annotations: map[string]string{
+				proxyNextUpstreamTriesAnnotation:   "0",
+				proxyNextUpstreamTimeoutAnnotation: "0",
+			},
+			experimentalChannel: true,
+			expectedRetry:       &gatewayv1.HTTPRouteRetry{},
+		},
+		{
+			name:                "off disables retries",
+			annotations:         map[string]string{proxyNextUpstreamAnnotation: "off", proxyNextUpstreamTimeoutAnnotation: "5"},
+			experimentalChannel: true,
+			expectedRetry:       &gatewayv1.HTTPRouteRetry{Attempts: ptr.To(0)},
+		},
+		{
+			name:                "unset annotations add no retry",
+			annotations:         map[string]string{},
+			experimentalChannel: true,
+		},
+		{
+			name:        "not converted without the experimental channel",
+			annotations: map[string]string{proxyNextUpstreamTimeoutAnnotation: "5"},
+		},
+		{
+			name:                "invalid timeout causes error",
+			annotations:         map[string]string{proxyNextUpstreamTimeoutAnnotation: "5s"},
+			experimentalChannel: true,
+			expectError:         true,
+		},
+		{
+			name:                "invalid status code causes error",
+			annotations:         map[string]string{proxyNextUpstreamAnnotation: "http_200"},
+			experimentalChannel: true,
+			expectError:         true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := timeoutFeature(false)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			errs = retryFeature(tc.experimentalChannel)(ingresses, nil, &ir)
+			if tc.expectError {
+				if len(errs) == 0 {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			rule := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules[0]
+			if diff := cmp.Diff(tc.expectedRetry, rule.Retry); diff != "" {
+				t.Errorf("unexpected retry (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedTimeouts, rule.Timeouts); diff != "" {
+				t.Errorf("unexpected timeouts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
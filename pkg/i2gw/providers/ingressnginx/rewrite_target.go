@@ -0,0 +1,299 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// rewriteTargetFeature converts a rewrite-target without capture groups to a
+// URLRewrite filter on each HTTPRoute rule generated from a path of the
+// Ingress. Like the rewrite of the location generated by ingress-nginx for
+// each path, the filter of a PathPrefix rule replaces the prefix matched by
+// that rule, while Exact and RegularExpression rules have their full path
+// replaced. Rewrites with capture groups only keeping the trailing segment of
+// the paths, like /prefix/(.*) rewritten to /$1, are prefix replacements and
+// are converted too. Other rewrites with capture groups are reported by
+// appLevelWarningsFeature, and the catch-all rules of defaultBackends are left
+// unchanged.
+func rewriteTargetFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		target := strings.TrimSpace(ing.Annotations[rewriteTargetAnnotation])
+		if target == "" {
+			continue
+		}
+		if strings.Contains(target, "$") {
+			rewrites, ok := capturePrefixRewrites(ing)
+			if !ok {
+				continue
+			}
+			if updated := applyCapturePrefixRewrites(ir, ing, rewrites); updated > 0 {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("%s %s only keeps the segment captured at the end of the paths, converted to PathPrefix matches with URLRewrite filters replacing the prefix on the %d HTTPRoute rules generated from the Ingress paths",
+						rewriteTargetAnnotation, target, updated),
+					ing,
+				)
+			}
+			continue
+		}
+		if !strings.HasPrefix(target, "/") {
+			errs = append(errs, field.Invalid(
+				field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations").Key(rewriteTargetAnnotation),
+				target, "must be an absolute path"))
+			continue
+		}
+
+		if updated := applyRewriteTarget(ir, ing, target); updated > 0 {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s converted to URLRewrite filters on the %d HTTPRoute rules generated from the Ingress paths", rewriteTargetAnnotation, updated),
+				ing,
+			)
+		}
+	}
+
+	return errs
+}
+
+// applyRewriteTarget adds a URLRewrite filter to the target to every HTTPRoute
+// rule generated from a path of the Ingress. Rules with a redirect, an
+// existing rewrite or mixed path match types are skipped with a Warning. It
+// returns the number of rules updated.
+func applyRewriteTarget(ir *intermediate.IR, ing *networkingv1.Ingress, target string) int {
+	updated := 0
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace {
+			continue
+		}
+		changed := false
+		for ruleIdx, sources := range routeCtx.RuleBackendSources {
+			if ruleIdx >= len(routeCtx.HTTPRoute.Spec.Rules) || !sourcesContainIngressPath(sources, ing) {
+				continue
+			}
+			rule := &routeCtx.HTTPRoute.Spec.Rules[ruleIdx]
+			pathModifier, err := rewritePathModifier(*rule, target)
+			if err != nil {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s not converted for a rule of HTTPRoute %s/%s: %v", rewriteTargetAnnotation, routeKey.Namespace, routeKey.Name, err),
+					ing,
+				)
+				continue
+			}
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: pathModifier},
+			})
+			changed = true
+			updated++
+		}
+		if changed {
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+	}
+	return updated
+}
+
+// capturePrefixRewrite is the prefix replacement equivalent to the rewrite of
+// an Ingress path with a capture group
+type capturePrefixRewrite struct {
+	// prefix is the literal part of the path, matched as a PathPrefix
+	prefix string
+	// replacement replaces the matched prefix
+	replacement string
+}
+
+// capturePrefixRewrites returns the prefix replacements by path of an Ingress
+// whose rewrite-target only keeps the segment captured at the end of its
+// paths, e.g. /prefix/(.*) or /prefix(/|$)(.*) rewritten to /$1 or /$2, or
+// false if any of its paths is rewritten in another way.
+func capturePrefixRewrites(ing *networkingv1.Ingress) (map[string]capturePrefixRewrite, bool) {
+	target := strings.TrimSpace(ing.Annotations[rewriteTargetAnnotation])
+	rewrites := make(map[string]capturePrefixRewrite)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if !isRegexPath(path) {
+				return nil, false
+			}
+			rewrite, ok := capturePrefixRewriteForPath(path.Path, target)
+			if !ok {
+				return nil, false
+			}
+			rewrites[path.Path] = rewrite
+		}
+	}
+	return rewrites, len(rewrites) > 0
+}
+
+// capturePrefixRewriteForPath returns the prefix replacement equivalent to
+// the rewrite of the path to the target, if the path is a literal prefix
+// followed by a capture of the rest of the path, which the target appends to
+// a literal path ending with "/".
+func capturePrefixRewriteForPath(path, target string) (capturePrefixRewrite, bool) {
+	path = strings.TrimPrefix(path, "^")
+	path = strings.TrimSuffix(path, "$")
+
+	var prefix, capture string
+	switch {
+	case strings.HasSuffix(path, "(/|$)(.*)"):
+		prefix, capture = strings.TrimSuffix(path, "(/|$)(.*)"), "$2"
+	case strings.HasSuffix(path, "/(.*)"):
+		prefix, capture = strings.TrimSuffix(path, "/(.*)"), "$1"
+	default:
+		return capturePrefixRewrite{}, false
+	}
+	replacement, found := strings.CutSuffix(target, capture)
+	if !found || !strings.HasPrefix(replacement, "/") || !strings.HasSuffix(replacement, "/") ||
+		strings.Contains(replacement, "$") || strings.ContainsAny(prefix, `\.+*?()[]{}|^$`) {
+		return capturePrefixRewrite{}, false
+	}
+
+	// Gateway API matches and replaces prefixes by path elements, so the
+	// trailing "/" is implied
+	rewrite := capturePrefixRewrite{prefix: prefix, replacement: strings.TrimSuffix(replacement, "/")}
+	if rewrite.prefix == "" {
+		rewrite.prefix = "/"
+	}
+	if rewrite.replacement == "" {
+		rewrite.replacement = "/"
+	}
+	return rewrite, true
+}
+
+// applyCapturePrefixRewrites replaces the path matches of every HTTPRoute rule
+// generated from a path of the Ingress with a PathPrefix match of its literal
+// prefix, and adds a URLRewrite filter replacing it. Rules with a redirect or
+// an existing rewrite are skipped with a Warning. It returns the number of
+// rules updated.
+func applyCapturePrefixRewrites(ir *intermediate.IR, ing *networkingv1.Ingress, rewrites map[string]capturePrefixRewrite) int {
+	prefixType := gatewayv1.PathMatchPathPrefix
+	updated := 0
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		if routeKey.Namespace != ing.Namespace {
+			continue
+		}
+		changed := false
+		for ruleIdx, sources := range routeCtx.RuleBackendSources {
+			if ruleIdx >= len(routeCtx.HTTPRoute.Spec.Rules) {
+				continue
+			}
+			rewrite, ok := sourcesCapturePrefixRewrite(sources, ing, rewrites)
+			if !ok {
+				continue
+			}
+
+			rule := routeCtx.HTTPRoute.Spec.Rules[ruleIdx]
+			rule.Matches = slices.Clone(rule.Matches)
+			for i := range rule.Matches {
+				rule.Matches[i].Path = &gatewayv1.HTTPPathMatch{Type: &prefixType, Value: ptr.To(rewrite.prefix)}
+			}
+			pathModifier, err := rewritePathModifier(rule, rewrite.replacement)
+			if err != nil {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s not converted for a rule of HTTPRoute %s/%s: %v", rewriteTargetAnnotation, routeKey.Namespace, routeKey.Name, err),
+					ing,
+				)
+				continue
+			}
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: pathModifier},
+			})
+			routeCtx.HTTPRoute.Spec.Rules[ruleIdx] = rule
+			changed = true
+			updated++
+		}
+		if changed {
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+	}
+	return updated
+}
+
+// sourcesCapturePrefixRewrite returns the prefix replacement of the path of
+// the Ingress a rule was generated from
+func sourcesCapturePrefixRewrite(sources []intermediate.BackendSource, ing *networkingv1.Ingress, rewrites map[string]capturePrefixRewrite) (capturePrefixRewrite, bool) {
+	for _, source := range sources {
+		if source.Path == nil || !sourcesContainIngress([]intermediate.BackendSource{source}, ing) {
+			continue
+		}
+		if rewrite, ok := rewrites[source.Path.Path]; ok {
+			return rewrite, true
+		}
+	}
+	return capturePrefixRewrite{}, false
+}
+
+// rewritePathModifier returns the path modifier rewriting the paths matched by
+// the rule to the target: the matched prefix is replaced for PathPrefix
+// matches, and the full path for Exact and RegularExpression matches.
+func rewritePathModifier(rule gatewayv1.HTTPRouteRule, target string) (*gatewayv1.HTTPPathModifier, error) {
+	for _, filter := range rule.Filters {
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestRedirect:
+			return nil, fmt.Errorf("the rule redirects requests")
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			return nil, fmt.Errorf("the rule already has a URLRewrite filter")
+		}
+	}
+
+	prefixMatches := 0
+	for _, match := range rule.Matches {
+		if match.Path == nil || match.Path.Type == nil || *match.Path.Type == gatewayv1.PathMatchPathPrefix {
+			prefixMatches++
+		}
+	}
+	switch prefixMatches {
+	case len(rule.Matches):
+		return &gatewayv1.HTTPPathModifier{
+			Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+			ReplacePrefixMatch: ptr.To(target),
+		}, nil
+	case 0:
+		return &gatewayv1.HTTPPathModifier{
+			Type:            gatewayv1.FullPathHTTPPathModifier,
+			ReplaceFullPath: ptr.To(target),
+		}, nil
+	default:
+		return nil, fmt.Errorf("the rule mixes PathPrefix with Exact or RegularExpression path matches")
+	}
+}
+
+// sourcesContainIngressPath checks if any of the backend sources came from a
+// path of the given Ingress, rather than from its defaultBackend
+func sourcesContainIngressPath(sources []intermediate.BackendSource, ing *networkingv1.Ingress) bool {
+	for _, source := range sources {
+		if source.Path != nil && sourcesContainIngress([]intermediate.BackendSource{source}, ing) {
+			return true
+		}
+	}
+	return false
+}
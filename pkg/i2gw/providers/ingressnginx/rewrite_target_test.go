@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestRewriteTargetFeature(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{rewriteTargetAnnotation: "/v2"})
+	ingress.Spec.Rules[0].HTTP.Paths = []networkingv1.HTTPIngressPath{
+		{
+			Path:     "/api",
+			PathType: pathTypePtr(networkingv1.PathTypePrefix),
+			Backend:  ingress.Spec.Rules[0].HTTP.Paths[0].Backend,
+		},
+		{
+			Path:     "/status",
+			PathType: pathTypePtr(networkingv1.PathTypeExact),
+			Backend:  ingress.Spec.Rules[0].HTTP.Paths[0].Backend,
+		},
+	}
+	ingress.Spec.DefaultBackend = &ingress.Spec.Rules[0].HTTP.Paths[0].Backend
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := defaultBackendFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if errs := rewriteTargetFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// Each path rule gets its own rewrite, the defaultBackend rule none
+	expectedFilters := map[string][]gatewayv1.HTTPRouteFilter{
+		"/api": {{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+				Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+				ReplacePrefixMatch: ptr.To("/v2"),
+			}},
+		}},
+		"/status": {{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+				Type:            gatewayv1.FullPathHTTPPathModifier,
+				ReplaceFullPath: ptr.To("/v2"),
+			}},
+		}},
+		"/": nil,
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	rules := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules
+	if len(rules) != len(expectedFilters) {
+		t.Fatalf("expected %d rules, got %d", len(expectedFilters), len(rules))
+	}
+	for _, rule := range rules {
+		path := *rule.Matches[0].Path.Value
+		if diff := cmp.Diff(expectedFilters[path], rule.Filters); diff != "" {
+			t.Errorf("unexpected filters of the %s rule (-want +got):\n%s", path, diff)
+		}
+	}
+}
+
+func TestRewriteTargetFeature_CaptureGroups(t *testing.T) {
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{rewriteTargetAnnotation: "/$2"})}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := rewriteTargetFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for _, routeCtx := range ir.HTTPRoutes {
+		for _, rule := range routeCtx.HTTPRoute.Spec.Rules {
+			if len(rule.Filters) > 0 {
+				t.Errorf("expected rewrites with capture groups to be left to the migration blocker, got %+v", rule.Filters)
+			}
+		}
+	}
+}
+
+func TestRewriteTargetFeature_CapturePrefix(t *testing.T) {
+	testCases := []struct {
+		name                string
+		path                string
+		target              string
+		expectedPrefix      string
+		expectedReplacement string
+	}{
+		{
+			name:                "trailing segment rewritten to the root",
+			path:                "/prefix/(.*)",
+			target:              "/$1",
+			expectedPrefix:      "/prefix",
+			expectedReplacement: "/",
+		},
+		{
+			name:                "optional trailing slash",
+			path:                "/app(/|$)(.*)",
+			target:              "/$2",
+			expectedPrefix:      "/app",
+			expectedReplacement: "/",
+		},
+		{
+			name:                "trailing segment rewritten under another prefix",
+			path:                "^/prefix/(.*)$",
+			target:              "/v2/$1",
+			expectedPrefix:      "/prefix",
+			expectedReplacement: "/v2",
+		},
+		{
+			name:   "capture in the middle of the path",
+			path:   "/prefix/(.*)/edit",
+			target: "/$1",
+		},
+		{
+			name:   "capture appended without a slash",
+			path:   "/prefix/(.*)",
+			target: "/v2$1",
+		},
+		{
+			name:   "regex prefix",
+			path:   "/v[0-9]+/(.*)",
+			target: "/$1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+			ingress := headersTestIngress(map[string]string{
+				useRegexAnnotation:      "true",
+				rewriteTargetAnnotation: tc.target,
+			})
+			ingress.Spec.Rules[0].HTTP.Paths[0].Path = tc.path
+			ingresses := []networkingv1.Ingress{ingress}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			for _, feature := range []i2gw.FeatureParser{appLevelWarningsFeature, regexPathFeature(true), rewriteTargetFeature} {
+				if errs := feature(ingresses, nil, &ir); len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+			}
+
+			blockers := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.ErrorNotification {
+					blockers++
+				}
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			rule := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules[0]
+			if tc.expectedPrefix == "" {
+				if len(rule.Filters) > 0 {
+					t.Errorf("expected the rewrite to be left to the migration blocker, got %+v", rule.Filters)
+				}
+				if blockers == 0 {
+					t.Errorf("expected a migration blocker for the rewrite")
+				}
+				return
+			}
+
+			if blockers > 0 {
+				t.Errorf("expected no migration blocker, got %d", blockers)
+			}
+			expectedMatch := gatewayv1.HTTPPathMatch{Type: ptr.To(gatewayv1.PathMatchPathPrefix), Value: ptr.To(tc.expectedPrefix)}
+			if diff := cmp.Diff(expectedMatch, *rule.Matches[0].Path); diff != "" {
+				t.Errorf("unexpected path match (-want +got):\n%s", diff)
+			}
+			expectedFilters := []gatewayv1.HTTPRouteFilter{{
+				Type: gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+					Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+					ReplacePrefixMatch: ptr.To(tc.expectedReplacement),
+				}},
+			}}
+			if diff := cmp.Diff(expectedFilters, rule.Filters); diff != "" {
+				t.Errorf("unexpected filters (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// sortHTTPRouteRules orders the rules of every HTTPRoute from the most to the
+// least specific path: Exact matches first, then PathPrefix matches from the
+// longest to the shortest prefix, then RegularExpression matches. Gateway API
+// implementations apply this precedence regardless of the order, so sorting
+// only makes the output predictable and easier to read. The backend sources
+// are reordered with the rules.
+func sortHTTPRouteRules(ir *intermediate.IR) {
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		rules := routeCtx.HTTPRoute.Spec.Rules
+		sources := routeCtx.RuleBackendSources
+		if len(rules) < 2 || (sources != nil && len(sources) != len(rules)) {
+			continue
+		}
+
+		order := make([]int, len(rules))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return compareRuleSpecificity(rules[a], rules[b])
+		})
+
+		sortedRules := make([]gatewayv1.HTTPRouteRule, len(rules))
+		var sortedSources [][]intermediate.BackendSource
+		if sources != nil {
+			sortedSources = make([][]intermediate.BackendSource, len(sources))
+		}
+		for i, idx := range order {
+			sortedRules[i] = rules[idx]
+			if sources != nil {
+				sortedSources[i] = sources[idx]
+			}
+		}
+
+		routeCtx.HTTPRoute.Spec.Rules = sortedRules
+		routeCtx.RuleBackendSources = sortedSources
+		ir.HTTPRoutes[routeKey] = routeCtx
+	}
+}
+
+// pathSpecificity describes how specific a match is, the lower the more specific
+type pathSpecificity struct {
+	typeRank int
+	length   int
+	headers  int
+}
+
+// compareRuleSpecificity orders rules by their most specific match
+func compareRuleSpecificity(a, b gatewayv1.HTTPRouteRule) int {
+	return comparePathSpecificity(ruleSpecificity(a), ruleSpecificity(b))
+}
+
+func comparePathSpecificity(a, b pathSpecificity) int {
+	return cmp.Or(
+		cmp.Compare(a.typeRank, b.typeRank),
+		// Longer paths and more header matches are more specific
+		cmp.Compare(b.length, a.length),
+		cmp.Compare(b.headers, a.headers),
+	)
+}
+
+// ruleSpecificity returns the specificity of the most specific match of the
+// rule. A rule without matches matches every path, like a "/" prefix.
+func ruleSpecificity(rule gatewayv1.HTTPRouteRule) pathSpecificity {
+	if len(rule.Matches) == 0 {
+		return matchSpecificity(gatewayv1.HTTPRouteMatch{})
+	}
+	specificity := matchSpecificity(rule.Matches[0])
+	for _, match := range rule.Matches[1:] {
+		if s := matchSpecificity(match); comparePathSpecificity(s, specificity) < 0 {
+			specificity = s
+		}
+	}
+	return specificity
+}
+
+func matchSpecificity(match gatewayv1.HTTPRouteMatch) pathSpecificity {
+	specificity := pathSpecificity{typeRank: 1, length: 1, headers: len(match.Headers)}
+	if match.Path == nil {
+		return specificity
+	}
+	if match.Path.Value != nil {
+		specificity.length = len(*match.Path.Value)
+	}
+	if match.Path.Type != nil {
+		switch *match.Path.Type {
+		case gatewayv1.PathMatchExact:
+			specificity.typeRank = 0
+		case gatewayv1.PathMatchRegularExpression:
+			specificity.typeRank = 2
+		}
+	}
+	return specificity
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestToIR_RulesSortedBySpecificity(t *testing.T) {
+	iPrefix := networkingv1.PathTypePrefix
+	iExact := networkingv1.PathTypeExact
+
+	path := func(value string, pathType *networkingv1.PathType, service string) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{
+			Path:     value,
+			PathType: pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: service,
+					Port: networkingv1.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "api"}
+	provider := NewProvider(&i2gw.ProviderConf{})
+	provider.(*Provider).storage.Ingresses = OrderedIngressMap{
+		ingressNames: []types.NamespacedName{key},
+		ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{
+			key: {
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptrTo("nginx"),
+					Rules: []networkingv1.IngressRule{{
+						Host: "api.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{
+									path("/", &iPrefix, "web"),
+									path("/api", &iPrefix, "api"),
+									path("/api/v1", &iPrefix, "api-v1"),
+									path("/health", &iExact, "health"),
+								},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+
+	routeCtx, ok := ir.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: "api-api-example-com"}]
+	if !ok {
+		t.Fatalf("Expected HTTPRoute default/api-api-example-com, got %v", ir.HTTPRoutes)
+	}
+
+	var gotPaths, gotServices, gotSourceServices []string
+	for i, rule := range routeCtx.HTTPRoute.Spec.Rules {
+		gotPaths = append(gotPaths, *rule.Matches[0].Path.Value)
+		gotServices = append(gotServices, string(rule.BackendRefs[0].Name))
+		gotSourceServices = append(gotSourceServices, routeCtx.RuleBackendSources[i][0].Path.Backend.Service.Name)
+	}
+
+	wantPaths := []string{"/health", "/api/v1", "/api", "/"}
+	if diff := cmp.Diff(wantPaths, gotPaths); diff != "" {
+		t.Errorf("Unexpected rule order (-want +got):\n%s", diff)
+	}
+	// Backends and their sources move with their rules
+	wantServices := []string{"health", "api-v1", "api", "web"}
+	if diff := cmp.Diff(wantServices, gotServices); diff != "" {
+		t.Errorf("Unexpected rule backends (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantServices, gotSourceServices); diff != "" {
+		t.Errorf("Unexpected rule backend sources (-want +got):\n%s", diff)
+	}
+}
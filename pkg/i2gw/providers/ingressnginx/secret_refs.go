@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const authSecretAnnotation = "nginx.ingress.kubernetes.io/auth-secret"
+
+// secretRefAnnotations are the annotations referencing a Secret, in the order
+// they are reported. ingress-nginx requires "<namespace>/<name>" references,
+// except for auth-secret, which also accepts a name in the Ingress namespace.
+var secretRefAnnotations = []struct {
+	annotation    string
+	allowBareName bool
+}{
+	{annotation: authTLSSecretAnnotation},
+	{annotation: proxySSLSecretAnnotation},
+	{annotation: authSecretAnnotation, allowBareName: true},
+}
+
+// secretRefsFeature returns a feature parser that validates the Secret
+// references of annotations and emits a Warning for each referenced Secret
+// missing from the input. Secrets are not part of the FeatureParser arguments,
+// so they are bound when the parser is created. A nil set means the Secrets
+// could not be listed, in which case only the references are validated.
+func secretRefsFeature(secrets sets.Set[types.NamespacedName]) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, _ *intermediate.IR) field.ErrorList {
+		var errs field.ErrorList
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+
+			// Annotations referencing the same missing Secret share a Warning
+			var missing []types.NamespacedName
+			missingAnnotations := make(map[types.NamespacedName][]string)
+			for _, ref := range secretRefAnnotations {
+				value := strings.TrimSpace(ing.Annotations[ref.annotation])
+				if value == "" {
+					continue
+				}
+				secretKey, err := parseSecretRef(value, ing.Namespace, ref.allowBareName)
+				if err != nil {
+					errs = append(errs, field.Invalid(
+						field.NewPath("metadata", "annotations", ref.annotation),
+						value,
+						err.Error(),
+					))
+					continue
+				}
+				if secrets == nil || secrets.Has(secretKey) {
+					continue
+				}
+				if missingAnnotations[secretKey] == nil {
+					missing = append(missing, secretKey)
+				}
+				missingAnnotations[secretKey] = append(missingAnnotations[secretKey], strings.TrimPrefix(ref.annotation, "nginx.ingress.kubernetes.io/"))
+			}
+
+			for _, secretKey := range missing {
+				notifyWithCode(notifications.WarningNotification, notifications.MissingSecret,
+					fmt.Sprintf("Secret %s referenced by %s was not found in the input. Create it before applying the generated resources",
+						secretKey, strings.Join(missingAnnotations[secretKey], ", ")),
+					ing,
+				)
+			}
+		}
+
+		return errs
+	}
+}
+
+// parseSecretRef parses a "<namespace>/<name>" Secret reference, or a name in
+// the Ingress namespace if allowBareName is set
+func parseSecretRef(ref, ingressNamespace string, allowBareName bool) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		if !allowBareName {
+			return types.NamespacedName{}, fmt.Errorf("must be a <namespace>/<name> Secret reference")
+		}
+		namespace, name = ingressNamespace, ref
+	}
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid Secret namespace %q: %s", namespace, strings.Join(msgs, ", "))
+	}
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return types.NamespacedName{}, fmt.Errorf("invalid Secret name %q: %s", name, strings.Join(msgs, ", "))
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
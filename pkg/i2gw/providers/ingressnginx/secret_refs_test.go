@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSecretRefsFeature(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		secrets          sets.Set[types.NamespacedName]
+		expectedWarnings []string
+		expectedErrors   int
+	}{
+		{
+			name: "missing Secret referenced by two annotations",
+			annotations: map[string]string{
+				authTLSSecretAnnotation:  "nginx/client-cert",
+				proxySSLSecretAnnotation: "nginx/client-cert",
+			},
+			secrets: sets.New(types.NamespacedName{Namespace: "default", Name: "other"}),
+			expectedWarnings: []string{
+				"Secret nginx/client-cert referenced by auth-tls-secret, proxy-ssl-secret was not found in the input. Create it before applying the generated resources",
+			},
+		},
+		{
+			name:        "existing Secret",
+			annotations: map[string]string{authTLSSecretAnnotation: "nginx/client-cert"},
+			secrets:     sets.New(types.NamespacedName{Namespace: "nginx", Name: "client-cert"}),
+		},
+		{
+			name:        "auth-secret in the Ingress namespace",
+			annotations: map[string]string{authSecretAnnotation: "basic-auth"},
+			secrets:     sets.New[types.NamespacedName](),
+			expectedWarnings: []string{
+				"Secret default/basic-auth referenced by auth-secret was not found in the input. Create it before applying the generated resources",
+			},
+		},
+		{
+			name:           "reference without a namespace",
+			annotations:    map[string]string{authTLSSecretAnnotation: "client-cert"},
+			secrets:        sets.New[types.NamespacedName](),
+			expectedErrors: 1,
+		},
+		{
+			name:        "Secrets not listed",
+			annotations: map[string]string{authTLSSecretAnnotation: "nginx/client-cert"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			errs = secretRefsFeature(tc.secrets)(ingresses, nil, &ir)
+			if len(errs) != tc.expectedErrors {
+				t.Fatalf("expected %d errors, got %v", tc.expectedErrors, errs)
+			}
+
+			var warnings []string
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type != notifications.WarningNotification {
+					continue
+				}
+				if n.Code != notifications.MissingSecret {
+					t.Errorf("expected the %s code, got %q", notifications.MissingSecret, n.Code)
+				}
+				warnings = append(warnings, n.Message)
+			}
+			if strings.Join(warnings, "\n") != strings.Join(tc.expectedWarnings, "\n") {
+				t.Errorf("expected warnings %q, got %q", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
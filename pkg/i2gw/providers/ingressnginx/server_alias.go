@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const serverAliasAnnotation = "nginx.ingress.kubernetes.io/server-alias"
+
+// serverAliasFeature converts server-alias to additional hostnames on the
+// HTTPRoutes generated from the rule hosts of the Ingress, with a listener for
+// each alias on their Gateway. When the rule host is served over TLS, the
+// alias gets an HTTPS listener with the certificates of the Ingress TLS blocks
+// covering it. An alias not covered by any TLS block only gets an HTTP
+// listener, since ingress-nginx would serve it a certificate for another host.
+func serverAliasFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	// ingress-nginx ignores aliases that are the host of a rule
+	ruleHosts := make(map[string]bool)
+	for _, ing := range ingresses {
+		for _, rule := range ing.Spec.Rules {
+			ruleHosts[strings.ToLower(rule.Host)] = true
+		}
+	}
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		value, ok := ing.Annotations[serverAliasAnnotation]
+		if !ok {
+			continue
+		}
+		aliasPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations", serverAliasAnnotation)
+
+		var aliases []string
+		for _, alias := range parseServerAliases(value) {
+			if msg := validateHostname(alias); msg != "" {
+				errs = append(errs, field.Invalid(aliasPath, alias, msg))
+				continue
+			}
+			if ruleHosts[alias] {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s %q is ignored: it is already the host of an Ingress rule", serverAliasAnnotation, alias),
+					ing,
+				)
+				continue
+			}
+			aliases = append(aliases, alias)
+		}
+		if len(aliases) == 0 {
+			continue
+		}
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" {
+				continue
+			}
+			for _, routeKey := range ingressHostRoutes(ir, ing, rule.Host) {
+				addServerAliases(ir, routeKey, ing, rule.Host, aliases)
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseServerAliases splits the comma or space separated aliases
+func parseServerAliases(value string) []string {
+	var aliases []string
+	for _, alias := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// ingressHostRoutes returns the HTTPRoutes of the host that the Ingress
+// contributes rules to
+func ingressHostRoutes(ir *intermediate.IR, ing *networkingv1.Ingress, host string) []types.NamespacedName {
+	var routeKeys []types.NamespacedName
+	for _, routeKey := range slices.SortedFunc(maps.Keys(ir.HTTPRoutes), compareNamespacedNames) {
+		routeCtx := ir.HTTPRoutes[routeKey]
+		if routeKey.Namespace != ing.Namespace || !slices.Contains(routeCtx.HTTPRoute.Spec.Hostnames, gatewayv1.Hostname(host)) {
+			continue
+		}
+		if slices.ContainsFunc(routeSourceIngresses(routeCtx), func(source *networkingv1.Ingress) bool {
+			return source.Name == ing.Name
+		}) {
+			routeKeys = append(routeKeys, routeKey)
+		}
+	}
+	return routeKeys
+}
+
+// addServerAliases adds the aliases to the hostnames of the route and the
+// listeners of its Gateway
+func addServerAliases(ir *intermediate.IR, routeKey types.NamespacedName, ing *networkingv1.Ingress, host string, aliases []string) {
+	routeCtx := ir.HTTPRoutes[routeKey]
+	for _, alias := range aliases {
+		if !slices.Contains(routeCtx.HTTPRoute.Spec.Hostnames, gatewayv1.Hostname(alias)) {
+			routeCtx.HTTPRoute.Spec.Hostnames = append(routeCtx.HTTPRoute.Spec.Hostnames, gatewayv1.Hostname(alias))
+		}
+	}
+	ir.HTTPRoutes[routeKey] = routeCtx
+
+	if len(routeCtx.HTTPRoute.Spec.ParentRefs) == 0 {
+		return
+	}
+	gwKey := types.NamespacedName{Namespace: routeKey.Namespace, Name: string(routeCtx.HTTPRoute.Spec.ParentRefs[0].Name)}
+	gatewayCtx, ok := ir.Gateways[gwKey]
+	if !ok {
+		return
+	}
+
+	hostTLS := common.IngressTLSForHost(ing.Spec.TLS, host)
+	for _, alias := range aliases {
+		hostname := gatewayv1.Hostname(alias)
+		addListener(&gatewayCtx.Gateway, gatewayv1.Listener{
+			Name:     gatewayv1.SectionName(common.NameFromHost(alias) + "-http"),
+			Hostname: &hostname,
+			Port:     80,
+			Protocol: gatewayv1.HTTPProtocolType,
+		})
+		if len(hostTLS) == 0 {
+			continue
+		}
+
+		aliasTLS := common.IngressTLSForHost(hostTLS, alias)
+		if len(aliasTLS) == 0 {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s %q of host %s is not covered by the TLS hosts of the Ingress, so it only gets an HTTP listener; "+
+					"add it to a TLS block with a certificate valid for it to serve HTTPS", serverAliasAnnotation, alias, host),
+				ing,
+			)
+			continue
+		}
+		listenerTLS := &gatewayv1.ListenerTLSConfig{}
+		for _, tls := range aliasTLS {
+			certificateRef := gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(tls.SecretName)}
+			if !slices.Contains(listenerTLS.CertificateRefs, certificateRef) {
+				listenerTLS.CertificateRefs = append(listenerTLS.CertificateRefs, certificateRef)
+			}
+		}
+		addListener(&gatewayCtx.Gateway, gatewayv1.Listener{
+			Name:     gatewayv1.SectionName(common.NameFromHost(alias) + "-https"),
+			Hostname: &hostname,
+			Port:     443,
+			Protocol: gatewayv1.HTTPSProtocolType,
+			TLS:      listenerTLS,
+		})
+	}
+	ir.Gateways[gwKey] = gatewayCtx
+}
+
+// addListener adds the listener to the Gateway unless it already has a
+// listener with the same name
+func addListener(gateway *gatewayv1.Gateway, listener gatewayv1.Listener) {
+	if slices.ContainsFunc(gateway.Spec.Listeners, func(existing gatewayv1.Listener) bool {
+		return existing.Name == listener.Name
+	}) {
+		return
+	}
+	gateway.Spec.Listeners = append(gateway.Spec.Listeners, listener)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestServerAliasFeature_TLS(t *testing.T) {
+	testCases := []struct {
+		name              string
+		alias             string
+		tls               []networkingv1.IngressTLS
+		expectedHostnames []gatewayv1.Hostname
+		// expectedListeners maps the listener names to the secret they serve,
+		// empty for HTTP listeners
+		expectedListeners map[gatewayv1.SectionName]string
+		expectedWarnings  int
+	}{
+		{
+			name:              "alias covered by a wildcard TLS host gets an HTTPS listener",
+			alias:             "www.example.com",
+			tls:               []networkingv1.IngressTLS{{Hosts: []string{"example.com", "*.example.com"}, SecretName: "example-tls"}},
+			expectedHostnames: []gatewayv1.Hostname{"example.com", "www.example.com"},
+			expectedListeners: map[gatewayv1.SectionName]string{
+				"example-com-http":      "",
+				"example-com-https":     "example-tls",
+				"www-example-com-http":  "",
+				"www-example-com-https": "example-tls",
+			},
+		},
+		{
+			name:              "alias not covered by the certificate only gets an HTTP listener",
+			alias:             "example.org",
+			tls:               []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}},
+			expectedHostnames: []gatewayv1.Hostname{"example.com", "example.org"},
+			expectedListeners: map[gatewayv1.SectionName]string{
+				"example-com-http":  "",
+				"example-com-https": "example-tls",
+				"example-org-http":  "",
+			},
+			expectedWarnings: 1,
+		},
+		{
+			name:              "alias of a host without TLS",
+			alias:             "example.org",
+			expectedHostnames: []gatewayv1.Hostname{"example.com", "example.org"},
+			expectedListeners: map[gatewayv1.SectionName]string{
+				"example-com-http": "",
+				"example-org-http": "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingress := headersTestIngress(map[string]string{serverAliasAnnotation: tc.alias})
+			ingress.Spec.TLS = tc.tls
+			ingresses := []networkingv1.Ingress{ingress}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			if errs := serverAliasFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			if diff := cmp.Diff(tc.expectedHostnames, ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Hostnames); diff != "" {
+				t.Errorf("unexpected HTTPRoute hostnames (-want +got):\n%s", diff)
+			}
+
+			gateway := ir.Gateways[types.NamespacedName{Namespace: "default", Name: "nginx"}]
+			listeners := make(map[gatewayv1.SectionName]string)
+			for _, listener := range gateway.Spec.Listeners {
+				listeners[listener.Name] = ""
+				if listener.TLS != nil && len(listener.TLS.CertificateRefs) > 0 {
+					listeners[listener.Name] = string(listener.TLS.CertificateRefs[0].Name)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedListeners, listeners); diff != "" {
+				t.Errorf("unexpected Gateway listeners (-want +got):\n%s", diff)
+			}
+
+			warnings := 0
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %d", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	serverSnippetAnnotation = "nginx.ingress.kubernetes.io/server-snippet"
+
+	// envoyMaxRequestHeadersKB is the largest max_request_headers_kb Envoy accepts
+	envoyMaxRequestHeadersKB = 8192
+)
+
+// serverSnippetFeature translates server-snippets that only raise the client
+// header buffer sizes into the max request headers size of the Gateway's
+// connection manager. Any other snippet is left to appLevelWarningsFeature,
+// which reports it as a migration blocker.
+func serverSnippetFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for _, ing := range ingresses {
+		snippet := ing.Annotations[serverSnippetAnnotation]
+		if snippet == "" {
+			continue
+		}
+		headersKB, ok := parseHeaderBufferSnippet(snippet)
+		if !ok {
+			continue
+		}
+
+		for routeKey, routeCtx := range ir.HTTPRoutes {
+			if routeKey.Namespace != ing.Namespace || !routeContainsIngress(routeCtx, &ing) {
+				continue
+			}
+			if routeCtx.ProviderSpecificIR.IngressNginx == nil {
+				routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+			}
+			nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+			nginxIR.MaxRequestHeadersKB = max(nginxIR.MaxRequestHeadersKB, headersKB)
+			ir.HTTPRoutes[routeKey] = routeCtx
+		}
+
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("server-snippet only sets client header buffer sizes, translated to an EnvoyFilter with max_request_headers_kb: %d", headersKB),
+			&ing,
+		)
+	}
+
+	return errs
+}
+
+// parseHeaderBufferSnippet returns the total request header size in KB allowed
+// by a snippet made up only of large_client_header_buffers and
+// client_header_buffer_size directives. It returns false if the snippet
+// contains any other directive.
+func parseHeaderBufferSnippet(snippet string) (int, bool) {
+	var headerBytes int64
+	found := false
+
+	for _, statement := range strings.Split(stripSnippetComments(snippet), ";") {
+		fields := strings.Fields(statement)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "large_client_header_buffers" && len(fields) == 3:
+			number, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil || number <= 0 {
+				return 0, false
+			}
+			size, err := ParseBodySize(fields[2])
+			if err != nil || size <= 0 {
+				return 0, false
+			}
+			headerBytes = max(headerBytes, number*size)
+		case fields[0] == "client_header_buffer_size" && len(fields) == 2:
+			size, err := ParseBodySize(fields[1])
+			if err != nil || size <= 0 {
+				return 0, false
+			}
+			headerBytes = max(headerBytes, size)
+		default:
+			return 0, false
+		}
+		found = true
+	}
+
+	if !found {
+		return 0, false
+	}
+
+	headersKB := (headerBytes + 1023) / 1024
+	return int(min(headersKB, envoyMaxRequestHeadersKB)), true
+}
+
+// stripSnippetComments removes "#" comments from an NGINX snippet
+func stripSnippetComments(snippet string) string {
+	lines := strings.Split(snippet, "\n")
+	for i, line := range lines {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			lines[i] = line[:idx]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// routeContainsIngress returns true if any rule of the route is backed by the Ingress
+func routeContainsIngress(routeCtx intermediate.HTTPRouteContext, ing *networkingv1.Ingress) bool {
+	for _, sources := range routeCtx.RuleBackendSources {
+		if sourcesContainIngress(sources, ing) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseHeaderBufferSnippet(t *testing.T) {
+	testCases := []struct {
+		name       string
+		snippet    string
+		expectedKB int
+		expectOK   bool
+	}{
+		{
+			name:       "large_client_header_buffers",
+			snippet:    "large_client_header_buffers 4 16k;",
+			expectedKB: 64,
+			expectOK:   true,
+		},
+		{
+			name:       "both directives with comment",
+			snippet:    "# raise header limits\nclient_header_buffer_size 8k;\nlarge_client_header_buffers 8 32k;",
+			expectedKB: 256,
+			expectOK:   true,
+		},
+		{
+			name:       "capped at the Envoy maximum",
+			snippet:    "large_client_header_buffers 16 1m;",
+			expectedKB: envoyMaxRequestHeadersKB,
+			expectOK:   true,
+		},
+		{
+			name:     "other directive",
+			snippet:  "large_client_header_buffers 4 16k;\nadd_header X-Frame-Options DENY;",
+			expectOK: false,
+		},
+		{
+			name:     "invalid size",
+			snippet:  "large_client_header_buffers 4 big;",
+			expectOK: false,
+		},
+		{
+			name:     "comment only",
+			snippet:  "# nothing here",
+			expectOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			headersKB, ok := parseHeaderBufferSnippet(tc.snippet)
+			if ok != tc.expectOK {
+				t.Fatalf("expected ok: %v, got: %v", tc.expectOK, ok)
+			}
+			if headersKB != tc.expectedKB {
+				t.Errorf("expected %d KB, got %d", tc.expectedKB, headersKB)
+			}
+		})
+	}
+}
+
+func TestServerSnippetFeature_HeaderBuffers(t *testing.T) {
+	ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{
+		serverSnippetAnnotation: "large_client_header_buffers 4 16k;",
+	})}
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+
+	if errs := serverSnippetFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+	if nginxIR == nil || nginxIR.MaxRequestHeadersKB != 64 {
+		t.Fatalf("expected MaxRequestHeadersKB 64, got %+v", nginxIR)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+	filter := generator.GenerateEnvoyFilters(ir)[types.NamespacedName{
+		Namespace: "default",
+		Name:      "default-" + routeKey.Name + "-headerbuffers",
+	}]
+	if filter == nil {
+		t.Fatalf("expected header buffers EnvoyFilter")
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) != 1 {
+		t.Fatalf("expected 1 config patch, got %d", len(patches))
+	}
+	headersKB, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "max_request_headers_kb")
+	if headersKB != 64 {
+		t.Errorf("expected max_request_headers_kb 64, got %d", headersKB)
+	}
+}
+
+func TestToGatewayResources_HeaderBufferSnippet(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace", EmitEnvoyFiltersFlag: "true"},
+		},
+	}).(*Provider)
+	ingress := headersTestIngress(map[string]string{
+		serverSnippetAnnotation: "large_client_header_buffers 4 16k;",
+	})
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var filter *unstructured.Unstructured
+	for i, extension := range gatewayResources.GatewayExtensions {
+		if extension.GetKind() == "EnvoyFilter" && strings.HasSuffix(extension.GetName(), "-headerbuffers") {
+			filter = &gatewayResources.GatewayExtensions[i]
+		}
+	}
+	if filter == nil {
+		t.Fatalf("expected a header buffers EnvoyFilter in the GatewayExtensions, got %d extensions", len(gatewayResources.GatewayExtensions))
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	headersKB, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "max_request_headers_kb")
+	if headersKB != 64 {
+		t.Errorf("expected max_request_headers_kb 64, got %d", headersKB)
+	}
+
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.ErrorNotification {
+			t.Errorf("expected no migration blocker for a header buffer server-snippet, got %q", n.Message)
+		}
+	}
+}
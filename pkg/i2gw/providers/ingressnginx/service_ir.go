@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// updateServiceIR applies update to the ingress-nginx IR of the Service,
+// creating it if needed. The IR of a Service is shared by every Ingress
+// referencing it, so update must only set the fields it owns, with
+// mergeServiceIRField, rather than replace the struct.
+func updateServiceIR(ir *intermediate.IR, svcKey types.NamespacedName, update func(*intermediate.IngressNginxServiceIR)) {
+	if ir.Services == nil {
+		ir.Services = make(map[types.NamespacedName]intermediate.ProviderSpecificServiceIR)
+	}
+	svcIR := ir.Services[svcKey]
+	if svcIR.IngressNginx == nil {
+		svcIR.IngressNginx = &intermediate.IngressNginxServiceIR{}
+	}
+	update(svcIR.IngressNginx)
+	ir.Services[svcKey] = svcIR
+}
+
+// mergeServiceIRField sets a field of the IR of a Service to the value of an
+// annotation. Setting the same value again has no effect. A different value
+// from another Ingress is ignored with a Warning, so the first Ingress wins.
+func mergeServiceIRField(current *string, value, annotation string, svcKey types.NamespacedName, ing *networkingv1.Ingress) {
+	switch {
+	case value == "" || *current == value:
+	case *current == "":
+		*current = value
+	default:
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("service %s has conflicting %s values (%q, %q), keeping %q", svcKey, annotation, *current, value, *current),
+			ing)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// Source range annotations. allowlist-source-range is the newer name of
+	// whitelist-source-range and takes precedence when both are set.
+	whitelistSourceRangeAnnotation = "nginx.ingress.kubernetes.io/whitelist-source-range"
+	allowlistSourceRangeAnnotation = "nginx.ingress.kubernetes.io/allowlist-source-range"
+	limitWhitelistAnnotation       = "nginx.ingress.kubernetes.io/limit-whitelist"
+)
+
+// sourceRangeFeature parses the client CIDRs allowed to access the routes of
+// an Ingress (whitelist-source-range) and the CIDRs exempted from its rate
+// limits (limit-whitelist), and stores them in the IR. The allow list is
+// enforced by an RBAC EnvoyFilter, or an AuthorizationPolicy with
+// --ingress-nginx-source-range-authorization-policy, that runs before the rate
+// limit, so only allowed clients are rate limited or exempted.
+func sourceRangeFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		annotationsPath := field.NewPath("ingress", ing.Namespace, ing.Name, "metadata", "annotations")
+
+		allowAnnotation := allowlistSourceRangeAnnotation
+		if _, ok := ing.Annotations[allowAnnotation]; !ok {
+			allowAnnotation = whitelistSourceRangeAnnotation
+		}
+		allowed, allowErrs := parseSourceRanges(ing.Annotations[allowAnnotation], annotationsPath.Child(allowAnnotation))
+		exempt, exemptErrs := parseSourceRanges(ing.Annotations[limitWhitelistAnnotation], annotationsPath.Child(limitWhitelistAnnotation))
+		errs = append(errs, allowErrs...)
+		errs = append(errs, exemptErrs...)
+
+		if len(exempt) > 0 {
+			if config, _ := parseRateLimitConfig(ing); config == nil || config.RPS == 0 {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("%s has no effect without a rate limit (limit-rps, limit-rpm or limit-req-zone)", limitWhitelistAnnotation),
+					ing,
+				)
+				exempt = nil
+			}
+		}
+
+		// Clients outside the allow list are denied before the rate limit,
+		// so exempting them has no effect
+		if len(allowed) > 0 && len(exempt) > 0 {
+			var outside []string
+			exempt = slices.DeleteFunc(exempt, func(exemptRange string) bool {
+				if !slices.ContainsFunc(allowed, func(allowedRange string) bool {
+					return sourceRangesOverlap(exemptRange, allowedRange)
+				}) {
+					outside = append(outside, exemptRange)
+					return true
+				}
+				return false
+			})
+			if len(outside) > 0 {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s ranges %s are outside of %s and are denied before the rate limit applies; they are dropped from the exemption",
+						limitWhitelistAnnotation, strings.Join(outside, ", "), allowAnnotation),
+					ing,
+				)
+			}
+		}
+
+		if len(allowed) == 0 && len(exempt) == 0 {
+			continue
+		}
+
+		updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+			if len(allowed) > 0 {
+				nginxIR.AllowSourceRanges = allowed
+			}
+			if len(exempt) > 0 {
+				nginxIR.RateLimitExemptRanges = exempt
+			}
+		})
+		if updated == 0 {
+			continue
+		}
+
+		if len(allowed) > 0 {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s converted to an RBAC EnvoyFilter denying clients outside of %s", allowAnnotation, strings.Join(allowed, ", ")),
+				ing,
+			)
+		}
+		if len(exempt) > 0 {
+			notify(notifications.InfoNotification,
+				fmt.Sprintf("%s converted to a rate limit exemption for %s", limitWhitelistAnnotation, strings.Join(exempt, ", ")),
+				ing,
+			)
+		}
+	}
+
+	return errs
+}
+
+// parseSourceRanges parses a comma separated list of CIDRs or IP addresses
+// into normalized CIDRs. Invalid entries are reported and skipped.
+func parseSourceRanges(value string, path *field.Path) ([]string, field.ErrorList) {
+	var errs field.ErrorList
+	var ranges []string
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		cidr := entry
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				errs = append(errs, field.Invalid(path, entry, "must be an IP address or CIDR"))
+				continue
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			errs = append(errs, field.Invalid(path, entry, "must be an IP address or CIDR"))
+			continue
+		}
+		if normalized := ipNet.String(); !slices.Contains(ranges, normalized) {
+			ranges = append(ranges, normalized)
+		}
+	}
+
+	return ranges, errs
+}
+
+// sourceRangesOverlap returns true if the two normalized CIDRs share any
+// address, i.e. one of them contains the other
+func sourceRangesOverlap(a, b string) bool {
+	_, netA, errA := net.ParseCIDR(a)
+	_, netB, errB := net.ParseCIDR(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSourceRangeFeature_WithRateLimitExemption(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(map[string]string{
+		whitelistSourceRangeAnnotation: "10.0.0.0/8, 172.16.0.1",
+		limitWhitelistAnnotation:       "10.1.0.0/16,192.168.1.1",
+		limitRPSAnnotation:             "10",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	for _, feature := range []i2gw.FeatureParser{rateLimitFeature, sourceRangeFeature} {
+		if errs := feature(ingresses, nil, &ir); len(errs) > 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	nginxIR := ir.HTTPRoutes[routeKey].ProviderSpecificIR.IngressNginx
+	if nginxIR == nil {
+		t.Fatalf("expected ingress-nginx IR on route %s", routeKey)
+	}
+	if diff := cmp.Diff([]string{"10.0.0.0/8", "172.16.0.1/32"}, nginxIR.AllowSourceRanges); diff != "" {
+		t.Errorf("unexpected allowed source ranges (-want +got):\n%s", diff)
+	}
+	// 192.168.1.1 is denied by the allow list, so exempting it has no effect
+	if diff := cmp.Diff([]string{"10.1.0.0/16"}, nginxIR.RateLimitExemptRanges); diff != "" {
+		t.Errorf("unexpected rate limit exempt ranges (-want +got):\n%s", diff)
+	}
+	warnings := 0
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected 1 warning for the exempt range outside of the allow list, got %d", warnings)
+	}
+
+	generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace", RateLimitBurstMultiplier: 1}}
+	filters := generator.GenerateEnvoyFilters(ir)
+
+	allowlistFilter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-allowlist"}]
+	rateLimitFilter := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-ratelimit"}]
+	if allowlistFilter == nil || rateLimitFilter == nil {
+		t.Fatalf("expected allowlist and ratelimit EnvoyFilters, got %d filters", len(filters))
+	}
+
+	// The allow list runs before the rate limit
+	expectedChain := []string{"envoy.filters.http.rbac", "envoy.filters.http.local_ratelimit", "envoy.filters.http.router"}
+	if diff := cmp.Diff(expectedChain, httpFilterChain(rateLimitFilter, allowlistFilter)); diff != "" {
+		t.Errorf("unexpected HTTP filters (-want +got):\n%s", diff)
+	}
+
+	allowlistPatches, _, _ := unstructured.NestedSlice(allowlistFilter.Object, "spec", "configPatches")
+	policy, _, _ := unstructured.NestedMap(allowlistPatches[0].(map[string]interface{}),
+		"patch", "value", "typed_config", "rules", "policies", allowlistFilter.GetName())
+	expectedPolicy := map[string]interface{}{
+		"permissions": []interface{}{
+			map[string]interface{}{
+				"header": map[string]interface{}{
+					"name": ":authority",
+					"string_match": map[string]interface{}{
+						"safe_regex": map[string]interface{}{"regex": `^(example\.com)(:[0-9]+)?$`},
+					},
+				},
+			},
+		},
+		"principals": []interface{}{
+			map[string]interface{}{
+				"not_id": map[string]interface{}{
+					"or_ids": map[string]interface{}{
+						"ids": []interface{}{
+							map[string]interface{}{"remote_ip": map[string]interface{}{"address_prefix": "10.0.0.0", "prefix_len": int64(8)}},
+							map[string]interface{}{"remote_ip": map[string]interface{}{"address_prefix": "172.16.0.1", "prefix_len": int64(32)}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedPolicy, policy); diff != "" {
+		t.Errorf("unexpected RBAC policy (-want +got):\n%s", diff)
+	}
+
+	rateLimitPatches, _, _ := unstructured.NestedSlice(rateLimitFilter.Object, "spec", "configPatches")
+	rateLimitPatch := rateLimitPatches[0].(map[string]interface{})
+	if name, _, _ := unstructured.NestedString(rateLimitPatch, "patch", "value", "typed_config", "extension_config", "name"); name != "envoy.filters.http.local_ratelimit" {
+		t.Errorf("expected the local rate limit to be wrapped in a matcher, got extension %q", name)
+	}
+	rangeMatchers, _, _ := unstructured.NestedSlice(rateLimitPatch,
+		"patch", "value", "typed_config", "xds_matcher", "matcher_tree", "custom_match", "typed_config", "range_matchers")
+	if len(rangeMatchers) != 1 {
+		t.Fatalf("expected 1 range matcher, got %d", len(rangeMatchers))
+	}
+	ranges, _, _ := unstructured.NestedSlice(rangeMatchers[0].(map[string]interface{}), "ranges")
+	expectedRanges := []interface{}{
+		map[string]interface{}{"address_prefix": "10.1.0.0", "prefix_len": int64(16)},
+	}
+	if diff := cmp.Diff(expectedRanges, ranges); diff != "" {
+		t.Errorf("unexpected rate limit exempt ranges (-want +got):\n%s", diff)
+	}
+}
+
+func TestSourceRangeAuthorizationPolicy(t *testing.T) {
+	ingress := headersTestIngress(map[string]string{
+		whitelistSourceRangeAnnotation: "10.0.0.0/8, 172.16.0.1",
+	})
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := sourceRangeFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	gwConfig := GatewayConfig{
+		Mode:                           "per-namespace",
+		GatewayInServiceNamespace:      true,
+		GatewayClassName:               "istio",
+		RateLimitBurstMultiplier:       1,
+		SSLRedirectStatus:              defaultSSLRedirectStatus,
+		SourceRangeAuthorizationPolicy: true,
+	}
+	if errs := gwConfig.Validate(); len(errs) > 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+	generator := &EnvoyFilterGenerator{GatewayConfig: gwConfig}
+	filters := generator.GenerateEnvoyFilters(ir)
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	if _, ok := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-allowlist"}]; ok {
+		t.Errorf("expected no allowlist EnvoyFilter with an AuthorizationPolicy")
+	}
+	policy := filters[types.NamespacedName{Namespace: "default", Name: "default-" + routeKey.Name + "-sourcerange"}]
+	if policy == nil {
+		t.Fatalf("expected a sourcerange AuthorizationPolicy, got %d resources", len(filters))
+	}
+	if policy.GetKind() != "AuthorizationPolicy" || policy.GetAPIVersion() != "security.istio.io/v1" {
+		t.Errorf("expected a security.istio.io/v1 AuthorizationPolicy, got %s %s", policy.GetAPIVersion(), policy.GetKind())
+	}
+
+	spec, _, _ := unstructured.NestedMap(policy.Object, "spec")
+	expectedSpec := map[string]interface{}{
+		"targetRefs": []interface{}{
+			map[string]interface{}{
+				"kind":  "Gateway",
+				"group": "gateway.networking.k8s.io",
+				"name":  "default-gateway",
+			},
+		},
+		"action": "DENY",
+		"rules": []interface{}{
+			map[string]interface{}{
+				"from": []interface{}{
+					map[string]interface{}{
+						"source": map[string]interface{}{
+							"notRemoteIpBlocks": []interface{}{"10.0.0.0/8", "172.16.0.1/32"},
+						},
+					},
+				},
+				"to": []interface{}{
+					map[string]interface{}{
+						"operation": map[string]interface{}{
+							"hosts": []interface{}{"example.com", "example.com:*"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedSpec, spec); diff != "" {
+		t.Errorf("unexpected AuthorizationPolicy spec (-want +got):\n%s", diff)
+	}
+
+	// AuthorizationPolicies are only enforced by Istio
+	gwConfig.GatewayClassName = "envoy-gateway"
+	if errs := gwConfig.Validate(); len(errs) != 1 {
+		t.Errorf("expected 1 validation error for a non-Istio gateway class, got %v", errs)
+	}
+}
+
+func TestParseSourceRanges(t *testing.T) {
+	ranges, errs := parseSourceRanges("10.0.0.1/8, 2001:db8::1, not-an-ip,10.0.0.0/8", nil)
+	if diff := cmp.Diff([]string{"10.0.0.0/8", "2001:db8::1/128"}, ranges); diff != "" {
+		t.Errorf("unexpected source ranges (-want +got):\n%s", diff)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error for the invalid entry, got %v", errs)
+	}
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package ingressnginx
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
@@ -32,29 +36,96 @@ import (
 
 const (
 	// SSL redirect annotations
-	sslRedirectAnnotation      = "nginx.ingress.kubernetes.io/ssl-redirect"
-	forceSSLRedirectAnnotation = "nginx.ingress.kubernetes.io/force-ssl-redirect"
+	sslRedirectAnnotation        = "nginx.ingress.kubernetes.io/ssl-redirect"
+	forceSSLRedirectAnnotation   = "nginx.ingress.kubernetes.io/force-ssl-redirect"
+	usePortInRedirectsAnnotation = "nginx.ingress.kubernetes.io/use-port-in-redirects"
+
+	// httpsRedirectPort is the port SSL redirects point to when the Gateway has
+	// no HTTPS listener for the host
+	httpsRedirectPort = 443
+
+	// defaultSSLRedirectStatus is the status code of SSL redirects, see SSLRedirectStatusFlag
+	defaultSSLRedirectStatus = 301
 )
 
+// sslRedirectStatusCodes are the redirect status codes allowed for SSL redirects.
+// Gateway API v1 only supports 301 and 302 in RequestRedirect filters, 307 and
+// 308 preserve the request method but need CRDs and implementations allowing them.
+var sslRedirectStatusCodes = []int{301, 302, 307, 308}
+
+// sslRedirectStatusCodeStrings returns sslRedirectStatusCodes for error messages
+func sslRedirectStatusCodeStrings() []string {
+	codes := make([]string, 0, len(sslRedirectStatusCodes))
+	for _, code := range sslRedirectStatusCodes {
+		codes = append(codes, strconv.Itoa(code))
+	}
+	return codes
+}
+
+// sslRedirectSetting is the SSL redirect behavior requested by a single Ingress
+type sslRedirectSetting int
+
+const (
+	sslRedirectUnset sslRedirectSetting = iota
+	sslRedirectDisabled
+	sslRedirectEnabled
+	sslRedirectForced
+)
+
+// parseSSLRedirectSetting returns the SSL redirect behavior requested by the Ingress annotations.
+// force-ssl-redirect=true takes precedence over ssl-redirect on the same Ingress.
+func parseSSLRedirectSetting(ingress *networkingv1.Ingress) sslRedirectSetting {
+	if ingress.Annotations[forceSSLRedirectAnnotation] == "true" {
+		return sslRedirectForced
+	}
+	switch ingress.Annotations[sslRedirectAnnotation] {
+	case "true":
+		return sslRedirectEnabled
+	case "false":
+		return sslRedirectDisabled
+	}
+	return sslRedirectUnset
+}
+
 // sslRedirectFeature processes ssl-redirect and force-ssl-redirect annotations
-// and adds RequestRedirect filters to HTTPRoutes
+// and adds RequestRedirect filters to HTTPRoutes.
+//
+// Multiple Ingresses on the same host share one HTTPRoute, so their settings are
+// combined with the following precedence:
+//  1. force-ssl-redirect=true on any Ingress enables the redirect
+//  2. otherwise, ssl-redirect=false on any Ingress disables the redirect
+//  3. otherwise, ssl-redirect=true on any Ingress enables the redirect
+//
+// A Warning is emitted when the contributing Ingresses disagree. ssl-redirect only
+// applies when the host has a TLS listener, whereas force-ssl-redirect redirects
+// even without TLS.
 func sslRedirectFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
 	var errList field.ErrorList
 
 	// Build a map of ingress to SSL redirect config
-	ingressRedirects := make(map[types.NamespacedName]bool)
-	for _, ingress := range ingresses {
-		sslRedirect := ingress.Annotations[sslRedirectAnnotation]
-		forceSSLRedirect := ingress.Annotations[forceSSLRedirectAnnotation]
-
-		// Check if SSL redirect is enabled
-		if sslRedirect == "true" || forceSSLRedirect == "true" {
-			key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
-			ingressRedirects[key] = true
+	ingressSettings := make(map[types.NamespacedName]sslRedirectSetting)
+	hasRedirect := false
+	for i := range ingresses {
+		setting := parseSSLRedirectSetting(&ingresses[i])
+		// Only the SSL redirects are converted, not the app-root and other
+		// redirects ingress-nginx sets the port of
+		if setting < sslRedirectEnabled && ingresses[i].Annotations[usePortInRedirectsAnnotation] == "true" {
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("%s has no effect without ssl-redirect or force-ssl-redirect: only the SSL redirects are converted, app-root and the other redirects of ingress-nginx are not",
+					usePortInRedirectsAnnotation),
+				&ingresses[i])
+		}
+		if setting == sslRedirectUnset {
+			continue
+		}
+		key := types.NamespacedName{Namespace: ingresses[i].Namespace, Name: ingresses[i].Name}
+		ingressSettings[key] = setting
+		if setting >= sslRedirectEnabled {
+			hasRedirect = true
 		}
 	}
 
-	if len(ingressRedirects) == 0 {
+	if !hasRedirect {
 		return errList
 	}
 
@@ -67,14 +138,56 @@ func sslRedirectFeature(ingresses []networkingv1.Ingress, _ map[types.Namespaced
 			continue
 		}
 
-		// Find if any contributing ingress has SSL redirect enabled
-		hasSSLRedirect := false
-		for _, ingress := range ingresses {
-			ingressKey := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
-			if ingressRedirects[ingressKey] && matchesRoute(&ingress, rg.Host) {
-				hasSSLRedirect = true
-				break
+		// Collect the settings of all contributing ingresses
+		var forced, enabled, disabled []string
+		hasTLS := false
+		usePortInRedirects := false
+		for i := range ingresses {
+			ingress := &ingresses[i]
+			if !matchesRoute(ingress, rg.Host) || ingress.Namespace != rg.Namespace {
+				continue
+			}
+			// The host gets a TLS listener if a TLS block of any contributing ingress covers it
+			if len(common.IngressTLSForHost(ingress.Spec.TLS, rg.Host)) > 0 {
+				hasTLS = true
+			}
+			if ingress.Annotations[usePortInRedirectsAnnotation] == "true" {
+				usePortInRedirects = true
+			}
+			switch ingressSettings[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] {
+			case sslRedirectForced:
+				forced = append(forced, ingress.Name)
+			case sslRedirectEnabled:
+				enabled = append(enabled, ingress.Name)
+			case sslRedirectDisabled:
+				disabled = append(disabled, ingress.Name)
+			}
+		}
+
+		// Plain ssl-redirect only applies when the host has a TLS listener,
+		// force-ssl-redirect redirects regardless of TLS
+		if len(enabled) > 0 && !hasTLS {
+			if len(forced) == 0 {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("HTTPRoute %s/%s has ssl-redirect enabled but no TLS is configured for host %q; no redirect will be generated. Use force-ssl-redirect to redirect without TLS.",
+						routeKey.Namespace, routeKey.Name, rg.Host),
+					&httpRouteContext.HTTPRoute)
+			}
+			enabled = nil
+		}
+
+		hasSSLRedirect := len(forced) > 0 || (len(enabled) > 0 && len(disabled) == 0)
+
+		if len(disabled) > 0 && len(forced)+len(enabled) > 0 {
+			decision := "force-ssl-redirect takes precedence, redirect enabled"
+			if !hasSSLRedirect {
+				decision = "ssl-redirect=false takes precedence, redirect disabled"
 			}
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Ingresses sharing HTTPRoute %s/%s disagree on ssl-redirect (enabled: %s, disabled: %s); %s",
+					routeKey.Namespace, routeKey.Name,
+					strings.Join(append(forced, enabled...), ", "), strings.Join(disabled, ", "), decision),
+				&httpRouteContext.HTTPRoute)
 		}
 
 		if !hasSSLRedirect {
@@ -86,6 +199,7 @@ func sslRedirectFeature(ingresses []networkingv1.Ingress, _ map[types.Namespaced
 			httpRouteContext.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
 		}
 		httpRouteContext.ProviderSpecificIR.IngressNginx.SSLRedirect = true
+		httpRouteContext.ProviderSpecificIR.IngressNginx.UsePortInRedirects = usePortInRedirects
 
 		ir.HTTPRoutes[routeKey] = httpRouteContext
 		
@@ -100,23 +214,44 @@ func sslRedirectFeature(ingresses []networkingv1.Ingress, _ map[types.Namespaced
 }
 
 // buildSSLRedirectFilter creates a RequestRedirect filter for HTTP to HTTPS redirect
-// This can be used when creating separate HTTP redirect routes
-func buildSSLRedirectFilter() gatewayv1.HTTPRouteFilter {
-	statusCode := 301
+// This can be used when creating separate HTTP redirect routes.
+// If port is set, it is set explicitly on the redirect, as with the
+// use-port-in-redirects annotation.
+func buildSSLRedirectFilter(statusCode int, port *gatewayv1.PortNumber) gatewayv1.HTTPRouteFilter {
 	scheme := "https"
 
-	return gatewayv1.HTTPRouteFilter{
+	filter := gatewayv1.HTTPRouteFilter{
 		Type: gatewayv1.HTTPRouteFilterRequestRedirect,
 		RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
 			Scheme:     &scheme,
 			StatusCode: &statusCode,
 		},
 	}
+	filter.RequestRedirect.Port = port
+	return filter
+}
+
+// httpsListenerPort returns the port of the HTTPS listener of the Gateway for
+// the hostname, or httpsRedirectPort when the Gateway has none
+func httpsListenerPort(gatewayResources *i2gw.GatewayResources, gatewayKey types.NamespacedName, hostname gatewayv1.Hostname) gatewayv1.PortNumber {
+	gateway, ok := gatewayResources.Gateways[gatewayKey]
+	if !ok {
+		return httpsRedirectPort
+	}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Protocol == gatewayv1.HTTPSProtocolType && (listener.Hostname == nil || *listener.Hostname == hostname) {
+			return listener.Port
+		}
+	}
+	return httpsRedirectPort
 }
 
 // buildSSLRedirectRoutes creates HTTPRoutes for HTTP→HTTPS redirect
 // These routes attach to the HTTP listener and redirect to HTTPS
 func buildSSLRedirectRoutes(ir intermediate.IR, gatewayResources *i2gw.GatewayResources, gwConfig GatewayConfig) {
+	statusCode := cmp.Or(gwConfig.SSLRedirectStatus, defaultSSLRedirectStatus)
+	redirects := 0
+
 	// Find HTTPRoutes that need SSL redirect
 	for routeKey, routeCtx := range ir.HTTPRoutes {
 		// Check if SSL redirect is enabled via the IR flag
@@ -127,72 +262,94 @@ func buildSSLRedirectRoutes(ir intermediate.IR, gatewayResources *i2gw.GatewayRe
 		route := routeCtx.HTTPRoute
 		
 		// Get the gateway reference
-		gwNamespace, gwName := gwConfig.GetGatewayRef(routeKey.Namespace)
+		gwNamespace, gwName := gwConfig.GetRouteGatewayRef(routeKey, routeCtx)
 		
-		// Find HTTP listener name for this route's host
+		// Routes without hostnames have no HTTP listener of their own
+		if len(route.Spec.Hostnames) == 0 {
+			continue
+		}
+
+		// A single redirect HTTPRoute covers all the hostnames of the route,
+		// including its server aliases, attached to the HTTP listener of each
+		redirectRouteKey := types.NamespacedName{
+			Namespace: routeKey.Namespace,
+			Name:      routeKey.Name + "-redirect",
+		}
+		if _, exists := gatewayResources.HTTPRoutes[redirectRouteKey]; exists {
+			continue
+		}
+
+		gwNs := gatewayv1.Namespace(gwNamespace)
+		var parentRefs []gatewayv1.ParentReference
+		var hosts []string
 		for _, hostname := range route.Spec.Hostnames {
-			httpListenerName := buildHTTPListenerName(string(hostname))
-			
-			// Create redirect HTTPRoute
-			redirectRouteKey := types.NamespacedName{
-				Namespace: routeKey.Namespace,
-				Name:      routeKey.Name + "-redirect",
-			}
-			
-			// Skip if redirect route already exists
-			if _, exists := gatewayResources.HTTPRoutes[redirectRouteKey]; exists {
-				continue
+			sectionName := gatewayv1.SectionName(buildHTTPListenerName(string(hostname)))
+			parentRefs = append(parentRefs, gatewayv1.ParentReference{
+				Name:        gatewayv1.ObjectName(gwName),
+				Namespace:   &gwNs,
+				SectionName: &sectionName, // Attach to HTTP listener only
+			})
+			hosts = append(hosts, string(hostname))
+		}
+
+		// use-port-in-redirects points the redirect to the HTTPS listener port
+		var port *gatewayv1.PortNumber
+		if routeCtx.ProviderSpecificIR.IngressNginx.UsePortInRedirects {
+			listenerPort := httpsListenerPort(gatewayResources, types.NamespacedName{Namespace: gwNamespace, Name: gwName}, route.Spec.Hostnames[0])
+			port = &listenerPort
+			if listenerPort == httpsRedirectPort {
+				notify(notifications.WarningNotification,
+					fmt.Sprintf("%s has no effect on HTTPRoute %s/%s: the HTTPS listener uses port %d, which Gateway API implementations omit from the Location header",
+						usePortInRedirectsAnnotation, routeKey.Namespace, routeKey.Name, listenerPort),
+					&routeCtx.HTTPRoute)
 			}
-			
-			gwNs := gatewayv1.Namespace(gwNamespace)
-			sectionName := gatewayv1.SectionName(httpListenerName)
-			
-			redirectRoute := gatewayv1.HTTPRoute{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "gateway.networking.k8s.io/v1",
-					Kind:       "HTTPRoute",
+		}
+
+		redirectRoute := gatewayv1.HTTPRoute{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "gateway.networking.k8s.io/v1",
+				Kind:       "HTTPRoute",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      redirectRouteKey.Name,
+				Namespace: redirectRouteKey.Namespace,
+				Labels:    gwConfig.resourceLabels(),
+				Annotations: map[string]string{
+					"ingress2gateway.kubernetes.io/source":      "nginx.ingress.kubernetes.io/ssl-redirect",
+					"ingress2gateway.kubernetes.io/description": "HTTP to HTTPS redirect route",
 				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      redirectRouteKey.Name,
-					Namespace: redirectRouteKey.Namespace,
-					Labels: map[string]string{
-						"app.kubernetes.io/managed-by": "ingress2gateway",
-						"gateway-api-migration":        "true",
-					},
-					Annotations: map[string]string{
-						"ingress2gateway.kubernetes.io/source":      "nginx.ingress.kubernetes.io/ssl-redirect",
-						"ingress2gateway.kubernetes.io/description": "HTTP to HTTPS redirect route",
-					},
+			},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: parentRefs,
 				},
-				Spec: gatewayv1.HTTPRouteSpec{
-					CommonRouteSpec: gatewayv1.CommonRouteSpec{
-						ParentRefs: []gatewayv1.ParentReference{
-							{
-								Name:        gatewayv1.ObjectName(gwName),
-								Namespace:   &gwNs,
-								SectionName: &sectionName, // Attach to HTTP listener only
-							},
-						},
-					},
-					Hostnames: []gatewayv1.Hostname{hostname},
-					Rules: []gatewayv1.HTTPRouteRule{
-						{
-							Filters: []gatewayv1.HTTPRouteFilter{
-								buildSSLRedirectFilter(),
-							},
+				Hostnames: slices.Clone(route.Spec.Hostnames),
+				Rules: []gatewayv1.HTTPRouteRule{
+					{
+						Filters: []gatewayv1.HTTPRouteFilter{
+							buildSSLRedirectFilter(statusCode, port),
 						},
 					},
 				},
-			}
-			
-			gatewayResources.HTTPRoutes[redirectRouteKey] = redirectRoute
-			
-			notify(notifications.InfoNotification,
-				fmt.Sprintf("Generated SSL redirect HTTPRoute %s/%s for HTTP→HTTPS redirect on host %s",
-					redirectRouteKey.Namespace, redirectRouteKey.Name, hostname),
-				&redirectRoute,
-			)
+			},
 		}
+
+		gatewayResources.HTTPRoutes[redirectRouteKey] = redirectRoute
+		redirects++
+
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("Generated SSL redirect HTTPRoute %s/%s for HTTP→HTTPS redirect on hosts %s",
+				redirectRouteKey.Namespace, redirectRouteKey.Name, strings.Join(hosts, ", ")),
+			&redirectRoute,
+		)
+	}
+
+	if redirects > 0 && statusCode != 301 && statusCode != 302 {
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("SSL redirect HTTPRoutes use status code %d, but Gateway API v1 RequestRedirect filters only support 301 and 302. "+
+				"Make sure the installed Gateway API CRDs and implementation accept it.", statusCode),
+			nil,
+		)
 	}
 }
 
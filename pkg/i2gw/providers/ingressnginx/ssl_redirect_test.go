@@ -0,0 +1,437 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func sslRedirectTestIngress(name, path string, annotations map[string]string, withTLS bool) networkingv1.Ingress {
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: strPtr("nginx"),
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     path,
+									PathType: pathTypePtr(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: name + "-service",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if withTLS {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{Hosts: []string{"example.com"}, SecretName: "example-com-tls"},
+		}
+	}
+	return ingress
+}
+
+func TestSSLRedirectFeature_SharedHost(t *testing.T) {
+	testCases := []struct {
+		name              string
+		firstAnnotations  map[string]string
+		secondAnnotations map[string]string
+		expectRedirect    bool
+	}{
+		{
+			name:              "both ingresses enable ssl-redirect",
+			firstAnnotations:  map[string]string{sslRedirectAnnotation: "true"},
+			secondAnnotations: map[string]string{sslRedirectAnnotation: "true"},
+			expectRedirect:    true,
+		},
+		{
+			name:              "ssl-redirect true and false disagree, false wins",
+			firstAnnotations:  map[string]string{sslRedirectAnnotation: "true"},
+			secondAnnotations: map[string]string{sslRedirectAnnotation: "false"},
+			expectRedirect:    false,
+		},
+		{
+			name:              "force-ssl-redirect wins over ssl-redirect false",
+			firstAnnotations:  map[string]string{forceSSLRedirectAnnotation: "true"},
+			secondAnnotations: map[string]string{sslRedirectAnnotation: "false"},
+			expectRedirect:    true,
+		},
+		{
+			name:              "unset does not disable redirect",
+			firstAnnotations:  map[string]string{sslRedirectAnnotation: "true"},
+			secondAnnotations: map[string]string{},
+			expectRedirect:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{
+				sslRedirectTestIngress("first", "/first", tc.firstAnnotations, true),
+				sslRedirectTestIngress("second", "/second", tc.secondAnnotations, true),
+			}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("first", "example.com")}
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found, got %v", routeKey, ir.HTTPRoutes)
+			}
+
+			nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+			hasRedirect := nginxIR != nil && nginxIR.SSLRedirect
+			if hasRedirect != tc.expectRedirect {
+				t.Errorf("expected ssl redirect: %v, got: %v", tc.expectRedirect, hasRedirect)
+			}
+		})
+	}
+}
+
+func TestSSLRedirectFeature_TLSPresence(t *testing.T) {
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		withTLS        bool
+		expectRedirect bool
+	}{
+		{
+			name:           "ssl-redirect with TLS redirects",
+			annotations:    map[string]string{sslRedirectAnnotation: "true"},
+			withTLS:        true,
+			expectRedirect: true,
+		},
+		{
+			name:           "ssl-redirect without TLS does not redirect",
+			annotations:    map[string]string{sslRedirectAnnotation: "true"},
+			expectRedirect: false,
+		},
+		{
+			name:           "force-ssl-redirect without TLS redirects",
+			annotations:    map[string]string{forceSSLRedirectAnnotation: "true"},
+			expectRedirect: true,
+		},
+		{
+			name:           "force-ssl-redirect with TLS redirects",
+			annotations:    map[string]string{forceSSLRedirectAnnotation: "true"},
+			withTLS:        true,
+			expectRedirect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{
+				sslRedirectTestIngress("test-ingress", "/", tc.annotations, tc.withTLS),
+			}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found", routeKey)
+			}
+
+			nginxIR := routeCtx.ProviderSpecificIR.IngressNginx
+			hasRedirect := nginxIR != nil && nginxIR.SSLRedirect
+			if hasRedirect != tc.expectRedirect {
+				t.Errorf("expected ssl redirect: %v, got: %v", tc.expectRedirect, hasRedirect)
+			}
+		})
+	}
+}
+
+func TestBuildSSLRedirectRoutes_UsePortInRedirects(t *testing.T) {
+	testCases := []struct {
+		name            string
+		annotations     map[string]string
+		listenerPort    gatewayv1.PortNumber
+		expectedPort    *gatewayv1.PortNumber
+		expectedWarning bool
+	}{
+		{
+			name:        "port omitted by default",
+			annotations: map[string]string{sslRedirectAnnotation: "true"},
+		},
+		{
+			name:        "use-port-in-redirects=false omits the port",
+			annotations: map[string]string{sslRedirectAnnotation: "true", usePortInRedirectsAnnotation: "false"},
+		},
+		{
+			name:            "use-port-in-redirects=true with the default HTTPS port",
+			annotations:     map[string]string{sslRedirectAnnotation: "true", usePortInRedirectsAnnotation: "true"},
+			expectedPort:    ptrTo(gatewayv1.PortNumber(443)),
+			expectedWarning: true,
+		},
+		{
+			name:         "use-port-in-redirects=true sets the HTTPS listener port",
+			annotations:  map[string]string{sslRedirectAnnotation: "true", usePortInRedirectsAnnotation: "true"},
+			listenerPort: 8443,
+			expectedPort: ptrTo(gatewayv1.PortNumber(8443)),
+		},
+		{
+			name:         "use-port-in-redirects=false with a non default HTTPS port",
+			annotations:  map[string]string{sslRedirectAnnotation: "true", usePortInRedirectsAnnotation: "false"},
+			listenerPort: 8443,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{
+				sslRedirectTestIngress("test-ingress", "/", tc.annotations, true),
+			}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+			gatewayResources := i2gw.GatewayResources{
+				Gateways:   map[types.NamespacedName]gatewayv1.Gateway{},
+				HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{},
+			}
+			if tc.listenerPort != 0 {
+				gwNamespace, gwName := GatewayConfig{}.GetRouteGatewayRef(routeKey, ir.HTTPRoutes[routeKey])
+				hostname := gatewayv1.Hostname("example.com")
+				gatewayResources.Gateways[types.NamespacedName{Namespace: gwNamespace, Name: gwName}] = gatewayv1.Gateway{
+					Spec: gatewayv1.GatewaySpec{
+						Listeners: []gatewayv1.Listener{
+							{Name: "example-com-http", Hostname: &hostname, Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+							{Name: "example-com-https", Hostname: &hostname, Port: tc.listenerPort, Protocol: gatewayv1.HTTPSProtocolType},
+						},
+					},
+				}
+			}
+			buildSSLRedirectRoutes(ir, &gatewayResources, GatewayConfig{})
+
+			redirectKey := types.NamespacedName{Namespace: "default", Name: routeKey.Name + "-redirect"}
+			redirectRoute, ok := gatewayResources.HTTPRoutes[redirectKey]
+			if !ok {
+				t.Fatalf("redirect HTTPRoute %s not found", redirectKey)
+			}
+
+			redirect := redirectRoute.Spec.Rules[0].Filters[0].RequestRedirect
+			if !reflect.DeepEqual(redirect.Port, tc.expectedPort) {
+				t.Errorf("expected redirect port %v, got %v", tc.expectedPort, redirect.Port)
+			}
+
+			var hasWarning bool
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.WarningNotification && strings.HasPrefix(n.Message, usePortInRedirectsAnnotation+" has no effect") {
+					hasWarning = true
+				}
+			}
+			if hasWarning != tc.expectedWarning {
+				t.Errorf("expected use-port-in-redirects Warning: %v, got: %v", tc.expectedWarning, hasWarning)
+			}
+		})
+	}
+}
+
+func TestSSLRedirectFeature_UsePortInRedirectsWithoutRedirect(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingresses := []networkingv1.Ingress{
+		sslRedirectTestIngress("test-ingress", "/", map[string]string{usePortInRedirectsAnnotation: "true"}, true),
+	}
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var warnings []string
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			warnings = append(warnings, n.Message)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "app-root") {
+		t.Errorf("expected a Warning that use-port-in-redirects has no effect, got %q", warnings)
+	}
+}
+
+func TestBuildSSLRedirectRoutes_ServerAlias(t *testing.T) {
+	ingress := sslRedirectTestIngress("test-ingress", "/", map[string]string{
+		sslRedirectAnnotation: "true",
+		serverAliasAnnotation: "www.example.com",
+	}, true)
+	ingress.Spec.TLS[0].Hosts = []string{"example.com", "www.example.com"}
+	ingresses := []networkingv1.Ingress{ingress}
+
+	ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	if errs := serverAliasFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	gatewayResources := i2gw.GatewayResources{HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{}}
+	buildSSLRedirectRoutes(ir, &gatewayResources, GatewayConfig{Mode: "per-namespace"})
+
+	if len(gatewayResources.HTTPRoutes) != 1 {
+		t.Fatalf("expected a single redirect HTTPRoute, got %d", len(gatewayResources.HTTPRoutes))
+	}
+	redirectKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com") + "-redirect"}
+	redirectRoute, ok := gatewayResources.HTTPRoutes[redirectKey]
+	if !ok {
+		t.Fatalf("redirect HTTPRoute %s not found", redirectKey)
+	}
+	if diff := cmp.Diff([]gatewayv1.Hostname{"example.com", "www.example.com"}, redirectRoute.Spec.Hostnames); diff != "" {
+		t.Errorf("unexpected redirect hostnames (-want +got):\n%s", diff)
+	}
+	var sectionNames []gatewayv1.SectionName
+	for _, parentRef := range redirectRoute.Spec.ParentRefs {
+		sectionNames = append(sectionNames, *parentRef.SectionName)
+	}
+	if diff := cmp.Diff([]gatewayv1.SectionName{"example-com-http", "www-example-com-http"}, sectionNames); diff != "" {
+		t.Errorf("unexpected redirect listeners (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildSSLRedirectRoutes_Status(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         int
+		expectedStatus int
+		expectWarning  bool
+	}{
+		{
+			name:           "301 by default",
+			expectedStatus: 301,
+		},
+		{
+			name:           "308 override",
+			status:         308,
+			expectedStatus: 308,
+			expectWarning:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingresses := []networkingv1.Ingress{
+				sslRedirectTestIngress("test-ingress", "/", map[string]string{sslRedirectAnnotation: "true"}, true),
+			}
+
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			if errs := sslRedirectFeature(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			gatewayResources := i2gw.GatewayResources{HTTPRoutes: map[types.NamespacedName]gatewayv1.HTTPRoute{}}
+			buildSSLRedirectRoutes(ir, &gatewayResources, GatewayConfig{SSLRedirectStatus: tc.status})
+
+			redirectKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com") + "-redirect"}
+			redirectRoute, ok := gatewayResources.HTTPRoutes[redirectKey]
+			if !ok {
+				t.Fatalf("redirect HTTPRoute %s not found", redirectKey)
+			}
+
+			redirect := redirectRoute.Spec.Rules[0].Filters[0].RequestRedirect
+			if redirect.StatusCode == nil || *redirect.StatusCode != tc.expectedStatus {
+				t.Errorf("expected redirect status %d, got %v", tc.expectedStatus, redirect.StatusCode)
+			}
+
+			var hasWarning bool
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.WarningNotification && strings.Contains(n.Message, "status code") {
+					hasWarning = true
+				}
+			}
+			if hasWarning != tc.expectWarning {
+				t.Errorf("expected status code Warning: %v, got: %v", tc.expectWarning, hasWarning)
+			}
+		})
+	}
+}
+
+func TestGatewayConfig_ValidateSSLRedirectStatus(t *testing.T) {
+	for _, status := range []int{301, 302, 307, 308} {
+		config := GatewayConfig{RateLimitBurstMultiplier: 1, SSLRedirectStatus: status}
+		if errs := config.Validate(); len(errs) > 0 {
+			t.Errorf("expected status %d to be valid, got: %v", status, errs)
+		}
+	}
+	for _, status := range []int{0, 200, 303, 404} {
+		config := GatewayConfig{RateLimitBurstMultiplier: 1, SSLRedirectStatus: status}
+		if errs := config.Validate(); len(errs) != 1 {
+			t.Errorf("expected status %d to be rejected, got: %v", status, errs)
+		}
+	}
+}
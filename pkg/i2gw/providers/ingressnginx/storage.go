@@ -19,8 +19,10 @@ package ingressnginx
 import (
 	"sort"
 
+	apiv1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type OrderedIngressMap struct {
@@ -30,6 +32,10 @@ type OrderedIngressMap struct {
 type storage struct {
 	Ingresses    OrderedIngressMap
 	ServicePorts map[types.NamespacedName]map[string]int32
+	ConfigMaps   map[types.NamespacedName]*apiv1.ConfigMap
+	// Secrets are the names of the Secrets in the input, nil if they could
+	// not be listed
+	Secrets sets.Set[types.NamespacedName]
 }
 
 func newResourcesStorage() *storage {
@@ -39,6 +45,7 @@ func newResourcesStorage() *storage {
 			ingressObjects: map[types.NamespacedName]*networkingv1.Ingress{},
 		},
 		ServicePorts: map[types.NamespacedName]map[string]int32{},
+		ConfigMaps:   map[types.NamespacedName]*apiv1.ConfigMap{},
 	}
 }
 
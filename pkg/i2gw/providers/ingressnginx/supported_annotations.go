@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"maps"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+)
+
+const (
+	configurationSnippetAnnotation = "nginx.ingress.kubernetes.io/configuration-snippet"
+	rewriteTargetAnnotation        = "nginx.ingress.kubernetes.io/rewrite-target"
+	appRootAnnotation              = "nginx.ingress.kubernetes.io/app-root"
+)
+
+func init() {
+	i2gw.RegisterSupportedAnnotations(Name, SupportedAnnotations)
+}
+
+// supportedAnnotations is the migration status of the annotations handled by
+// the provider, see SupportedAnnotations
+var supportedAnnotations = map[string]i2gw.AnnotationSupport{
+	// Routing
+	canaryAnnotation:                {Level: i2gw.FullSupport, Resource: "HTTPRoute (weighted backendRefs)"},
+	canaryWeightAnnotation:          {Level: i2gw.FullSupport, Resource: "HTTPRoute (weighted backendRefs)"},
+	canaryWeightTotalAnnotation:     {Level: i2gw.FullSupport, Resource: "HTTPRoute (weighted backendRefs)"},
+	canaryByHeaderAnnotation:        {Level: i2gw.FullSupport, Resource: "HTTPRoute (header matches)"},
+	canaryByHeaderValueAnnotation:   {Level: i2gw.FullSupport, Resource: "HTTPRoute (header matches)"},
+	canaryByHeaderPatternAnnotation: {Level: i2gw.FullSupport, Resource: "HTTPRoute (header matches)"},
+	canaryByCookieAnnotation:        {Level: i2gw.FullSupport, Resource: "HTTPRoute (Cookie header matches)"},
+	serverAliasAnnotation:           {Level: i2gw.FullSupport, Resource: "HTTPRoute (hostnames)"},
+	sslRedirectAnnotation:           {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestRedirect filter)"},
+	forceSSLRedirectAnnotation:      {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestRedirect filter)"},
+	usePortInRedirectsAnnotation:    {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestRedirect filter)"},
+	useRegexAnnotation:              {Level: i2gw.PartialSupport, Resource: "HTTPRoute (RegularExpression path matches, experimental channel)"},
+	rewriteTargetAnnotation:         {Level: i2gw.PartialSupport, Resource: "HTTPRoute (URLRewrite filter)"},
+	gatewayModeAnnotation:           {Level: i2gw.FullSupport, Resource: "HTTPRoute (parentRefs)"},
+
+	// Headers and mirroring
+	proxyHideHeadersAnnotation:      {Level: i2gw.FullSupport, Resource: "HTTPRoute (ResponseHeaderModifier filter)"},
+	connectionProxyHeaderAnnotation: {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestHeaderModifier filter)"},
+	proxySetHeadersAnnotation:       {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestHeaderModifier filter)"},
+	customHeadersAnnotation:         {Level: i2gw.FullSupport, Resource: "HTTPRoute (ResponseHeaderModifier filter)"},
+	upstreamVhostAnnotation:         {Level: i2gw.PartialSupport, Resource: "HTTPRoute (RequestHeaderModifier filter)"},
+	mirrorTargetAnnotation:          {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestMirror filter)"},
+	mirrorPercentageAnnotation:      {Level: i2gw.FullSupport, Resource: "HTTPRoute (RequestMirror filter)"},
+	mirrorRequestBodyAnnotation:     {Level: i2gw.PartialSupport, Resource: "HTTPRoute (RequestMirror filter)"},
+	mirrorHostAnnotation:            {Level: i2gw.NoSupport},
+
+	// Retries
+	proxyNextUpstreamAnnotation:        {Level: i2gw.PartialSupport, Resource: "HTTPRoute (retry, experimental)"},
+	proxyNextUpstreamTriesAnnotation:   {Level: i2gw.PartialSupport, Resource: "HTTPRoute (retry, experimental)"},
+	proxyNextUpstreamTimeoutAnnotation: {Level: i2gw.PartialSupport, Resource: "HTTPRoute (retry timeouts)"},
+
+	// Timeouts and proxy settings
+	proxyConnectTimeoutAnnotation:   {Level: i2gw.PartialSupport, Resource: "HTTPRoute (timeouts), DestinationRule (connectTimeout) or BackendTrafficPolicy (timeout)"},
+	proxyReadTimeoutAnnotation:      {Level: i2gw.PartialSupport, Resource: "HTTPRoute (timeouts)"},
+	proxySendTimeoutAnnotation:      {Level: i2gw.PartialSupport, Resource: "HTTPRoute (timeouts)"},
+	proxyBodySizeAnnotation:         {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (buffer) or BackendTrafficPolicy (requestBuffer)"},
+	clientBodyBufferSizeAnnotation:  {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (per_connection_buffer_limit_bytes)"},
+	proxyBufferingAnnotation:        {Level: i2gw.NoSupport},
+	proxyRequestBufferingAnnotation: {Level: i2gw.NoSupport},
+	proxyBufferSizeAnnotation:       {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (per_connection_buffer_limit_bytes)"},
+	proxyBuffersNumberAnnotation:    {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (per_connection_buffer_limit_bytes)"},
+	proxyBusyBuffersSizeAnnotation:  {Level: i2gw.NoSupport},
+	proxyHTTPVersionAnnotation:      {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (HttpProtocolOptions)"},
+	loadBalanceAnnotation:           {Level: i2gw.PartialSupport, Resource: "DestinationRule (loadBalancer)"},
+
+	// Backends
+	backendProtocolAnnotation:    {Level: i2gw.FullSupport, Resource: "BackendTLSPolicy, DestinationRule (h2UpgradePolicy)"},
+	proxySSLSecretAnnotation:     {Level: i2gw.FullSupport, Resource: "BackendTLSPolicy"},
+	proxySSLVerifyAnnotation:     {Level: i2gw.FullSupport, Resource: "BackendTLSPolicy"},
+	proxySSLNameAnnotation:       {Level: i2gw.FullSupport, Resource: "BackendTLSPolicy"},
+	proxySSLServerNameAnnotation: {Level: i2gw.PartialSupport, Resource: "BackendTLSPolicy"},
+	proxySSLProtocolsAnnotation:  {Level: i2gw.PartialSupport, Resource: "BackendTLSPolicy"},
+	proxySSLCiphersAnnotation:    {Level: i2gw.PartialSupport, Resource: "BackendTLSPolicy"},
+
+	// Session affinity
+	affinityAnnotation:                     {Level: i2gw.PartialSupport, Resource: "DestinationRule (consistentHash)"},
+	affinityCanaryBehaviorAnnotation:       {Level: i2gw.PartialSupport, Resource: "DestinationRule (consistentHash)"},
+	affinityModeAnnotation:                 {Level: i2gw.PartialSupport, Resource: "DestinationRule (consistentHash) or HTTPRoute (sessionPersistence, experimental)"},
+	upstreamHashByAnnotation:               {Level: i2gw.PartialSupport, Resource: "DestinationRule (consistentHash)"},
+	sessionCookieNameAnnotation:            {Level: i2gw.FullSupport, Resource: "DestinationRule (consistentHash)"},
+	sessionCookiePathAnnotation:            {Level: i2gw.FullSupport, Resource: "DestinationRule (consistentHash)"},
+	sessionCookieMaxAgeAnnotation:          {Level: i2gw.FullSupport, Resource: "DestinationRule (consistentHash)"},
+	sessionCookieExpiresAnnotation:         {Level: i2gw.FullSupport, Resource: "DestinationRule (consistentHash)"},
+	sessionCookieChangeOnFailureAnnotation: {Level: i2gw.PartialSupport, Resource: "DestinationRule (outlierDetection)"},
+	sessionCookieHashAnnotation:            {Level: i2gw.PartialSupport, Resource: "DestinationRule (consistentHash)"},
+
+	// Rate limiting and access control
+	limitRPSAnnotation:             {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (local_ratelimit) or BackendTrafficPolicy (rateLimit)"},
+	limitRPMAnnotation:             {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (local_ratelimit) or BackendTrafficPolicy (rateLimit)"},
+	limitBurstAnnotation:           {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (local_ratelimit)"},
+	limitReqZoneAnnotation:         {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (local_ratelimit)"},
+	limitConnectionsAnnotation:     {Level: i2gw.NoSupport},
+	limitWhitelistAnnotation:       {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (local_ratelimit matcher)"},
+	whitelistSourceRangeAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (rbac) or AuthorizationPolicy"},
+	allowlistSourceRangeAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (rbac) or AuthorizationPolicy"},
+
+	// Authentication
+	authURLAnnotation:                   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authMethodAnnotation:                {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authResponseHeadersAnnotation:       {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authFailOpenAnnotation:              {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authJWTIssuerAnnotation:             {Level: i2gw.PartialSupport, Resource: "RequestAuthentication, AuthorizationPolicy"},
+	authJWKSURIAnnotation:               {Level: i2gw.PartialSupport, Resource: "RequestAuthentication"},
+	authSigninAnnotation:                {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authSigninRedirectParamAnnotation:   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authRequestRedirectAnnotation:       {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (ext_authz)"},
+	authCacheKeyAnnotation:              {Level: i2gw.NoSupport},
+	authCacheDurationAnnotation:         {Level: i2gw.NoSupport},
+	authSnippetAnnotation:               {Level: i2gw.NoSupport},
+	authTLSSecretAnnotation:             {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (DownstreamTlsContext)"},
+	authTLSVerifyClientAnnotation:       {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (DownstreamTlsContext)"},
+	authTLSVerifyDepthAnnotation:        {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (DownstreamTlsContext)"},
+	authTLSErrorPageAnnotation:          {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (lua)"},
+	authTLSPassCertToUpstreamAnnotation: {Level: i2gw.NoSupport},
+
+	// Observability
+	enableOpentracingAnnotation:   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (tracing)"},
+	enableOpentelemetryAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (tracing)"},
+	logFormatUpstreamAnnotation:   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (access_log)"},
+
+	// CORS
+	enableCORSAnnotation:           {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsAllowOriginAnnotation:      {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsAllowMethodsAnnotation:     {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsAllowHeadersAnnotation:     {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsExposeHeadersAnnotation:    {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsAllowCredentialsAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+	corsMaxAgeAnnotation:           {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (cors)"},
+
+	// Custom errors
+	customHTTPErrorsAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (lua)"},
+	defaultBackendAnnotation:   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (lua)"},
+
+	// Location rewrites
+	proxyRedirectFromAnnotation: {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (lua)"},
+	proxyRedirectToAnnotation:   {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (lua)"},
+
+	// Snippets
+	serverSnippetAnnotation:        {Level: i2gw.PartialSupport, Resource: "EnvoyFilter (max_request_headers_kb)"},
+	configurationSnippetAnnotation: {Level: i2gw.NoSupport},
+}
+
+// SupportedAnnotations returns the migration status of the annotations the
+// provider handles: whether each is fully, partially or not converted, and
+// the resource it produces. Partially supported annotations are converted for
+// some values only, or with differences reported as notifications. Annotations
+// only converted to EnvoyFilters or other Istio policies are partially
+// supported, since these are not generated for Envoy Gateway classes.
+func SupportedAnnotations() map[string]i2gw.AnnotationSupport {
+	return maps.Clone(supportedAnnotations)
+}
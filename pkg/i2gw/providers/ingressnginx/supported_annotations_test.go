@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+)
+
+func TestSupportedAnnotations(t *testing.T) {
+	testCases := []struct {
+		annotation       string
+		expectedLevel    i2gw.SupportLevel
+		expectedResource string
+	}{
+		{
+			annotation:       "nginx.ingress.kubernetes.io/limit-rps",
+			expectedLevel:    i2gw.PartialSupport,
+			expectedResource: "EnvoyFilter (local_ratelimit) or BackendTrafficPolicy (rateLimit)",
+		},
+		{
+			annotation:       "nginx.ingress.kubernetes.io/enable-cors",
+			expectedLevel:    i2gw.PartialSupport,
+			expectedResource: "EnvoyFilter (cors)",
+		},
+		{
+			annotation:       "nginx.ingress.kubernetes.io/ssl-redirect",
+			expectedLevel:    i2gw.FullSupport,
+			expectedResource: "HTTPRoute (RequestRedirect filter)",
+		},
+		{
+			annotation:       "nginx.ingress.kubernetes.io/rewrite-target",
+			expectedLevel:    i2gw.PartialSupport,
+			expectedResource: "HTTPRoute (URLRewrite filter)",
+		},
+		{
+			annotation:    "nginx.ingress.kubernetes.io/configuration-snippet",
+			expectedLevel: i2gw.NoSupport,
+		},
+		{
+			annotation:       "nginx.ingress.kubernetes.io/server-snippet",
+			expectedLevel:    i2gw.PartialSupport,
+			expectedResource: "EnvoyFilter (max_request_headers_kb)",
+		},
+	}
+
+	annotations, ok := i2gw.GetSupportedAnnotations(Name)
+	if !ok {
+		t.Fatalf("expected the supported annotations of %s to be registered", Name)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.annotation, func(t *testing.T) {
+			support, ok := annotations[tc.annotation]
+			if !ok {
+				t.Fatalf("expected %s to be listed", tc.annotation)
+			}
+			if support.Level != tc.expectedLevel {
+				t.Errorf("expected level %q, got %q", tc.expectedLevel, support.Level)
+			}
+			if support.Resource != tc.expectedResource {
+				t.Errorf("expected resource %q, got %q", tc.expectedResource, support.Resource)
+			}
+		})
+	}
+
+	// Every listed annotation must have a resource unless it is not supported
+	for annotation, support := range SupportedAnnotations() {
+		if (support.Level == i2gw.NoSupport) != (support.Resource == "") {
+			t.Errorf("%s: unexpected resource %q for level %q", annotation, support.Resource, support.Level)
+		}
+	}
+}
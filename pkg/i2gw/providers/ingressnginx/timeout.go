@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
@@ -82,8 +83,18 @@ func parseTimeoutConfig(ingress *networkingv1.Ingress) (*timeoutConfig, error) {
 	return config, nil
 }
 
-// timeoutFeature processes proxy-*-timeout annotations and sets HTTPRoute timeouts
-func timeoutFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+// timeoutFeature processes proxy-*-timeout annotations and sets HTTPRoute timeouts.
+// HTTPRoutes have no connect timeout, so proxy-connect-timeout is approximated
+// by the backend request timeout unless strictTimeoutMapping is set, in which
+// case it is only stored in the IR to generate a connection-level policy.
+func timeoutFeature(strictTimeoutMapping bool) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		return applyTimeouts(ingresses, ir, strictTimeoutMapping)
+	}
+}
+
+// applyTimeouts sets the timeouts of the Ingresses on their HTTPRoutes
+func applyTimeouts(ingresses []networkingv1.Ingress, ir *intermediate.IR, strictTimeoutMapping bool) field.ErrorList {
 	var errList field.ErrorList
 
 	// Build a map of ingress to timeout config
@@ -154,7 +165,7 @@ func timeoutFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName
 			}
 
 			// Set backend request timeout (connect timeout maps more closely to this)
-			if timeoutCfg.connectTimeout > 0 {
+			if timeoutCfg.connectTimeout > 0 && !strictTimeoutMapping {
 				if rule.Timeouts == nil {
 					rule.Timeouts = &gatewayv1.HTTPRouteTimeouts{}
 				}
@@ -163,6 +174,13 @@ func timeoutFeature(ingresses []networkingv1.Ingress, _ map[types.NamespacedName
 			}
 		}
 
+		if timeoutCfg.connectTimeout > 0 {
+			if httpRouteContext.ProviderSpecificIR.IngressNginx == nil {
+				httpRouteContext.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{}
+			}
+			httpRouteContext.ProviderSpecificIR.IngressNginx.ConnectTimeoutSeconds = timeoutCfg.connectTimeout
+		}
+
 		// Update the route in IR
 		ir.HTTPRoutes[routeKey] = httpRouteContext
 
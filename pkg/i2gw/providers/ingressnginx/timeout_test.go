@@ -23,6 +23,8 @@ import (
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestTimeoutFeature(t *testing.T) {
@@ -124,7 +126,7 @@ func TestTimeoutFeature(t *testing.T) {
 			}
 
 			// Apply timeout feature
-			errs = timeoutFeature(tc.ingresses, nil, &ir)
+			errs = timeoutFeature(false)(tc.ingresses, nil, &ir)
 
 			if tc.expectError {
 				if len(errs) == 0 {
@@ -253,3 +255,75 @@ func strPtr(s string) *string {
 func pathTypePtr(pt networkingv1.PathType) *networkingv1.PathType {
 	return &pt
 }
+
+func TestTimeoutFeature_ConnectTimeout(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		strictTimeoutMapping   bool
+		expectedBackendRequest string
+		expectDestinationRule  bool
+	}{
+		{
+			name:                   "connect timeout approximated by backend request timeout",
+			expectedBackendRequest: "5s",
+		},
+		{
+			name:                  "strict mapping generates a DestinationRule",
+			strictTimeoutMapping:  true,
+			expectDestinationRule: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(map[string]string{proxyConnectTimeoutAnnotation: "5"})}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("common.ToIR failed: %v", errs)
+			}
+
+			if errs := timeoutFeature(tc.strictTimeoutMapping)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			routeCtx := ir.HTTPRoutes[types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}]
+			if nginxIR := routeCtx.ProviderSpecificIR.IngressNginx; nginxIR == nil || nginxIR.ConnectTimeoutSeconds != 5 {
+				t.Errorf("expected ConnectTimeoutSeconds 5 in the IR, got %+v", nginxIR)
+			}
+
+			var backendRequest string
+			if timeouts := routeCtx.HTTPRoute.Spec.Rules[0].Timeouts; timeouts != nil && timeouts.BackendRequest != nil {
+				backendRequest = string(*timeouts.BackendRequest)
+			}
+			if backendRequest != tc.expectedBackendRequest {
+				t.Errorf("expected backendRequest timeout %q, got %q", tc.expectedBackendRequest, backendRequest)
+			}
+
+			gatewayResources := i2gw.GatewayResources{}
+			buildDestinationRules(ir, &gatewayResources, GatewayConfig{StrictTimeoutMapping: tc.strictTimeoutMapping})
+			if !tc.expectDestinationRule {
+				if len(gatewayResources.GatewayExtensions) != 0 {
+					t.Errorf("expected no DestinationRule, got %d extensions", len(gatewayResources.GatewayExtensions))
+				}
+				return
+			}
+			if len(gatewayResources.GatewayExtensions) != 1 {
+				t.Fatalf("expected 1 DestinationRule, got %d extensions", len(gatewayResources.GatewayExtensions))
+			}
+
+			destinationRule := gatewayResources.GatewayExtensions[0]
+			if destinationRule.GetKind() != "DestinationRule" || destinationRule.GetNamespace() != "default" || destinationRule.GetName() != "my-service-traffic-policy" {
+				t.Errorf("expected DestinationRule default/my-service-traffic-policy, got %s %s/%s",
+					destinationRule.GetKind(), destinationRule.GetNamespace(), destinationRule.GetName())
+			}
+			host, _, _ := unstructured.NestedString(destinationRule.Object, "spec", "host")
+			if host != "my-service.default.svc.cluster.local" {
+				t.Errorf("expected host my-service.default.svc.cluster.local, got %s", host)
+			}
+			connectTimeout, _, _ := unstructured.NestedString(destinationRule.Object, "spec", "trafficPolicy", "connectionPool", "tcp", "connectTimeout")
+			if connectTimeout != "5s" {
+				t.Errorf("expected connectTimeout 5s, got %s", connectTimeout)
+			}
+		})
+	}
+}
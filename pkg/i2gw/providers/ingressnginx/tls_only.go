@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// addTLSOnlyListeners adds an HTTPS listener for each TLS host of the Ingresses
+// with TLS but no rules nor defaultBackend, which are typically used to
+// provision a certificate. common.ToIR only builds listeners from rules, so
+// these Ingresses would otherwise produce nothing. No HTTPRoute is generated.
+// Ingresses without an ingress class have no Gateway to add the listeners to
+// and are skipped with a Warning.
+func addTLSOnlyListeners(ingresses []networkingv1.Ingress, ir *intermediate.IR) {
+	for i := range ingresses {
+		ing := &ingresses[i]
+		if len(ing.Spec.Rules) > 0 || ing.Spec.DefaultBackend != nil || len(ing.Spec.TLS) == 0 {
+			continue
+		}
+
+		class := common.GetIngressClass(*ing)
+		if class == "" {
+			notify(notifications.WarningNotification,
+				"Ingress has TLS but no rules nor ingress class, no HTTPS listener was generated for its TLS hosts",
+				ing,
+			)
+			continue
+		}
+
+		gwKey := types.NamespacedName{Namespace: ing.Namespace, Name: class}
+		gwCtx, ok := ir.Gateways[gwKey]
+		if !ok {
+			gwCtx = intermediate.GatewayContext{Gateway: gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: gwKey.Namespace, Name: gwKey.Name},
+				Spec:       gatewayv1.GatewaySpec{GatewayClassName: gatewayv1.ObjectName(gwKey.Name)},
+			}}
+			gwCtx.Gateway.SetGroupVersionKind(common.GatewayGVK)
+		}
+		if gwCtx.ProviderSpecificIR.IngressNginx == nil {
+			gwCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxGatewayIR{}
+		}
+		gwCtx.ProviderSpecificIR.IngressNginx.TLSOnlyIngresses = append(gwCtx.ProviderSpecificIR.IngressNginx.TLSOnlyIngresses, ing)
+
+		var hosts []string
+		for _, tls := range ing.Spec.TLS {
+			tlsHosts := tls.Hosts
+			if len(tlsHosts) == 0 {
+				tlsHosts = []string{""}
+			}
+			for _, host := range tlsHosts {
+				addTLSOnlyListener(&gwCtx.Gateway, host, tls.SecretName)
+				if host == "" {
+					host = "all hosts"
+				}
+				hosts = append(hosts, host)
+			}
+		}
+		ir.Gateways[gwKey] = gwCtx
+
+		notify(notifications.InfoNotification,
+			fmt.Sprintf("Ingress has TLS but no rules, only HTTPS listeners were generated for %s, without an HTTPRoute", strings.Join(hosts, ", ")),
+			ing,
+		)
+	}
+}
+
+// addTLSOnlyListener adds the secret to the HTTPS listener of the host, adding
+// the listener if the Gateway has none for the host
+func addTLSOnlyListener(gateway *gatewayv1.Gateway, host, secretName string) {
+	certificateRef := gatewayv1.SecretObjectReference{Name: gatewayv1.ObjectName(secretName)}
+	for i := range gateway.Spec.Listeners {
+		listener := &gateway.Spec.Listeners[i]
+		if listener.Protocol != gatewayv1.HTTPSProtocolType || string(ptr.Deref(listener.Hostname, "")) != host {
+			continue
+		}
+		if listener.TLS == nil {
+			listener.TLS = &gatewayv1.ListenerTLSConfig{}
+		}
+		if !slices.Contains(listener.TLS.CertificateRefs, certificateRef) {
+			listener.TLS.CertificateRefs = append(listener.TLS.CertificateRefs, certificateRef)
+		}
+		return
+	}
+
+	listener := gatewayv1.Listener{
+		Name:     gatewayv1.SectionName(common.NameFromHost(host) + "-https"),
+		Port:     443,
+		Protocol: gatewayv1.HTTPSProtocolType,
+		TLS:      &gatewayv1.ListenerTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{certificateRef}},
+	}
+	if host != "" {
+		listener.Hostname = (*gatewayv1.Hostname)(&host)
+	} else {
+		listener.Name = "https"
+	}
+	gateway.Spec.Listeners = append(gateway.Spec.Listeners, listener)
+}
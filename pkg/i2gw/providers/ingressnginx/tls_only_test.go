@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestToGatewayResources_TLSOnlyIngress(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cert-only",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+				limitRPSAnnotation:                         "10",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ptrTo("nginx"),
+			TLS: []networkingv1.IngressTLS{{
+				Hosts:      []string{"example.com"},
+				SecretName: "example-com-tls",
+			}},
+		},
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+		},
+	}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors building IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if len(gatewayResources.HTTPRoutes) != 0 {
+		t.Errorf("expected no HTTPRoutes, got %d", len(gatewayResources.HTTPRoutes))
+	}
+	gateway, ok := gatewayResources.Gateways[types.NamespacedName{Namespace: "default", Name: "default-gateway"}]
+	if !ok {
+		t.Fatalf("expected Gateway default/default-gateway, got %v", gatewayResources.Gateways)
+	}
+	expectedListeners := []gatewayv1.Listener{{
+		Name:     "example-com-https",
+		Hostname: ptrTo(gatewayv1.Hostname("example.com")),
+		Port:     443,
+		Protocol: gatewayv1.HTTPSProtocolType,
+		TLS: &gatewayv1.ListenerTLSConfig{
+			CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "example-com-tls"}},
+		},
+	}}
+	if diff := cmp.Diff(expectedListeners, gateway.Spec.Listeners); diff != "" {
+		t.Errorf("unexpected listeners (-want +got):\n%s", diff)
+	}
+
+	hasInfo := false
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.InfoNotification && strings.Contains(n.Message, "only HTTPS listeners were generated for example.com") {
+			hasInfo = true
+		}
+	}
+	if !hasInfo {
+		t.Errorf("expected an INFO notification that only a listener was generated")
+	}
+}
+
+func TestToGatewayResources_TLSOnlyIngressGatewayMode(t *testing.T) {
+	testCases := []struct {
+		name             string
+		flags            map[string]string
+		annotations      map[string]string
+		class            string
+		expectedGateways []types.NamespacedName
+		expectedMessage  string
+	}{
+		{
+			name:             "per-Ingress per-namespace override",
+			flags:            map[string]string{GatewayModeFlag: "centralized", GatewayInServiceNamespaceFlag: "true"},
+			annotations:      map[string]string{gatewayModeAnnotation: "per-namespace"},
+			class:            "nginx",
+			expectedGateways: []types.NamespacedName{{Namespace: "default", Name: "default-gateway"}},
+		},
+		{
+			name:            "per-Ingress centralized override",
+			flags:           map[string]string{GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+			annotations:     map[string]string{gatewayModeAnnotation: "centralized"},
+			class:           "nginx",
+			expectedMessage: "the HTTPS listeners of the TLS-only Ingress are not generated in centralized mode",
+		},
+		{
+			name: "original gateway name kept",
+			flags: map[string]string{
+				GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true", KeepOriginalGatewayNamesFlag: "true",
+			},
+			class:            "nginx",
+			expectedGateways: []types.NamespacedName{{Namespace: "default", Name: "nginx"}},
+		},
+		{
+			name:            "no ingress class",
+			flags:           map[string]string{GatewayModeFlag: "per-namespace", GatewayInServiceNamespaceFlag: "true"},
+			expectedMessage: "Ingress has TLS but no rules nor ingress class",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			ingress := networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert-only", Namespace: "default", Annotations: tc.annotations},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-com-tls"}},
+				},
+			}
+			if tc.class != "" {
+				ingress.Spec.IngressClassName = ptrTo(tc.class)
+			}
+
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{Name: tc.flags},
+			}).(*Provider)
+			provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+				{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+			})
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			var gateways []types.NamespacedName
+			for gwKey := range gatewayResources.Gateways {
+				gateways = append(gateways, gwKey)
+			}
+			if diff := cmp.Diff(tc.expectedGateways, gateways); diff != "" {
+				t.Errorf("unexpected Gateways (-want +got):\n%s", diff)
+			}
+
+			if tc.expectedMessage == "" {
+				return
+			}
+			found := false
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if strings.HasPrefix(n.Message, tc.expectedMessage) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a notification starting with %q", tc.expectedMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// Tracing annotations
+	enableOpentracingAnnotation   = "nginx.ingress.kubernetes.io/enable-opentracing"
+	enableOpentelemetryAnnotation = "nginx.ingress.kubernetes.io/enable-opentelemetry"
+)
+
+// tracingFeature returns the feature parser of the opentracing / opentelemetry
+// annotations, which stores the tracing config in the IR of the routes
+// generated from the Ingress. ingress-nginx only sets the sampling ratio
+// globally (otel-sampler-ratio in its ConfigMap), so it comes from
+// samplerRatio, the --tracing-sampler-ratio flag, and all requests are traced
+// when it is empty. Envoy enables tracing on the Gateway's HTTP connection
+// manager, so the generated EnvoyFilter applies to every route of the Gateway.
+func tracingFeature(samplerRatio string) i2gw.FeatureParser {
+	return func(ingresses []networkingv1.Ingress, _ map[types.NamespacedName]map[string]int32, ir *intermediate.IR) field.ErrorList {
+		samplingPercentage := 100.0
+		if samplerRatio != "" {
+			// Invalid ratios are rejected by GatewayConfig.Validate
+			if ratio, err := parseTracingSamplerRatio(samplerRatio); err == nil {
+				samplingPercentage = ratio * 100
+			}
+		}
+
+		for i := range ingresses {
+			ing := &ingresses[i]
+			if ing.Annotations[enableOpentracingAnnotation] != "true" && ing.Annotations[enableOpentelemetryAnnotation] != "true" {
+				continue
+			}
+
+			config := &intermediate.TracingConfig{SamplingPercentage: samplingPercentage}
+			updated := updateIngressRoutes(ir, ing, func(nginxIR *intermediate.IngressNginxHTTPRouteIR) {
+				nginxIR.Tracing = config
+			})
+			if updated == 0 {
+				continue
+			}
+
+			if samplerRatio == "" {
+				notify(notifications.InfoNotification,
+					fmt.Sprintf("ingress-nginx sets the tracing sampling ratio globally with otel-sampler-ratio in its ConfigMap, so all requests are traced. "+
+						"Set --%s-%s to keep another ratio.", Name, TracingSamplerRatioFlag),
+					ing,
+				)
+			}
+			notify(notifications.WarningNotification,
+				fmt.Sprintf("Tracing (sampling %g%%) is enabled on the Gateway's HTTP connection manager in Envoy, so it applies to all routes of the Gateway, not only this Ingress. A tracing provider must be configured in the Istio mesh config for traces to be exported.",
+					config.SamplingPercentage),
+				ing,
+			)
+		}
+
+		return nil
+	}
+}
+
+// parseTracingSamplerRatio parses a sampling ratio between 0.0 and 1.0
+func parseTracingSamplerRatio(value string) (float64, error) {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("must be a number between 0.0 and 1.0")
+	}
+	return ratio, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestTracingFeature(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		samplerRatio     string
+		expectTracing    bool
+		expectedSampling float64
+		expectInfo       bool
+	}{
+		{
+			name:             "opentelemetry samples all requests by default",
+			annotations:      map[string]string{enableOpentelemetryAnnotation: "true"},
+			expectTracing:    true,
+			expectedSampling: 100,
+			expectInfo:       true,
+		},
+		{
+			name:             "opentracing with sampler ratio",
+			annotations:      map[string]string{enableOpentracingAnnotation: "true"},
+			samplerRatio:     "0.25",
+			expectTracing:    true,
+			expectedSampling: 25,
+		},
+		{
+			name:        "tracing disabled",
+			annotations: map[string]string{enableOpentelemetryAnnotation: "false"},
+		},
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(tc.annotations)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			notifications.NotificationAggr.Notifications[Name] = nil
+			if errs := tracingFeature(tc.samplerRatio)(ingresses, nil, &ir); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			hasInfo := false
+			for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+				if n.Type == notifications.InfoNotification && strings.Contains(n.Message, "otel-sampler-ratio") {
+					hasInfo = true
+				}
+			}
+			if hasInfo != tc.expectInfo {
+				t.Errorf("expected otel-sampler-ratio Info: %v, got: %v", tc.expectInfo, hasInfo)
+			}
+
+			generator := &EnvoyFilterGenerator{GatewayConfig: GatewayConfig{Mode: "per-namespace"}}
+			filter := generator.GenerateEnvoyFilters(ir)[types.NamespacedName{
+				Namespace: "default",
+				Name:      "default-" + routeKey.Name + "-tracing",
+			}]
+			if (filter != nil) != tc.expectTracing {
+				t.Fatalf("expected tracing EnvoyFilter: %v, got: %v", tc.expectTracing, filter != nil)
+			}
+			if !tc.expectTracing {
+				return
+			}
+
+			patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+			if len(patches) != 1 {
+				t.Fatalf("expected 1 config patch, got %d", len(patches))
+			}
+			sampling, found, err := unstructured.NestedFloat64(patches[0].(map[string]interface{}),
+				"patch", "value", "typed_config", "tracing", "random_sampling", "value")
+			if err != nil || !found {
+				t.Fatalf("expected tracing random_sampling, found: %v, err: %v", found, err)
+			}
+			if sampling != tc.expectedSampling {
+				t.Errorf("expected random_sampling %g, got %g", tc.expectedSampling, sampling)
+			}
+		})
+	}
+}
+
+func TestValidateTracingSamplerRatio(t *testing.T) {
+	testCases := []struct {
+		ratio       string
+		expectError bool
+	}{
+		{ratio: ""},
+		{ratio: "0"},
+		{ratio: "0.5"},
+		{ratio: "1.0"},
+		{ratio: "1.5", expectError: true},
+		{ratio: "half", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ratio, func(t *testing.T) {
+			gwConfig := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {TracingSamplerRatioFlag: tc.ratio},
+				},
+			}).(*Provider).gatewayConfig
+			if errs := gwConfig.Validate(); (len(errs) > 0) != tc.expectError {
+				t.Errorf("expected error: %v, got: %v", tc.expectError, errs)
+			}
+		})
+	}
+}
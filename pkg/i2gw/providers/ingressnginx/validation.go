@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// validateIR checks the IR for internally inconsistent states that the feature
+// parsers should never produce, so they surface as errors instead of silently
+// generating broken Gateway API resources.
+func validateIR(ir intermediate.IR) field.ErrorList {
+	var errs field.ErrorList
+
+	for routeKey, routeCtx := range ir.HTTPRoutes {
+		routePath := field.NewPath("httproute", routeKey.Namespace, routeKey.Name)
+
+		errs = append(errs, validateBackendSources(routeCtx, routePath)...)
+
+		if nginxIR := routeCtx.ProviderSpecificIR.IngressNginx; nginxIR != nil {
+			errs = append(errs, validateIngressNginxRouteIR(nginxIR, routePath.Child("providerSpecificIR", "ingressNginx"))...)
+		}
+	}
+
+	return errs
+}
+
+// validateIngressHosts checks the rule and TLS hosts of the Ingresses against
+// the Gateway API hostname rules (RFC 1123 subdomain, optionally with a leading
+// wildcard label, and not an IP address). Rules and TLS hosts with an invalid
+// host are dropped from the returned Ingresses and reported as Errors, so the
+// other hosts are still converted. A TLS entry left without hosts is dropped,
+// since an empty host list would cover all hosts.
+func validateIngressHosts(ingresses []networkingv1.Ingress) []networkingv1.Ingress {
+	validIngresses := make([]networkingv1.Ingress, 0, len(ingresses))
+
+	for _, ingress := range ingresses {
+		var invalidHosts []string
+
+		var validRules []networkingv1.IngressRule
+		for i, rule := range ingress.Spec.Rules {
+			if msg := validateHostname(rule.Host); msg != "" {
+				invalidHosts = append(invalidHosts, fmt.Sprintf("spec.rules[%d].host %q (%s)", i, rule.Host, msg))
+				continue
+			}
+			validRules = append(validRules, rule)
+		}
+
+		var validTLS []networkingv1.IngressTLS
+		for i, tls := range ingress.Spec.TLS {
+			var validHosts []string
+			for j, host := range tls.Hosts {
+				if msg := validateHostname(host); msg != "" {
+					invalidHosts = append(invalidHosts, fmt.Sprintf("spec.tls[%d].hosts[%d] %q (%s)", i, j, host, msg))
+					continue
+				}
+				validHosts = append(validHosts, host)
+			}
+			if len(tls.Hosts) > 0 && len(validHosts) == 0 {
+				continue
+			}
+			tls.Hosts = validHosts
+			validTLS = append(validTLS, tls)
+		}
+
+		if len(invalidHosts) == 0 {
+			validIngresses = append(validIngresses, ingress)
+			continue
+		}
+
+		ingress = *ingress.DeepCopy()
+		ingress.Spec.Rules = validRules
+		ingress.Spec.TLS = validTLS
+		notifyWithCode(notifications.ErrorNotification, notifications.InvalidHostname,
+			fmt.Sprintf("%s cannot be used as Gateway API hostnames, their rules and TLS hosts are skipped", strings.Join(invalidHosts, ", ")),
+			&ingress)
+		validIngresses = append(validIngresses, ingress)
+	}
+
+	return validIngresses
+}
+
+// reportResourceBackends emits an Error for every Ingress backend referencing
+// a resource instead of a Service. The backendRef generated for it keeps the
+// group and kind of the resource, which Gateway implementations reject as
+// they only support Services.
+func reportResourceBackends(ingresses []networkingv1.Ingress) {
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		report := func(backend networkingv1.IngressBackend, location string) {
+			if backend.Service != nil || backend.Resource == nil {
+				return
+			}
+			notifyWithCode(notifications.ErrorNotification, notifications.UnsupportedResourceBackend,
+				fmt.Sprintf("MIGRATION BLOCKER - %s references %s, a resource backend, which has no Gateway API equivalent. "+
+					"Serve it through a Service, or replace the generated backendRef with a backend supported by the Gateway implementation",
+					location, resourceBackendName(backend.Resource)),
+				ingress)
+		}
+
+		if ingress.Spec.DefaultBackend != nil {
+			report(*ingress.Spec.DefaultBackend, "the defaultBackend")
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				report(path.Backend, fmt.Sprintf("the backend of path %q", rule.Host+path.Path))
+			}
+		}
+	}
+}
+
+// resourceBackendName formats a resource backend as <kind>.<group> <name>
+func resourceBackendName(resource *corev1.TypedLocalObjectReference) string {
+	kind := resource.Kind
+	if resource.APIGroup != nil && *resource.APIGroup != "" {
+		kind += "." + *resource.APIGroup
+	}
+	return kind + " " + resource.Name
+}
+
+// defaultPathTypes sets the pathType of Ingress paths without one, as in
+// manifests written for networking.k8s.io/v1beta1, to Prefix, which is how
+// ingress-nginx matches them. A Warning lists the paths of each Ingress that
+// were defaulted.
+func defaultPathTypes(ingresses []networkingv1.Ingress) []networkingv1.Ingress {
+	defaulted := make([]networkingv1.Ingress, 0, len(ingresses))
+
+	for _, ingress := range ingresses {
+		var paths []string
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.PathType == nil {
+					paths = append(paths, fmt.Sprintf("%q", rule.Host+path.Path))
+				}
+			}
+		}
+		if len(paths) == 0 {
+			defaulted = append(defaulted, ingress)
+			continue
+		}
+
+		ingress = *ingress.DeepCopy()
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.HTTP.Paths {
+				if rule.HTTP.Paths[i].PathType == nil {
+					rule.HTTP.Paths[i].PathType = ptr.To(networkingv1.PathTypePrefix)
+				}
+			}
+		}
+		notify(notifications.WarningNotification,
+			fmt.Sprintf("paths %s have no pathType, assuming Prefix like ingress-nginx. Set the pathType explicitly to confirm", strings.Join(paths, ", ")),
+			&ingress)
+		defaulted = append(defaulted, ingress)
+	}
+
+	return defaulted
+}
+
+// validateHostname returns why the host can't be used as a Gateway API
+// hostname, or an empty string if it can. An empty host matches all hosts.
+func validateHostname(host string) string {
+	if host == "" {
+		return ""
+	}
+	if net.ParseIP(host) != nil {
+		return "must be a DNS name, not an IP address"
+	}
+
+	var msgs []string
+	if strings.HasPrefix(host, "*.") {
+		msgs = validation.IsWildcardDNS1123Subdomain(host)
+	} else {
+		msgs = validation.IsDNS1123Subdomain(host)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validateBackendSources checks that every Service backend of the route is
+// backed by a source with the same Service in RuleBackendSources.
+func validateBackendSources(routeCtx intermediate.HTTPRouteContext, routePath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	// Routes not built from Ingresses carry no sources
+	if routeCtx.RuleBackendSources == nil {
+		return errs
+	}
+
+	for ruleIdx, rule := range routeCtx.HTTPRoute.Spec.Rules {
+		for backendIdx, backendRef := range rule.BackendRefs {
+			if !isServiceBackendRef(backendRef.BackendObjectReference) {
+				continue
+			}
+			backendPath := routePath.Child("spec", "rules").Index(ruleIdx).Child("backendRefs").Index(backendIdx)
+
+			if ruleIdx >= len(routeCtx.RuleBackendSources) || backendIdx >= len(routeCtx.RuleBackendSources[ruleIdx]) {
+				errs = append(errs, field.Invalid(backendPath, string(backendRef.Name),
+					"dangling backend reference: no source Ingress backend in the IR"))
+				continue
+			}
+
+			sourceService := backendSourceServiceName(routeCtx.RuleBackendSources[ruleIdx][backendIdx])
+			if sourceService != "" && sourceService != string(backendRef.Name) {
+				errs = append(errs, field.Invalid(backendPath, string(backendRef.Name),
+					"dangling backend reference: source Ingress backend references service "+sourceService))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateIngressNginxRouteIR checks the ingress-nginx specific settings of a route
+func validateIngressNginxRouteIR(nginxIR *intermediate.IngressNginxHTTPRouteIR, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if nginxIR.RateLimitRPS < 0 {
+		errs = append(errs, field.Invalid(path.Child("rateLimitRPS"), nginxIR.RateLimitRPS, "must not be negative"))
+	}
+	if nginxIR.RateLimitBurst < 0 {
+		errs = append(errs, field.Invalid(path.Child("rateLimitBurst"), nginxIR.RateLimitBurst, "must not be negative"))
+	}
+	if nginxIR.ClientCertAuth != nil && nginxIR.ClientCertAuth.VerifyDepth < 0 {
+		errs = append(errs, field.Invalid(path.Child("clientCertAuth", "verifyDepth"), nginxIR.ClientCertAuth.VerifyDepth, "must not be negative"))
+	}
+	if nginxIR.MaxRequestHeadersKB < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxRequestHeadersKB"), nginxIR.MaxRequestHeadersKB, "must not be negative"))
+	}
+	if nginxIR.ProxyBufferBytes < 0 {
+		errs = append(errs, field.Invalid(path.Child("proxyBufferBytes"), nginxIR.ProxyBufferBytes, "must not be negative"))
+	}
+	if nginxIR.Tracing != nil && (nginxIR.Tracing.SamplingPercentage < 0 || nginxIR.Tracing.SamplingPercentage > 100) {
+		errs = append(errs, field.Invalid(path.Child("tracing", "samplingPercentage"), nginxIR.Tracing.SamplingPercentage, "must be between 0 and 100"))
+	}
+	if nginxIR.ExternalAuth != nil && nginxIR.ExternalAuth.URL == "" {
+		errs = append(errs, field.Required(path.Child("externalAuth", "url"), "external auth requires a URL"))
+	}
+
+	return errs
+}
+
+// isServiceBackendRef returns true if the reference points to a core Service
+func isServiceBackendRef(ref gatewayv1.BackendObjectReference) bool {
+	if ref.Group != nil && *ref.Group != "" {
+		return false
+	}
+	return ref.Kind == nil || *ref.Kind == "Service"
+}
+
+// backendSourceServiceName returns the name of the Service the source Ingress
+// backend points to, or an empty string for non-Service backends.
+func backendSourceServiceName(source intermediate.BackendSource) string {
+	switch {
+	case source.Path != nil && source.Path.Backend.Service != nil:
+		return source.Path.Backend.Service.Name
+	case source.DefaultBackend != nil && source.DefaultBackend.Service != nil:
+		return source.DefaultBackend.Service.Name
+	}
+	return ""
+}
@@ -0,0 +1,359 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressnginx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestValidateIR(t *testing.T) {
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+
+	testCases := []struct {
+		name          string
+		modify        func(*intermediate.HTTPRouteContext)
+		expectedField string
+		expectedType  field.ErrorType
+	}{
+		{
+			name:   "consistent IR is valid",
+			modify: func(_ *intermediate.HTTPRouteContext) {},
+		},
+		{
+			name: "negative rate limit RPS",
+			modify: func(routeCtx *intermediate.HTTPRouteContext) {
+				routeCtx.ProviderSpecificIR.IngressNginx = &intermediate.IngressNginxHTTPRouteIR{RateLimitRPS: -5}
+			},
+			expectedField: "httproute.default.test-ingress-example-com.providerSpecificIR.ingressNginx.rateLimitRPS",
+			expectedType:  field.ErrorTypeInvalid,
+		},
+		{
+			name: "dangling backend reference",
+			modify: func(routeCtx *intermediate.HTTPRouteContext) {
+				routeCtx.HTTPRoute.Spec.Rules[0].BackendRefs[0].Name = "unknown-service"
+			},
+			expectedField: "httproute.default.test-ingress-example-com.spec.rules[0].backendRefs[0]",
+			expectedType:  field.ErrorTypeInvalid,
+		},
+		{
+			name: "backend reference without source",
+			modify: func(routeCtx *intermediate.HTTPRouteContext) {
+				routeCtx.RuleBackendSources[0] = nil
+			},
+			expectedField: "httproute.default.test-ingress-example-com.spec.rules[0].backendRefs[0]",
+			expectedType:  field.ErrorTypeInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ingresses := []networkingv1.Ingress{headersTestIngress(nil)}
+			ir, errs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", errs)
+			}
+
+			routeCtx, ok := ir.HTTPRoutes[routeKey]
+			if !ok {
+				t.Fatalf("HTTPRoute %s not found", routeKey)
+			}
+			tc.modify(&routeCtx)
+			ir.HTTPRoutes[routeKey] = routeCtx
+
+			errs = validateIR(ir)
+			if tc.expectedField == "" {
+				if len(errs) > 0 {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+			}
+			if errs[0].Field != tc.expectedField {
+				t.Errorf("expected error on field %q, got %q", tc.expectedField, errs[0].Field)
+			}
+			if errs[0].Type != tc.expectedType {
+				t.Errorf("expected error type %q, got %q", tc.expectedType, errs[0].Type)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_InvalidIR(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: "route"}
+	ir := intermediate.IR{
+		HTTPRoutes: map[types.NamespacedName]intermediate.HTTPRouteContext{
+			routeKey: {
+				ProviderSpecificIR: intermediate.ProviderSpecificHTTPRouteIR{
+					IngressNginx: &intermediate.IngressNginxHTTPRouteIR{RateLimitRPS: -1},
+				},
+			},
+		},
+	}
+
+	if _, errs := provider.ToGatewayResources(ir); len(errs) == 0 {
+		t.Errorf("expected validation errors for negative RateLimitRPS")
+	}
+}
+
+func TestValidateIngressHosts(t *testing.T) {
+	testCases := []struct {
+		name          string
+		host          string
+		expectedError bool
+	}{
+		{
+			name: "valid host",
+			host: "example.com",
+		},
+		{
+			name: "valid wildcard host",
+			host: "*.example.com",
+		},
+		{
+			name: "empty host",
+			host: "",
+		},
+		{
+			name:          "invalid characters",
+			host:          "my_app.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "wildcard not in the leftmost label",
+			host:          "app.*.example.com",
+			expectedError: true,
+		},
+		{
+			name:          "IP address",
+			host:          "10.0.0.1",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifications.NotificationAggr.Notifications[Name] = nil
+
+			invalid := headersTestIngress(nil)
+			invalid.Spec.Rules[0].Host = tc.host
+
+			valid := headersTestIngress(nil)
+			valid.Name = "other-ingress"
+
+			ingresses := validateIngressHosts([]networkingv1.Ingress{invalid, valid})
+			if len(ingresses) != 2 {
+				t.Fatalf("expected 2 ingresses, got %d", len(ingresses))
+			}
+			if len(ingresses[1].Spec.Rules) != 1 {
+				t.Errorf("expected the rule with a valid host to be kept")
+			}
+
+			var errorNotifications []notifications.Notification
+			for _, n := range notifications.NotificationAggr.Notifications[Name] {
+				if n.Type == notifications.ErrorNotification {
+					errorNotifications = append(errorNotifications, n)
+				}
+			}
+
+			if !tc.expectedError {
+				if len(errorNotifications) > 0 {
+					t.Errorf("expected no errors, got %v", errorNotifications)
+				}
+				if len(ingresses[0].Spec.Rules) != 1 {
+					t.Errorf("expected the rule to be kept")
+				}
+				return
+			}
+
+			if len(errorNotifications) != 1 {
+				t.Fatalf("expected 1 error, got %d: %v", len(errorNotifications), errorNotifications)
+			}
+			if n := errorNotifications[0]; n.Code != notifications.InvalidHostname || !strings.Contains(n.Message, "spec.rules[0].host") {
+				t.Errorf("expected an InvalidHostname error for spec.rules[0].host, got %+v", n)
+			}
+			if len(ingresses[0].Spec.Rules) != 0 {
+				t.Errorf("expected the rule with an invalid host to be dropped")
+			}
+			if len(invalid.Spec.Rules) != 1 {
+				t.Errorf("expected the input Ingress to be left unmodified")
+			}
+
+			ir, irErrs := common.ToIR(ingresses, nil, i2gw.ProviderImplementationSpecificOptions{})
+			if len(irErrs) > 0 {
+				t.Fatalf("unexpected errors building IR: %v", irErrs)
+			}
+			if len(ir.HTTPRoutes) != 1 {
+				t.Errorf("expected only the route with a valid host, got %d routes", len(ir.HTTPRoutes))
+			}
+		})
+	}
+}
+
+func TestValidateIngressHosts_TLSHosts(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(nil)
+	ingress.Spec.TLS = []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com", "my_app.example.com"}, SecretName: "example-cert"},
+		{Hosts: []string{"10.0.0.1"}, SecretName: "ip-cert"},
+	}
+
+	ingresses := validateIngressHosts([]networkingv1.Ingress{ingress})
+	expectedTLS := []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-cert"},
+	}
+	if diff := cmp.Diff(expectedTLS, ingresses[0].Spec.TLS); diff != "" {
+		t.Errorf("unexpected TLS entries (-want +got):\n%s", diff)
+	}
+
+	var messages []string
+	for _, n := range notifications.NotificationAggr.Notifications[Name] {
+		if n.Type == notifications.ErrorNotification && n.Code == notifications.InvalidHostname {
+			messages = append(messages, n.Message)
+		}
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0], `spec.tls[0].hosts[1] "my_app.example.com"`) || !strings.Contains(messages[0], `spec.tls[1].hosts[0] "10.0.0.1"`) {
+		t.Errorf("expected an InvalidHostname error listing the invalid TLS hosts, got %v", messages)
+	}
+}
+
+func TestToGatewayResources_InvalidHostSkipped(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	provider := NewProvider(&i2gw.ProviderConf{
+		ProviderSpecificFlags: map[string]map[string]string{
+			Name: {GatewayModeFlag: "per-namespace"},
+		},
+	}).(*Provider)
+	invalid := headersTestIngress(nil)
+	invalid.Name = "invalid-ingress"
+	invalid.Spec.Rules[0].Host = "my_app.example.com"
+	valid := headersTestIngress(nil)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: invalid.Namespace, Name: invalid.Name}: &invalid,
+		{Namespace: valid.Namespace, Name: valid.Name}:     &valid,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("expected the invalid host to be skipped without errors, got %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName(valid.Name, "example.com")}
+	if _, ok := gatewayResources.HTTPRoutes[routeKey]; !ok || len(gatewayResources.HTTPRoutes) != 1 {
+		t.Errorf("expected only the HTTPRoute %s, got %d HTTPRoutes", routeKey, len(gatewayResources.HTTPRoutes))
+	}
+}
+
+func TestToIR_NilPathType(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(nil)
+	ingress.Spec.Rules[0].HTTP.Paths[0].PathType = nil
+
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+
+	routeKey := types.NamespacedName{Namespace: "default", Name: common.RouteName("test-ingress", "example.com")}
+	rules := ir.HTTPRoutes[routeKey].HTTPRoute.Spec.Rules
+	if len(rules) != 1 || len(rules[0].Matches) != 1 {
+		t.Fatalf("expected 1 rule with 1 match, got %+v", rules)
+	}
+	if path := rules[0].Matches[0].Path; path == nil || *path.Type != gatewayv1.PathMatchPathPrefix || *path.Value != "/" {
+		t.Errorf("expected a PathPrefix match on /, got %+v", path)
+	}
+	if ingress.Spec.Rules[0].HTTP.Paths[0].PathType != nil {
+		t.Errorf("expected the stored Ingress to be left unmodified")
+	}
+
+	var warnings []string
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.WarningNotification {
+			warnings = append(warnings, n.Message)
+		}
+	}
+	expectedWarnings := []string{`paths "example.com/" have no pathType, assuming Prefix like ingress-nginx. Set the pathType explicitly to confirm`}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestToIR_ResourceBackend(t *testing.T) {
+	notifications.NotificationAggr.Notifications[Name] = nil
+
+	ingress := headersTestIngress(nil)
+	ingress.Spec.Rules[0].HTTP.Paths[0].Backend = networkingv1.IngressBackend{
+		Resource: &corev1.TypedLocalObjectReference{
+			APIGroup: ptr.To("storage.example.com"),
+			Kind:     "Bucket",
+			Name:     "static-assets",
+		},
+	}
+
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+	provider.storage.Ingresses.FromMap(map[types.NamespacedName]*networkingv1.Ingress{
+		{Namespace: ingress.Namespace, Name: ingress.Name}: &ingress,
+	})
+
+	if _, errs := provider.ToIR(); len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+
+	var errorNotifications []notifications.Notification
+	for _, n := range notifications.NotificationAggr.ProviderNotifications(Name) {
+		if n.Type == notifications.ErrorNotification {
+			errorNotifications = append(errorNotifications, n)
+		}
+	}
+	if len(errorNotifications) != 1 {
+		t.Fatalf("expected 1 error, got %+v", errorNotifications)
+	}
+	if errorNotifications[0].Code != notifications.UnsupportedResourceBackend {
+		t.Errorf("expected code %s, got %s", notifications.UnsupportedResourceBackend, errorNotifications[0].Code)
+	}
+	if !strings.Contains(errorNotifications[0].Message, `the backend of path "example.com/" references Bucket.storage.example.com static-assets`) {
+		t.Errorf("expected the error to name the resource, got %q", errorNotifications[0].Message)
+	}
+}
@@ -17,14 +17,21 @@ limitations under the License.
 package nginx
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/intermediate"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/notifications"
+	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw/providers/common"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -49,6 +56,20 @@ const (
 	// GatewayNameFlag specifies the name of the centralized gateway
 	// Default: "platform-gateway"
 	GatewayNameFlag = "gateway-name"
+
+	// GatewayNamespaceTemplateFlag specifies the per-namespace gateway namespace/name pattern
+	// Default: "{ns}-gateway"
+	GatewayNamespaceTemplateFlag = "gateway-namespace-template"
+
+	// GatewayAnnotationsFlag adds annotations to generated Gateways, as key=value,...
+	GatewayAnnotationsFlag = "gateway-annotations"
+
+	// GatewayLabelsFlag adds labels to generated Gateways, as key=value,...
+	GatewayLabelsFlag = "gateway-labels"
+
+	// KeepOriginalGatewayNamesFlag keeps the names of the Gateways generated from the
+	// ingress classes when they are moved to the gateway namespaces
+	KeepOriginalGatewayNamesFlag = "keep-original-gateway-names"
 	
 	// Default values - Centralized gateway is the default
 	DefaultGatewayMode      = "centralized"
@@ -78,6 +99,26 @@ func init() {
 		Description:  "Name of the centralized gateway (only used when gateway-mode=centralized)",
 		DefaultValue: DefaultGatewayName,
 	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayNamespaceTemplateFlag,
+		Description:  "Gateway namespace pattern for per-namespace mode, where {ns} is the service namespace. Either '<namespace>' used for both namespace and name (e.g. '{ns}-gw') or '<namespace>/<name>' (e.g. 'gateways/{ns}')",
+		DefaultValue: common.DefaultGatewayNamespaceTemplate,
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayAnnotationsFlag,
+		Description:  "Annotations added to every generated Gateway, as a comma-separated list of key=value pairs (e.g. 'networking.istio.io/service-type=ClusterIP')",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         GatewayLabelsFlag,
+		Description:  "Labels added to every generated Gateway, as a comma-separated list of key=value pairs (e.g. 'istio.io/rev=stable')",
+		DefaultValue: "",
+	})
+	i2gw.RegisterProviderSpecificFlag(Name, i2gw.ProviderSpecificFlag{
+		Name:         KeepOriginalGatewayNamesFlag,
+		Description:  "Keep the Gateway names generated from the ingress classes (e.g. 'nginx'), only moving the Gateways to the centralized or per-namespace gateway namespace",
+		DefaultValue: "false",
+	})
 }
 
 // GatewayConfig holds gateway deployment configuration
@@ -88,6 +129,14 @@ type GatewayConfig struct {
 	Namespace string
 	// Name is the gateway name (for centralized mode, e.g., platform-gateway)
 	Name string
+	// NamespaceTemplate is the gateway namespace pattern for per-namespace mode (e.g., {ns}-gateway)
+	NamespaceTemplate string
+	// GatewayAnnotations are key=value pairs added to the annotations of generated Gateways
+	GatewayAnnotations string
+	// GatewayLabels are key=value pairs added to the labels of generated Gateways
+	GatewayLabels string
+	// KeepOriginalGatewayNames keeps the generated Gateway names when relocating them
+	KeepOriginalGatewayNames bool
 }
 
 // IsCentralized returns true if using centralized gateway mode
@@ -100,9 +149,42 @@ func (c GatewayConfig) GetGatewayRef(serviceNamespace string) (namespace, name s
 	if c.IsCentralized() {
 		return c.Namespace, c.Name
 	}
-	// Per-namespace mode: dedicated gateway namespace
-	gatewayNS := fmt.Sprintf("%s-gateway", serviceNamespace)
-	return gatewayNS, gatewayNS
+	// Per-namespace mode: expanded namespace template, <service>-gateway by default
+	return common.ExpandGatewayNamespaceTemplate(cmp.Or(c.NamespaceTemplate, common.DefaultGatewayNamespaceTemplate), serviceNamespace)
+}
+
+// Validate checks the gateway configuration flags
+func (c GatewayConfig) Validate() field.ErrorList {
+	var errs field.ErrorList
+
+	if !c.IsCentralized() && c.NamespaceTemplate != "" {
+		errs = append(errs, common.ValidateGatewayNamespaceTemplate(c.NamespaceTemplate, field.NewPath(Name, GatewayNamespaceTemplateFlag))...)
+	}
+	if _, annotationErrs := common.ParseGatewayAnnotations(c.GatewayAnnotations, field.NewPath(Name, GatewayAnnotationsFlag)); len(annotationErrs) > 0 {
+		errs = append(errs, annotationErrs...)
+	}
+	if _, labelErrs := common.ParseGatewayLabels(c.GatewayLabels, field.NewPath(Name, GatewayLabelsFlag)); len(labelErrs) > 0 {
+		errs = append(errs, labelErrs...)
+	}
+
+	return errs
+}
+
+// GatewayNamespaceCollisions returns the computed per-namespace gateway
+// namespaces that are also workload namespaces, keyed by the workload namespace
+// whose gateway collides. Centralized mode never collides.
+func (c GatewayConfig) GatewayNamespaceCollisions(workloadNamespaces sets.Set[string]) map[string]string {
+	collisions := map[string]string{}
+	if c.IsCentralized() {
+		return collisions
+	}
+	for namespace := range workloadNamespaces {
+		gatewayNS, _ := c.GetGatewayRef(namespace)
+		if workloadNamespaces.Has(gatewayNS) {
+			collisions[namespace] = gatewayNS
+		}
+	}
+	return collisions
 }
 
 type Provider struct {
@@ -133,6 +215,18 @@ func NewProvider(conf *i2gw.ProviderConf) i2gw.Provider {
 			if name, ok := flags[GatewayNameFlag]; ok && name != "" {
 				gwConfig.Name = name
 			}
+			if template, ok := flags[GatewayNamespaceTemplateFlag]; ok && template != "" {
+				gwConfig.NamespaceTemplate = template
+			}
+			if annotations, ok := flags[GatewayAnnotationsFlag]; ok {
+				gwConfig.GatewayAnnotations = annotations
+			}
+			if labels, ok := flags[GatewayLabelsFlag]; ok {
+				gwConfig.GatewayLabels = labels
+			}
+			if keepNames, ok := flags[KeepOriginalGatewayNamesFlag]; ok {
+				gwConfig.KeepOriginalGatewayNames = keepNames == "true"
+			}
 		}
 	}
 	
@@ -171,6 +265,10 @@ func (p *Provider) ToIR() (intermediate.IR, field.ErrorList) {
 
 // ToGatewayResources converts the IR to Gateway API resources
 func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources, field.ErrorList) {
+	if errs := p.gatewayConfig.Validate(); len(errs) != 0 {
+		return i2gw.GatewayResources{}, errs
+	}
+
 	gatewayResources, errs := p.gatewayResourcesConverter.convert(ir)
 	if len(errs) != 0 {
 		return i2gw.GatewayResources{}, errs
@@ -178,6 +276,15 @@ func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources
 	
 	// Transform Gateways based on gateway mode
 	p.transformGatewaysForMode(&gatewayResources, ir)
+
+	// Add the configured infrastructure annotations and labels to the Gateways.
+	// Both values were checked by Validate above.
+	annotations, _ := common.ParseGatewayAnnotations(p.gatewayConfig.GatewayAnnotations, nil)
+	labels, _ := common.ParseGatewayLabels(p.gatewayConfig.GatewayLabels, nil)
+	common.ApplyGatewayMetadata(&gatewayResources, annotations, labels)
+
+	// Let routes from the service namespaces attach to the Gateways
+	common.AllowCrossNamespaceRoutes(&gatewayResources)
 	
 	return gatewayResources, nil
 }
@@ -186,6 +293,11 @@ func (p *Provider) ToGatewayResources(ir intermediate.IR) (i2gw.GatewayResources
 // In centralized mode (default), all routes use a single platform Gateway in ionianshared.
 // In per-namespace mode, each service namespace gets its own Gateway in a dedicated gateway namespace.
 func (p *Provider) transformGatewaysForMode(gatewayResources *i2gw.GatewayResources, ir intermediate.IR) {
+	if p.gatewayConfig.KeepOriginalGatewayNames {
+		p.relocateGateways(gatewayResources, ir)
+		return
+	}
+
 	if p.gatewayConfig.Mode == "centralized" {
 		// For centralized mode, update Gateway to use the configured namespace/name
 		newGateways := make(map[types.NamespacedName]gatewayv1.Gateway)
@@ -200,6 +312,10 @@ func (p *Provider) transformGatewaysForMode(gatewayResources *i2gw.GatewayResour
 			// Use istio as the gateway class for Istio deployments
 			istioClass := gatewayv1.ObjectName("istio")
 			gw.Spec.GatewayClassName = istioClass
+			// Merge listeners of the Gateways generated for other namespaces
+			if existing, ok := newGateways[newKey]; ok {
+				gw.Spec.Listeners = append(existing.Spec.Listeners, gw.Spec.Listeners...)
+			}
 			newGateways[newKey] = gw
 			
 			// Update HTTPRoutes to reference the new gateway
@@ -214,6 +330,8 @@ func (p *Provider) transformGatewaysForMode(gatewayResources *i2gw.GatewayResour
 	for routeKey := range gatewayResources.HTTPRoutes {
 		namespaceRoutes[routeKey.Namespace] = append(namespaceRoutes[routeKey.Namespace], routeKey)
 	}
+
+	p.warnGatewayNamespaceCollisions(p.workloadNamespaces(ir))
 	
 	newGateways := make(map[types.NamespacedName]gatewayv1.Gateway)
 	oldToNewGateway := make(map[types.NamespacedName]types.NamespacedName)
@@ -253,6 +371,84 @@ func (p *Provider) transformGatewaysForMode(gatewayResources *i2gw.GatewayResour
 	gatewayResources.Gateways = newGateways
 }
 
+// relocateGateways moves the generated Gateways to the gateway namespace of their
+// mode without renaming them, so references to the ingress class named Gateways
+// keep working. Gateways with the same name that end up in the same namespace
+// are merged.
+func (p *Provider) relocateGateways(gatewayResources *i2gw.GatewayResources, ir intermediate.IR) {
+	if !p.gatewayConfig.IsCentralized() {
+		p.warnGatewayNamespaceCollisions(p.workloadNamespaces(ir))
+	}
+
+	newGateways := make(map[types.NamespacedName]gatewayv1.Gateway)
+	oldToNewGateway := make(map[types.NamespacedName]types.NamespacedName)
+	for _, oldKey := range slices.SortedFunc(maps.Keys(gatewayResources.Gateways), compareNamespacedNames) {
+		gw := gatewayResources.Gateways[oldKey]
+		gwNamespace, _ := p.gatewayConfig.GetGatewayRef(oldKey.Namespace)
+		newKey := types.NamespacedName{Namespace: gwNamespace, Name: oldKey.Name}
+
+		gw.Namespace = newKey.Namespace
+		gw.Spec.GatewayClassName = gatewayv1.ObjectName("istio")
+		if existing, ok := newGateways[newKey]; ok {
+			gw.Spec.Listeners = append(existing.Spec.Listeners, gw.Spec.Listeners...)
+		}
+		newGateways[newKey] = gw
+		oldToNewGateway[oldKey] = newKey
+	}
+
+	// Each parentRef is resolved against the original Gateways once, since a
+	// relocated Gateway may have the key of another original Gateway
+	for routeKey, route := range gatewayResources.HTTPRoutes {
+		parentRefs := slices.Clone(route.Spec.ParentRefs)
+		for i, parentRef := range parentRefs {
+			oldKey := types.NamespacedName{Namespace: route.Namespace, Name: string(parentRef.Name)}
+			if parentRef.Namespace != nil {
+				oldKey.Namespace = string(*parentRef.Namespace)
+			}
+			if newKey, ok := oldToNewGateway[oldKey]; ok {
+				parentRefs[i].Namespace = ptr.To(gatewayv1.Namespace(newKey.Namespace))
+			}
+		}
+		route.Spec.ParentRefs = parentRefs
+		gatewayResources.HTTPRoutes[routeKey] = route
+	}
+
+	gatewayResources.Gateways = newGateways
+}
+
+// compareNamespacedNames orders NamespacedNames by namespace, then name
+func compareNamespacedNames(a, b types.NamespacedName) int {
+	return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+}
+
+// workloadNamespaces returns the namespaces holding the converted Ingresses and routes
+func (p *Provider) workloadNamespaces(ir intermediate.IR) sets.Set[string] {
+	namespaces := sets.New[string]()
+	if p.storage != nil {
+		for key := range p.storage.Ingresses {
+			namespaces.Insert(key.Namespace)
+		}
+	}
+	for key := range ir.HTTPRoutes {
+		namespaces.Insert(key.Namespace)
+	}
+	return namespaces
+}
+
+// warnGatewayNamespaceCollisions warns when a per-namespace gateway namespace
+// is also a workload namespace, since the generated Gateway would be placed
+// next to that namespace's own workloads.
+func (p *Provider) warnGatewayNamespaceCollisions(workloadNamespaces sets.Set[string]) {
+	collisions := p.gatewayConfig.GatewayNamespaceCollisions(workloadNamespaces)
+	for _, namespace := range sets.List(sets.KeySet(collisions)) {
+		notify(notifications.WarningNotification, fmt.Sprintf(
+			"Gateway namespace %q computed for namespace %q already exists as a workload namespace; "+
+				"the per-namespace Gateway would share it with unrelated workloads. "+
+				"Consider centralized mode (--nginx-gateway-mode=centralized) or renaming the namespace",
+			collisions[namespace], namespace))
+	}
+}
+
 // updateHTTPRouteParentRefs updates HTTPRoute parentRefs from old gateway to new gateway
 func (p *Provider) updateHTTPRouteParentRefs(gatewayResources *i2gw.GatewayResources, oldGw, newGw types.NamespacedName) {
 	for routeKey, route := range gatewayResources.HTTPRoutes {
@@ -17,8 +17,18 @@ limitations under the License.
 package nginx
 
 import (
+	"reflect"
 	"testing"
 
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
 	"github.com/kubernetes-sigs/ingress2gateway/pkg/i2gw"
 )
 
@@ -40,3 +50,323 @@ func TestNewProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestGatewayNamespaceCollisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		namespaces []string
+		want       map[string]string
+	}{
+		{
+			name:       "no collision",
+			mode:       "per-namespace",
+			namespaces: []string{"team-a", "team-b"},
+			want:       map[string]string{},
+		},
+		{
+			name:       "gateway namespace is a workload namespace",
+			mode:       "per-namespace",
+			namespaces: []string{"team-a", "team-a-gateway", "team-b"},
+			want:       map[string]string{"team-a": "team-a-gateway"},
+		},
+		{
+			name:       "chained collisions",
+			mode:       "per-namespace",
+			namespaces: []string{"team-a", "team-a-gateway", "team-a-gateway-gateway"},
+			want: map[string]string{
+				"team-a":         "team-a-gateway",
+				"team-a-gateway": "team-a-gateway-gateway",
+			},
+		},
+		{
+			name:       "centralized mode never collides",
+			mode:       "centralized",
+			namespaces: []string{"team-a", "team-a-gateway"},
+			want:       map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GatewayConfig{Mode: tt.mode, Namespace: DefaultGatewayNamespace, Name: DefaultGatewayName}
+			got := config.GatewayNamespaceCollisions(sets.New(tt.namespaces...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GatewayNamespaceCollisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayConfig_NamespaceTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		wantNamespace string
+		wantName      string
+	}{
+		{name: "default template", wantNamespace: "team-a-gateway", wantName: "team-a-gateway"},
+		{name: "custom suffix", template: "{ns}-gw", wantNamespace: "team-a-gw", wantName: "team-a-gw"},
+		{name: "shared gateway namespace", template: "gateways/{ns}", wantNamespace: "gateways", wantName: "team-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:              "per-namespace",
+						GatewayNamespaceTemplateFlag: tt.template,
+					},
+				},
+			}).(*Provider)
+			namespace, name := provider.gatewayConfig.GetGatewayRef("team-a")
+			if namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("GetGatewayRef() = %s/%s, want %s/%s", namespace, name, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_CentralizedAllowedRoutes(t *testing.T) {
+	provider := NewProvider(&i2gw.ProviderConf{}).(*Provider)
+	provider.storage = newResourceStorage()
+
+	pathType := networkingv1.PathTypePrefix
+	for _, namespace := range []string{"team-a", "team-b"} {
+		provider.storage.Ingresses[types.NamespacedName{Namespace: namespace, Name: "web"}] = &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: ptr.To(NginxIngressClass),
+				Rules: []networkingv1.IngressRule{{
+					Host: namespace + ".example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &pathType,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: "web",
+										Port: networkingv1.ServiceBackendPort{Number: 80},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	ir, errs := provider.ToIR()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors converting to IR: %v", errs)
+	}
+	gatewayResources, errs := provider.ToGatewayResources(ir)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	gatewayKey := types.NamespacedName{Namespace: DefaultGatewayNamespace, Name: DefaultGatewayName}
+	gateway, ok := gatewayResources.Gateways[gatewayKey]
+	if !ok {
+		t.Fatalf("expected centralized Gateway %s, got %v", gatewayKey, gatewayResources.Gateways)
+	}
+	if len(gateway.Spec.Listeners) != 2 {
+		t.Fatalf("expected the listeners of both namespaces, got %d", len(gateway.Spec.Listeners))
+	}
+
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil {
+			t.Fatalf("listener %s: expected allowedRoutes.namespaces to be set", listener.Name)
+		}
+		namespaces := listener.AllowedRoutes.Namespaces
+		if namespaces.From == nil || *namespaces.From != gatewayv1.NamespacesFromSelector {
+			t.Errorf("listener %s: expected from: Selector, got %v", listener.Name, namespaces.From)
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(namespaces.Selector)
+		if err != nil {
+			t.Fatalf("listener %s: invalid selector: %v", listener.Name, err)
+		}
+		for _, namespace := range []string{"team-a", "team-b"} {
+			if !selector.Matches(labels.Set{apiv1.LabelMetadataName: namespace}) {
+				t.Errorf("listener %s: expected routes from namespace %s to be allowed", listener.Name, namespace)
+			}
+		}
+		if selector.Matches(labels.Set{apiv1.LabelMetadataName: "team-c"}) {
+			t.Errorf("listener %s: expected routes from namespace team-c not to be allowed", listener.Name)
+		}
+	}
+}
+
+func TestToGatewayResources_GatewayMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		wantGateway types.NamespacedName
+	}{
+		{
+			name:        "centralized",
+			mode:        "centralized",
+			wantGateway: types.NamespacedName{Namespace: DefaultGatewayNamespace, Name: DefaultGatewayName},
+		},
+		{
+			name:        "per-namespace",
+			mode:        "per-namespace",
+			wantGateway: types.NamespacedName{Namespace: "team-a-gateway", Name: "team-a-gateway"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:        tt.mode,
+						GatewayAnnotationsFlag: "networking.istio.io/service-type=ClusterIP",
+						GatewayLabelsFlag:      "istio.io/rev=stable,team=platform",
+					},
+				},
+			}).(*Provider)
+			provider.storage = newResourceStorage()
+
+			pathType := networkingv1.PathTypePrefix
+			provider.storage.Ingresses[types.NamespacedName{Namespace: "team-a", Name: "web"}] = &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: ptr.To(NginxIngressClass),
+					Rules: []networkingv1.IngressRule{{
+						Host: "team-a.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "web",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								}},
+							},
+						},
+					}},
+				},
+			}
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			gateway, ok := gatewayResources.Gateways[tt.wantGateway]
+			if !ok {
+				t.Fatalf("expected Gateway %s, got %v", tt.wantGateway, gatewayResources.Gateways)
+			}
+			wantAnnotations := map[string]string{"networking.istio.io/service-type": "ClusterIP"}
+			if !reflect.DeepEqual(gateway.Annotations, wantAnnotations) {
+				t.Errorf("Gateway annotations = %v, want %v", gateway.Annotations, wantAnnotations)
+			}
+			wantLabels := map[string]string{"istio.io/rev": "stable", "team": "platform"}
+			if !reflect.DeepEqual(gateway.Labels, wantLabels) {
+				t.Errorf("Gateway labels = %v, want %v", gateway.Labels, wantLabels)
+			}
+		})
+	}
+}
+
+func TestToGatewayResources_KeepOriginalGatewayNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		wantGateways map[string]types.NamespacedName
+	}{
+		{
+			name: "centralized",
+			mode: "centralized",
+			wantGateways: map[string]types.NamespacedName{
+				"team-a": {Namespace: DefaultGatewayNamespace, Name: NginxIngressClass},
+				"team-b": {Namespace: DefaultGatewayNamespace, Name: NginxIngressClass},
+			},
+		},
+		{
+			name: "per-namespace",
+			mode: "per-namespace",
+			wantGateways: map[string]types.NamespacedName{
+				"team-a": {Namespace: "team-a-gateway", Name: NginxIngressClass},
+				"team-b": {Namespace: "team-b-gateway", Name: NginxIngressClass},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(&i2gw.ProviderConf{
+				ProviderSpecificFlags: map[string]map[string]string{
+					Name: {
+						GatewayModeFlag:              tt.mode,
+						KeepOriginalGatewayNamesFlag: "true",
+					},
+				},
+			}).(*Provider)
+			provider.storage = newResourceStorage()
+
+			pathType := networkingv1.PathTypePrefix
+			for _, namespace := range []string{"team-a", "team-b"} {
+				provider.storage.Ingresses[types.NamespacedName{Namespace: namespace, Name: "web"}] = &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"},
+					Spec: networkingv1.IngressSpec{
+						IngressClassName: ptr.To(NginxIngressClass),
+						Rules: []networkingv1.IngressRule{{
+							Host: namespace + ".example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{{
+										Path:     "/",
+										PathType: &pathType,
+										Backend: networkingv1.IngressBackend{
+											Service: &networkingv1.IngressServiceBackend{
+												Name: "web",
+												Port: networkingv1.ServiceBackendPort{Number: 80},
+											},
+										},
+									}},
+								},
+							},
+						}},
+					},
+				}
+			}
+
+			ir, errs := provider.ToIR()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors converting to IR: %v", errs)
+			}
+			gatewayResources, errs := provider.ToGatewayResources(ir)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			wantKeys := sets.New[types.NamespacedName]()
+			for _, key := range tt.wantGateways {
+				wantKeys.Insert(key)
+			}
+			gotKeys := sets.KeySet(gatewayResources.Gateways)
+			if !gotKeys.Equal(wantKeys) {
+				t.Errorf("Gateways = %v, want %v", gotKeys.UnsortedList(), wantKeys.UnsortedList())
+			}
+
+			for routeKey, route := range gatewayResources.HTTPRoutes {
+				want := tt.wantGateways[routeKey.Namespace]
+				for _, parentRef := range route.Spec.ParentRefs {
+					if parentRef.Namespace == nil || string(*parentRef.Namespace) != want.Namespace || string(parentRef.Name) != want.Name {
+						t.Errorf("HTTPRoute %s: parentRef = %v/%s, want %s", routeKey, parentRef.Namespace, parentRef.Name, want)
+					}
+				}
+			}
+		})
+	}
+}